@@ -1,4 +1,4 @@
 #!/bin/bash
-# Creates the database tables and seeds them with sample data
+# Migrates the database schema to the version expected by the back-end service and seeds it with sample data
 . vars.sh
-psql -a -q -f migrate.sql $DB_URL
+cd service && go run main.go migrate up --seed
@@ -1,9 +1,26 @@
 package cmd
 
 import (
+	"database/sql"
+	"fmt"
 	"os"
+	"strings"
+	"syscall"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/dberror"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/memory"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/postgres"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/sqlite"
+	"github.com/LeviMatus/readcommend/service/internal/metrics"
+	"github.com/LeviMatus/readcommend/service/internal/tracing"
 
 	"github.com/LeviMatus/readcommend/service/pkg/config"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -31,6 +48,30 @@ const (
 
 	// ExitServing indicates that a fatal error took place while serving.
 	ExitServing
+
+	// ExitSearching indicates that a fatal error took place while searching for, or printing, books.
+	ExitSearching
+
+	// ExitMigrating indicates that a fatal error took place while migrating or seeding the database.
+	ExitMigrating
+
+	// ExitSchema indicates that the schema of the database is behind the version required by the binary.
+	ExitSchema
+
+	// ExitImporting indicates that a catalog could not be imported, such as when any of its records is invalid.
+	ExitImporting
+
+	// ExitExporting indicates that a fatal error took place while exporting the catalog.
+	ExitExporting
+
+	// ExitSeeding indicates that a synthetic catalog could not be generated or inserted into the database.
+	ExitSeeding
+
+	// ExitShutdown indicates that the server did not drain its in-flight requests before the shutdown deadline.
+	ExitShutdown
+
+	// ExitCleanup indicates that the database or the logger could not be closed once the command was done.
+	ExitCleanup
 )
 
 // Exit calls the appropriate exit code on the ExitCode type.
@@ -38,8 +79,276 @@ func (e ExitCode) Exit() {
 	os.Exit(int(e))
 }
 
+// The databases which the repositories can be backed by.
+const (
+	driverPostgres = "postgres"
+	driverSQLite   = "sqlite"
+)
+
 var (
 	cfg        config.Config
 	configFile string
 	logger     *zap.Logger
+
+	// logLevel is the level of the logger, which /log/level changes.
+	logLevel zap.AtomicLevel
 )
+
+// syncLogger flushes the logger. Terminals cannot be synced, so the errors syncing stdout or stderr when they
+// are terminals are ignored.
+func syncLogger() error {
+	err := logger.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) {
+		return nil
+	}
+	return err
+}
+
+// openDatabase connects to the database described by the config and verifies the connection. Its statements are
+// traced once tracing is set up. If either fails, or the configured driver is unknown, then the error is logged
+// and the CLI exits.
+func openDatabase() *sql.DB {
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch cfg.Database.Driver {
+	case driverPostgres:
+		conStr := fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Database.Host, cfg.Database.Port, cfg.Database.Username, cfg.Database.Password, cfg.Database.Database, cfg.Database.SSL)
+
+		if cfg.Database.Schema != "" {
+			conStr = fmt.Sprintf("%s search_path=%s", conStr, cfg.Database.Schema)
+		}
+
+		db, err = tracing.OpenDB("postgres", conStr, tracing.SystemPostgres)
+	case driverSQLite:
+		db, err = tracing.OpenDB(sqlite.DriverName, sqlite.DSN(cfg.Database.File), tracing.SystemSQLite)
+	default:
+		logger.Error(fmt.Sprintf("invalid database driver %q: must be %q or %q",
+			cfg.Database.Driver, driverPostgres, driverSQLite))
+		ExitConfigSetup.Exit()
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to connect to database: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	if err := db.Ping(); err != nil {
+		logger.Error(fmt.Sprintf("unable to verify DB connection: %s", err))
+		ExitRequirements.Exit()
+	}
+	logger.Info("database connection established")
+	return db
+}
+
+// repositories holds a repository for every entity, all backed by the same database or memory Store.
+type repositories struct {
+	books   book.Repository
+	authors author.Repository
+	genres  genre.Repository
+	eras    era.Repository
+	sizes   size.Repository
+}
+
+// newRepositories creates a repository for every entity, all backed by the database of the configured
+// driver, which openDatabase has connected to. The errors of the database are translated into domain errors. If
+// any of them cannot be created, then the error is logged and the CLI exits.
+func newRepositories(db *sql.DB) repositories {
+	if cfg.Database.Driver == driverSQLite {
+		return newSQLiteRepositories(db).translated(sqlite.TranslateError)
+	}
+	return newPostgresRepositories(db).translated(postgres.TranslateError)
+}
+
+// newPostgresRepositories creates a repository for every entity, all backed by the Postgres database. If any
+// of them cannot be created, then the error is logged and the CLI exits.
+func newPostgresRepositories(db *sql.DB) repositories {
+	bookRepo, err := postgres.NewBookRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Book repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	authorRepo, err := postgres.NewAuthorRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Author repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	genreRepo, err := postgres.NewGenreRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Genre repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	eraRepo, err := postgres.NewEraRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Era repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	sizeRepo, err := postgres.NewSizeRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Size repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	return repositories{books: bookRepo, authors: authorRepo, genres: genreRepo, eras: eraRepo, sizes: sizeRepo}
+}
+
+// newSQLiteRepositories creates a repository for every entity, all backed by the SQLite database. If any
+// of them cannot be created, then the error is logged and the CLI exits.
+func newSQLiteRepositories(db *sql.DB) repositories {
+	bookRepo, err := sqlite.NewBookRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Book repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	authorRepo, err := sqlite.NewAuthorRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Author repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	genreRepo, err := sqlite.NewGenreRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Genre repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	eraRepo, err := sqlite.NewEraRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Era repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	sizeRepo, err := sqlite.NewSizeRepository(db, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Size repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	return repositories{books: bookRepo, authors: authorRepo, genres: genreRepo, eras: eraRepo, sizes: sizeRepo}
+}
+
+// newMemoryRepositories creates a repository for every entity, all backed by a memory Store. The Store is
+// seeded with the configured fixture or, if there is none, with the migrations and seed data of the postgres
+// store. If the Store cannot be seeded or any repository cannot be created, then the error is logged and the
+// CLI exits.
+func newMemoryRepositories() repositories {
+	var (
+		fixture memory.Fixture
+		err     error
+	)
+	if cfg.Store.Fixture != "" {
+		fixture, err = memory.LoadFixture(cfg.Store.Fixture)
+	} else {
+		fixture, err = postgresFixture()
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to read memory store fixture: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	store := memory.NewStore()
+	if err := store.Load(fixture); err != nil {
+		logger.Error(fmt.Sprintf("unable to seed memory store: %s", err))
+		ExitRequirements.Exit()
+	}
+	logger.Info(fmt.Sprintf("memory store seeded with %d books", len(fixture.Books)))
+
+	bookRepo, err := memory.NewBookRepository(store, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Book repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	authorRepo, err := memory.NewAuthorRepository(store, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Author repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	genreRepo, err := memory.NewGenreRepository(store, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Genre repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	eraRepo, err := memory.NewEraRepository(store, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Era repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	sizeRepo, err := memory.NewSizeRepository(store, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Size repository: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	return repositories{books: bookRepo, authors: authorRepo, genres: genreRepo, eras: eraRepo, sizes: sizeRepo}
+}
+
+// postgresFixture parses the INSERT statements of every migration, followed by those of the seed data, into a
+// memory.Fixture, so that the memory store holds what a migrated and seeded database would.
+func postgresFixture() (memory.Fixture, error) {
+	migrations, err := postgres.Migrations()
+	if err != nil {
+		return memory.Fixture{}, err
+	}
+
+	var script strings.Builder
+	for _, m := range migrations {
+		script.WriteString(m.Up)
+		script.WriteString("\n")
+	}
+	script.WriteString(postgres.SeedSQL())
+
+	return memory.ParseSQL(script.String())
+}
+
+// traced wraps every repository in a decorator which traces its methods.
+func (r repositories) traced() repositories {
+	return repositories{
+		books:   tracing.BookRepository(r.books),
+		authors: tracing.AuthorRepository(r.authors),
+		genres:  tracing.GenreRepository(r.genres),
+		eras:    tracing.EraRepository(r.eras),
+		sizes:   tracing.SizeRepository(r.sizes),
+	}
+}
+
+// translated wraps every repository in a decorator which translates the errors of its database with the
+// dberror.Translator, so that they are reported as timeouts or as the database being unavailable.
+func (r repositories) translated(t dberror.Translator) repositories {
+	return repositories{
+		books:   t.Books(r.books),
+		authors: t.Authors(r.authors),
+		genres:  t.Genres(r.genres),
+		eras:    t.Eras(r.eras),
+		sizes:   t.Sizes(r.sizes),
+	}
+}
+
+// instrumented wraps every repository in a decorator which measures its queries with the metrics.
+func (r repositories) instrumented(m *metrics.Repositories) repositories {
+	return repositories{
+		books:   m.Books(r.books),
+		authors: m.Authors(r.authors),
+		genres:  m.Genres(r.genres),
+		eras:    m.Eras(r.eras),
+		sizes:   m.Sizes(r.sizes),
+	}
+}
+
+// bookDriver creates a book.Driver over the repositories, which is bounded by the configured page sizes.
+func (r repositories) bookDriver() book.Driver {
+	return book.NewDriver(r.books, r.authors, r.genres, r.eras, r.sizes, book.Pagination{
+		DefaultLimit: cfg.Search.DefaultPageSize,
+		MaxLimit:     cfg.Search.MaxPageSize,
+	})
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresFixture(t *testing.T) {
+
+	fixture, err := postgresFixture()
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, fixture.Eras)
+	assert.NotEmpty(t, fixture.Sizes)
+	assert.NotEmpty(t, fixture.Genres)
+	assert.NotEmpty(t, fixture.Authors)
+	assert.NotEmpty(t, fixture.Books)
+	assert.NoError(t, memory.NewStore().Load(fixture))
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// exportFormat is the format of the catalog, which is inferred from the extension of the file if it is empty.
+	exportFormat string
+
+	// exportFilters filter the books which are exported, as they filter a search.
+	exportFilters searchFlags
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	attachDatabaseFlags(exportCmd)
+	attachFilterFlags(exportCmd, &exportFilters)
+
+	exportCmd.Flags().StringVar(&exportFilters.sort,
+		"sort",
+		"",
+		`Comma-separated fields to order books by, prefixed with "-" for descending order, such as "-rating,title"`)
+	exportCmd.Flags().Uint64Var(&exportFilters.limit,
+		"limit",
+		0,
+		`The maximum number of books to export (default every book)`)
+	exportCmd.Flags().StringVar(&exportFormat,
+		"format",
+		"",
+		fmt.Sprintf(`The format of the file: %s, %s or %s (default from the file's extension, or %s for stdout)`,
+			catalog.FormatCSV, catalog.FormatJSON, catalog.FormatJSONL, catalog.FormatCSV))
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export books, with their authors and genres, to a CSV, JSON or JSON Lines file",
+	Long: `Export books, with their authors and genres, to a CSV, JSON or JSON Lines file, or to stdout if no file or "-"
+is given. Every book is exported, unless the search flags filter them. The books are read in a single
+repeatable-read transaction, so the export is a consistent snapshot of the catalog, which can be restored
+with ` + "`readcommend import`" + `.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		file := "-"
+		if len(args) > 0 {
+			file = args[0]
+		}
+
+		format := exportFormat
+		if format == "" && file == "-" {
+			format = catalog.FormatCSV
+		}
+		format, err := catalogFormat(format, file)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			ExitConfigSetup.Exit()
+		}
+
+		params, err := exportFilters.searchInput(cmd)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			ExitConfigSetup.Exit()
+		}
+
+		db := openDatabase()
+		defer db.Close()
+
+		var out io.Writer = os.Stdout
+		if file != "-" {
+			f, err := os.Create(file)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "unable to create catalog: %s\n", err)
+				ExitConfigSetup.Exit()
+			}
+			defer f.Close()
+			out = f
+		}
+
+		w, err := catalog.NewWriter(out, format)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			ExitConfigSetup.Exit()
+		}
+
+		var exported int
+		err = newRepositories(db).bookDriver().ExportBooks(context.Background(), params, func(b entity.Book) error {
+			exported++
+			return w.Write(b)
+		})
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to export books: %s\n", err)
+
+			// A partial catalog would be mistaken for a snapshot, so it is not left behind.
+			if file != "-" {
+				_ = os.Remove(file)
+			}
+			ExitExporting.Exit()
+		}
+
+		if file != "-" {
+			_, _ = fmt.Fprintf(os.Stderr, "exported %d books to %s\n", exported, file)
+		}
+	},
+}
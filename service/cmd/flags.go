@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -16,6 +18,14 @@ var (
 // attachDatabaseFlags can be used by more commands in the future. It attaches all database param flags
 // to a specified command.
 func attachDatabaseFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&cfg.Database.Driver,
+		"db-driver",
+		driverPostgres,
+		`The database to use, either "postgres" or "sqlite" (default "postgres")`)
+	cmd.Flags().StringVar(&cfg.Database.File,
+		"db-file",
+		"readcommend.db",
+		`The file of the SQLite database, which is created if it does not exist (default "readcommend.db")`)
 	cmd.Flags().StringVar(&cfg.Database.Host,
 		"db-host",
 		"localhost",
@@ -45,12 +55,52 @@ func attachDatabaseFlags(cmd *cobra.Command) {
 		false,
 		`Prompt for the database password, if true`)
 
-	viper.BindPFlag("database.host", cmd.Flag("db-host"))
-	viper.BindPFlag("database.port", cmd.Flag("db-port"))
-	viper.BindPFlag("database.name", cmd.Flag("db-name"))
-	viper.BindPFlag("database.schema", cmd.Flag("db-schema"))
-	viper.BindPFlag("database.ssl-mode", cmd.Flag("db-ssl-mode"))
-	viper.BindPFlag("database.username", cmd.Flag("db-username"))
+	bindConfig(cmd, "database.driver", "db-driver")
+	bindConfig(cmd, "database.file", "db-file")
+	bindConfig(cmd, "database.host", "db-host")
+	bindConfig(cmd, "database.port", "db-port")
+	bindConfig(cmd, "database.name", "db-name")
+	bindConfig(cmd, "database.schema", "db-schema")
+	bindConfig(cmd, "database.ssl-mode", "db-ssl-mode")
+	bindConfig(cmd, "database.username", "db-username")
+}
+
+// configKey is the annotation of a flag which holds the key of the config value that the flag sets.
+const configKey = "config-key"
+
+// bindConfig marks the named flag of the command as setting the config value with the given key. The flag is
+// only bound to the config by bindFlags, once it is known which command is being executed, since several
+// commands share the same flags and config keys.
+func bindConfig(cmd *cobra.Command, key, name string) {
+	flags := cmd.Flags()
+	if flags.Lookup(name) == nil {
+		flags = cmd.PersistentFlags()
+	}
+	_ = flags.SetAnnotation(name, configKey, []string{key})
+}
+
+// bindFlags binds every flag of the command which was marked by bindConfig to its config value.
+func bindFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if keys, ok := f.Annotations[configKey]; ok {
+			_ = viper.BindPFlag(keys[0], f)
+		}
+	})
+}
+
+// attachSearchFlags attaches the flags which bound book searches to a specified command.
+func attachSearchFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64Var(&cfg.Search.DefaultPageSize,
+		"search-default-page-size",
+		book.DefaultPageSize,
+		fmt.Sprintf(`The number of books returned when no limit is requested (default %d)`, book.DefaultPageSize))
+	cmd.Flags().Uint64Var(&cfg.Search.MaxPageSize,
+		"search-max-page-size",
+		book.MaxPageSize,
+		fmt.Sprintf(`The maximum number of books returned for a single request (default %d)`, book.MaxPageSize))
+
+	bindConfig(cmd, "search.default-page-size", "search-default-page-size")
+	bindConfig(cmd, "search.max-page-size", "search-max-page-size")
 }
 
 // validatePassword will prompt for a user-provided password interactively.
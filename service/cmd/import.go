@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/postgres"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/sqlite"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// importFormat is the format of the catalog, which is inferred from the extension of the file if it is empty.
+	importFormat string
+
+	// importOptions are the catalog.Options of the import.
+	importOptions catalog.Options
+)
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	attachDatabaseFlags(importCmd)
+
+	importCmd.Flags().StringVar(&importFormat,
+		"format",
+		"",
+		fmt.Sprintf(`The format of the file: %s, %s or %s (default from the file's extension)`, catalog.FormatCSV, catalog.FormatJSON, catalog.FormatJSONL))
+	importCmd.Flags().BoolVar(&importOptions.DryRun,
+		"dry-run",
+		false,
+		`Validate every record and report the invalid ones, without writing anything`)
+	importCmd.Flags().BoolVar(&importOptions.Upsert,
+		"upsert",
+		false,
+		`Update books with the same title and author, rather than inserting them again`)
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import books, authors and genres in bulk from a CSV, JSON or JSON Lines file",
+	Long: `Import books, authors and genres in bulk from a CSV, JSON or JSON Lines file, or from stdin if the file is "-".
+Authors and genres are matched by name, and created if they do not exist. The file is imported in a single
+transaction: if any record is invalid, then every invalid record is reported with its line number and
+nothing is imported.
+
+CSV files have a header row naming their columns, of which title, author_first_name, author_last_name,
+genre, year_published, pages and rating are required. JSON files hold an array of books, and JSON Lines files
+a book per line, shaped as the API shapes them. Catalogs written by ` + "`readcommend export`" + ` can be imported as they are.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		format, err := catalogFormat(importFormat, args[0])
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			ExitConfigSetup.Exit()
+		}
+
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "unable to open catalog: %s\n", err)
+				ExitConfigSetup.Exit()
+			}
+			defer f.Close()
+			in = f
+		}
+
+		reader, err := catalog.NewReader(in, format)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to read catalog: %s\n", err)
+			ExitImporting.Exit()
+		}
+
+		// A dry run only validates the catalog, so it does not need a database.
+		var repo catalog.Repository
+		if !importOptions.DryRun {
+			db := openDatabase()
+			defer db.Close()
+			repo = newCatalogRepository(db)
+		}
+
+		summary, err := catalog.NewDriver(repo).Import(context.Background(), reader, importOptions)
+		for _, lineErr := range summary.Errors {
+			_, _ = fmt.Fprintln(os.Stderr, lineErr)
+		}
+
+		var importErr *catalog.ImportError
+		switch {
+		case errors.As(err, &importErr):
+			_, _ = fmt.Fprintf(os.Stderr, "%d records are invalid, so nothing was imported\n", len(importErr.Lines))
+			ExitImporting.Exit()
+		case err != nil:
+			_, _ = fmt.Fprintf(os.Stderr, "unable to import catalog: %s\n", err)
+			ExitImporting.Exit()
+		}
+
+		printImportSummary(os.Stdout, summary, importOptions)
+	},
+}
+
+// catalogFormat returns the format of the catalog file. If no format was given, then it is inferred from the
+// extension of the file.
+func catalogFormat(format, file string) (string, error) {
+	switch format {
+	case catalog.FormatCSV, catalog.FormatJSON, catalog.FormatJSONL:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("format is %q but should be %s, %s or %s",
+			format, catalog.FormatCSV, catalog.FormatJSON, catalog.FormatJSONL)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return catalog.FormatCSV, nil
+	case ".json":
+		return catalog.FormatJSON, nil
+	case ".jsonl", ".ndjson":
+		return catalog.FormatJSONL, nil
+	}
+	return "", fmt.Errorf("unable to infer the format of %s; use --format %s, %s or %s",
+		file, catalog.FormatCSV, catalog.FormatJSON, catalog.FormatJSONL)
+}
+
+// printImportSummary prints what an import did, or would have done if it was a dry run.
+func printImportSummary(w io.Writer, summary catalog.Summary, opts catalog.Options) {
+	if opts.DryRun {
+		_, _ = fmt.Fprintf(w, "%d records are valid; nothing was imported\n", summary.Records)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "imported %d records: %d books created, %d updated, %d authors created, %d genres created\n",
+		summary.Records, summary.Created, summary.Updated, summary.AuthorsCreated, summary.GenresCreated)
+}
+
+// newCatalogRepository creates the catalog.Repository of the configured driver over the database. If it
+// cannot be created, then the error is logged and the CLI exits.
+func newCatalogRepository(db *sql.DB) catalog.Repository {
+	var (
+		repo catalog.Repository
+		err  error
+	)
+	if cfg.Database.Driver == driverSQLite {
+		repo, err = sqlite.NewCatalogRepository(db, logger)
+	} else {
+		repo, err = postgres.NewCatalogRepository(db, logger)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create Catalog repository: %s", err))
+		ExitRequirements.Exit()
+	}
+	return repo
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogFormat(t *testing.T) {
+
+	tests := map[string]struct {
+		format       string
+		file         string
+		expect       string
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"format is given": {
+			format:       catalog.FormatJSONL,
+			file:         "books.csv",
+			expect:       catalog.FormatJSONL,
+			errAssertion: assert.NoError,
+		},
+		"unknown format": {
+			format:       "xml",
+			file:         "books.xml",
+			errAssertion: assert.Error,
+		},
+		"csv extension": {
+			file:         "books.CSV",
+			expect:       catalog.FormatCSV,
+			errAssertion: assert.NoError,
+		},
+		"json extension": {
+			file:         "/tmp/books.json",
+			expect:       catalog.FormatJSON,
+			errAssertion: assert.NoError,
+		},
+		"ndjson extension": {
+			file:         "books.ndjson",
+			expect:       catalog.FormatJSONL,
+			errAssertion: assert.NoError,
+		},
+		"unknown extension": {
+			file:         "books.txt",
+			errAssertion: assert.Error,
+		},
+		"stdin": {
+			file:         "-",
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := catalogFormat(tt.format, tt.file)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
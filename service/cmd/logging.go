@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/pkg/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// The encodings which the logs can be written in.
+const (
+	logEncodingJSON    = "json"
+	logEncodingConsole = "console"
+)
+
+// newLogger creates a logger, which writes to stderr, as the config describes. Its level is the returned
+// zap.AtomicLevel, which can be changed while the logger is in use.
+func newLogger(c config.Log) (*zap.Logger, zap.AtomicLevel, error) {
+	level := zap.NewAtomicLevel()
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return nil, level, fmt.Errorf("invalid log level %q: %w", c.Level, err)
+	}
+
+	zc := zap.NewProductionConfig()
+	zc.Level = level
+	switch c.Encoding {
+	case logEncodingJSON:
+	case logEncodingConsole:
+		zc.Encoding = logEncodingConsole
+		zc.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	default:
+		return nil, level, fmt.Errorf("invalid log encoding %q: must be %q or %q", c.Encoding, logEncodingJSON, logEncodingConsole)
+	}
+
+	zc.Sampling = nil
+	if c.SamplingInitial > 0 {
+		if c.SamplingThereafter < 1 {
+			return nil, level, fmt.Errorf("invalid log sampling: every %d-th log cannot be written", c.SamplingThereafter)
+		}
+		zc.Sampling = &zap.SamplingConfig{Initial: c.SamplingInitial, Thereafter: c.SamplingThereafter}
+	}
+
+	logger, err := zc.Build()
+	if err != nil {
+		return nil, level, err
+	}
+	return logger, level, nil
+}
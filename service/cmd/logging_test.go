@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := map[string]struct {
+		cfg   config.Log
+		level zapcore.Level
+		err   bool
+	}{
+		"json": {
+			cfg:   config.Log{Level: "info", Encoding: "json", SamplingInitial: 100, SamplingThereafter: 100},
+			level: zapcore.InfoLevel,
+		},
+		"console without sampling": {
+			cfg:   config.Log{Level: "debug", Encoding: "console"},
+			level: zapcore.DebugLevel,
+		},
+		"invalid level": {
+			cfg: config.Log{Level: "loud", Encoding: "json"},
+			err: true,
+		},
+		"invalid encoding": {
+			cfg: config.Log{Level: "info", Encoding: "xml"},
+			err: true,
+		},
+		"invalid sampling": {
+			cfg: config.Log{Level: "info", Encoding: "json", SamplingInitial: 100},
+			err: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			logger, level, err := newLogger(test.cfg)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.level, level.Level())
+
+			// The logger's level follows the atomic level.
+			level.SetLevel(zapcore.ErrorLevel)
+			assert.False(t, logger.Core().Enabled(zapcore.WarnLevel))
+			level.SetLevel(zapcore.DebugLevel)
+			assert.True(t, logger.Core().Enabled(zapcore.DebugLevel))
+		})
+	}
+}
+
+func TestLogSamplingDefault(t *testing.T) {
+	// Every access log has the same message, so sampling by default would drop most requests under load.
+	flag := rootCmd.PersistentFlags().Lookup("log-sampling-initial")
+	require.NotNil(t, flag)
+	assert.Equal(t, "0", flag.DefValue)
+}
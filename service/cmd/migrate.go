@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/migration"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/postgres"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/sqlite"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// migrateSeed seeds the database with sample data once it has been migrated up.
+	migrateSeed bool
+
+	// migrateSteps is the number of migrations rolled back by migrate down.
+	migrateSteps int
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateToCmd)
+
+	for _, cmd := range migrateCmd.Commands() {
+		attachDatabaseFlags(cmd)
+	}
+
+	migrateUpCmd.Flags().BoolVar(&migrateSeed,
+		"seed",
+		false,
+		`Seed the database with sample genres, authors and books once it has been migrated`)
+	migrateDownCmd.Flags().IntVar(&migrateSteps,
+		"steps",
+		1,
+		`The number of migrations to roll back (default 1)`)
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the schema of the readcommend database",
+	Long: `Manage the schema of the readcommend database. The migrations are built into the binary, and the
+version of every applied migration is recorded in the database. On postgres, migrations are run
+while holding an advisory lock, so concurrent deploys migrate one after the other.`,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply every migration which has not been applied yet",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		m, db := newMigrator()
+		defer db.Close()
+
+		applied, err := m.Up(context.Background())
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to migrate up: %s", err))
+			ExitMigrating.Exit()
+		}
+		printMigrations("applied", applied)
+
+		if migrateSeed {
+			if err := m.Seed(context.Background()); err != nil {
+				logger.Error(fmt.Sprintf("unable to seed the database: %s", err))
+				ExitMigrating.Exit()
+			}
+			fmt.Println("seeded sample data")
+		}
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Roll back the latest applied migrations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		m, db := newMigrator()
+		defer db.Close()
+
+		rolledBack, err := m.Down(context.Background(), migrateSteps)
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to migrate down: %s", err))
+			ExitMigrating.Exit()
+		}
+		printMigrations("rolled back", rolledBack)
+	},
+}
+
+var migrateToCmd = &cobra.Command{
+	Use:   "to <version>",
+	Short: "Apply or roll back migrations until the given version is the latest applied one",
+	Long: `Apply or roll back migrations until the given version is the latest applied one. Version 0 rolls
+back every migration.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < 0 {
+			_, _ = fmt.Fprintf(os.Stderr, "version is %q but should be a migration's version, or 0\n", args[0])
+			ExitConfigSetup.Exit()
+		}
+
+		m, db := newMigrator()
+		defer db.Close()
+
+		run, err := m.To(context.Background(), version)
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to migrate to version %d: %s", version, err))
+			ExitMigrating.Exit()
+		}
+		printMigrations("ran", run)
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print every migration and when it was applied",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		m, db := newMigrator()
+		defer db.Close()
+
+		statuses, err := m.Status(context.Background())
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to get the migration status: %s", err))
+			ExitMigrating.Exit()
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		_ = tw.Flush()
+	},
+}
+
+// newMigrator connects to the database and creates a migrator for it. The database is returned so that it
+// can be closed once the command is done.
+func newMigrator() (migrator, *sql.DB) {
+	db := openDatabase()
+	return databaseMigrator(db), db
+}
+
+// databaseMigrator creates a migrator which applies the migrations of the configured driver to the database.
+// If it cannot be created, then the error is logged and the CLI exits.
+func databaseMigrator(db *sql.DB) migrator {
+	var (
+		m   *migration.Migrator
+		err error
+	)
+	if cfg.Database.Driver == driverSQLite {
+		m, err = sqlite.NewMigrator(db, logger)
+	} else {
+		m, err = postgres.NewMigrator(db, logger)
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to create migrator: %s", err))
+		ExitRequirements.Exit()
+	}
+	return m
+}
+
+// migrator is satisfied by the migration.Migrator of every database.
+type migrator interface {
+	SchemaVersion() int
+	Version(ctx context.Context) (int, error)
+	Status(ctx context.Context) ([]migration.Status, error)
+	Up(ctx context.Context) ([]migration.Migration, error)
+	Down(ctx context.Context, steps int) ([]migration.Migration, error)
+	To(ctx context.Context, version int) ([]migration.Migration, error)
+	Seed(ctx context.Context) error
+}
+
+// printMigrations prints the migrations which were run, and what was done to them.
+func printMigrations(action string, migrations []migration.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("the schema is already at the requested version")
+		return
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %d_%s\n", action, m.Version, m.Name)
+	}
+}
+
+// checkSchema exits if the schema of the database is behind the version which the binary expects, since the
+// repositories would fail on it.
+func checkSchema(m migrator) {
+	expected := m.SchemaVersion()
+
+	version, err := m.Version(context.Background())
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to check the schema version: %s", err))
+		ExitRequirements.Exit()
+	}
+
+	if version < expected {
+		logger.Error(fmt.Sprintf(
+			"the schema is at version %d but version %d is required; run `readcommend migrate up` first",
+			version, expected))
+		ExitSchema.Exit()
+	}
+}
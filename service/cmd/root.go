@@ -9,30 +9,51 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 )
 
-func init() {
-	cobra.OnInitialize(setupConfig)
-}
-
 var rootCmd = &cobra.Command{
 	Use:   "readcommend",
 	Short: "Backend tooling for human bookworms",
 	Long: `Tooling and supporting backend API for interfacing with the readcommend backing database.
 				use this to query for books directly or standup an application server.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		bindFlags(cmd)
+		setupConfig()
+
 		var err error
-		// for simplicity, I'm just using a default zap production logger.
-		logger, err = zap.NewProduction()
+		logger, logLevel, err = newLogger(cfg.Log)
 		if err != nil {
-			_, _ = fmt.Fprintf(os.Stderr, "cannot setup logger: %s", err)
-			ExitRequirements.Exit()
+			_, _ = fmt.Fprintf(os.Stderr, "cannot setup logger: %s\n", err)
+			ExitConfigSetup.Exit()
 		}
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&cfg.Log.Level,
+		"log-level",
+		"info",
+		`The minimum level of the logs: "debug", "info", "warn" or "error" (default "info")`)
+	rootCmd.PersistentFlags().StringVar(&cfg.Log.Encoding,
+		"log-encoding",
+		logEncodingJSON,
+		fmt.Sprintf(`The encoding of the logs, either %q or %q (default %q)`, logEncodingJSON, logEncodingConsole, logEncodingJSON))
+	rootCmd.PersistentFlags().IntVar(&cfg.Log.SamplingInitial,
+		"log-sampling-initial",
+		0,
+		`Of the logs with the same level and message each second, how many are written before sampling, or 0 to write every log. Access logs share one message, so sampling drops requests too (default 0)`)
+	rootCmd.PersistentFlags().IntVar(&cfg.Log.SamplingThereafter,
+		"log-sampling-thereafter",
+		100,
+		`Once sampling, write every this many logs with the same level and message each second (default 100)`)
+
+	bindConfig(rootCmd, "log.level", "log-level")
+	bindConfig(rootCmd, "log.encoding", "log-encoding")
+	bindConfig(rootCmd, "log.sampling-initial", "log-sampling-initial")
+	bindConfig(rootCmd, "log.sampling-thereafter", "log-sampling-thereafter")
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err)
@@ -78,7 +99,8 @@ func setupConfig() {
 	if err := viper.MergeInConfig(); err != nil {
 		switch err.(type) {
 		case viper.ConfigFileNotFoundError:
-			fmt.Printf("user's config file %s was not found, but will try continuing anyway...", configFile)
+			// This is written to stderr so that it does not mix with the output of commands such as search.
+			_, _ = fmt.Fprintf(os.Stderr, "user's config file %s was not found, but will try continuing anyway...\n", configFile)
 		default:
 			_, _ = fmt.Fprintln(os.Stderr, err)
 			ExitConfigSetup.Exit()
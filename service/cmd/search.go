@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// outputTable prints books as a table with aligned columns.
+	outputTable = "table"
+
+	// outputJSON prints books as a JSON array, shaped as they are by the API.
+	outputJSON = catalog.FormatJSON
+
+	// outputCSV prints books as CSV, with a header row.
+	outputCSV = catalog.FormatCSV
+)
+
+// searchFlags holds the values of the flags of the search command. They mirror the query parameters of a
+// BookRequest, and are only applied to the search when they are set.
+type searchFlags struct {
+	title     string
+	query     string
+	authorIDs []int32
+	genreIDs  []int32
+	eraIDs    []int32
+	sizeIDs   []int32
+	minPages  int16
+	maxPages  int16
+	minYear   int16
+	maxYear   int16
+	pages     string
+	year      string
+	limit     uint64
+	sort      string
+	cursor    string
+	output    string
+}
+
+var search searchFlags
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	attachDatabaseFlags(searchCmd)
+	attachSearchFlags(searchCmd)
+	attachFilterFlags(searchCmd, &search)
+
+	searchCmd.Flags().Uint64Var(&search.limit,
+		"limit",
+		0,
+		`The maximum number of books to print (default search-default-page-size)`)
+	searchCmd.Flags().StringVar(&search.sort,
+		"sort",
+		"",
+		`Comma-separated fields to order books by, prefixed with "-" for descending order, such as "-rating,title"`)
+	searchCmd.Flags().StringVar(&search.cursor,
+		"cursor",
+		"",
+		`Continue a previous search which had more books, with the same flags`)
+	searchCmd.Flags().StringVarP(&search.output,
+		"output",
+		"o",
+		outputTable,
+		fmt.Sprintf(`The format to print books in: %s, %s or %s`, outputTable, outputJSON, outputCSV))
+}
+
+// attachFilterFlags registers the flags which filter the books of a book.SearchInput on the command, and binds
+// them to the searchFlags.
+func attachFilterFlags(cmd *cobra.Command, f *searchFlags) {
+	cmd.Flags().StringVar(&f.title,
+		"title",
+		"",
+		`Only include books with exactly this title`)
+	cmd.Flags().StringVarP(&f.query,
+		"query",
+		"q",
+		"",
+		`Search the titles and author names of books, including close matches`)
+	cmd.Flags().Int32SliceVar(&f.authorIDs,
+		"authors",
+		nil,
+		`Only include books by any of these author IDs, such as "1,2"`)
+	cmd.Flags().Int32SliceVar(&f.genreIDs,
+		"genres",
+		nil,
+		`Only include books in any of these genre IDs, such as "1,2"`)
+	cmd.Flags().Int32SliceVar(&f.eraIDs,
+		"eras",
+		nil,
+		`Only include books published within any of these era IDs, such as "1,2"`)
+	cmd.Flags().Int32SliceVar(&f.sizeIDs,
+		"sizes",
+		nil,
+		`Only include books whose page count falls within any of these size IDs, such as "1,2"`)
+	cmd.Flags().Int16Var(&f.minPages,
+		"min-pages",
+		0,
+		`Only include books with at least this many pages`)
+	cmd.Flags().Int16Var(&f.maxPages,
+		"max-pages",
+		0,
+		`Only include books with at most this many pages`)
+	cmd.Flags().Int16Var(&f.minYear,
+		"min-year",
+		0,
+		`Only include books published in or after this year`)
+	cmd.Flags().Int16Var(&f.maxYear,
+		"max-year",
+		0,
+		`Only include books published in or before this year`)
+	cmd.Flags().StringVar(&f.pages,
+		"pages",
+		"",
+		`Only include books whose page count is within this range, such as "100..300", "100.." or "..300"`)
+	cmd.Flags().StringVar(&f.year,
+		"year",
+		"",
+		`Only include books published within this range of years, such as "1970..1980", "1970.." or "..1980"`)
+}
+
+var searchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search for books in the readcommend database",
+	Long: `Search for books in the readcommend database, exactly as the API's /books endpoint does, and print them.
+If more books match than were printed, then the cursor to continue the search with is written to stderr.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		params, err := search.searchInput(cmd)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			ExitConfigSetup.Exit()
+		}
+
+		if !validOutput(search.output) {
+			_, _ = fmt.Fprintf(os.Stderr, "output is %q but should be one of %s, %s or %s\n",
+				search.output, outputTable, outputJSON, outputCSV)
+			ExitConfigSetup.Exit()
+		}
+
+		db := openDatabase()
+		defer db.Close()
+
+		page, err := newRepositories(db).bookDriver().SearchBooks(context.Background(), params)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to search for books: %s\n", err)
+			ExitSearching.Exit()
+		}
+
+		if err := writeBooks(os.Stdout, search.output, page.Books); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to print books: %s\n", err)
+			ExitSearching.Exit()
+		}
+
+		if page.HasMore {
+			_, _ = fmt.Fprintf(os.Stderr, "more books match; continue with --cursor %s\n", page.NextCursor.Encode())
+		}
+	},
+}
+
+// errInvalidSearchFlag is the kind of the *entity.ValidationError listing the invalid flags of a search.
+var errInvalidSearchFlag = errors.New("invalid search flags")
+
+// searchInput maps the flags which were set on the command to a book.SearchInput. They are validated by
+// book.ValidateSearch, as the query parameters of the API are, so an *entity.ValidationError lists every invalid
+// flag under the name of its query parameter, such as "q" for --query.
+func (f searchFlags) searchInput(cmd *cobra.Command) (book.SearchInput, error) {
+	var params book.SearchParams
+	changed := cmd.Flags().Changed
+	v := validation.New(errInvalidSearchFlag)
+
+	if changed("title") {
+		params.Title = util.StringPtr(f.title)
+	}
+	if changed("query") {
+		params.Query = util.StringPtr(f.query)
+	}
+	params.AuthorIDs = ids(v, "authors", f.authorIDs)
+	params.GenreIDs = ids(v, "genres", f.genreIDs)
+	params.EraIDs = ids(v, "eras", f.eraIDs)
+	params.SizeIDs = ids(v, "sizes", f.sizeIDs)
+
+	bounds := []struct {
+		name  string
+		value int16
+		param **int16
+	}{
+		{"min-pages", f.minPages, &params.MinPages},
+		{"max-pages", f.maxPages, &params.MaxPages},
+		{"min-year", f.minYear, &params.MinYearPublished},
+		{"max-year", f.maxYear, &params.MaxYearPublished},
+	}
+	for _, b := range bounds {
+		if changed(b.name) {
+			*b.param = util.Int16Ptr(b.value)
+		}
+	}
+
+	if changed("pages") {
+		params.Pages = util.StringPtr(f.pages)
+	}
+	if changed("year") {
+		params.Year = util.StringPtr(f.year)
+	}
+	if changed("limit") {
+		params.Limit = util.Uint64Ptr(f.limit)
+	}
+	if changed("sort") {
+		params.Sort = util.StringPtr(f.sort)
+	}
+	if changed("cursor") {
+		params.Cursor = util.StringPtr(f.cursor)
+	}
+
+	input := book.ValidateSearch(v, params)
+	if err := v.Err(); err != nil {
+		return book.SearchInput{}, err
+	}
+	return input, nil
+}
+
+// ids converts the IDs given to the named flag to the int16 IDs of a book.SearchParams. IDs which do not fit
+// are rejected as the API rejects them.
+func ids(v *validation.Validator, name string, in []int32) []int16 {
+	var out []int16
+	for _, id := range in {
+		if !v.Check(id >= math.MinInt16 && id <= math.MaxInt16, name, validation.CodeInvalidType,
+			"should be an integer") {
+			return nil
+		}
+		out = append(out, int16(id))
+	}
+	return out
+}
+
+// validOutput reports whether the output format is one that writeBooks supports.
+func validOutput(output string) bool {
+	switch output {
+	case outputTable, outputJSON, outputCSV:
+		return true
+	}
+	return false
+}
+
+// writeBooks prints the books to w in the output format.
+func writeBooks(w io.Writer, output string, books []entity.Book) error {
+	switch output {
+	case outputJSON, outputCSV:
+		// The output names are those of the catalog formats, so results can be imported elsewhere.
+		cw, err := catalog.NewWriter(w, output)
+		if err != nil {
+			return err
+		}
+		for _, b := range books {
+			if err := cw.Write(b); err != nil {
+				return err
+			}
+		}
+		return cw.Close()
+
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "ID\tTITLE\tAUTHOR\tGENRE\tYEAR\tPAGES\tRATING")
+		for _, b := range books {
+			_, _ = fmt.Fprintf(tw, "%d\t%s\t%s %s\t%s\t%d\t%d\t%.2f\n",
+				b.ID, b.Title, b.Author.FirstName, b.Author.LastName, b.Genre.Title, b.YearPublished, b.Pages, b.Rating)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("output is %q but should be one of %s, %s or %s", output, outputTable, outputJSON, outputCSV)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBooks(t *testing.T) {
+
+	books := []entity.Book{
+		{
+			ID:            1,
+			Title:         "Eye of the World",
+			YearPublished: 1990,
+			Rating:        4.5,
+			Pages:         782,
+			Genre:         entity.Genre{ID: 2, Title: "Fantasy"},
+			Author:        entity.Author{ID: 3, FirstName: "Robert", LastName: "Jordan"},
+		},
+		{
+			ID:            2,
+			Title:         "Dune, Part One",
+			YearPublished: 1965,
+			Rating:        4.25,
+			Pages:         412,
+			Genre:         entity.Genre{ID: 4, Title: "Sci-Fi"},
+			Author:        entity.Author{ID: 5, FirstName: "Frank", LastName: "Herbert"},
+		},
+	}
+
+	tests := map[string]struct {
+		output      string
+		books       []entity.Book
+		expect      string
+		expectError string
+	}{
+		"table": {
+			output: outputTable,
+			books:  books,
+			expect: "ID  TITLE             AUTHOR         GENRE    YEAR  PAGES  RATING\n" +
+				"1   Eye of the World  Robert Jordan  Fantasy  1990  782    4.50\n" +
+				"2   Dune, Part One    Frank Herbert  Sci-Fi   1965  412    4.25\n",
+		},
+		"csv": {
+			output: outputCSV,
+			books:  books,
+			expect: "id,title,author_id,author_first_name,author_last_name,genre_id,genre,year_published,pages,rating\n" +
+				"1,Eye of the World,3,Robert,Jordan,2,Fantasy,1990,782,4.50\n" +
+				"2,\"Dune, Part One\",5,Frank,Herbert,4,Sci-Fi,1965,412,4.25\n",
+		},
+		"json": {
+			output: outputJSON,
+			books:  books[:1],
+			expect: `[
+  {
+    "id": 1,
+    "title": "Eye of the World",
+    "yearPublished": 1990,
+    "rating": 4.5,
+    "pages": 782,
+    "genre": {
+      "id": 2,
+      "title": "Fantasy"
+    },
+    "author": {
+      "id": 3,
+      "firstName": "Robert",
+      "lastName": "Jordan"
+    }
+  }
+]
+`,
+		},
+		"json without books": {
+			output: outputJSON,
+			expect: "[]\n",
+		},
+		"unknown output": {
+			output:      "xml",
+			expectError: `output is "xml" but should be one of table, json or csv`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := writeBooks(&buf, tt.output, tt.books)
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
+
+func TestSearchFlags_SearchInput(t *testing.T) {
+
+	tests := map[string]struct {
+		args         []string
+		expect       book.SearchInput
+		expectFields []entity.FieldError
+	}{
+		"no flags": {},
+		"ranges are shorthands for their bounds": {
+			args: []string{"--pages=100..300", "--year=1970..", "--genres=1,2", "--limit=5"},
+			expect: book.SearchInput{
+				MinPages:         util.Int16Ptr(100),
+				MaxPages:         util.Int16Ptr(300),
+				MinYearPublished: util.Int16Ptr(1970),
+				GenreIDs:         []int16{1, 2},
+				Limit:            util.Uint64Ptr(5),
+			},
+		},
+		"queries are sorted by relevance": {
+			args:   []string{"-q", "dune"},
+			expect: book.SearchInput{Query: util.StringPtr("dune"), Sort: book.RelevanceSort},
+		},
+		"every invalid flag is listed": {
+			args: []string{"--min-pages=0", "--year=1970..1960", "--pages=1..2", "--max-pages=5", "-q", " ",
+				"--limit=0", "--sort=-relevance,-relevance", "--authors=70000"},
+			expectFields: []entity.FieldError{
+				{Field: "authors", Code: validation.CodeInvalidType, Message: "should be an integer"},
+				{Field: "pages", Code: validation.CodeConflict, Message: "cannot be used together with min-pages or max-pages"},
+				{Field: "min-pages", Code: validation.CodeOutOfRange, Message: "is 0 but should be in range [1,10000]"},
+				{Field: "year", Code: validation.CodeMinExceedsMax, Message: "starts at 1970 but should not end before it, at 1960"},
+				{Field: "limit", Code: validation.CodeOutOfRange, Message: "is 0 but should be at least 1"},
+				{Field: "q", Code: validation.CodeBlank, Message: "must not be blank"},
+				{Field: "sort", Code: validation.CodeInvalid, Message: `sort is invalid: "relevance" appears more than once`},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var f searchFlags
+			cmd := &cobra.Command{}
+			attachFilterFlags(cmd, &f)
+			cmd.Flags().Uint64Var(&f.limit, "limit", 0, "")
+			cmd.Flags().StringVar(&f.sort, "sort", "", "")
+			cmd.Flags().StringVar(&f.cursor, "cursor", "", "")
+			require.NoError(t, cmd.ParseFlags(tt.args))
+
+			actual, err := f.searchInput(cmd)
+			if tt.expectFields == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expect, actual)
+				return
+			}
+
+			var validationErr *entity.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.expectFields, validationErr.Fields)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/spf13/cobra"
+)
+
+// seedOptions are the catalog.GenerateOptions of the synthetic catalog. Its ranges are those of the eras and
+// sizes in the database.
+var seedOptions catalog.GenerateOptions
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	attachDatabaseFlags(seedCmd)
+
+	seedCmd.Flags().IntVar(&seedOptions.Books,
+		"books",
+		10000,
+		`The number of books to generate`)
+	seedCmd.Flags().IntVar(&seedOptions.Authors,
+		"authors",
+		1000,
+		`The number of authors to generate, each of which has at least one book`)
+	seedCmd.Flags().IntVar(&seedOptions.Genres,
+		"genres",
+		30,
+		`The number of genres to generate, each of which has at least one book`)
+	seedCmd.Flags().Int64Var(&seedOptions.Seed,
+		"seed",
+		1,
+		`The seed of the random numbers which the catalog is generated from`)
+}
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Insert a synthetic catalog of books, authors and genres, to exercise the service at scale",
+	Long: `Insert a synthetic catalog of books, authors and genres, to exercise the service at scale. The
+catalog is generated from --seed, so the same flags always generate the same catalog.
+
+Ratings are skewed towards good reviews, and the number of books per author and genre follows a power law, so
+that a few authors are prolific and most have a handful of books. Publication years and page counts spread across
+every era and size in the database. The catalog is inserted in a single transaction, as ` + "`readcommend import`" + `
+inserts one. Authors and genres are matched by name, so seeding twice with the same flags adds books, not authors.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer logger.Sync()
+
+		db := openDatabase()
+		defer db.Close()
+		repos := newRepositories(db)
+
+		ctx := context.Background()
+		eras, err := repos.eras.List(ctx)
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to list eras: %s", err))
+			ExitSeeding.Exit()
+		}
+		sizes, err := repos.sizes.List(ctx)
+		if err != nil {
+			logger.Error(fmt.Sprintf("unable to list sizes: %s", err))
+			ExitSeeding.Exit()
+		}
+		seedOptions.YearRanges, seedOptions.PageRanges = seedRanges(eras, sizes)
+
+		summary, err := catalog.NewDriver(newCatalogRepository(db)).Generate(ctx, seedOptions)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to seed catalog: %s\n", err)
+			ExitSeeding.Exit()
+		}
+
+		_, _ = fmt.Fprintf(os.Stdout, "seeded %d books: %d authors created, %d genres created\n",
+			summary.Created, summary.AuthorsCreated, summary.GenresCreated)
+	},
+}
+
+// seedRanges returns the years of the eras and the page counts of the sizes, as book.Ranges. Eras and sizes
+// without bounds, such as "Any", are left out, since every Book falls into them.
+func seedRanges(eras []entity.Era, sizes []entity.Size) (years, pages []book.Range) {
+	for _, e := range eras {
+		if e.MinYear != nil || e.MaxYear != nil {
+			years = append(years, book.Range{Min: e.MinYear, Max: e.MaxYear})
+		}
+	}
+	for _, s := range sizes {
+		if s.MinPages != nil || s.MaxPages != nil {
+			pages = append(pages, book.Range{Min: s.MinPages, Max: s.MaxPages})
+		}
+	}
+	return years, pages
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeedRanges(t *testing.T) {
+	min, max := int16(1970), int16(85)
+
+	years, pages := seedRanges(
+		[]entity.Era{{ID: 0, Title: "Any"}, {ID: 2, Title: "Modern", MinYear: &min}},
+		[]entity.Size{{ID: 0, Title: "Any"}, {ID: 1, Title: "Short story", MaxPages: &max}},
+	)
+	assert.Equal(t, []book.Range{{Min: &min}}, years)
+	assert.Equal(t, []book.Range{{Max: &max}}, pages)
+}
@@ -1,25 +1,52 @@
 package cmd
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/LeviMatus/readcommend/service/internal/api"
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
-	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
-	"github.com/LeviMatus/readcommend/service/internal/infra/repository/postgres"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/booksql"
+	"github.com/LeviMatus/readcommend/service/internal/metrics"
+	"github.com/LeviMatus/readcommend/service/internal/tracing"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+)
+
+// traceFlushTimeout bounds how long the spans which have yet to be exported may take to flush on shutdown.
+const traceFlushTimeout = 5 * time.Second
+
+// The stores which the API can serve from.
+const (
+	storeDatabase = "database"
+	storeMemory   = "memory"
+
+	// storePostgres is the former name of storeDatabase, from before the database driver was configurable. It
+	// is still accepted, so that existing deployments keep starting, but is deprecated.
+	storePostgres = "postgres"
+)
+
+// The environments which the service runs in.
+const (
+	environmentDevelopment = "development"
+	environmentProduction  = "production"
 )
 
 func init() {
 	rootCmd.AddCommand(serveCmd)
 
 	attachDatabaseFlags(serveCmd)
+	attachSearchFlags(serveCmd)
 
 	serveCmd.Flags().StringVar(&cfg.API.Host,
 		"api-host",
@@ -30,88 +57,395 @@ func init() {
 		"5000",
 		`The port that the API listens on (default "5000")`)
 
-	viper.BindPFlag("api.host", serveCmd.Flag("api-host"))
-	viper.BindPFlag("api.port", serveCmd.Flag("api-port"))
+	serveCmd.Flags().StringVar(&cfg.API.AdminPort,
+		"api-admin-port",
+		"",
+		`The port of an admin server which serves /metrics and /log/level, on the API's host (default "", which serves them on the API's port)`)
+	serveCmd.Flags().StringVar(&cfg.API.AdminToken,
+		"api-admin-token",
+		"",
+		`The bearer token which requests to /log/level must present (default "", which does not serve /log/level)`)
+	serveCmd.Flags().BoolVar(&cfg.API.StrictQuery,
+		"api-strict-query",
+		false,
+		`Reject requests with unknown query parameters rather than ignoring them`)
+	serveCmd.Flags().StringVar(&cfg.API.SpecValidation,
+		"api-spec-validation",
+		"",
+		fmt.Sprintf(`Validate requests and responses against the OpenAPI spec: %q, %q or %q (default "", which is %q in development and %q in production)`,
+			v1.SpecValidationOff, v1.SpecValidationLog, v1.SpecValidationReject, v1.SpecValidationLog, v1.SpecValidationOff))
+
+	serveCmd.Flags().DurationVar(&cfg.API.ReadTimeout,
+		"api-read-timeout",
+		15*time.Second,
+		`How long a client may take to send a whole request (default 15s)`)
+	serveCmd.Flags().DurationVar(&cfg.API.ReadHeaderTimeout,
+		"api-read-header-timeout",
+		5*time.Second,
+		`How long a client may take to send the headers of a request (default 5s)`)
+	serveCmd.Flags().DurationVar(&cfg.API.WriteTimeout,
+		"api-write-timeout",
+		60*time.Second,
+		`How long a response may take to be written, other than a book export (default 1m0s)`)
+	serveCmd.Flags().DurationVar(&cfg.API.ExportTimeout,
+		"api-export-timeout",
+		10*time.Minute,
+		`How long a book export may take to be written, in place of the write timeout (default 10m0s)`)
+	serveCmd.Flags().DurationVar(&cfg.API.IdleTimeout,
+		"api-idle-timeout",
+		120*time.Second,
+		`How long a keep-alive connection may wait idle for its next request (default 2m0s)`)
+	serveCmd.Flags().IntVar(&cfg.API.MaxHeaderBytes,
+		"api-max-header-bytes",
+		http.DefaultMaxHeaderBytes,
+		fmt.Sprintf(`The largest size of the headers of a request, in bytes (default %d)`, http.DefaultMaxHeaderBytes))
+	serveCmd.Flags().DurationVar(&cfg.API.ShutdownTimeout,
+		"api-shutdown-timeout",
+		30*time.Second,
+		`How long in-flight requests may take to complete once SIGINT or SIGTERM is received (default 30s)`)
+	serveCmd.Flags().DurationVar(&cfg.API.ShutdownDelay,
+		"api-shutdown-delay",
+		5*time.Second,
+		`How long to keep accepting connections once shutting down, while /readyz reports 503 (default 5s)`)
+
+	serveCmd.Flags().StringVar(&cfg.API.TLSCert,
+		"api-tls-cert",
+		"",
+		`A PEM certificate chain to serve TLS with, which is reloaded when it changes (requires --api-tls-key)`)
+	serveCmd.Flags().StringVar(&cfg.API.TLSKey,
+		"api-tls-key",
+		"",
+		`The PEM private key of --api-tls-cert`)
+	serveCmd.Flags().StringVar(&cfg.API.ClientCA,
+		"api-client-ca",
+		"",
+		`A PEM certificate authority which requests that write resources must present a client certificate from`)
+
+	serveCmd.Flags().StringVar(&cfg.Tracing.Exporter,
+		"tracing-exporter",
+		tracing.ExporterNone,
+		fmt.Sprintf(`Where to export the spans of traced requests: %q, %q or %q (default %q)`,
+			tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterFile, tracing.ExporterNone))
+	serveCmd.Flags().StringVar(&cfg.Tracing.File,
+		"tracing-file",
+		"traces.jsonl",
+		`The file which the "file" exporter appends spans to, one JSON object per line (default "traces.jsonl")`)
+	serveCmd.Flags().Float64Var(&cfg.Tracing.SampleRatio,
+		"tracing-sample-ratio",
+		1,
+		`The fraction of traces to sample, from 0 to 1, unless a request's traceparent was sampled upstream (default 1)`)
+
+	serveCmd.Flags().BoolVar(&cfg.Database.CheckSchema,
+		"db-check-schema",
+		true,
+		`Refuse to start if the database schema is behind the version this binary requires (default true)`)
+
+	serveCmd.Flags().StringVar(&cfg.Environment,
+		"environment",
+		environmentProduction,
+		fmt.Sprintf(`The environment the service runs in, either %q or %q, which sets the defaults of development aids (default %q)`,
+			environmentDevelopment, environmentProduction, environmentProduction))
+
+	serveCmd.Flags().StringVar(&cfg.Store.Type,
+		"store",
+		storeDatabase,
+		`The store the API serves from, either "database" or "memory", of which "postgres" is a deprecated alias (default "database")`)
+	serveCmd.Flags().StringVar(&cfg.Store.Fixture,
+		"store-fixture",
+		"",
+		`A .json, .yaml or .sql file to seed the memory store with, instead of the postgres migrations and seed data`)
+
+	bindConfig(serveCmd, "environment", "environment")
+	bindConfig(serveCmd, "database.check-schema", "db-check-schema")
+	bindConfig(serveCmd, "store.type", "store")
+	bindConfig(serveCmd, "store.fixture", "store-fixture")
+	bindConfig(serveCmd, "tracing.exporter", "tracing-exporter")
+	bindConfig(serveCmd, "tracing.file", "tracing-file")
+	bindConfig(serveCmd, "tracing.sample-ratio", "tracing-sample-ratio")
+	bindConfig(serveCmd, "api.host", "api-host")
+	bindConfig(serveCmd, "api.port", "api-port")
+	bindConfig(serveCmd, "api.admin-port", "api-admin-port")
+	bindConfig(serveCmd, "api.admin-token", "api-admin-token")
+	bindConfig(serveCmd, "api.strict-query", "api-strict-query")
+	bindConfig(serveCmd, "api.spec-validation", "api-spec-validation")
+	bindConfig(serveCmd, "api.read-timeout", "api-read-timeout")
+	bindConfig(serveCmd, "api.read-header-timeout", "api-read-header-timeout")
+	bindConfig(serveCmd, "api.write-timeout", "api-write-timeout")
+	bindConfig(serveCmd, "api.export-timeout", "api-export-timeout")
+	bindConfig(serveCmd, "api.idle-timeout", "api-idle-timeout")
+	bindConfig(serveCmd, "api.max-header-bytes", "api-max-header-bytes")
+	bindConfig(serveCmd, "api.shutdown-timeout", "api-shutdown-timeout")
+	bindConfig(serveCmd, "api.shutdown-delay", "api-shutdown-delay")
+	bindConfig(serveCmd, "api.tls-cert", "api-tls-cert")
+	bindConfig(serveCmd, "api.tls-key", "api-tls-key")
+	bindConfig(serveCmd, "api.client-ca", "api-client-ca")
 }
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
 	Short: "Start the readcommend server",
-	Run: func(cmd *cobra.Command, args []string) {
-		defer logger.Sync()
+	Long: `Start the readcommend server. /healthz reports that the process is alive, and /readyz that the database
+is reachable and has its tables. /metrics serves Prometheus metrics of the requests, the queries of the
+repositories and the database pool, on --api-admin-port if it is set and otherwise on --api-port.
 
-		conStr := fmt.Sprintf(
-			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-			cfg.Database.Host, cfg.Database.Port, cfg.Database.Username, cfg.Database.Password, cfg.Database.Database, cfg.Database.SSL)
+Every request is logged once it has been served. If --api-admin-token is set, /log/level, which is served
+alongside /metrics, changes the log level without a restart: GET it for the current level, or PUT
+the JSON {"level":"debug"}, with the token as an Authorization: Bearer header.
 
-		if cfg.Database.Schema != "" {
-			conStr = fmt.Sprintf("%s search_path=%s", conStr, cfg.Database.Schema)
-		}
+Every request is traced through the drivers, repositories and SQL statements, continuing the trace of its W3C
+traceparent header, and its trace ID is its X-Request-Id unless it sent one. Spans are exported to stdout or a
+file with --tracing-exporter.
 
-		db, err := sql.Open("postgres", conStr)
+In development, the requests to /api/v1 and their responses are validated against the embedded OpenAPI spec,
+and violations of it are logged, so that drift between the spec and the API is caught. --api-spec-validation
+rejects them instead, or turns validation off, which is the default in production.
+
+On SIGINT or SIGTERM, /readyz reports 503 and the server keeps accepting connections for --api-shutdown-delay,
+so that load balancers stop routing to it. It then stops accepting connections and waits, within
+--api-shutdown-timeout, for in-flight requests to complete, and finally closes the database and flushes the logs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := v1.Options{StrictQuery: cfg.API.StrictQuery, SpecValidation: specValidation()}
+
+		flushTraces, err := tracing.Setup(tracing.Options{
+			Exporter:    cfg.Tracing.Exporter,
+			File:        cfg.Tracing.File,
+			SampleRatio: cfg.Tracing.SampleRatio,
+		})
 		if err != nil {
-			logger.Error(fmt.Sprintf("unable to connect to database: %s", err))
-			ExitRequirements.Exit()
+			logger.Error(fmt.Sprintf("unable to set up tracing: %s", err))
+			ExitConfigSetup.Exit()
 		}
 
-		if err := db.Ping(); err != nil {
-			logger.Error(fmt.Sprintf("unable to verify DB connection: %s", err))
-			ExitRequirements.Exit()
+		var (
+			db    *sql.DB
+			repos repositories
+		)
+		switch storeType(cfg.Store.Type) {
+		case storeMemory:
+			repos = newMemoryRepositories()
+		case storeDatabase:
+			db = openDatabase()
+			if cfg.Database.CheckSchema {
+				checkSchema(databaseMigrator(db))
+			}
+			repos = newRepositories(db)
+		default:
+			logger.Error(fmt.Sprintf("invalid store %q: must be %q or %q", cfg.Store.Type, storeDatabase, storeMemory))
+			ExitConfigSetup.Exit()
 		}
-		logger.Info("database connection established")
 
-		bookRepo, err := postgres.NewBookRepository(db, logger)
+		reg := metrics.NewRegistry()
+		repoMetrics, err := metrics.NewRepositories(reg)
 		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create Book repository: %s", err))
+			logger.Error(fmt.Sprintf("unable to register repository metrics: %s", err))
 			ExitRequirements.Exit()
 		}
-
-		authorRepo, err := postgres.NewAuthorRepository(db, logger)
-		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create Author repository: %s", err))
-			ExitRequirements.Exit()
+		repos = repos.instrumented(repoMetrics).traced()
+		if db != nil {
+			if err := metrics.RegisterDBStats(reg, db, cfg.Database.Driver); err != nil {
+				logger.Error(fmt.Sprintf("unable to register database metrics: %s", err))
+				ExitRequirements.Exit()
+			}
 		}
 
-		genreRepo, err := postgres.NewGenreRepository(db, logger)
+		r, err := api.New(
+			tracing.AuthorDriver(author.NewDriver(repos.authors)),
+			tracing.SizeDriver(size.NewDriver(repos.sizes)),
+			tracing.GenreDriver(genre.NewDriver(repos.genres)),
+			tracing.EraDriver(era.NewDriver(repos.eras)),
+			tracing.BookDriver(repos.bookDriver()),
+			logger,
+			opts)
+
 		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create Genre repository: %s", err))
+			logger.Error(fmt.Sprintf("unable to create Driver: %s", err))
 			ExitRequirements.Exit()
 		}
+		if db != nil {
+			r.AddReadinessCheck("database", db.PingContext)
+			r.AddReadinessCheck("tables", func(ctx context.Context) error { return booksql.CheckTables(ctx, db) })
+		}
 
-		eraRepo, err := postgres.NewEraRepository(db, logger)
+		httpMetrics, err := metrics.NewHTTP(reg)
 		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create Era repository: %s", err))
+			logger.Error(fmt.Sprintf("unable to register HTTP metrics: %s", err))
 			ExitRequirements.Exit()
 		}
+		r.Instrument(httpMetrics)
 
-		sizeRepo, err := postgres.NewSizeRepository(db, logger)
-		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create Size repository: %s", err))
-			ExitRequirements.Exit()
+		var admin *api.Server
+		target := r
+		if cfg.API.AdminPort != "" {
+			admin = api.NewAdmin()
+			target = admin
+		}
+		target.Handle("/metrics", metrics.Handler(reg))
+		if cfg.API.AdminToken != "" {
+			target.HandleAuthenticated("/log/level", cfg.API.AdminToken, logLevel)
+		} else {
+			logger.Info("/log/level is not served, since no admin token is set")
 		}
 
-		r, err := api.New(
-			author.NewDriver(authorRepo),
-			size.NewDriver(sizeRepo),
-			genre.NewDriver(genreRepo),
-			era.NewDriver(eraRepo),
-			book.NewDriver(bookRepo),
-			logger)
+		certs := loadTLS()
 
-		if err != nil {
-			logger.Error(fmt.Sprintf("unable to create Driver: %s", err))
-			ExitRequirements.Exit()
+		l := listen(cfg.API.Port)
+		var adminListener net.Listener
+		if admin != nil {
+			adminListener = listen(cfg.API.AdminPort)
 		}
 
-		l, err := net.Listen("tcp", fmt.Sprintf("%s:%s", cfg.API.Host, cfg.API.Port))
-		if err != nil {
-			logger.Error(fmt.Sprintf("unable to listen on specified interface/port: %s", err))
-			ExitListen.Exit()
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		limits := api.Limits{
+			ReadTimeout:       cfg.API.ReadTimeout,
+			ReadHeaderTimeout: cfg.API.ReadHeaderTimeout,
+			WriteTimeout:      cfg.API.WriteTimeout,
+			ExportTimeout:     cfg.API.ExportTimeout,
+			IdleTimeout:       cfg.API.IdleTimeout,
+			MaxHeaderBytes:    cfg.API.MaxHeaderBytes,
+		}
+		served := make(chan error, 2)
+		serve("API", r, l, limits, certs, served)
+		if admin != nil {
+			serve("admin server", admin, adminListener, limits, certs, served)
 		}
 
-		if err := r.Serve(l); err != nil {
+		code := OK
+		select {
+		case err := <-served:
 			logger.Error(fmt.Sprintf("an error occurred while serving: %s", err))
-			ExitServing.Exit()
+			code = ExitServing
+		case <-ctx.Done():
+			// A second signal terminates the process rather than waiting for the drain.
+			stop()
+			logger.Info(fmt.Sprintf("shutting down: draining in-flight requests for up to %s", cfg.API.ShutdownTimeout))
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.API.ShutdownTimeout)
+			defer cancel()
+			if err := r.Shutdown(shutdownCtx, cfg.API.ShutdownDelay); err != nil {
+				logger.Error(fmt.Sprintf("unable to drain in-flight requests: %s", err))
+				code = ExitShutdown
+			}
+			// The admin server is shut down last, so that the drain can be observed.
+			if admin != nil {
+				if err := admin.Shutdown(shutdownCtx, 0); err != nil {
+					logger.Error(fmt.Sprintf("unable to drain in-flight admin requests: %s", err))
+					code = ExitShutdown
+				}
+			}
 		}
+
+		closeResources(db, flushTraces, code).Exit()
 	},
 }
+
+// storeType returns the store which the configured store type selects. The deprecated "postgres" store selects the
+// database store, and a warning to configure "database" instead is logged.
+func storeType(configured string) string {
+	if configured != storePostgres {
+		return configured
+	}
+	logger.Warn(fmt.Sprintf("store %q is deprecated: use %q, whose driver is set by database.driver",
+		storePostgres, storeDatabase))
+	return storeDatabase
+}
+
+// specValidation returns the configured mode of spec validation, or the default of the environment if none is
+// configured. If the environment is unknown, then the error is logged and the CLI exits.
+func specValidation() string {
+	switch cfg.Environment {
+	case environmentDevelopment, environmentProduction:
+	default:
+		logger.Error(fmt.Sprintf("invalid environment %q: must be %q or %q",
+			cfg.Environment, environmentDevelopment, environmentProduction))
+		ExitConfigSetup.Exit()
+	}
+
+	switch {
+	case cfg.API.SpecValidation != "":
+		return cfg.API.SpecValidation
+	case cfg.Environment == environmentProduction:
+		return v1.SpecValidationOff
+	}
+	return v1.SpecValidationLog
+}
+
+// listen listens on the port of the configured API host. If it cannot, then the error is logged and the CLI exits.
+func listen(port string) net.Listener {
+	l, err := net.Listen("tcp", fmt.Sprintf("%s:%s", cfg.API.Host, port))
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to listen on specified interface/port: %s", err))
+		ExitListen.Exit()
+	}
+	return l
+}
+
+// serve serves the Server, which the name describes in the logs, on the listener in the background. It is served
+// over TLS if there are certificates. The error which stops it is sent to served, which is nil if it was shut down.
+func serve(name string, s *api.Server, l net.Listener, limits api.Limits, certs *api.TLS, served chan<- error) {
+	go func() {
+		if certs != nil {
+			served <- s.ServeTLS(l, limits, certs)
+			return
+		}
+		served <- s.Serve(l, limits)
+	}()
+	if certs != nil {
+		logger.Info(fmt.Sprintf("serving %s over TLS on %s", name, l.Addr()))
+	} else {
+		logger.Info(fmt.Sprintf("serving %s on %s", name, l.Addr()))
+	}
+}
+
+// loadTLS loads the configured TLS certificates. If none are configured, then nil is returned and the API serves
+// plain HTTP. If they cannot be loaded, or a client CA is configured without them, then the error is logged and
+// the CLI exits.
+func loadTLS() *api.TLS {
+	if cfg.API.TLSCert == "" && cfg.API.TLSKey == "" {
+		if cfg.API.ClientCA != "" {
+			logger.Error("a client CA requires TLS: set the TLS certificate and key as well")
+			ExitConfigSetup.Exit()
+		}
+		return nil
+	}
+
+	certs, err := api.LoadTLS(api.TLSFiles{Cert: cfg.API.TLSCert, Key: cfg.API.TLSKey, ClientCA: cfg.API.ClientCA}, logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("unable to load TLS certificates: %s", err))
+		ExitConfigSetup.Exit()
+	}
+	return certs
+}
+
+// closeResources flushes the spans which have yet to be exported, closes the database, if there is one, and then
+// flushes the logger. The ExitCode of the command is returned, unless it exited OK and a resource could not be
+// closed, in which case ExitCleanup is returned.
+func closeResources(db *sql.DB, flushTraces func(context.Context) error, code ExitCode) ExitCode {
+	ctx, cancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+	defer cancel()
+	if err := flushTraces(ctx); err != nil {
+		logger.Error(fmt.Sprintf("unable to flush traces: %s", err))
+		if code == OK {
+			code = ExitCleanup
+		}
+	}
+
+	if db != nil {
+		if err := db.Close(); err != nil {
+			logger.Error(fmt.Sprintf("unable to close database: %s", err))
+			if code == OK {
+				code = ExitCleanup
+			}
+		}
+	}
+	logger.Info("shut down")
+
+	if err := syncLogger(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "unable to flush logs: %s\n", err)
+		if code == OK {
+			code = ExitCleanup
+		}
+	}
+	return code
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEnvironmentDefault(t *testing.T) {
+	// Development aids such as spec validation cost too much to run in production, so only development opts in.
+	flag := serveCmd.Flags().Lookup("environment")
+	require.NotNil(t, flag)
+	assert.Equal(t, environmentProduction, flag.DefValue)
+}
+
+func TestStoreType(t *testing.T) {
+
+	tests := map[string]struct {
+		configured string
+		expect     string
+		warned     bool
+	}{
+		"database": {configured: storeDatabase, expect: storeDatabase},
+		"memory":   {configured: storeMemory, expect: storeMemory},
+		"postgres is a deprecated alias of database": {
+			configured: storePostgres,
+			expect:     storeDatabase,
+			warned:     true,
+		},
+		"unknown stores are left to be rejected": {configured: "redis", expect: "redis"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			previous := logger
+			logger = zap.New(core)
+			defer func() { logger = previous }()
+
+			assert.Equal(t, tt.expect, storeType(tt.configured))
+			assert.Equal(t, tt.warned, logs.Len() == 1)
+		})
+	}
+}
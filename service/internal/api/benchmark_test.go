@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
@@ -89,9 +90,9 @@ func BenchmarkAPI_Books(b *testing.B) {
 	driver := booktest.DriverMock{}
 	driver.
 		On("SearchBooks", mock.MatchedBy(func(_ context.Context) bool { return true }), book.SearchInput{}).
-		Return(books, nil)
+		Return(book.Page{Books: books}, nil)
 
-	apiServer, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &driver, zap.NewNop())
+	apiServer, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &driver, zap.NewNop(), v1.Options{})
 	assert.NoError(b, err)
 	assert.NotNil(b, apiServer)
 
@@ -109,7 +110,7 @@ func BenchmarkAPI_Authors(b *testing.B) {
 		On("ListAuthors", mock.MatchedBy(func(_ context.Context) bool { return true })).
 		Return(authors, nil)
 
-	apiServer, err := New(&driver, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop())
+	apiServer, err := New(&driver, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
 	assert.NoError(b, err)
 	assert.NotNil(b, apiServer)
 
@@ -127,7 +128,7 @@ func BenchmarkAPI_Genres(b *testing.B) {
 		On("ListGenres", mock.MatchedBy(func(_ context.Context) bool { return true })).
 		Return(genres, nil)
 
-	apiServer, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &driver, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop())
+	apiServer, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &driver, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
 	assert.NoError(b, err)
 	assert.NotNil(b, apiServer)
 
@@ -145,7 +146,7 @@ func BenchmarkAPI_Sizes(b *testing.B) {
 		On("ListSizes", mock.MatchedBy(func(_ context.Context) bool { return true })).
 		Return(sizes, nil)
 
-	apiServer, err := New(&authortest.DriverMock{}, &driver, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop())
+	apiServer, err := New(&authortest.DriverMock{}, &driver, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
 	assert.NoError(b, err)
 	assert.NotNil(b, apiServer)
 
@@ -163,7 +164,7 @@ func BenchmarkAPI_Eras(b *testing.B) {
 		On("ListEras", mock.MatchedBy(func(_ context.Context) bool { return true })).
 		Return(eras, nil)
 
-	apiServer, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &driver, &booktest.DriverMock{}, zap.NewNop())
+	apiServer, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &driver, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
 	assert.NoError(b, err)
 	assert.NotNil(b, apiServer)
 
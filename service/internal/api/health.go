@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// checkTimeout bounds how long a readiness check may take, so that a dependency which hangs is reported as down
+// rather than hanging the probe.
+const checkTimeout = 2 * time.Second
+
+// The statuses reported by the health endpoints.
+const (
+	statusOK           = "ok"
+	statusReady        = "ready"
+	statusNotReady     = "not ready"
+	statusShuttingDown = "shutting down"
+	statusUp           = "up"
+	statusDown         = "down"
+)
+
+// readinessCheck is a named check of a dependency, which returns an error if the dependency is unavailable.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// HealthResponse is the response of /healthz and /readyz.
+type HealthResponse struct {
+	// Status is "ok" for /healthz. For /readyz it is "ready", "not ready" if any check failed, or "shutting down".
+	Status string `json:"status"`
+
+	// Checks reports every dependency checked by /readyz, by name.
+	Checks map[string]CheckResponse `json:"checks,omitempty"`
+}
+
+// CheckResponse reports a dependency checked by /readyz.
+type CheckResponse struct {
+	// Status is "up" or "down".
+	Status string `json:"status"`
+
+	// Error describes why the dependency is down.
+	Error string `json:"error,omitempty"`
+
+	// DurationMillis is how long the check took.
+	DurationMillis int64 `json:"durationMillis"`
+}
+
+// AddReadinessCheck adds a check of a dependency, such as a database, to /readyz. The Server is only ready while
+// every check succeeds within checkTimeout. Checks must be added before the Server is served.
+func (s *Server) AddReadinessCheck(name string, check func(ctx context.Context) error) {
+	s.checks = append(s.checks, readinessCheck{name: name, check: check})
+}
+
+// healthz reports that the process is alive, which it is for as long as it can respond.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, HealthResponse{Status: statusOK})
+}
+
+// readyz reports whether the Server is ready to serve requests. Every readiness check is run concurrently, and
+// their results are reported along with the overall status. If any check fails, or the Server is shutting down,
+// then the status is 503 Service Unavailable.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&s.shuttingDown) == 1 {
+		render.Status(r, http.StatusServiceUnavailable)
+		render.JSON(w, r, HealthResponse{Status: statusShuttingDown})
+		return
+	}
+
+	resp := HealthResponse{Status: statusReady, Checks: make(map[string]CheckResponse, len(s.checks))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range s.checks {
+		wg.Add(1)
+		go func(c readinessCheck) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+			defer cancel()
+			start := time.Now()
+			err := c.check(ctx)
+
+			result := CheckResponse{Status: statusUp, DurationMillis: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status, result.Error = statusDown, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			resp.Checks[c.name] = result
+			if err != nil {
+				resp.Status = statusNotReady
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if resp.Status != statusReady {
+		render.Status(r, http.StatusServiceUnavailable)
+	}
+	render.JSON(w, r, resp)
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
+	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era/eratest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre/genretest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size/sizetest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestServer creates a Server whose drivers expect no calls.
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
+	require.NoError(t, err)
+	return server
+}
+
+// getHealth requests the path of the URL, and decodes its HealthResponse.
+func getHealth(t *testing.T, url string) (int, HealthResponse) {
+	t.Helper()
+
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "application/json; charset=utf-8", res.Header.Get("Content-Type"))
+
+	var health HealthResponse
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&health))
+	return res.StatusCode, health
+}
+
+func TestServer_Healthz(t *testing.T) {
+	server := newTestServer(t)
+	server.AddReadinessCheck("database", func(context.Context) error { return errors.New("connection refused") })
+	ts := httptest.NewServer(server.mux)
+	defer ts.Close()
+
+	// The process is alive even while its dependencies are not.
+	status, health := getHealth(t, ts.URL+"/healthz")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, HealthResponse{Status: statusOK}, health)
+}
+
+func TestServer_Readyz(t *testing.T) {
+
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := map[string]struct {
+		checks       map[string]func(context.Context) error
+		shuttingDown bool
+		status       int
+		expect       HealthResponse
+	}{
+		"no dependencies": {
+			status: http.StatusOK,
+			expect: HealthResponse{Status: statusReady},
+		},
+		"every dependency is up": {
+			checks: map[string]func(context.Context) error{"database": up, "tables": up},
+			status: http.StatusOK,
+			expect: HealthResponse{Status: statusReady, Checks: map[string]CheckResponse{
+				"database": {Status: statusUp},
+				"tables":   {Status: statusUp},
+			}},
+		},
+		"a dependency is down": {
+			checks: map[string]func(context.Context) error{"database": down, "tables": up},
+			status: http.StatusServiceUnavailable,
+			expect: HealthResponse{Status: statusNotReady, Checks: map[string]CheckResponse{
+				"database": {Status: statusDown, Error: "connection refused"},
+				"tables":   {Status: statusUp},
+			}},
+		},
+		"shutting down": {
+			checks:       map[string]func(context.Context) error{"database": up},
+			shuttingDown: true,
+			status:       http.StatusServiceUnavailable,
+			expect:       HealthResponse{Status: statusShuttingDown},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := newTestServer(t)
+			for name, check := range tt.checks {
+				server.AddReadinessCheck(name, check)
+			}
+			if tt.shuttingDown {
+				server.shuttingDown = 1
+			}
+			ts := httptest.NewServer(server.mux)
+			defer ts.Close()
+
+			status, health := getHealth(t, ts.URL+"/readyz")
+			assert.Equal(t, tt.status, status)
+
+			// Durations vary, so they are only checked to be set.
+			for name, check := range health.Checks {
+				assert.GreaterOrEqual(t, check.DurationMillis, int64(0))
+				check.DurationMillis = 0
+				health.Checks[name] = check
+			}
+			assert.Equal(t, tt.expect, health)
+		})
+	}
+}
+
+func TestServer_Readyz_Timeout(t *testing.T) {
+	server := newTestServer(t)
+	server.AddReadinessCheck("database", func(ctx context.Context) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok, "checks should be bounded")
+		assert.WithinDuration(t, time.Now().Add(checkTimeout), deadline, time.Second)
+		return nil
+	})
+	ts := httptest.NewServer(server.mux)
+	defer ts.Close()
+
+	status, _ := getHealth(t, ts.URL+"/readyz")
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestServer_Shutdown_Readyz(t *testing.T) {
+	server := newTestServer(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(l, Limits{}) }()
+	url := fmt.Sprintf("http://%s/readyz", l.Addr())
+
+	status, _ := getHealth(t, url)
+	assert.Equal(t, http.StatusOK, status)
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- server.Shutdown(context.Background(), 200*time.Millisecond) }()
+
+	// Connections are accepted during the delay, but the Server reports that it is not ready.
+	assert.Eventually(t, func() bool {
+		status, health := getHealth(t, url)
+		return status == http.StatusServiceUnavailable && health.Status == statusShuttingDown
+	}, 150*time.Millisecond, 10*time.Millisecond)
+
+	assert.NoError(t, <-shutdown)
+	_, err = http.Get(url)
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
+	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era/eratest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre/genretest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size/sizetest"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestServer_AccessLog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	driver := booktest.DriverMock{}
+	driver.On("GetBook", mock.Anything, int32(1)).Return(entity.Book{}, nil)
+	driver.On("GetBook", mock.Anything, int32(2)).Return(entity.Book{}, entity.ErrNotFound)
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &driver, zap.New(core), v1.Options{})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		path   string
+		status int
+		route  string
+	}{
+		"found": {
+			path:   "/api/v1/books/1",
+			status: http.StatusOK,
+			route:  "/api/v1/books/{id:[0-9]+}",
+		},
+		"not found": {
+			path:   "/api/v1/books/2",
+			status: http.StatusNotFound,
+			route:  "/api/v1/books/{id:[0-9]+}",
+		},
+		"unmatched": {
+			path:   "/missing",
+			status: http.StatusNotFound,
+			route:  "",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			req.Header.Set("X-Request-Id", "req-"+name)
+			rec := httptest.NewRecorder()
+			server.mux.ServeHTTP(rec, req)
+
+			entries := logs.FilterMessage("request").FilterField(zap.String("request_id", "req-"+name)).TakeAll()
+			require.Len(t, entries, 1)
+			entry := entries[0]
+			assert.Equal(t, zapcore.InfoLevel, entry.Level)
+
+			fields := entry.ContextMap()
+			assert.Equal(t, http.MethodGet, fields["method"])
+			assert.Equal(t, test.path, fields["path"])
+			assert.Equal(t, test.route, fields["route"])
+			assert.EqualValues(t, test.status, fields["status"])
+			assert.EqualValues(t, rec.Body.Len(), fields["bytes"])
+			assert.Contains(t, fields, "latency")
+		})
+	}
+}
+
+func TestServer_HandleAuthenticated(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	admin := NewAdmin()
+	admin.HandleAuthenticated("/log/level", "secret", level)
+
+	tests := map[string]struct {
+		authorization string
+		status        int
+		level         zapcore.Level
+	}{
+		"no token": {
+			status: http.StatusUnauthorized,
+			level:  zapcore.InfoLevel,
+		},
+		"wrong token": {
+			authorization: "Bearer wrong",
+			status:        http.StatusUnauthorized,
+			level:         zapcore.InfoLevel,
+		},
+		"not a bearer token": {
+			authorization: "Basic secret",
+			status:        http.StatusUnauthorized,
+			level:         zapcore.InfoLevel,
+		},
+		"right token": {
+			authorization: "Bearer secret",
+			status:        http.StatusOK,
+			level:         zapcore.DebugLevel,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			level.SetLevel(zapcore.InfoLevel)
+
+			req := httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			rec := httptest.NewRecorder()
+			admin.mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.status, rec.Code)
+			assert.Equal(t, test.level, level.Level())
+			if test.status == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
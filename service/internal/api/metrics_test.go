@@ -0,0 +1,83 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
+	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era/eratest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre/genretest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size/sizetest"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// scrape requests the metrics at the URL, and returns them in the text format.
+func scrape(t *testing.T, url string) string {
+	t.Helper()
+
+	res, err := http.Get(url)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestServer_Instrument(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.NewHTTP(reg)
+	require.NoError(t, err)
+
+	driver := booktest.DriverMock{}
+	driver.On("GetBook", mock.Anything, mock.Anything).Return(entity.Book{}, nil)
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &driver, zap.NewNop(), v1.Options{})
+	require.NoError(t, err)
+	server.Instrument(m)
+	server.Handle("/metrics", metrics.Handler(reg))
+	ts := httptest.NewServer(server.mux)
+	defer ts.Close()
+
+	for _, path := range []string{"/api/v1/books/1", "/api/v1/books/2", "/missing"} {
+		res, err := http.Get(ts.URL + path)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+
+	// Requests are labelled by the pattern of the route in the mounted v1 router, rather than by their path.
+	body := scrape(t, ts.URL+"/metrics")
+	assert.Contains(t, body, `readcommend_http_requests_total{method="GET",route="/api/v1/books/{id:[0-9]+}",status="200"} 2`)
+	assert.Contains(t, body, `readcommend_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, `/api/v1/books/1`)
+}
+
+func TestNewAdmin(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "A test counter."})
+	reg.MustRegister(counter)
+	counter.Inc()
+
+	admin := NewAdmin()
+	admin.Handle("/metrics", metrics.Handler(reg))
+	ts := httptest.NewServer(admin.mux)
+	defer ts.Close()
+
+	assert.Contains(t, scrape(t, ts.URL+"/metrics"), "test_total 1")
+
+	// The admin server serves none of the API's routes.
+	res, err := http.Get(ts.URL + "/api/v1/books")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
@@ -0,0 +1,26 @@
+//go:build ignore
+// +build ignore
+
+// gen copies open-api.yaml, from the root of the repository where it is maintained, beside the openapi package, so
+// that it can be embedded. It is run by go generate.
+package main
+
+import (
+	"io/ioutil"
+	"log"
+)
+
+const (
+	source = "../../../../open-api.yaml"
+	target = "open-api.yaml"
+)
+
+func main() {
+	spec, err := ioutil.ReadFile(source)
+	if err != nil {
+		log.Fatalf("unable to read the OpenAPI spec: %s", err)
+	}
+	if err := ioutil.WriteFile(target, spec, 0644); err != nil {
+		log.Fatalf("unable to copy the OpenAPI spec: %s", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package openapi embeds open-api.yaml, the spec of the v1 API, and validates requests and responses against it,
+// so that drift between the spec and the routes which actually serve the API is caught.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// BasePath is the path which the v1 API is served under, and which the paths of the spec are relative to.
+const BasePath = "/api/v1"
+
+//go:generate go run gen.go
+
+// spec is the OpenAPI spec of the API, as YAML. It is a copy of the open-api.yaml at the root of the repository,
+// which is where the spec is maintained; run go generate to update it.
+//
+//go:embed open-api.yaml
+var spec []byte
+
+// ErrUndocumented is wrapped by the errors of requests whose route is not in the spec.
+var ErrUndocumented = errors.New("route is not documented by the OpenAPI spec")
+
+// Validator validates requests to the v1 API, and its responses to them, against the spec. The zero value is not
+// usable; create Validators with New.
+type Validator struct {
+	router routers.Router
+}
+
+// New loads the embedded spec, and returns an error if it is not a valid OpenAPI 3 document.
+func New() (*Validator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+
+	// Paths with servers of their own, such as /healthz, are served beside the v1 API rather than by it. The rest
+	// are routed under BasePath alone, so that requests match whichever host they were sent to.
+	for path, item := range doc.Paths {
+		if len(item.Servers) > 0 {
+			delete(doc.Paths, path)
+		}
+	}
+	doc.Servers = openapi3.Servers{{URL: BasePath}}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to route OpenAPI spec: %w", err)
+	}
+	return &Validator{router: router}, nil
+}
+
+// ValidateRequest validates the path and query parameters of the http.Request against those of its operation.
+// Request bodies are left to the handlers which decode them. If the request has no operation, then an error
+// wrapping ErrUndocumented is returned. If any parameter is invalid, then an *entity.ValidationError of the kind
+// entity.ErrInvalidQueryParam is returned, with a FieldError for each of them.
+func (v *Validator) ValidateRequest(r *http.Request) error {
+	input, err := v.input(r)
+	if err != nil {
+		return err
+	}
+	input.Options = &openapi3filter.Options{
+		ExcludeRequestBody: true,
+		MultiError:         true,
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	err = openapi3filter.ValidateRequest(r.Context(), input)
+	if err == nil {
+		return nil
+	}
+
+	val := validation.New(entity.ErrInvalidQueryParam)
+	var multiErr openapi3.MultiError
+	if !errors.As(err, &multiErr) {
+		multiErr = openapi3.MultiError{err}
+	}
+	for _, e := range multiErr {
+		var reqErr *openapi3filter.RequestError
+		if errors.As(e, &reqErr) && reqErr.Parameter != nil {
+			val.Add(reqErr.Parameter.Name, validation.CodeInvalid, "%s", reason(reqErr))
+			continue
+		}
+		val.Add("", validation.CodeInvalid, "%s", e)
+	}
+	return val.Err()
+}
+
+// ValidateResponse validates the status, headers and body of the response to the http.Request against those
+// documented by its operation. Only JSON bodies are validated; the media type of any other body must merely be
+// documented. If the request has no operation, then an error wrapping ErrUndocumented is returned.
+func (v *Validator) ValidateResponse(r *http.Request, status int, header http.Header, body []byte) error {
+	input, err := v.input(r)
+	if err != nil {
+		return err
+	}
+
+	contentType := header.Get("Content-Type")
+	json := isJSON(contentType)
+	if response := documented(input.Route.Operation, status); response != nil {
+		if !json && contentType != "" && response.Content.Get(contentType) == nil {
+			return fmt.Errorf("response of status %d has undocumented Content-Type %q", status, contentType)
+		}
+		if err := validateHeaders(response, header); err != nil {
+			return err
+		}
+	}
+
+	// Only the Content-Type is left to openapi3filter, which validates the values of headers as strings.
+	res := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 http.Header{"Content-Type": header.Values("Content-Type")},
+		Options: &openapi3filter.Options{
+			ExcludeResponseBody:   !json,
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	}
+	res.SetBodyBytes(body)
+	return openapi3filter.ValidateResponse(context.Background(), res)
+}
+
+// input finds the operation of the http.Request, which its validation needs.
+func (v *Validator) input(r *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	route, params, err := v.router.FindRoute(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %s: %s", ErrUndocumented, r.Method, r.URL.Path, err)
+	}
+	return &openapi3filter.RequestValidationInput{Request: r, PathParams: params, Route: route}, nil
+}
+
+// reason describes what is wrong with the parameter of the RequestError, such as "number must be at least 1",
+// without repeating the name of the parameter.
+func reason(err *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+	if err.Err != nil {
+		return err.Err.Error()
+	}
+	return err.Reason
+}
+
+// documented returns the response which the operation documents for the status, either exactly or by its default
+// response, or nil if it documents none.
+func documented(op *openapi3.Operation, status int) *openapi3.Response {
+	ref := op.Responses.Get(status)
+	if ref == nil {
+		ref = op.Responses.Default()
+	}
+	if ref == nil {
+		return nil
+	}
+	return ref.Value
+}
+
+// validateHeaders validates the headers which the response documents, if they are present, against their schemas.
+// Their values are parsed as the type of their schema first, such as a boolean for Has-More.
+func validateHeaders(response *openapi3.Response, header http.Header) error {
+	for name, ref := range response.Headers {
+		value := header.Get(name)
+		if value == "" || ref.Value == nil || ref.Value.Schema == nil || ref.Value.Schema.Value == nil {
+			continue
+		}
+		schema := ref.Value.Schema.Value
+
+		var parsed interface{} = value
+		switch schema.Type {
+		case openapi3.TypeBoolean:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("response header %q is %q but should be a boolean", name, value)
+			}
+			parsed = b
+		case openapi3.TypeInteger, openapi3.TypeNumber:
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("response header %q is %q but should be a number", name, value)
+			}
+			parsed = n
+		}
+		if err := schema.VisitJSON(parsed); err != nil {
+			return fmt.Errorf("response header %q doesn't match the schema: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// isJSON returns true if the media type is JSON, such as application/json or application/problem+json.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,210 @@
+package openapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecIsGenerated(t *testing.T) {
+	root, err := ioutil.ReadFile("../../../../open-api.yaml")
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(root, spec), "open-api.yaml differs from the spec at the root; run go generate")
+}
+
+func TestValidator_ValidateRequest(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		method         string
+		target         string
+		undocumented   bool
+		expectedFields []entity.FieldError
+	}{
+		"valid query": {
+			method: http.MethodGet,
+			target: "/api/v1/books?authors=1,2&pages=100..300&limit=10&sort=-rating,title",
+		},
+		"valid path": {
+			method: http.MethodDelete,
+			target: "/api/v1/genres/3?reassign-to=4",
+		},
+		"any host is matched": {
+			method: http.MethodGet,
+			target: "https://readcommend.example.com/api/v1/eras",
+		},
+		"invalid parameters": {
+			method: http.MethodGet,
+			target: "/api/v1/books?min-pages=0&pages=100",
+			expectedFields: []entity.FieldError{
+				{Field: "min-pages", Code: validation.CodeInvalid, Message: "number must be at least 1"},
+				{Field: "pages", Code: validation.CodeInvalid,
+					Message: `string "100" doesn't match the regular expression "^([0-9]+\.\.[0-9]*|\.\.[0-9]+)$"`},
+			},
+		},
+		"parameter of the wrong type": {
+			method: http.MethodGet,
+			target: "/api/v1/books?limit=ten",
+			expectedFields: []entity.FieldError{
+				{Field: "limit", Code: validation.CodeInvalid, Message: `value ten: an invalid integer: invalid syntax`},
+			},
+		},
+		"undocumented path": {
+			method:       http.MethodGet,
+			target:       "/api/v1/publishers",
+			undocumented: true,
+		},
+		"undocumented method": {
+			method:       http.MethodPost,
+			target:       "/api/v1/books/1",
+			undocumented: true,
+		},
+		"paths beside the API are undocumented": {
+			method:       http.MethodGet,
+			target:       "/healthz",
+			undocumented: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := v.ValidateRequest(httptest.NewRequest(tt.method, tt.target, nil))
+
+			switch {
+			case tt.undocumented:
+				assert.ErrorIs(t, err, ErrUndocumented)
+			case tt.expectedFields == nil:
+				assert.NoError(t, err)
+			default:
+				assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+				var validationErr *entity.ValidationError
+				if assert.ErrorAs(t, err, &validationErr) {
+					assert.ElementsMatch(t, tt.expectedFields, validationErr.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestValidator_ValidateResponse(t *testing.T) {
+	v, err := New()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		method      string
+		target      string
+		status      int
+		contentType string
+		hasMore     string
+		body        string
+		expectErr   bool
+	}{
+		"conforming body": {
+			method:      http.MethodGet,
+			target:      "/api/v1/eras",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `[{"id":1,"title":"Classic","minYear":1800,"maxYear":1969}]`,
+		},
+		"problem": {
+			method:      http.MethodGet,
+			target:      "/api/v1/books/1",
+			status:      http.StatusNotFound,
+			contentType: "application/problem+json",
+			body:        `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"not found"}`,
+		},
+		"undocumented status falls back to the default problem": {
+			method:      http.MethodGet,
+			target:      "/api/v1/eras",
+			status:      http.StatusServiceUnavailable,
+			contentType: "application/problem+json",
+			body:        `{"type":"about:blank","title":"Service Unavailable","status":503,"code":"unavailable"}`,
+		},
+		"headers are parsed as the type of their schema": {
+			method:      http.MethodGet,
+			target:      "/api/v1/books",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			hasMore:     "false",
+			body:        `[]`,
+		},
+		"header of the wrong type": {
+			method:      http.MethodGet,
+			target:      "/api/v1/books",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			hasMore:     "maybe",
+			body:        `[]`,
+			expectErr:   true,
+		},
+		"no content": {
+			method: http.MethodDelete,
+			target: "/api/v1/eras/1",
+			status: http.StatusNoContent,
+		},
+		"documented media type which is not JSON": {
+			method:      http.MethodGet,
+			target:      "/api/v1/books/export?format=csv",
+			status:      http.StatusOK,
+			contentType: "text/csv",
+			body:        "id,title\n1,Dune\n",
+		},
+		"body missing a required property": {
+			method:      http.MethodGet,
+			target:      "/api/v1/eras",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `[{"id":1,"minYear":1800,"maxYear":1969}]`,
+			expectErr:   true,
+		},
+		"body of the wrong type": {
+			method:      http.MethodGet,
+			target:      "/api/v1/eras",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"id":1}`,
+			expectErr:   true,
+		},
+		"undocumented media type": {
+			method:      http.MethodGet,
+			target:      "/api/v1/eras",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			body:        "<p>Classic</p>",
+			expectErr:   true,
+		},
+		"undocumented path": {
+			method:    http.MethodGet,
+			target:    "/api/v1/publishers",
+			status:    http.StatusOK,
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.contentType != "" {
+				header.Set("Content-Type", tt.contentType)
+			}
+			if tt.hasMore != "" {
+				header.Set("Has-More", tt.hasMore)
+			}
+
+			err := v.ValidateResponse(httptest.NewRequest(tt.method, tt.target, nil), tt.status, header, []byte(tt.body))
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package openapitest serves handlers of the v1 API in tests, and fails the tests whose requests are served
+// with responses that the OpenAPI spec does not document.
+package openapitest
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi"
+)
+
+var (
+	loadOnce  sync.Once
+	validator *openapi.Validator
+	loadErr   error
+)
+
+// NewServer starts an httptest.Server which serves the handler, whose routes are those of the spec under the path,
+// such as "/books", and which is closed when the test completes. Every response which it serves is validated
+// against the spec, and so is every request which it serves successfully, since the API must not accept what its
+// spec rejects. Requests which the handler rejects are not, so that tests may send invalid requests on purpose.
+// Any violation fails the test.
+func NewServer(t testing.TB, path string, h http.Handler) *httptest.Server {
+	t.Helper()
+
+	loadOnce.Do(func() { validator, loadErr = openapi.New() })
+	if loadErr != nil {
+		t.Fatalf("unable to load the OpenAPI spec: %s", loadErr)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		assertConforms(t, specRequest(r, path), rec)
+
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// assertConforms fails the test if the recorded response to the request, whose path is that of the spec, violates
+// the spec. So does the request itself, if its response was successful. Routes which the spec does not document
+// must not be found.
+func assertConforms(t testing.TB, r *http.Request, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	if rec.Code < http.StatusBadRequest {
+		if err := validator.ValidateRequest(r); err != nil {
+			t.Errorf("%s %s was served with %d, but violates the OpenAPI spec: %s", r.Method, r.URL, rec.Code, err)
+		}
+	}
+
+	err := validator.ValidateResponse(r, rec.Code, rec.Header(), rec.Body.Bytes())
+	if errors.Is(err, openapi.ErrUndocumented) &&
+		(rec.Code == http.StatusNotFound || rec.Code == http.StatusMethodNotAllowed) {
+		return
+	}
+	if err != nil {
+		t.Errorf("response of %d to %s %s violates the OpenAPI spec: %s", rec.Code, r.Method, r.URL, err)
+	}
+}
+
+// specRequest copies the request, which was sent to a handler served under the path, with the path of the spec
+// which it was routed to.
+func specRequest(r *http.Request, path string) *http.Request {
+	spec := r.Clone(r.Context())
+	spec.URL.Path = openapi.BasePath + path + strings.TrimSuffix(r.URL.Path, "/")
+	spec.URL.RawPath = ""
+	return spec
+}
@@ -1,8 +1,15 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
@@ -10,6 +17,8 @@ import (
 	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/metrics"
+	"github.com/LeviMatus/readcommend/service/internal/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -18,7 +27,22 @@ import (
 )
 
 type Server struct {
-	mux *chi.Mux
+	mux  *chi.Mux
+	http *http.Server
+
+	// tls holds the certificates of the Server while it is serving TLS.
+	tls *TLS
+
+	// metrics measures the requests of the Server once it is instrumented.
+	metrics *metrics.HTTP
+
+	// exportTimeout bounds how long the response of a book export may take to be written, in place of the write
+	// timeout of the Server's connections. Zero leaves it unbounded.
+	exportTimeout time.Duration
+
+	// checks are run by /readyz, which reports that the Server is not ready once shuttingDown is set to 1.
+	checks       []readinessCheck
+	shuttingDown int32
 
 	host string
 	port string
@@ -44,7 +68,7 @@ func (req RequiredDrivers) Validate() error {
 	return nil
 }
 
-func New(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver, bd book.Driver, logger *zap.Logger) (*Server, error) {
+func New(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver, bd book.Driver, logger *zap.Logger, opts v1.Options) (*Server, error) {
 	if ad == nil || sd == nil || gd == nil || ed == nil || bd == nil || logger == nil {
 		return nil, errors.New("dependencies for the API are not satisfied - non-nil drivers and logger are required")
 	}
@@ -52,15 +76,25 @@ func New(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver, bd bo
 	s := Server{
 		mux: chi.NewRouter(),
 	}
+	s.http = &http.Server{Handler: s.mux, ConnContext: withConn}
 
 	s.mux.Use(
+		s.instrument,
+		tracing.Middleware,
 		middleware.RequestID,
-		middleware.Logger,
-		middleware.Recoverer,
+		echoRequestID,
+		accessLog(logger),
+		recoverer(logger),
+		s.exportDeadline,
 		render.SetContentType(render.ContentTypeJSON),
+		s.requireClientCert,
 	)
 
-	v1Router, err := v1.NewRouter(ad, sd, gd, ed, bd, logger)
+	s.mux.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		v1.RenderProblem(w, v1.ErrRouteNotFound(r.URL.Path))
+	})
+
+	v1Router, err := v1.NewRouter(ad, sd, gd, ed, bd, logger, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -68,10 +102,189 @@ func New(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver, bd bo
 	s.mux.Route("/api", func(r chi.Router) {
 		r.Mount("/v1", v1Router)
 	})
+	s.mux.Get("/healthz", s.healthz)
+	s.mux.Get("/readyz", s.readyz)
 
 	return &s, nil
 }
 
-func (s *Server) Serve(listener net.Listener) error {
-	return http.Serve(listener, s.mux)
+// NewAdmin creates a Server for operators, such as one serving metrics, which listens apart from the API so that
+// its routes need not be exposed to the API's clients. Its routes are added with Handle. If it is served over TLS
+// with a client CA, then its writes require a client certificate, as the API's do.
+func NewAdmin() *Server {
+	s := Server{
+		mux: chi.NewRouter(),
+	}
+	s.http = &http.Server{Handler: s.mux}
+
+	s.mux.Use(
+		middleware.Recoverer,
+		s.requireClientCert,
+	)
+	return &s
+}
+
+// Handle routes requests for the pattern to the handler, such as /metrics to the handler of a metrics registry.
+// Routes must be added before the Server is served.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// bearerPrefix precedes the token in the Authorization header of a request to an authenticated route.
+const bearerPrefix = "Bearer "
+
+// HandleAuthenticated is Handle for routes which only operators may use, such as one which changes the log level.
+// Requests must present the token as a bearer token in their Authorization header, or they are rejected with 401
+// Unauthorized. The token must not be empty.
+func (s *Server) HandleAuthenticated(pattern, token string, handler http.Handler) {
+	s.mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if token == "" || !strings.HasPrefix(auth, bearerPrefix) ||
+			subtle.ConstantTimeCompare([]byte(auth[len(bearerPrefix):]), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			v1.RenderProblem(w, v1.ErrUnauthorized(errors.New("a valid bearer token is required")))
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+}
+
+// Instrument measures every request of the Server with the metrics. It must be called before the Server is served.
+func (s *Server) Instrument(m *metrics.HTTP) {
+	s.metrics = m
+}
+
+// instrument measures requests with the metrics of the Server, if it is instrumented.
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.metrics == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		s.metrics.Middleware(next).ServeHTTP(w, r)
+	})
+}
+
+// echoRequestID sets the X-Request-Id header of the response to the ID of the request, which is its trace ID
+// unless the client sent one, so that clients can quote it when reporting a problem.
+func echoRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(middleware.RequestIDHeader, middleware.GetReqID(r.Context()))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recoverer recovers from panics of the next handler, logs them with their stack, and responds with a 500 if
+// nothing has been written yet. A handler which panics with http.ErrAbortHandler, such as an export which fails
+// partway, has already begun its response, so the panic is passed on to the http.Server, which closes the
+// connection without completing the response. Clients then see that it was cut short.
+func recoverer(logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rvr := recover()
+				if rvr == nil {
+					return
+				}
+				if rvr == http.ErrAbortHandler {
+					panic(rvr)
+				}
+
+				logger.Error(fmt.Sprintf("recovered from panic serving %s %s: %v", r.Method, r.URL.Path, rvr),
+					zap.String("request_id", middleware.GetReqID(r.Context())), zap.Stack("stack"))
+				w.WriteHeader(http.StatusInternalServerError)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// exportPath is the path of book exports, which stream every matching Book and so may take far longer to write
+// than any other response.
+const exportPath = "/api/v1/books/export"
+
+// connKey is the key of the net.Conn of a request in its context.
+type connKey struct{}
+
+// withConn is the http.Server's ConnContext, which adds the connection to the context of its requests, so that
+// exportDeadline can set its write deadline.
+func withConn(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connKey{}, c)
+}
+
+// exportDeadline gives book exports the export timeout of the Server in place of the write timeout of their
+// connection, which the http.Server sets as each request is read. As the http.Server does not reset a deadline it
+// has not set, the deadline is cleared for other requests when there is no write timeout, in case an earlier
+// export on the connection left it behind.
+func (s *Server) exportDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := r.Context().Value(connKey{}).(net.Conn); ok {
+			switch {
+			case r.URL.Path == exportPath:
+				var deadline time.Time
+				if s.exportTimeout > 0 {
+					deadline = time.Now().Add(s.exportTimeout)
+				}
+				_ = c.SetWriteDeadline(deadline)
+			case s.http.WriteTimeout == 0:
+				_ = c.SetWriteDeadline(time.Time{})
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Limits bound the connections of a Server. A zero timeout leaves that phase of a connection unbounded, and a
+// zero MaxHeaderBytes is http.DefaultMaxHeaderBytes. Book exports are bounded by the ExportTimeout rather than
+// the WriteTimeout, as they stream every matching Book.
+type Limits struct {
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	ExportTimeout     time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+}
+
+// Serve accepts connections on the listener, bounded by the Limits, until the Server is shut down, in which
+// case nil is returned. Otherwise the error which stopped it is returned.
+func (s *Server) Serve(listener net.Listener, limits Limits) error {
+	s.http.ReadTimeout = limits.ReadTimeout
+	s.http.ReadHeaderTimeout = limits.ReadHeaderTimeout
+	s.http.WriteTimeout = limits.WriteTimeout
+	s.exportTimeout = limits.ExportTimeout
+	s.http.IdleTimeout = limits.IdleTimeout
+	s.http.MaxHeaderBytes = limits.MaxHeaderBytes
+
+	if err := s.http.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeTLS is Serve over TLS, which is terminated with the certificates of the TLS. If the TLS verifies client
+// certificates, then requests which write resources must present one.
+func (s *Server) ServeTLS(listener net.Listener, limits Limits, certs *TLS) error {
+	s.tls = certs
+	return s.Serve(tls.NewListener(listener, certs.listenerConfig()), limits)
+}
+
+// Shutdown stops the Server from accepting connections, and waits for in-flight requests to complete. From the
+// moment it is called /readyz reports that the Server is shutting down, and connections are still accepted for
+// the delay, so that load balancers stop routing requests to the Server before it stops listening. If the
+// context ends first, then the remaining connections are closed and the context's error is returned.
+func (s *Server) Shutdown(ctx context.Context, delay time.Duration) error {
+	atomic.StoreInt32(&s.shuttingDown, 1)
+
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+	}
+
+	if err := s.http.Shutdown(ctx); err != nil {
+		_ = s.http.Close()
+		return err
+	}
+	return nil
 }
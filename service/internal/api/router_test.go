@@ -1,9 +1,18 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
@@ -14,8 +23,12 @@ import (
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre/genretest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size/sizetest"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestNew(t *testing.T) {
@@ -48,7 +61,7 @@ func TestNew(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			server, err := New(tt.authorDriver, tt.sizeDriver, tt.genreDriver, tt.eraDriver, tt.bookDriver, zap.NewNop())
+			server, err := New(tt.authorDriver, tt.sizeDriver, tt.genreDriver, tt.eraDriver, tt.bookDriver, zap.NewNop(), v1.Options{})
 			tt.errAssertion(t, err)
 			tt.valAssertion(t, server)
 			if server != nil {
@@ -66,3 +79,224 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_Shutdown(t *testing.T) {
+
+	tests := map[string]struct {
+		// release is how long the in-flight request takes to complete once shutdown has begun.
+		release      time.Duration
+		timeout      time.Duration
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"in-flight requests are drained": {
+			release:      50 * time.Millisecond,
+			timeout:      time.Second,
+			errAssertion: assert.NoError,
+		},
+		"drain deadline is exceeded": {
+			release: time.Second,
+			timeout: 50 * time.Millisecond,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// The handler may outlive the subtest, so it does not refer to tt.
+			started, release := make(chan struct{}), tt.release
+			driver := booktest.DriverMock{}
+			driver.
+				On("SearchBooks", mock.Anything, book.SearchInput{}).
+				Run(func(mock.Arguments) {
+					close(started)
+					time.Sleep(release)
+				}).
+				Return(book.Page{Books: books}, nil)
+
+			server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &driver, zap.NewNop(), v1.Options{})
+			require.NoError(t, err)
+
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			served := make(chan error, 1)
+			go func() { served <- server.Serve(l, Limits{ReadHeaderTimeout: time.Second}) }()
+
+			responded := make(chan int, 1)
+			go func() {
+				res, err := http.Get(fmt.Sprintf("http://%s/api/v1/books", l.Addr()))
+				if err != nil {
+					responded <- 0
+					return
+				}
+				_ = res.Body.Close()
+				responded <- res.StatusCode
+			}()
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			tt.errAssertion(t, server.Shutdown(ctx, 0))
+			assert.NoError(t, <-served)
+
+			if tt.release < tt.timeout {
+				assert.Equal(t, http.StatusOK, <-responded)
+			} else {
+				assert.Zero(t, <-responded, "the connection should have been closed")
+			}
+
+			// Once shut down, connections are refused.
+			_, err = http.Get(fmt.Sprintf("http://%s/api/v1/books", l.Addr()))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestServer_Serve_Limits(t *testing.T) {
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(l, Limits{MaxHeaderBytes: 1 << 10}) }()
+	defer func() { _ = server.Shutdown(context.Background(), 0) }()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s/api/v1/books", l.Addr()), nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Padding", strings.Repeat("a", 8<<10))
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, res.StatusCode)
+}
+
+func TestServer_Export(t *testing.T) {
+
+	tests := map[string]struct {
+		limits Limits
+		opts   v1.Options
+		// delay is how long the driver takes before it streams the books.
+		delay     time.Duration
+		err       error
+		truncated bool
+	}{
+		"export completes": {},
+		"driver fails after streaming books": {
+			err:       errors.New("connection reset"),
+			truncated: true,
+		},
+		"driver fails after streaming books held for spec validation": {
+			opts:      v1.Options{SpecValidation: v1.SpecValidationReject},
+			err:       errors.New("connection reset"),
+			truncated: true,
+		},
+		"export outlasts the write timeout": {
+			limits: Limits{WriteTimeout: 50 * time.Millisecond, ExportTimeout: time.Second},
+			delay:  150 * time.Millisecond,
+		},
+		"export outlasts the export timeout": {
+			limits:    Limits{WriteTimeout: time.Second, ExportTimeout: 50 * time.Millisecond},
+			delay:     150 * time.Millisecond,
+			truncated: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			delay := tt.delay
+			driver := booktest.DriverMock{}
+			driver.
+				On("ExportBooks", mock.Anything, book.SearchInput{}).
+				Run(func(mock.Arguments) { time.Sleep(delay) }).
+				Return(books, tt.err)
+
+			server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &driver, zap.NewNop(), tt.opts)
+			require.NoError(t, err)
+
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() { _ = server.Serve(l, tt.limits) }()
+			defer func() { _ = server.Shutdown(context.Background(), 0) }()
+
+			// The export is requested through the whole middleware stack, so a truncated catalog must reach the
+			// client as an error rather than as a complete response.
+			res, err := http.Get(fmt.Sprintf("http://%s/api/v1/books/export", l.Addr()))
+			if err == nil {
+				_, err = io.ReadAll(res.Body)
+				_ = res.Body.Close()
+			}
+
+			if tt.truncated {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		})
+	}
+}
+
+func TestServer_Recoverer(t *testing.T) {
+	core, logs := observer.New(zap.ErrorLevel)
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.New(core), v1.Options{})
+	require.NoError(t, err)
+
+	// The genre driver has not been told to expect ListGenres, so it panics.
+	rec := httptest.NewRecorder()
+	server.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/genres", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	entries := logs.FilterMessageSnippet("recovered from panic serving GET /api/v1/genres").All()
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0].ContextMap(), "stack")
+}
+
+func TestServer_RequestID(t *testing.T) {
+	ts := httptest.NewServer(newTestServer(t).mux)
+	defer ts.Close()
+
+	tests := map[string]struct {
+		requestID string
+	}{
+		"generated request ID": {},
+		"client's request ID":  {requestID: "client-id"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, ts.URL+"/healthz", nil)
+			require.NoError(t, err)
+			if tt.requestID != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.requestID)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			// Every response carries the ID of its request.
+			got := res.Header.Get(middleware.RequestIDHeader)
+			assert.NotEmpty(t, got)
+			if tt.requestID != "" {
+				assert.Equal(t, tt.requestID, got)
+			}
+		})
+	}
+}
+
+func TestServer_NotFound(t *testing.T) {
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
+	require.NoError(t, err)
+
+	for _, path := range []string{"/missing", "/api/v1/missing"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		server.mux.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code, path)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"), path)
+		assert.Equal(t, `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: `+path+` does not exist"}`+"\n", rec.Body.String(), path)
+	}
+}
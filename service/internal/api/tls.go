@@ -0,0 +1,187 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// TLSFiles are the PEM files which a Server terminates TLS with.
+type TLSFiles struct {
+	// Cert and Key are the certificate chain and private key which the Server presents.
+	Cert string
+	Key  string
+
+	// ClientCA is the certificate authority which client certificates are verified against. If it is set, then
+	// clients may present a certificate, and requests which write resources must present one.
+	ClientCA string
+}
+
+// TLS holds the certificates loaded from TLSFiles. The files are checked for changes at most once per
+// reloadInterval, as connections are accepted, and reloaded if they have changed, so that certificates can be
+// rotated without a restart. If a reload fails, then the previous certificates are served until the files change
+// again.
+type TLS struct {
+	files          TLSFiles
+	logger         *zap.Logger
+	reloadInterval time.Duration
+
+	mu      sync.Mutex
+	checked time.Time
+	stamps  []fileStamp
+	config  *tls.Config
+}
+
+// fileStamp identifies the version of a file, so that changes to it can be detected.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// LoadTLS loads the TLSFiles. If any of them cannot be read or parsed, then an error is returned.
+func LoadTLS(files TLSFiles, logger *zap.Logger) (*TLS, error) {
+	if logger == nil {
+		return nil, errors.New("a non-nil logger is required")
+	}
+	if files.Cert == "" || files.Key == "" {
+		return nil, errors.New("both a TLS certificate and key are required")
+	}
+
+	t := &TLS{files: files, logger: logger, reloadInterval: time.Second}
+	stamps, err := t.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.load(stamps); err != nil {
+		return nil, err
+	}
+	t.checked = time.Now()
+	return t, nil
+}
+
+// verifiesClients returns true if client certificates are verified against a ClientCA.
+func (t *TLS) verifiesClients() bool {
+	return t.files.ClientCA != ""
+}
+
+// listenerConfig returns the tls.Config of a listener, which serves the latest certificates to every connection.
+func (t *TLS) listenerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: t.configForClient,
+	}
+}
+
+// configForClient returns the tls.Config of a connection, after reloading the TLSFiles if they have changed.
+func (t *TLS) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if time.Since(t.checked) < t.reloadInterval {
+		return t.config, nil
+	}
+	t.checked = time.Now()
+
+	stamps, err := t.stat()
+	if err != nil {
+		// The files may be midway through being replaced, so they are checked again later.
+		t.logger.Warn(fmt.Sprintf("unable to check TLS files for changes: %s", err))
+		return t.config, nil
+	}
+	if equalStamps(stamps, t.stamps) {
+		return t.config, nil
+	}
+
+	if err := t.load(stamps); err != nil {
+		// The stamps are kept so that the error is only logged once for every change of the files.
+		t.stamps = stamps
+		t.logger.Error(fmt.Sprintf("unable to reload TLS files, so the previous certificates are served: %s", err))
+		return t.config, nil
+	}
+	t.logger.Info("reloaded TLS certificates")
+	return t.config, nil
+}
+
+// load reads the TLSFiles, which had the stamps, into a tls.Config.
+func (t *TLS) load(stamps []fileStamp) error {
+	cert, err := tls.LoadX509KeyPair(t.files.Cert, t.files.Key)
+	if err != nil {
+		return fmt.Errorf("unable to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if t.verifiesClients() {
+		data, err := os.ReadFile(t.files.ClientCA)
+		if err != nil {
+			return fmt.Errorf("unable to read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no PEM certificates found in client CA %s", t.files.ClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	t.config, t.stamps = config, stamps
+	return nil
+}
+
+// stat returns the fileStamps of the TLSFiles.
+func (t *TLS) stat() ([]fileStamp, error) {
+	var stamps []fileStamp
+	for _, name := range []string{t.files.Cert, t.files.Key, t.files.ClientCA} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{modTime: info.ModTime(), size: info.Size()})
+	}
+	return stamps, nil
+}
+
+// equalStamps returns true if both slices hold the same fileStamps.
+func equalStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
+
+// requireClientCert rejects requests which write resources with 403 Forbidden, unless their connection
+// presented a client certificate that was verified against the ClientCA. Requests are only checked while the
+// Server is serving TLS with a ClientCA, and reads and CORS preflights are never checked.
+func (s *Server) requireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if s.tls != nil && s.tls.verifiesClients() && (r.TLS == nil || len(r.TLS.VerifiedChains) == 0) {
+			v1.RenderProblem(w, v1.ErrForbidden(errors.New("a verified client certificate is required to write resources")))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/LeviMatus/readcommend/service/internal/api/v1"
+	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era/eratest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre/genretest"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size/sizetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testCert is a certificate and its private key, which may sign other certificates.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCert creates a certificate for the common name, which is signed by the parent or, if it is nil, by
+// itself as a certificate authority.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// write writes the certificate and its key to cert.pem and key.pem in the directory.
+func (c *testCert) write(t *testing.T, dir string) TLSFiles {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	files := TLSFiles{Cert: filepath.Join(dir, "cert.pem"), Key: filepath.Join(dir, "key.pem")}
+	require.NoError(t, os.WriteFile(files.Cert, c.pem, 0600))
+	require.NoError(t, os.WriteFile(files.Key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600))
+	return files
+}
+
+// tlsCertificate returns the certificate as a tls.Certificate, which a client can present.
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// serveTLS serves a Server over TLS with the certificates, until the test ends. The URL of the Server is returned.
+func serveTLS(t *testing.T, certs *TLS) string {
+	t.Helper()
+
+	server, err := New(&authortest.DriverMock{}, &sizetest.DriverMock{}, &genretest.DriverMock{}, &eratest.DriverMock{}, &booktest.DriverMock{}, zap.NewNop(), v1.Options{})
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.ServeTLS(l, Limits{}, certs) }()
+	t.Cleanup(func() { _ = server.http.Close() })
+	return fmt.Sprintf("https://%s", l.Addr())
+}
+
+// tlsClient returns a client which trusts the certificate authority, and presents the certificate if it is not nil.
+// The certificate is presented even if the server's client CA did not sign it, which Certificates would not do.
+func tlsClient(ca *testCert, cert *testCert) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: roots}
+	if cert != nil {
+		c := cert.tlsCertificate()
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) { return &c, nil }
+	}
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config, DisableKeepAlives: true}}
+}
+
+func TestLoadTLS(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	dir := t.TempDir()
+	files := newTestCert(t, "server", ca).write(t, dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.pem, 0600))
+	notPEM := filepath.Join(dir, "ca.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+
+	tests := map[string]struct {
+		files        TLSFiles
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"certificate and key": {
+			files:        files,
+			errAssertion: assert.NoError,
+		},
+		"client CA": {
+			files:        TLSFiles{Cert: files.Cert, Key: files.Key, ClientCA: caFile},
+			errAssertion: assert.NoError,
+		},
+		"missing key": {
+			files:        TLSFiles{Cert: files.Cert},
+			errAssertion: assert.Error,
+		},
+		"key does not exist": {
+			files:        TLSFiles{Cert: files.Cert, Key: filepath.Join(dir, "missing.pem")},
+			errAssertion: assert.Error,
+		},
+		"key is not the certificate's": {
+			files:        TLSFiles{Cert: files.Cert, Key: newTestCert(t, "other", ca).write(t, t.TempDir()).Key},
+			errAssertion: assert.Error,
+		},
+		"client CA has no certificates": {
+			files:        TLSFiles{Cert: files.Cert, Key: files.Key, ClientCA: notPEM},
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadTLS(tt.files, zap.NewNop())
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestServer_ServeTLS_ClientCert(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	dir := t.TempDir()
+	files := newTestCert(t, "server", ca).write(t, dir)
+	files.ClientCA = filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(files.ClientCA, ca.pem, 0600))
+
+	certs, err := LoadTLS(files, zap.NewNop())
+	require.NoError(t, err)
+	url := serveTLS(t, certs)
+
+	untrusted := newTestCert(t, "untrusted", nil)
+
+	tests := map[string]struct {
+		method     string
+		clientCert *testCert
+		// status is the expected status. Requests which pass the check are not routed, or fail to decode their
+		// empty body, so that no driver is called.
+		status int
+	}{
+		"reads need no certificate": {
+			method: http.MethodGet,
+			status: http.StatusNotFound,
+		},
+		"writes need a certificate": {
+			method: http.MethodPost,
+			status: http.StatusForbidden,
+		},
+		"writes with a verified certificate": {
+			method:     http.MethodPost,
+			clientCert: newTestCert(t, "admin", ca),
+			status:     http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := "/api/v1/books/facets/x"
+			if tt.method == http.MethodPost {
+				path = "/api/v1/genres"
+			}
+			req, err := http.NewRequest(tt.method, url+path, strings.NewReader(""))
+			require.NoError(t, err)
+
+			res, err := tlsClient(ca, tt.clientCert).Do(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+			assert.Equal(t, tt.status, res.StatusCode)
+		})
+	}
+
+	// Certificates which the client CA did not sign are rejected during the handshake.
+	_, err = tlsClient(ca, untrusted).Get(url + "/api/v1/genres")
+	assert.Error(t, err)
+}
+
+func TestServer_ServeTLS_Reload(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	dir := t.TempDir()
+	files := newTestCert(t, "first", ca).write(t, dir)
+
+	certs, err := LoadTLS(files, zap.NewNop())
+	require.NoError(t, err)
+	certs.reloadInterval = 0
+	url := serveTLS(t, certs)
+
+	// servedName returns the common name of the certificate which the server presents.
+	servedName := func() string {
+		res, err := tlsClient(ca, nil).Get(url + "/api/v1/books/facets/x")
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		return res.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	assert.Equal(t, "first", servedName())
+
+	// The modification time is moved on, since the files may be rewritten within its resolution.
+	rotate := func(name string) {
+		newTestCert(t, name, ca).write(t, dir)
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(files.Cert, later, later))
+	}
+	rotate("second")
+	assert.Equal(t, "second", servedName())
+
+	// A certificate which cannot be loaded is ignored, and the previous one is served until the files change again.
+	require.NoError(t, os.WriteFile(files.Key, []byte("corrupt"), 0600))
+	assert.Equal(t, "second", servedName())
+
+	rotate("third")
+	assert.Equal(t, "third", servedName())
+}
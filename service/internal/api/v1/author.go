@@ -1,8 +1,9 @@
 package v1
 
 import (
-	"fmt"
 	"net/http"
+	"path"
+	"strconv"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
@@ -17,13 +18,17 @@ func authorRoutes(h *authorHandler) chi.Router {
 	r := chi.NewRouter()
 	r.Route("/", func(r chi.Router) {
 		r.Use(
-			cors.Handler(cors.Options{AllowedMethods: []string{"GET"}}),
+			cors.Handler(cors.Options{
+				AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+				ExposedHeaders: []string{"Location"},
+			}),
 		)
-		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-			_ = render.Render(w, r, ErrMethodNotAllowed(r.Method))
-			return
-		})
+		r.MethodNotAllowed(renderMethodNotAllowed)
 		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get(idParam, h.Get)
+		r.Put(idParam, h.Replace)
+		r.Delete(idParam, h.Delete)
 	})
 	return r
 }
@@ -32,6 +37,26 @@ func authorRoutes(h *authorHandler) chi.Router {
  * Request and Response payloads/models for the REST api.
  **********************************************************/
 
+// AuthorWriteRequest is the request model used for writing entity.Author types. It is decoded from the JSON
+// body of POST and PUT requests, which require every attribute.
+type AuthorWriteRequest struct {
+	_ struct{}
+
+	FirstName *string `json:"firstName"`
+	LastName  *string `json:"lastName"`
+}
+
+// decodeAuthorWriteRequest decodes the JSON body of the http.Request into an AuthorWriteRequest, and maps it
+// to an author.WriteInput. If the body is not valid JSON, then an error wrapping entity.ErrInvalidBody is returned.
+func decodeAuthorWriteRequest(w http.ResponseWriter, r *http.Request) (author.WriteInput, error) {
+	var req AuthorWriteRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return author.WriteInput{}, err
+	}
+
+	return author.WriteInput{FirstName: req.FirstName, LastName: req.LastName}, nil
+}
+
 // AuthorResponse is the response struct sent back to the client.
 // Currently it embeds a pointer to entity.Author. In the future it would be
 // possible to separate the two models and perform mapping if necessary.
@@ -59,6 +84,46 @@ func newAuthorListResponse(authors []entity.Author) []render.Renderer {
 	return out
 }
 
+// AuthorStatsResponse holds aggregates over the books written by an author.
+type AuthorStatsResponse struct {
+	BookCount          int64    `json:"bookCount"`
+	AverageRating      *float64 `json:"averageRating,omitempty"`
+	FirstYearPublished *int16   `json:"firstYearPublished,omitempty"`
+	LastYearPublished  *int16   `json:"lastYearPublished,omitempty"`
+}
+
+// AuthorDetailResponse is the response struct sent back to the client for a single author. It embeds
+// the entity.Author along with aggregates over, and the top-rated of, the author's books.
+type AuthorDetailResponse struct {
+	entity.Author
+	Stats    AuthorStatsResponse `json:"stats"`
+	TopBooks []*BookResponse     `json:"topBooks"`
+}
+
+// newAuthorDetailResponse accepts an author.Detail and maps it into an AuthorDetailResponse.
+func newAuthorDetailResponse(detail author.Detail) *AuthorDetailResponse {
+	books := make([]*BookResponse, len(detail.TopBooks))
+	for i, b := range detail.TopBooks {
+		books[i] = newBookResponse(b)
+	}
+
+	return &AuthorDetailResponse{
+		Author: detail.Author,
+		Stats: AuthorStatsResponse{
+			BookCount:          detail.Stats.BookCount,
+			AverageRating:      detail.Stats.AverageRating,
+			FirstYearPublished: detail.Stats.FirstYearPublished,
+			LastYearPublished:  detail.Stats.LastYearPublished,
+		},
+		TopBooks: books,
+	}
+}
+
+// Render is a stub for preprocessing the AuthorDetailResponse model.
+func (adr *AuthorDetailResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 /*****************************
  * v1 Author endpoint handlers
  *****************************/
@@ -84,14 +149,111 @@ func NewAuthorHandler(driver author.Driver, logger *zap.Logger) (*authorHandler,
 func (handler *authorHandler) List(w http.ResponseWriter, r *http.Request) {
 	authors, err := handler.driver.ListAuthors(r.Context())
 	if err != nil {
-		handler.logger.Error(fmt.Sprintf("error listing authors: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "listing authors")
 		return
 	}
 
 	if err := render.RenderList(w, r, newAuthorListResponse(authors)); err != nil {
-		handler.logger.Error(fmt.Sprintf("error rendering authors: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "rendering authors")
+		return
+	}
+}
+
+// Get is an HTTP method that renders the entity.Author identified by the URL parameter, along with aggregates
+// over the author's books. If the Author does not exist, then a 404 status code is returned.
+func (handler *authorHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "getting author")
+		return
+	}
+
+	detail, err := handler.driver.GetAuthor(r.Context(), id)
+	if err != nil {
+		renderError(w, handler.logger, err, "getting author")
+		return
+	}
+
+	if err := render.Render(w, r, newAuthorDetailResponse(detail)); err != nil {
+		renderError(w, handler.logger, err, "rendering author")
+		return
+	}
+}
+
+// Create is an HTTP method that validates the AuthorWriteRequest in the body and creates an entity.Author from
+// it. The created Author is rendered with a 201 status code and its URL in the Location header. If the Author is
+// invalid, then a 422 status code is returned along with the invalid fields.
+func (handler *authorHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeAuthorWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating author")
 		return
 	}
+
+	a, err := handler.driver.CreateAuthor(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating author")
+		return
+	}
+
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(int(a.ID))))
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, newAuthorResponse(a)); err != nil {
+		renderError(w, handler.logger, err, "rendering author")
+		return
+	}
+}
+
+// Replace is an HTTP method that validates the AuthorWriteRequest in the body and replaces every attribute of
+// the entity.Author identified by the URL parameter with it. The updated Author is rendered. If the Author does
+// not exist, then a 404 status code is returned. If the Author is invalid, then a 422 status code is returned
+// along with the invalid fields.
+func (handler *authorHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating author")
+		return
+	}
+
+	params, err := decodeAuthorWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating author")
+		return
+	}
+
+	a, err := handler.driver.ReplaceAuthor(r.Context(), id, params)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating author")
+		return
+	}
+
+	if err := render.Render(w, r, newAuthorResponse(a)); err != nil {
+		renderError(w, handler.logger, err, "rendering author")
+		return
+	}
+}
+
+// Delete is an HTTP method that deletes the entity.Author identified by the URL parameter. A 204 status code is
+// returned on success. If the Author does not exist, then a 404 status code is returned. If Books still
+// reference the Author, then a 409 status code is returned, unless the reassign-to query parameter identifies
+// another Author to reassign them to.
+func (handler *authorHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting author")
+		return
+	}
+
+	target, err := reassignTo(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting author")
+		return
+	}
+
+	if err := handler.driver.DeleteAuthor(r.Context(), id, target); err != nil {
+		renderError(w, handler.logger, err, "deleting author")
+		return
+	}
+
+	render.NoContent(w, r)
 }
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi/openapitest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/driver/author/authortest"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -59,6 +62,7 @@ func TestAuthorHandler_List(t *testing.T) {
 		expectedHandler string
 		expectedBody    string
 		expectedCode    int
+		expectedAllow   string
 		expectedErr     error
 		sendRequest     func(string) (*http.Response, error)
 	}{
@@ -76,18 +80,23 @@ func TestAuthorHandler_List(t *testing.T) {
 			expectedHandler: "ListAuthors",
 			target:          "/",
 			driverReturn:    []entity.Author{mockAuthor},
-			expectedBody:    `{"message":"HTTP method POST is not allowed"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Method Not Allowed","status":405,"code":"method_not_allowed","detail":"HTTP method PATCH is not allowed"}`,
+			expectedCode:    405,
+			expectedAllow:   "GET, POST",
 			sendRequest: func(url string) (*http.Response, error) {
-				return http.Post(url, "application/json", nil)
+				req, err := http.NewRequest(http.MethodPatch, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				return http.DefaultClient.Do(req)
 			},
 		},
 		"driver returns error": {
 			expectedHandler: "ListAuthors",
 			target:          "/",
 			driverReturn:    []entity.Author{},
-			expectedBody:    `{"message":"Internal Server Error"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode:    500,
 			expectedErr:     errors.New("mock error returned from driver"),
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -102,8 +111,7 @@ func TestAuthorHandler_List(t *testing.T) {
 
 			r := authorRoutes(&handler)
 
-			server := httptest.NewServer(r)
-			defer server.Close()
+			server := openapitest.NewServer(t, "/authors", r)
 
 			driverMock.
 				On(tt.expectedHandler, mock.MatchedBy(func(_ context.Context) bool { return true })).
@@ -112,6 +120,96 @@ func TestAuthorHandler_List(t *testing.T) {
 			resp, err := tt.sendRequest(fmt.Sprintf("%s%s", server.URL, tt.target))
 			assert.NoError(t, err)
 
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedAllow, resp.Header.Get("Allow"))
+			assert.Equal(t, tt.expectedBody+"\n", string(body))
+		})
+	}
+}
+
+func TestAuthorHandler_Get(t *testing.T) {
+	detail := author.Detail{
+		Author: entity.Author{ID: 1, FirstName: "John", LastName: "Tolkien"},
+		Stats: author.Stats{
+			BookCount:          1,
+			AverageRating:      util.Float64Ptr(3.9),
+			FirstYearPublished: util.Int16Ptr(1977),
+			LastYearPublished:  util.Int16Ptr(1977),
+		},
+		TopBooks: []entity.Book{{
+			ID:            1,
+			Title:         "The Silmarillion",
+			YearPublished: 1977,
+			Rating:        3.9,
+			Pages:         365,
+			Genre:         entity.Genre{ID: 2, Title: "Fantasy/SciFi"},
+			Author:        entity.Author{ID: 1, FirstName: "John", LastName: "Tolkien"},
+		}},
+	}
+
+	tests := map[string]struct {
+		target       string
+		expectedID   int32
+		driverReturn author.Detail
+		expectedBody string
+		expectedCode int
+		expectedErr  error
+	}{
+		"get author": {
+			target:       "/1",
+			expectedID:   1,
+			driverReturn: detail,
+			expectedBody: `{"id":1,"firstName":"John","lastName":"Tolkien",` +
+				`"stats":{"bookCount":1,"averageRating":3.9,"firstYearPublished":1977,"lastYearPublished":1977},` +
+				`"topBooks":[{"id":1,"title":"The Silmarillion","yearPublished":1977,"rating":3.9,"pages":365,` +
+				`"genre":{"id":2,"title":"Fantasy/SciFi"},"author":{"id":1,"firstName":"John","lastName":"Tolkien"}}]}`,
+			expectedCode: 200,
+		},
+		"get author without books": {
+			target:       "/2",
+			expectedID:   2,
+			driverReturn: author.Detail{Author: entity.Author{ID: 2, FirstName: "Jane", LastName: "Doe"}},
+			expectedBody: `{"id":2,"firstName":"Jane","lastName":"Doe","stats":{"bookCount":0},"topBooks":[]}`,
+			expectedCode: 200,
+		},
+		"author does not exist": {
+			target:       "/9",
+			expectedID:   9,
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: author 9 does not exist"}`,
+			expectedCode: 404,
+			expectedErr:  fmt.Errorf("%w: author 9 does not exist", entity.ErrNotFound),
+		},
+		"id out of range": {
+			target:       "/9999999999",
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: 9999999999 does not exist"}`,
+			expectedCode: 404,
+		},
+		"driver returns error": {
+			target:       "/1",
+			expectedID:   1,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode: 500,
+			expectedErr:  errors.New("mock error returned from driver"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := authortest.DriverMock{}
+			handler := authorHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/authors", authorRoutes(&handler))
+
+			driverMock.
+				On("GetAuthor", mock.MatchedBy(func(_ context.Context) bool { return true }), tt.expectedID).
+				Return(tt.driverReturn, tt.expectedErr)
+
+			resp, err := http.Get(fmt.Sprintf("%s%s", server.URL, tt.target))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
 			body, err := ioutil.ReadAll(resp.Body)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedCode, resp.StatusCode)
@@ -119,3 +217,158 @@ func TestAuthorHandler_List(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorHandler_Write(t *testing.T) {
+	var (
+		anyContext = mock.MatchedBy(func(_ context.Context) bool { return true })
+		leGuin     = entity.Author{ID: 37, FirstName: "Ursula", LastName: "Le Guin"}
+		leGuinJson = `{"id":37,"firstName":"Ursula","lastName":"Le Guin"}`
+		leGuinBody = `{"firstName":"Ursula","lastName":"Le Guin"}`
+		leGuinArgs = author.WriteInput{FirstName: util.StringPtr("Ursula"), LastName: util.StringPtr("Le Guin")}
+	)
+
+	tests := map[string]struct {
+		method          string
+		target          string
+		body            string
+		expectedHandler string
+		expectedArgs    []interface{}
+		driverReturn    []interface{}
+		expectedBody    string
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		"create author": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            leGuinBody,
+			expectedHandler: "CreateAuthor",
+			expectedArgs:    []interface{}{anyContext, leGuinArgs},
+			driverReturn:    []interface{}{leGuin, nil},
+			expectedBody:    leGuinJson,
+			expectedCode:    201,
+			expectedHeaders: map[string]string{"Location": "/37"},
+		},
+		"create invalid author": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            `{"lastName":"Le Guin"}`,
+			expectedHandler: "CreateAuthor",
+			expectedArgs:    []interface{}{anyContext, author.WriteInput{LastName: util.StringPtr("Le Guin")}},
+			driverReturn: []interface{}{entity.Author{}, &entity.ValidationError{Fields: []entity.FieldError{
+				{Field: "firstName", Code: validation.CodeRequired, Message: "is required"},
+			}}},
+			expectedBody: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"code":"invalid_entity","detail":"invalid entity provided","fields":[{"field":"firstName","code":"required","message":"is required"}]}`,
+			expectedCode: 422,
+		},
+		"create author with malformed body": {
+			method:       http.MethodPost,
+			target:       "/",
+			body:         `{"firstName":`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: unexpected EOF"}`,
+			expectedCode: 400,
+		},
+		"create author with unknown field": {
+			method:       http.MethodPost,
+			target:       "/",
+			body:         `{"firstName":"Ursula","surname":"Le Guin"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: json: unknown field \"surname\""}`,
+			expectedCode: 400,
+		},
+		"replace author": {
+			method:          http.MethodPut,
+			target:          "/37",
+			body:            leGuinBody,
+			expectedHandler: "ReplaceAuthor",
+			expectedArgs:    []interface{}{anyContext, int32(37), leGuinArgs},
+			driverReturn:    []interface{}{leGuin, nil},
+			expectedBody:    leGuinJson,
+			expectedCode:    200,
+		},
+		"replace missing author": {
+			method:          http.MethodPut,
+			target:          "/9",
+			body:            leGuinBody,
+			expectedHandler: "ReplaceAuthor",
+			expectedArgs:    []interface{}{anyContext, int32(9), leGuinArgs},
+			driverReturn:    []interface{}{entity.Author{}, fmt.Errorf("%w: author 9 does not exist", entity.ErrNotFound)},
+			expectedBody:    `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: author 9 does not exist"}`,
+			expectedCode:    404,
+		},
+		"delete author": {
+			method:          http.MethodDelete,
+			target:          "/37",
+			expectedHandler: "DeleteAuthor",
+			expectedArgs:    []interface{}{anyContext, int32(37), (*int32)(nil)},
+			driverReturn:    []interface{}{nil},
+			expectedCode:    204,
+		},
+		"delete referenced author": {
+			method:          http.MethodDelete,
+			target:          "/1",
+			expectedHandler: "DeleteAuthor",
+			expectedArgs:    []interface{}{anyContext, int32(1), (*int32)(nil)},
+			driverReturn: []interface{}{fmt.Errorf("%w: author 1 still has 2 books, which must be reassigned to another author",
+				entity.ErrConflict)},
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"code":"conflict","detail":"conflict with the current state of the resources: ` +
+				`author 1 still has 2 books, which must be reassigned to another author"}`,
+			expectedCode: 409,
+		},
+		"delete author with reassignment": {
+			method:          http.MethodDelete,
+			target:          "/1?reassign-to=37",
+			expectedHandler: "DeleteAuthor",
+			expectedArgs:    []interface{}{anyContext, int32(1), util.Int32Ptr(37)},
+			driverReturn:    []interface{}{nil},
+			expectedCode:    204,
+		},
+		"delete author with reassignment to missing author": {
+			method:          http.MethodDelete,
+			target:          "/1?reassign-to=9",
+			expectedHandler: "DeleteAuthor",
+			expectedArgs:    []interface{}{anyContext, int32(1), util.Int32Ptr(9)},
+			driverReturn:    []interface{}{fmt.Errorf("%w: author 9 does not exist", entity.ErrInvalidQueryParam)},
+			expectedBody:    `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided: author 9 does not exist"}`,
+			expectedCode:    400,
+		},
+		"delete author with malformed reassignment": {
+			method:       http.MethodDelete,
+			target:       "/1?reassign-to=tolkien",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided: reassign-to must be an ID but is \"tolkien\""}`,
+			expectedCode: 400,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := authortest.DriverMock{}
+			handler := authorHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/authors", authorRoutes(&handler))
+
+			if tt.expectedHandler != "" {
+				driverMock.On(tt.expectedHandler, tt.expectedArgs...).Return(tt.driverReturn...)
+			}
+
+			req, err := http.NewRequest(tt.method, fmt.Sprintf("%s%s", server.URL, tt.target), strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.expectedBody != "" {
+				tt.expectedBody += "\n"
+			}
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
+			driverMock.AssertExpectations(t)
+		})
+	}
+}
@@ -3,41 +3,57 @@ package v1
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
+	"path"
+	"strconv"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
-	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/render"
-	"github.com/gorilla/schema"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 const (
-	minimumPageParam int16 = 1
-	maximumPageParam int16 = 10000
-	minimumYearParam int16 = 1800
-	maximumYearParam int16 = 2100
-
 	bookSearchParamKey = "book-search-params"
+
+	// nextCursorHeader carries the cursor to pass as the "cursor" query parameter to fetch the next page.
+	nextCursorHeader = "Next-Cursor"
+
+	// hasMoreHeader is "true" if there are more results after the current page, otherwise "false".
+	hasMoreHeader = "Has-More"
 )
 
+// exportContentTypes are the Content-Types of the catalog formats which books can be exported in.
+var exportContentTypes = map[string]string{
+	catalog.FormatCSV:   "text/csv; charset=utf-8",
+	catalog.FormatJSON:  "application/json",
+	catalog.FormatJSONL: "application/x-ndjson",
+}
+
 func bookRoutes(h *bookHandler) chi.Router {
 	r := chi.NewRouter()
+	r.Use(
+		cors.Handler(cors.Options{
+			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			ExposedHeaders: []string{nextCursorHeader, hasMoreHeader, "Location", "Content-Disposition"},
+		}),
+	)
+	r.MethodNotAllowed(renderMethodNotAllowed)
+	r.Post("/", h.Create)
+	r.Get(idParam, h.Get)
+	r.Put(idParam, h.Replace)
+	r.Patch(idParam, h.Update)
+	r.Delete(idParam, h.Delete)
+	r.With(h.ValidateExportRequest).Get("/export", h.Export)
 	r.Route("/", func(r chi.Router) {
-		r.Use(
-			cors.Handler(cors.Options{AllowedMethods: []string{"GET"}}),
-			ValidateBookRequest,
-		)
-		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-			_ = render.Render(w, r, ErrMethodNotAllowed(r.Method))
-			return
-		})
+		r.Use(h.ValidateBookRequest)
 		r.Get("/", h.List)
+		r.Get("/facets", h.Facets)
 	})
 	return r
 }
@@ -52,97 +68,134 @@ type BookRequest struct {
 	_ struct{}
 
 	Title            *string `schema:"title"`
+	Query            *string `schema:"q"`
 	MaxYearPublished *int16  `schema:"max-year"`
 	MinYearPublished *int16  `schema:"min-year"`
 	MaxPages         *int16  `schema:"max-pages"`
 	MinPages         *int16  `schema:"min-pages"`
-	GenreIDs         []int16 `schema:"genres"`
-	AuthorIDs        []int16 `schema:"authors"`
-	Limit            *uint64 `schema:"limit"`
+
+	GenreIDs  []int16 `schema:"genres"`
+	AuthorIDs []int16 `schema:"authors"`
+	EraIDs    []int16 `schema:"eras"`
+	SizeIDs   []int16 `schema:"sizes"`
+	Limit     *uint64 `schema:"limit"`
+	Sort      *string `schema:"sort"`
+	Cursor    *string `schema:"cursor"`
+
+	// Format is the catalog.Writer format of an export. Other endpoints treat it as an unknown parameter.
+	Format *string `schema:"format"`
+
+	// Pages and Year are ranges, such as 100..300 or 1970.., which are shorthands for MinPages and MaxPages,
+	// and MinYearPublished and MaxYearPublished. They cannot be used together with the fields they stand for.
+	Pages *string `schema:"pages"`
+	Year  *string `schema:"year"`
+
+	// input is the validated book.SearchInput. It is populated by ValidateBookRequest.
+	input book.SearchInput
 }
 
-// ValidateBookRequest maps the query parameters to a BookRequest struct, which is injected
-// into the context of the request. As a part of this process, BookRequest.GenreIDs and
-// BookRequest.AuthorIDs are validated. If a string, such as "alpha" appears in the incoming string list,
-// then then validation fails and a 400 StatusCode code is returned.
+// ValidateBookRequest maps the query parameters to a BookRequest struct, which is injected into the context of
+// the request. Every parameter is validated before the request is rejected, so that a 400 Problem lists every
+// invalid parameter as a field with a code, such as "out_of_range", and a message.
 //
-// Following this, the resulting BookRequest is validated. If any search criteria fail validation, then
-// the routine returns a 400 StatusCode code and error message.
-func ValidateBookRequest(next http.Handler) http.Handler {
+// Lists, such as BookRequest.GenreIDs, may be given as comma-delimited values, as repeated parameters, or both.
+// Parameters which cannot be decoded, such as "alpha" in BookRequest.GenreIDs, are invalid, as are bounds outside
+// of their ranges and lower bounds greater than their upper bounds. The pages and year ranges, such as
+// pages=100..300 or year=1970.., are shorthands for the min- and max- parameters. BookRequest.Query must not
+// be blank, BookRequest.Sort must only reference whitelisted fields, and BookRequest.Cursor must be a token
+// previously returned in the Next-Cursor header for the same sort. Unknown parameters are rejected if the
+// handler's Options are strict, and are otherwise ignored. BookRequest.Format is only known to exports, which
+// are validated by ValidateExportRequest instead.
+func (handler *bookHandler) ValidateBookRequest(next http.Handler) http.Handler {
+	return handler.validateBookRequest(next, false)
+}
+
+// ValidateExportRequest is ValidateBookRequest for exports, which also accept BookRequest.Format.
+func (handler *bookHandler) ValidateExportRequest(next http.Handler) http.Handler {
+	return handler.validateBookRequest(next, true)
+}
+
+// validateBookRequest is ValidateBookRequest, which treats BookRequest.Format as an unknown parameter unless the
+// request is an export.
+func (handler *bookHandler) validateBookRequest(next http.Handler, export bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if err := r.ParseForm(); err != nil {
-			_ = render.Render(w, r, ErrBadRequest(fmt.Errorf("an unexpected error occurred: %w", err)))
+		v := validation.New(entity.ErrInvalidQueryParam)
+		queryParams := new(BookRequest)
+		if err := decodeQuery(r, queryParams, handler.opts.StrictQuery, v, handler.logger); err != nil {
+			RenderProblem(w, NewProblem(err))
 			return
 		}
-
-		queryParams := new(BookRequest)
-		if err := schema.NewDecoder().Decode(queryParams, r.Form); err != nil {
-			var schemaErr schema.MultiError
-			if !errors.As(err, &schemaErr) {
-				_ = render.Render(w, r, ErrBadRequest(fmt.Errorf("an unexpected error occured: %w", schemaErr)))
-				return
-			}
-			for k, v := range schemaErr {
-				switch v.(type) {
-				case schema.ConversionError:
-					_ = render.Render(w, r, ErrBadRequest(fmt.Errorf("%w: received wrong type for parameter %s", entity.ErrInvalidQueryParam, k)))
-					return
-				case schema.UnknownKeyError:
-					log.Println("WARN: " + err.Error())
-				}
+		if queryParams.Format != nil && !export {
+			if handler.opts.StrictQuery {
+				v.Add("format", validation.CodeUnknown, "is not a known parameter")
+			} else {
+				handler.logger.Warn("ignoring unknown query parameter format")
 			}
+			queryParams.Format = nil
 		}
 
-		if queryParams.MinPages != nil && !util.Int16InRange(*queryParams.MinPages, minimumPageParam, maximumPageParam) {
-			_ = render.Render(w, r, ErrBadRequest(
-				fmt.Errorf("%w: min-pages is %d but should be in range [%d,%d]",
-					entity.ErrInvalidQueryParam,
-					*queryParams.MinPages,
-					minimumPageParam,
-					maximumPageParam)))
-			return
-		}
+		queryParams.input = book.ValidateSearch(v, queryParams.searchParams())
 
-		if queryParams.MaxPages != nil && !util.Int16InRange(*queryParams.MaxPages, minimumPageParam, maximumPageParam) {
-			_ = render.Render(w, r, ErrBadRequest(
-				fmt.Errorf("%w: max-pages is %d but should be in range [%d,%d]",
-					entity.ErrInvalidQueryParam,
-					*queryParams.MaxPages,
-					minimumPageParam,
-					maximumPageParam)))
+		if err := v.Err(); err != nil {
+			RenderProblem(w, NewProblem(err))
 			return
 		}
 
-		if queryParams.MinYearPublished != nil && !util.Int16InRange(*queryParams.MinYearPublished, minimumYearParam, maximumYearParam) {
-			_ = render.Render(w, r, ErrBadRequest(
-				fmt.Errorf("%w: min-year is %d but should be in range [%d,%d]",
-					entity.ErrInvalidQueryParam,
-					*queryParams.MinYearPublished,
-					minimumYearParam,
-					maximumYearParam)))
-			return
-		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bookSearchParamKey, queryParams)))
+	})
+}
 
-		if queryParams.MaxYearPublished != nil && !util.Int16InRange(*queryParams.MaxYearPublished, minimumYearParam, maximumYearParam) {
-			_ = render.Render(w, r, ErrBadRequest(
-				fmt.Errorf("%w: max-year is %d but should be in range [%d,%d]",
-					entity.ErrInvalidQueryParam,
-					*queryParams.MaxYearPublished,
-					minimumYearParam,
-					maximumYearParam)))
-			return
-		}
+// searchParams maps the BookRequest to the book.SearchParams which it is validated as.
+func (br *BookRequest) searchParams() book.SearchParams {
+	return book.SearchParams{
+		Title:            br.Title,
+		Query:            br.Query,
+		MaxYearPublished: br.MaxYearPublished,
+		MinYearPublished: br.MinYearPublished,
+		MaxPages:         br.MaxPages,
+		MinPages:         br.MinPages,
+		Pages:            br.Pages,
+		Year:             br.Year,
+		GenreIDs:         br.GenreIDs,
+		AuthorIDs:        br.AuthorIDs,
+		EraIDs:           br.EraIDs,
+		SizeIDs:          br.SizeIDs,
+		Limit:            br.Limit,
+		Sort:             br.Sort,
+		Cursor:           br.Cursor,
+	}
+}
 
-		if queryParams.Limit != nil && *queryParams.Limit < 1 {
-			_ = render.Render(w, r, ErrBadRequest(
-				fmt.Errorf("%w: limit is %d but should be greater than 0",
-					entity.ErrInvalidQueryParam,
-					*queryParams.Limit)))
-			return
-		}
+// BookWriteRequest is the request model used for writing entity.Book types. It is decoded from the JSON
+// body of POST, PUT and PATCH requests. Attributes which are omitted from the body are nil. POST and PUT
+// require every attribute, whereas PATCH only changes the attributes which are present.
+type BookWriteRequest struct {
+	_ struct{}
 
-		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bookSearchParamKey, queryParams)))
-	})
+	Title         *string  `json:"title"`
+	YearPublished *int16   `json:"yearPublished"`
+	Rating        *float32 `json:"rating"`
+	Pages         *int16   `json:"pages"`
+	AuthorID      *int32   `json:"authorId"`
+	GenreID       *int32   `json:"genreId"`
+}
+
+// decodeBookWriteRequest decodes the JSON body of the http.Request into a BookWriteRequest, and maps it to
+// a book.WriteInput. If the body is not valid JSON, then an error wrapping entity.ErrInvalidBody is returned.
+func decodeBookWriteRequest(w http.ResponseWriter, r *http.Request) (book.WriteInput, error) {
+	var req BookWriteRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return book.WriteInput{}, err
+	}
+
+	return book.WriteInput{
+		Title:         req.Title,
+		YearPublished: req.YearPublished,
+		Rating:        req.Rating,
+		Pages:         req.Pages,
+		AuthorID:      req.AuthorID,
+		GenreID:       req.GenreID,
+	}, nil
 }
 
 // BookResponse is the response struct sent back to the client.
@@ -172,6 +225,44 @@ func newBookListResponse(books []entity.Book) []render.Renderer {
 	return out
 }
 
+// FacetCountResponse is the number of books that match a search for a single genre, author, era or size.
+type FacetCountResponse struct {
+	ID    int32 `json:"id"`
+	Count int64 `json:"count"`
+}
+
+// FacetsResponse is the response struct sent back to the client for a facet search. Each
+// list holds a FacetCountResponse for every genre, author, era or size, in order of ID.
+type FacetsResponse struct {
+	Genres  []FacetCountResponse `json:"genres"`
+	Authors []FacetCountResponse `json:"authors"`
+	Eras    []FacetCountResponse `json:"eras"`
+	Sizes   []FacetCountResponse `json:"sizes"`
+}
+
+// newFacetsResponse accepts a book.Facets and maps it into a FacetsResponse.
+func newFacetsResponse(facets book.Facets) *FacetsResponse {
+	return &FacetsResponse{
+		Genres:  newFacetCountResponses(facets.Genres),
+		Authors: newFacetCountResponses(facets.Authors),
+		Eras:    newFacetCountResponses(facets.Eras),
+		Sizes:   newFacetCountResponses(facets.Sizes),
+	}
+}
+
+func newFacetCountResponses(counts []book.FacetCount) []FacetCountResponse {
+	out := make([]FacetCountResponse, len(counts))
+	for i, c := range counts {
+		out[i] = FacetCountResponse{ID: c.ID, Count: c.Count}
+	}
+	return out
+}
+
+// Render is a stub for preprocessing the FacetsResponse model.
+func (fr *FacetsResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 /*****************************
  * v1 Book endpoint handlers
  *****************************/
@@ -180,52 +271,233 @@ func newBookListResponse(books []entity.Book) []render.Renderer {
 type bookHandler struct {
 	driver book.Driver
 	logger *zap.Logger
+	opts   Options
 }
 
 // NewBookHandler accepts a book.Driver which will be wrapped into a bookHandler. If the driver
 // is nil, then an error will be returned and the setup will fail. Otherwise a pointer to a new bookHandler
 // is returned. It as assumed that the API has already checked for valid input params.
-func NewBookHandler(driver book.Driver, logger *zap.Logger) (*bookHandler, error) {
+func NewBookHandler(driver book.Driver, logger *zap.Logger, opts Options) (*bookHandler, error) {
 	if driver == nil {
 		return nil, errors.New("non-nil book driver is required to create a book handler")
 	}
 
-	return &bookHandler{driver: driver, logger: logger}, nil
+	return &bookHandler{driver: driver, logger: logger, opts: opts}, nil
 }
 
 // List will use the incoming http.Request's Context to get a BookRequest. If this does not exist, then
 // an error is returned and processing is terminated.
 //
 // The BookRequest fields are mapped to a book.SearchInput. This will use the bookHandler's book.Driver
-// to find a list of entity.Book items that satisfy the search parameters.
+// to find a page of entity.Book items that satisfy the search parameters. The page is rendered as a list,
+// and the Next-Cursor and Has-More headers describe how to fetch the page after it.
 func (handler *bookHandler) List(w http.ResponseWriter, r *http.Request) {
 	reqParams, ok := r.Context().Value(bookSearchParamKey).(*BookRequest)
 
 	// This should have been placed into the context by the GET api/v1/books middleware
 	if !ok || reqParams == nil {
-		_ = render.Render(w, r, ErrInternalServer(errors.New("expected middleware to inject params into context")))
+		renderError(w, handler.logger, errors.New("expected middleware to inject params into context"), "searching books")
 		return
 	}
 
-	books, err := handler.driver.SearchBooks(r.Context(), book.SearchInput{
-		Title:            reqParams.Title,
-		MaxYearPublished: reqParams.MaxYearPublished,
-		MinYearPublished: reqParams.MinYearPublished,
-		MaxPages:         reqParams.MaxPages,
-		MinPages:         reqParams.MinPages,
-		GenreIDs:         reqParams.GenreIDs,
-		AuthorIDs:        reqParams.AuthorIDs,
-		Limit:            reqParams.Limit,
+	page, err := handler.driver.SearchBooks(r.Context(), reqParams.input)
+	if err != nil {
+		renderError(w, handler.logger, err, "searching books")
+		return
+	}
+
+	if page.NextCursor != nil {
+		w.Header().Set(nextCursorHeader, page.NextCursor.Encode())
+	}
+	w.Header().Set(hasMoreHeader, strconv.FormatBool(page.HasMore))
+
+	if err := render.RenderList(w, r, newBookListResponse(page.Books)); err != nil {
+		renderError(w, handler.logger, err, "rendering books")
+		return
+	}
+}
+
+// Facets will use the incoming http.Request's Context to get a BookRequest. If this does not exist, then
+// an error is returned and processing is terminated.
+//
+// The BookRequest fields are mapped to a book.SearchInput. This will use the bookHandler's book.Driver
+// to count the entity.Book items that satisfy the search parameters for each genre, author, era and size.
+// Each facet is counted with its own filter left out. The sort, cursor and limit parameters have no effect.
+func (handler *bookHandler) Facets(w http.ResponseWriter, r *http.Request) {
+	reqParams, ok := r.Context().Value(bookSearchParamKey).(*BookRequest)
+
+	// This should have been placed into the context by the GET api/v1/books middleware
+	if !ok || reqParams == nil {
+		renderError(w, handler.logger, errors.New("expected middleware to inject params into context"), "counting book facets")
+		return
+	}
+
+	facets, err := handler.driver.CountFacets(r.Context(), reqParams.input)
+	if err != nil {
+		renderError(w, handler.logger, err, "counting book facets")
+		return
+	}
+
+	if err := render.Render(w, r, newFacetsResponse(facets)); err != nil {
+		renderError(w, handler.logger, err, "rendering book facets")
+		return
+	}
+}
+
+// Export will use the incoming http.Request's Context to get a BookRequest. If this does not exist, then
+// an error is returned and processing is terminated.
+//
+// The BookRequest fields are mapped to a book.SearchInput. Every entity.Book that satisfies the search
+// parameters is streamed, unpaginated, as a catalog in the requested format, which is CSV by default. The
+// catalog can be loaded with the import command. If the export fails once the catalog has started streaming,
+// then the connection is aborted, so that a partial catalog is not mistaken for a complete one.
+func (handler *bookHandler) Export(w http.ResponseWriter, r *http.Request) {
+	reqParams, ok := r.Context().Value(bookSearchParamKey).(*BookRequest)
+
+	// This should have been placed into the context by the GET api/v1/books middleware
+	if !ok || reqParams == nil {
+		renderError(w, handler.logger, errors.New("expected middleware to inject params into context"), "exporting books")
+		return
+	}
+
+	format := catalog.FormatCSV
+	if reqParams.Format != nil {
+		format = *reqParams.Format
+	}
+	cw, err := catalog.NewWriter(w, format)
+	if err != nil {
+		renderError(w, handler.logger, fmt.Errorf("%w: %s", entity.ErrInvalidQueryParam, err), "exporting books")
+		return
+	}
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="books.%s"`, format))
+
+	var exported int
+	err = handler.driver.ExportBooks(r.Context(), reqParams.input, func(b entity.Book) error {
+		exported++
+		return cw.Write(b)
 	})
+	if err == nil {
+		err = cw.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	// Nothing has been written before the first Book, so the error can still be rendered in place of the catalog.
+	if exported == 0 {
+		w.Header().Del("Content-Disposition")
+		renderError(w, handler.logger, err, "exporting books")
+		return
+	}
+
+	handler.logger.Error(fmt.Sprintf("error exporting books after %d books: %s", exported, err))
+	panic(http.ErrAbortHandler)
+}
+
+// Get is an HTTP method that renders the entity.Book identified by the URL parameter. If the Book does not
+// exist, then a 404 status code is returned.
+func (handler *bookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "getting book")
+		return
+	}
+
+	b, err := handler.driver.GetBook(r.Context(), id)
+	if err != nil {
+		renderError(w, handler.logger, err, "getting book")
+		return
+	}
+
+	if err := render.Render(w, r, newBookResponse(b)); err != nil {
+		renderError(w, handler.logger, err, "rendering book")
+		return
+	}
+}
+
+// Create is an HTTP method that validates the BookWriteRequest in the body and creates an entity.Book from it.
+// The created Book is rendered with a 201 status code and its URL in the Location header. If the Book is
+// invalid, then a 422 status code is returned along with the invalid fields.
+func (handler *bookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeBookWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating book")
+		return
+	}
+
+	b, err := handler.driver.CreateBook(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating book")
+		return
+	}
+
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(int(b.ID))))
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, newBookResponse(b)); err != nil {
+		renderError(w, handler.logger, err, "rendering book")
+		return
+	}
+}
+
+// Replace is an HTTP method that validates the BookWriteRequest in the body and replaces every attribute of the
+// entity.Book identified by the URL parameter with it. The updated Book is rendered. If the Book does not exist,
+// then a 404 status code is returned. If the Book is invalid, then a 422 status code is returned along with the
+// invalid fields.
+func (handler *bookHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	handler.write(w, r, handler.driver.ReplaceBook)
+}
+
+// Update is an HTTP method that updates the attributes of the entity.Book identified by the URL parameter which
+// are present in the BookWriteRequest in the body. The updated Book is rendered. If the Book does not exist, then
+// a 404 status code is returned. If the updated Book is invalid, then a 422 status code is returned along with
+// the invalid fields.
+func (handler *bookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	handler.write(w, r, handler.driver.UpdateBook)
+}
+
+// Delete is an HTTP method that deletes the entity.Book identified by the URL parameter. A 204 status code is
+// returned on success. If the Book does not exist, then a 404 status code is returned.
+func (handler *bookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting book")
+		return
+	}
+
+	if err := handler.driver.DeleteBook(r.Context(), id); err != nil {
+		renderError(w, handler.logger, err, "deleting book")
+		return
+	}
+
+	render.NoContent(w, r)
+}
+
+// write decodes the BookWriteRequest in the body and uses the provided routine to write it to the entity.Book
+// identified by the URL parameter. The updated Book is rendered.
+func (handler *bookHandler) write(w http.ResponseWriter, r *http.Request,
+	write func(ctx context.Context, id int32, params book.WriteInput) (entity.Book, error)) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating book")
+		return
+	}
+
+	params, err := decodeBookWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating book")
+		return
+	}
+
+	b, err := write(r.Context(), id, params)
 	if err != nil {
-		handler.logger.Error(fmt.Sprintf("error searching books: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "updating book")
 		return
 	}
 
-	if err := render.RenderList(w, r, newBookListResponse(books)); err != nil {
-		handler.logger.Error(fmt.Sprintf("error rendering books: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+	if err := render.Render(w, r, newBookResponse(b)); err != nil {
+		renderError(w, handler.logger, err, "rendering book")
 		return
 	}
 }
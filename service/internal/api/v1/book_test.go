@@ -6,11 +6,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi/openapitest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
 	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -38,7 +41,7 @@ func TestNewBookHandler(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			h, err := NewBookHandler(tt.driver, zap.NewNop())
+			h, err := NewBookHandler(tt.driver, zap.NewNop(), Options{})
 			tt.errAssertion(t, err)
 			tt.valAssertion(t, h)
 		})
@@ -68,19 +71,76 @@ func TestBookHandler_List(t *testing.T) {
 	tests := map[string]struct {
 		target          string
 		expectedParams  book.SearchInput
-		driverReturn    []entity.Book
+		driverReturn    book.Page
 		expectedHandler string
 		expectedBody    string
 		expectedCode    int
+		expectedAllow   string
+		expectedHeaders map[string]string
 		expectedErr     error
+		strictQuery     bool
 		sendRequest     func(string) (*http.Response, error)
 	}{
 		"search all books": {
 			expectedHandler: "SearchBooks",
 			target:          "/",
-			driverReturn:    []entity.Book{mockBook},
+			driverReturn:    book.Page{Books: []entity.Book{mockBook}},
 			expectedBody:    expectedJson,
 			expectedCode:    200,
+			expectedHeaders: map[string]string{"Has-More": "false", "Next-Cursor": ""},
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"search next page of books": {
+			expectedHandler: "SearchBooks",
+			target:          "/?limit=1&cursor=" + (book.Cursor{Sort: "-rating,id", Rating: 4.5, ID: 7}).Encode(),
+			expectedParams: book.SearchInput{
+				After: &book.Cursor{Sort: "-rating,id", Rating: 4.5, ID: 7},
+				Limit: util.Uint64Ptr(1),
+			},
+			driverReturn: book.Page{
+				Books:      []entity.Book{mockBook},
+				NextCursor: &book.Cursor{Sort: "-rating,id", Rating: 3.9, ID: 1},
+				HasMore:    true,
+			},
+			expectedBody: expectedJson,
+			expectedCode: 200,
+			expectedHeaders: map[string]string{
+				"Has-More":    "true",
+				"Next-Cursor": (book.Cursor{Sort: "-rating,id", Rating: 3.9, ID: 1}).Encode(),
+			},
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"search books with sort": {
+			expectedHandler: "SearchBooks",
+			target:          "/?sort=-year_published,title",
+			expectedParams: book.SearchInput{
+				Sort: book.Sort{
+					{Field: book.SortByYearPublished, Descending: true},
+					{Field: book.SortByTitle},
+				},
+			},
+			driverReturn: book.Page{Books: []entity.Book{mockBook}},
+			expectedBody: expectedJson,
+			expectedCode: 200,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"search books with query": {
+			expectedHandler: "SearchBooks",
+			target:          "/?q=silmarilion&title=The+Silmarillion",
+			expectedParams: book.SearchInput{
+				Title: util.StringPtr("The Silmarillion"),
+				Query: util.StringPtr("silmarilion"),
+				Sort:  book.RelevanceSort,
+			},
+			driverReturn: book.Page{Books: []entity.Book{mockBook}},
+			expectedBody: expectedJson,
+			expectedCode: 200,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
 			},
@@ -88,9 +148,9 @@ func TestBookHandler_List(t *testing.T) {
 		"driver returns error": {
 			expectedHandler: "SearchBooks",
 			target:          "/",
-			driverReturn:    []entity.Book{mockBook},
-			expectedBody:    `{"message":"Internal Server Error"}`,
-			expectedCode:    400,
+			driverReturn:    book.Page{Books: []entity.Book{mockBook}},
+			expectedBody:    `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode:    500,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
 			},
@@ -98,7 +158,7 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"search for specific books": {
 			expectedHandler: "SearchBooks",
-			target:          "/?title=The+Silmarillion&max-year=1980&min-year=1970&max-pages=400&min-pages=300&genres=2&genres=6&authors=42&authors=43&limit=50",
+			target:          "/?title=The+Silmarillion&max-year=1980&min-year=1970&max-pages=400&min-pages=300&genres=2&genres=6&authors=42&authors=43&eras=1&sizes=3&sizes=4&limit=50",
 			expectedParams: book.SearchInput{
 				Title:            util.StringPtr("The Silmarillion"),
 				MaxYearPublished: util.Int16Ptr(1980),
@@ -107,27 +167,87 @@ func TestBookHandler_List(t *testing.T) {
 				MinPages:         util.Int16Ptr(300),
 				GenreIDs:         []int16{2, 6},
 				AuthorIDs:        []int16{42, 43},
+				EraIDs:           []int16{1},
+				SizeIDs:          []int16{3, 4},
 				Limit:            util.Uint64Ptr(50),
 			},
-			driverReturn: []entity.Book{mockBook},
+			driverReturn: book.Page{Books: []entity.Book{mockBook}},
+			expectedBody: expectedJson,
+			expectedCode: 200,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"search books with comma-delimited and repeated lists": {
+			expectedHandler: "SearchBooks",
+			target:          "/?genres=2,6&authors=42&authors=43,44&eras=1&sizes=3,4",
+			expectedParams: book.SearchInput{
+				GenreIDs:  []int16{2, 6},
+				AuthorIDs: []int16{42, 43, 44},
+				EraIDs:    []int16{1},
+				SizeIDs:   []int16{3, 4},
+			},
+			driverReturn: book.Page{Books: []entity.Book{mockBook}},
 			expectedBody: expectedJson,
 			expectedCode: 200,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
 			},
 		},
+		"search books with ranges": {
+			expectedHandler: "SearchBooks",
+			target:          "/?pages=100..300&year=1970..",
+			expectedParams: book.SearchInput{
+				MinPages:         util.Int16Ptr(100),
+				MaxPages:         util.Int16Ptr(300),
+				MinYearPublished: util.Int16Ptr(1970),
+			},
+			driverReturn: book.Page{Books: []entity.Book{mockBook}},
+			expectedBody: expectedJson,
+			expectedCode: 200,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"search books with range open below": {
+			expectedHandler: "SearchBooks",
+			target:          "/?year=..1980",
+			expectedParams:  book.SearchInput{MaxYearPublished: util.Int16Ptr(1980)},
+			driverReturn:    book.Page{Books: []entity.Book{mockBook}},
+			expectedBody:    expectedJson,
+			expectedCode:    200,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"driver rejects unknown era": {
+			expectedHandler: "SearchBooks",
+			target:          "/?eras=9",
+			expectedParams:  book.SearchInput{EraIDs: []int16{9}},
+			expectedBody:    `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided: era 9 does not exist"}`,
+			expectedCode:    400,
+			expectedErr:     fmt.Errorf("%w: era 9 does not exist", entity.ErrInvalidQueryParam),
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
 		"invalid http method": {
 			expectedHandler: "ListAuthors",
 			target:          "/",
-			expectedBody:    `{"message":"HTTP method POST is not allowed"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Method Not Allowed","status":405,"code":"method_not_allowed","detail":"HTTP method PUT is not allowed"}`,
+			expectedCode:    405,
+			expectedAllow:   "GET, POST",
 			sendRequest: func(url string) (*http.Response, error) {
-				return http.Post(url, "application/json", nil)
+				req, err := http.NewRequest(http.MethodPut, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				return http.DefaultClient.Do(req)
 			},
 		},
 		"invalid books param - min-pages": {
 			target:       "/?min-pages=0",
-			expectedBody: `{"message":"invalid URL query parameter provided: min-pages is 0 but should be in range [1,10000]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"min-pages","code":"out_of_range","message":"is 0 but should be in range [1,10000]"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -135,7 +255,7 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"invalid books param - max-pages": {
 			target:       "/?max-pages=10001",
-			expectedBody: `{"message":"invalid URL query parameter provided: max-pages is 10001 but should be in range [1,10000]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"max-pages","code":"out_of_range","message":"is 10001 but should be in range [1,10000]"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -143,7 +263,7 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"invalid books param - min-year": {
 			target:       "/?min-year=1799",
-			expectedBody: `{"message":"invalid URL query parameter provided: min-year is 1799 but should be in range [1800,2100]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"min-year","code":"out_of_range","message":"is 1799 but should be in range [1800,2100]"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -151,7 +271,7 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"invalid books param - max-year": {
 			target:       "/?max-year=2101",
-			expectedBody: `{"message":"invalid URL query parameter provided: max-year is 2101 but should be in range [1800,2100]"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"max-year","code":"out_of_range","message":"is 2101 but should be in range [1800,2100]"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -159,7 +279,149 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"invalid books param - limit": {
 			target:       "/?limit=0",
-			expectedBody: `{"message":"invalid URL query parameter provided: limit is 0 but should be greater than 0"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"limit","code":"out_of_range","message":"is 0 but should be at least 1"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - cursor": {
+			target:       "/?cursor=not-a-cursor",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"cursor","code":"invalid","message":"cursor is malformed"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - sort": {
+			target:       "/?sort=-rating,author",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"sort","code":"invalid","message":"sort is invalid: \"author\" is not one of relevance, rating, year_published, pages, title, id"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - blank query": {
+			target:       "/?q=+",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"q","code":"blank","message":"must not be blank"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - relevance without query": {
+			target:       "/?sort=-relevance",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"sort","code":"conflict","message":"cannot sort by relevance without q"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - cursor for another sort": {
+			target:       "/?sort=title&cursor=" + (book.Cursor{Sort: "-rating,id", Rating: 4.5, ID: 7}).Encode(),
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"cursor","code":"conflict","message":"was issued for sort -rating,id"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - min-pages greater than max-pages": {
+			target:       "/?min-pages=400&max-pages=300",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"min-pages","code":"min_exceeds_max","message":"is 400 but should not be greater than max-pages, which is 300"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - min-year greater than max-year": {
+			target:       "/?min-year=1990&max-year=1980",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"min-year","code":"min_exceeds_max","message":"is 1990 but should not be greater than max-year, which is 1980"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books params - every violation is listed": {
+			target:       "/?genres=beta&min-pages=0&max-year=2101&min-year=1700&limit=0&q=+&cursor=not-a-cursor",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"genres","code":"invalid_type","message":"should be an integer"},{"field":"min-pages","code":"out_of_range","message":"is 0 but should be in range [1,10000]"},{"field":"min-year","code":"out_of_range","message":"is 1700 but should be in range [1800,2100]"},{"field":"max-year","code":"out_of_range","message":"is 2101 but should be in range [1800,2100]"},{"field":"limit","code":"out_of_range","message":"is 0 but should be at least 1"},{"field":"q","code":"blank","message":"must not be blank"},{"field":"cursor","code":"invalid","message":"cursor is malformed"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"unknown books param is ignored": {
+			expectedHandler: "SearchBooks",
+			target:          "/?colour=red",
+			driverReturn:    book.Page{Books: []entity.Book{mockBook}},
+			expectedBody:    expectedJson,
+			expectedCode:    200,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"unknown books params are rejected when strict": {
+			target:       "/?colour=red&min-pages=0&authour=42",
+			strictQuery:  true,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"authour","code":"unknown","message":"is not a known parameter"},{"field":"colour","code":"unknown","message":"is not a known parameter"},{"field":"min-pages","code":"out_of_range","message":"is 0 but should be in range [1,10000]"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"format is ignored outside of exports": {
+			expectedHandler: "SearchBooks",
+			target:          "/?format=csv",
+			driverReturn:    book.Page{Books: []entity.Book{mockBook}},
+			expectedBody:    expectedJson,
+			expectedCode:    200,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"format is rejected outside of exports when strict": {
+			target:       "/?format=csv",
+			strictQuery:  true,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"format","code":"unknown","message":"is not a known parameter"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - pages range ends before it starts": {
+			target:       "/?pages=300..100",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"pages","code":"min_exceeds_max","message":"starts at 300 but should not end before it, at 100"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - pages is not a range": {
+			target:       "/?pages=100",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"pages","code":"invalid","message":"is \"100\" but should be a range such as 100..300, 100.. or ..300"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - year range of words": {
+			target:       "/?year=then..now",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"year","code":"invalid_type","message":"is \"then..now\" but its bounds should be integers"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - year range out of range": {
+			target:       "/?year=1700..",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"year","code":"out_of_range","message":"is 1700 but should be in range [1800,2100]"}]}`,
+			expectedCode: 400,
+			sendRequest: func(url string) (*http.Response, error) {
+				return http.Get(url)
+			},
+		},
+		"invalid books param - pages with min-pages": {
+			target:       "/?pages=100..300&min-pages=50",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"pages","code":"conflict","message":"cannot be used together with min-pages or max-pages"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -167,7 +429,7 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"invalid books param - authors": {
 			target:       "/books?authors=1,beta,3",
-			expectedBody: `{"message":"invalid URL query parameter provided: received wrong type for parameter authors"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"authors","code":"invalid_type","message":"should be an integer"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -175,7 +437,7 @@ func TestBookHandler_List(t *testing.T) {
 		},
 		"invalid books param - genres": {
 			target:       "/books?genres=1,beta,3",
-			expectedBody: `{"message":"invalid URL query parameter provided: received wrong type for parameter genres"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"genres","code":"invalid_type","message":"should be an integer"}]}`,
 			expectedCode: 400,
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -186,12 +448,11 @@ func TestBookHandler_List(t *testing.T) {
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
 			driverMock := booktest.DriverMock{}
-			handler := bookHandler{driver: &driverMock, logger: zap.NewNop()}
+			handler := bookHandler{driver: &driverMock, logger: zap.NewNop(), opts: Options{StrictQuery: tt.strictQuery}}
 
 			r := bookRoutes(&handler)
 
-			server := httptest.NewServer(r)
-			defer server.Close()
+			server := openapitest.NewServer(t, "/books", r)
 
 			driverMock.
 				On(tt.expectedHandler, mock.MatchedBy(func(_ context.Context) bool { return true }), tt.expectedParams).
@@ -205,15 +466,19 @@ func TestBookHandler_List(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedBody+"\n", string(body))
 			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedAllow, resp.Header.Get("Allow"))
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
 		})
 	}
 
 	t.Run("error when nil not provided", func(t *testing.T) {
 		driverMock := booktest.DriverMock{}
-		handler := bookHandler{driver: &driverMock}
+		handler := bookHandler{driver: &driverMock, logger: zap.NewNop()}
 		driverMock.On("SearchBooks",
 			mock.MatchedBy(func(_ context.Context) bool { return true }),
-			book.SearchInput{}).Return([]entity.Book{}, nil)
+			book.SearchInput{}).Return(book.Page{}, nil)
 
 		req := httptest.
 			NewRequest("GET", "/", nil).
@@ -224,6 +489,439 @@ func TestBookHandler_List(t *testing.T) {
 		resp := w.Result()
 		body, err := ioutil.ReadAll(resp.Body)
 		assert.NoError(t, err)
-		assert.Equal(t, `{"message":"Internal Server Error"}`+"\n", string(body))
+		assert.Equal(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`+"\n", string(body))
 	})
 }
+
+func TestBookHandler_Facets(t *testing.T) {
+	tests := map[string]struct {
+		target         string
+		expectedParams book.SearchInput
+		driverReturn   book.Facets
+		expectedBody   string
+		expectedCode   int
+		expectedErr    error
+	}{
+		"count facets of all books": {
+			target: "/facets",
+			driverReturn: book.Facets{
+				Genres:  []book.FacetCount{{ID: 1, Count: 0}, {ID: 2, Count: 3}},
+				Authors: []book.FacetCount{{ID: 42, Count: 3}},
+				Eras:    []book.FacetCount{{ID: 0, Count: 3}},
+				Sizes:   []book.FacetCount{{ID: 0, Count: 3}},
+			},
+			expectedBody: `{"genres":[{"id":1,"count":0},{"id":2,"count":3}],"authors":[{"id":42,"count":3}],"eras":[{"id":0,"count":3}],"sizes":[{"id":0,"count":3}]}`,
+			expectedCode: 200,
+		},
+		"count facets of specific books": {
+			target: "/facets?q=tolkien&genres=2&eras=1",
+			expectedParams: book.SearchInput{
+				Query:    util.StringPtr("tolkien"),
+				GenreIDs: []int16{2},
+				EraIDs:   []int16{1},
+				Sort:     book.RelevanceSort,
+			},
+			expectedBody: `{"genres":[],"authors":[],"eras":[],"sizes":[]}`,
+			expectedCode: 200,
+		},
+		"driver rejects unknown size": {
+			target:         "/facets?sizes=9",
+			expectedParams: book.SearchInput{SizeIDs: []int16{9}},
+			expectedBody:   `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided: size 9 does not exist"}`,
+			expectedCode:   400,
+			expectedErr:    fmt.Errorf("%w: size 9 does not exist", entity.ErrInvalidQueryParam),
+		},
+		"driver returns error": {
+			target:       "/facets",
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode: 500,
+			expectedErr:  errors.New("mock internal error from driver"),
+		},
+		"invalid books param - min-year": {
+			target:       "/facets?min-year=1700",
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided","fields":[{"field":"min-year","code":"out_of_range","message":"is 1700 but should be in range [1800,2100]"}]}`,
+			expectedCode: 400,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := booktest.DriverMock{}
+			handler := bookHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/books", bookRoutes(&handler))
+
+			driverMock.
+				On("CountFacets", mock.MatchedBy(func(_ context.Context) bool { return true }), tt.expectedParams).
+				Return(tt.driverReturn, tt.expectedErr)
+
+			resp, err := http.Get(fmt.Sprintf("%s%s", server.URL, tt.target))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBody+"\n", string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestBookHandler_Export(t *testing.T) {
+
+	books := []entity.Book{
+		{ID: 1, Title: "The Hobbit", YearPublished: 1937, Rating: 4.5, Pages: 310,
+			Genre: entity.Genre{ID: 2, Title: "Fantasy"}, Author: entity.Author{ID: 42, FirstName: "John", LastName: "Tolkien"}},
+		{ID: 2, Title: "The Silmarillion", YearPublished: 1977, Rating: 4.25, Pages: 365,
+			Genre: entity.Genre{ID: 2, Title: "Fantasy"}, Author: entity.Author{ID: 42, FirstName: "John", LastName: "Tolkien"}},
+	}
+
+	tests := map[string]struct {
+		target              string
+		expectedParams      book.SearchInput
+		driverReturn        []entity.Book
+		driverErr           error
+		expectedBody        string
+		expectedCode        int
+		expectedContentType string
+		expectAbort         bool
+		strictQuery         bool
+	}{
+		"export all books as csv": {
+			target:       "/export",
+			driverReturn: books,
+			expectedBody: "id,title,author_id,author_first_name,author_last_name,genre_id,genre,year_published,pages,rating\n" +
+				"1,The Hobbit,42,John,Tolkien,2,Fantasy,1937,310,4.50\n" +
+				"2,The Silmarillion,42,John,Tolkien,2,Fantasy,1977,365,4.25\n",
+			expectedCode:        200,
+			expectedContentType: "text/csv; charset=utf-8",
+		},
+		"export specific books as json lines": {
+			target: "/export?format=jsonl&genres=2&sort=title",
+			// Exports know the format parameter, even when unknown parameters are rejected.
+			strictQuery: true,
+			expectedParams: book.SearchInput{
+				GenreIDs: []int16{2},
+				Sort:     book.Sort{{Field: book.SortByTitle}},
+			},
+			driverReturn: books[:1],
+			expectedBody: `{"id":1,"title":"The Hobbit","yearPublished":1937,"rating":4.5,"pages":310,` +
+				`"genre":{"id":2,"title":"Fantasy"},"author":{"id":42,"firstName":"John","lastName":"Tolkien"}}` + "\n",
+			expectedCode:        200,
+			expectedContentType: "application/x-ndjson",
+		},
+		"export no books as json": {
+			target:              "/export?format=json",
+			expectedBody:        "[]\n",
+			expectedCode:        200,
+			expectedContentType: "application/json",
+		},
+		"unknown format": {
+			target:              "/export?format=xml",
+			expectedBody:        `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided: format is \"xml\" but should be csv, json or jsonl"}` + "\n",
+			expectedCode:        400,
+			expectedContentType: "application/problem+json",
+		},
+		"driver rejects unknown era": {
+			target:              "/export?eras=9",
+			expectedParams:      book.SearchInput{EraIDs: []int16{9}},
+			driverErr:           fmt.Errorf("%w: era 9 does not exist", entity.ErrInvalidQueryParam),
+			expectedBody:        `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param","detail":"invalid URL query parameter provided: era 9 does not exist"}` + "\n",
+			expectedCode:        400,
+			expectedContentType: "application/problem+json",
+		},
+		"driver fails after streaming books": {
+			target:       "/export",
+			driverReturn: books,
+			driverErr:    errors.New("mock internal error from driver"),
+			expectAbort:  true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := booktest.DriverMock{}
+			handler := bookHandler{driver: &driverMock, logger: zap.NewNop(), opts: Options{StrictQuery: tt.strictQuery}}
+
+			server := openapitest.NewServer(t, "/books", bookRoutes(&handler))
+
+			driverMock.
+				On("ExportBooks", mock.MatchedBy(func(_ context.Context) bool { return true }), tt.expectedParams).
+				Return(tt.driverReturn, tt.driverErr)
+
+			resp, err := http.Get(fmt.Sprintf("%s%s", server.URL, tt.target))
+			if tt.expectAbort {
+				// The connection is closed before the response, or its body, is complete.
+				if err == nil {
+					_, err = ioutil.ReadAll(resp.Body)
+					_ = resp.Body.Close()
+				}
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedContentType, resp.Header.Get("Content-Type"))
+		})
+	}
+}
+
+func TestBookHandler_Get(t *testing.T) {
+	tests := map[string]struct {
+		target       string
+		expectedID   int32
+		driverReturn entity.Book
+		expectedBody string
+		expectedCode int
+		expectedErr  error
+	}{
+		"get book": {
+			target:     "/1",
+			expectedID: 1,
+			driverReturn: entity.Book{
+				ID:            1,
+				Title:         "The Silmarillion",
+				YearPublished: 1977,
+				Rating:        3.9,
+				Pages:         365,
+				Genre:         entity.Genre{ID: 2, Title: "Fantasy/SciFi"},
+				Author:        entity.Author{ID: 42, FirstName: "John", LastName: "Tolkien"},
+			},
+			expectedBody: `{"id":1,"title":"The Silmarillion","yearPublished":1977,"rating":3.9,"pages":365,"genre":{"id":2,"title":"Fantasy/SciFi"},"author":{"id":42,"firstName":"John","lastName":"Tolkien"}}`,
+			expectedCode: 200,
+		},
+		"get book ignores search params": {
+			target:       "/1?min-pages=0",
+			expectedID:   1,
+			driverReturn: entity.Book{ID: 1},
+			expectedBody: `{"id":1,"title":"","yearPublished":0,"rating":0,"pages":0,"genre":{"id":0,"title":""},"author":{"id":0,"firstName":"","lastName":""}}`,
+			expectedCode: 200,
+		},
+		"book does not exist": {
+			target:       "/9",
+			expectedID:   9,
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: book 9 does not exist"}`,
+			expectedCode: 404,
+			expectedErr:  fmt.Errorf("%w: book 9 does not exist", entity.ErrNotFound),
+		},
+		"driver returns error": {
+			target:       "/1",
+			expectedID:   1,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode: 500,
+			expectedErr:  errors.New("mock internal error from driver"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := booktest.DriverMock{}
+			handler := bookHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/books", bookRoutes(&handler))
+
+			driverMock.
+				On("GetBook", mock.MatchedBy(func(_ context.Context) bool { return true }), tt.expectedID).
+				Return(tt.driverReturn, tt.expectedErr)
+
+			resp, err := http.Get(fmt.Sprintf("%s%s", server.URL, tt.target))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBody+"\n", string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestBookHandler_Write(t *testing.T) {
+	var (
+		anyContext = mock.MatchedBy(func(_ context.Context) bool { return true })
+		hobbit     = entity.Book{
+			ID:            59,
+			Title:         "The Hobbit",
+			YearPublished: 1937,
+			Rating:        4.3,
+			Pages:         310,
+			Genre:         entity.Genre{ID: 2, Title: "Fantasy/SciFi"},
+			Author:        entity.Author{ID: 42, FirstName: "John", LastName: "Tolkien"},
+		}
+		hobbitJson = `{"id":59,"title":"The Hobbit","yearPublished":1937,"rating":4.3,"pages":310,"genre":{"id":2,"title":"Fantasy/SciFi"},"author":{"id":42,"firstName":"John","lastName":"Tolkien"}}`
+		hobbitBody = `{"title":"The Hobbit","yearPublished":1937,"rating":4.3,"pages":310,"authorId":42,"genreId":2}`
+		hobbitArgs = book.WriteInput{
+			Title:         util.StringPtr("The Hobbit"),
+			YearPublished: util.Int16Ptr(1937),
+			Rating:        util.Float32Ptr(4.3),
+			Pages:         util.Int16Ptr(310),
+			AuthorID:      util.Int32Ptr(42),
+			GenreID:       util.Int32Ptr(2),
+		}
+		invalidErr = &entity.ValidationError{Fields: []entity.FieldError{
+			{Field: "title", Code: validation.CodeBlank, Message: "must not be blank"},
+			{Field: "authorId", Code: validation.CodeNotFound, Message: "author 9 does not exist"},
+		}}
+		invalidJson = `{"type":"about:blank","title":"Unprocessable Entity","status":422,"code":"invalid_entity","detail":"invalid entity provided","fields":[{"field":"title","code":"blank","message":"must not be blank"},{"field":"authorId","code":"not_found","message":"author 9 does not exist"}]}`
+	)
+
+	tests := map[string]struct {
+		method          string
+		target          string
+		body            string
+		expectedHandler string
+		expectedArgs    []interface{}
+		driverReturn    []interface{}
+		expectedBody    string
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		"create book": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            hobbitBody,
+			expectedHandler: "CreateBook",
+			expectedArgs:    []interface{}{anyContext, hobbitArgs},
+			driverReturn:    []interface{}{hobbit, nil},
+			expectedBody:    hobbitJson,
+			expectedCode:    201,
+			expectedHeaders: map[string]string{"Location": "/59"},
+		},
+		"create invalid book": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            `{"title":" ","authorId":9}`,
+			expectedHandler: "CreateBook",
+			expectedArgs:    []interface{}{anyContext, book.WriteInput{Title: util.StringPtr(" "), AuthorID: util.Int32Ptr(9)}},
+			driverReturn:    []interface{}{entity.Book{}, invalidErr},
+			expectedBody:    invalidJson,
+			expectedCode:    422,
+		},
+		"create book with malformed body": {
+			method:       http.MethodPost,
+			target:       "/",
+			body:         `{"title":`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: unexpected EOF"}`,
+			expectedCode: 400,
+		},
+		"create book with wrong type": {
+			method:       http.MethodPost,
+			target:       "/",
+			body:         `{"pages":"many"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: json: cannot unmarshal string into Go struct field BookWriteRequest.pages of type int16"}`,
+			expectedCode: 400,
+		},
+		"create book with unknown field": {
+			method:       http.MethodPost,
+			target:       "/",
+			body:         `{"title":"The Hobbit","autorId":9}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: json: unknown field \"autorId\""}`,
+			expectedCode: 400,
+		},
+		"create book driver returns error": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            hobbitBody,
+			expectedHandler: "CreateBook",
+			expectedArgs:    []interface{}{anyContext, hobbitArgs},
+			driverReturn:    []interface{}{entity.Book{}, errors.New("mock internal error from driver")},
+			expectedBody:    `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode:    500,
+		},
+		"replace book": {
+			method:          http.MethodPut,
+			target:          "/59",
+			body:            hobbitBody,
+			expectedHandler: "ReplaceBook",
+			expectedArgs:    []interface{}{anyContext, int32(59), hobbitArgs},
+			driverReturn:    []interface{}{hobbit, nil},
+			expectedBody:    hobbitJson,
+			expectedCode:    200,
+		},
+		"replace missing book": {
+			method:          http.MethodPut,
+			target:          "/9",
+			body:            hobbitBody,
+			expectedHandler: "ReplaceBook",
+			expectedArgs:    []interface{}{anyContext, int32(9), hobbitArgs},
+			driverReturn:    []interface{}{entity.Book{}, fmt.Errorf("%w: book 9 does not exist", entity.ErrNotFound)},
+			expectedBody:    `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: book 9 does not exist"}`,
+			expectedCode:    404,
+		},
+		"update book": {
+			method:          http.MethodPatch,
+			target:          "/59",
+			body:            `{"rating":4.3}`,
+			expectedHandler: "UpdateBook",
+			expectedArgs:    []interface{}{anyContext, int32(59), book.WriteInput{Rating: util.Float32Ptr(4.3)}},
+			driverReturn:    []interface{}{hobbit, nil},
+			expectedBody:    hobbitJson,
+			expectedCode:    200,
+		},
+		"update book with invalid attribute": {
+			method:          http.MethodPatch,
+			target:          "/59",
+			body:            `{"title":" ","authorId":9}`,
+			expectedHandler: "UpdateBook",
+			expectedArgs:    []interface{}{anyContext, int32(59), book.WriteInput{Title: util.StringPtr(" "), AuthorID: util.Int32Ptr(9)}},
+			driverReturn:    []interface{}{entity.Book{}, invalidErr},
+			expectedBody:    invalidJson,
+			expectedCode:    422,
+		},
+		"delete book": {
+			method:          http.MethodDelete,
+			target:          "/59",
+			expectedHandler: "DeleteBook",
+			expectedArgs:    []interface{}{anyContext, int32(59)},
+			driverReturn:    []interface{}{nil},
+			expectedCode:    204,
+		},
+		"delete missing book": {
+			method:          http.MethodDelete,
+			target:          "/9",
+			expectedHandler: "DeleteBook",
+			expectedArgs:    []interface{}{anyContext, int32(9)},
+			driverReturn:    []interface{}{fmt.Errorf("%w: book 9 does not exist", entity.ErrNotFound)},
+			expectedBody:    `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: book 9 does not exist"}`,
+			expectedCode:    404,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := booktest.DriverMock{}
+			handler := bookHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/books", bookRoutes(&handler))
+
+			if tt.expectedHandler != "" {
+				driverMock.On(tt.expectedHandler, tt.expectedArgs...).Return(tt.driverReturn...)
+			}
+
+			req, err := http.NewRequest(tt.method, fmt.Sprintf("%s%s", server.URL, tt.target), strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.expectedBody != "" {
+				tt.expectedBody += "\n"
+			}
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
+			driverMock.AssertExpectations(t)
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package v1
 
 import (
-	"fmt"
 	"net/http"
+	"path"
+	"strconv"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
@@ -17,12 +18,17 @@ func eraRoutes(h *eraHandler) chi.Router {
 	r := chi.NewRouter()
 	r.Route("/", func(r chi.Router) {
 		r.Use(
-			cors.Handler(cors.Options{AllowedMethods: []string{"GET"}}),
+			cors.Handler(cors.Options{
+				AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+				ExposedHeaders: []string{"Location"},
+			}),
 		)
-		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-			_ = render.Render(w, r, ErrMethodNotAllowed(r.Method))
-		})
+		r.MethodNotAllowed(renderMethodNotAllowed)
 		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Put("/", h.ReplaceAll)
+		r.Put(idParam, h.Replace)
+		r.Delete(idParam, h.Delete)
 	})
 	return r
 }
@@ -31,6 +37,40 @@ func eraRoutes(h *eraHandler) chi.Router {
  * Request and Response payloads/models for the REST api.
  **********************************************************/
 
+// EraWriteRequest is the request model used for writing entity.Era types. It is decoded from the JSON body of
+// POST and PUT requests. The title is required, whereas an omitted minYear or maxYear leaves that end of the
+// Era's range open.
+type EraWriteRequest struct {
+	_ struct{}
+
+	Title   *string `json:"title"`
+	MinYear *int16  `json:"minYear"`
+	MaxYear *int16  `json:"maxYear"`
+}
+
+// EraBulkRequest is an element of the JSON array in the body of a PUT request replacing every Era. If the id
+// is omitted, then a new Era is created.
+type EraBulkRequest struct {
+	ID *int32 `json:"id"`
+
+	EraWriteRequest
+}
+
+// writeInput maps the EraWriteRequest to an era.WriteInput.
+func (req EraWriteRequest) writeInput() era.WriteInput {
+	return era.WriteInput{Title: req.Title, MinYear: req.MinYear, MaxYear: req.MaxYear}
+}
+
+// decodeEraWriteRequest decodes the JSON body of the http.Request into an EraWriteRequest, and maps it to an
+// era.WriteInput. If the body is not valid JSON, then an error wrapping entity.ErrInvalidBody is returned.
+func decodeEraWriteRequest(w http.ResponseWriter, r *http.Request) (era.WriteInput, error) {
+	var req EraWriteRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return era.WriteInput{}, err
+	}
+	return req.writeInput(), nil
+}
+
 // EraResponse is the response struct sent back to the client.
 // Currently it embeds a pointer to entity.Era. In the future it would be
 // possible to separate the two models and perform mapping if necessary.
@@ -83,14 +123,112 @@ func NewEraHandler(driver era.Driver, logger *zap.Logger) (*eraHandler, error) {
 func (handler *eraHandler) List(w http.ResponseWriter, r *http.Request) {
 	eras, err := handler.driver.ListEras(r.Context())
 	if err != nil {
-		handler.logger.Error(fmt.Sprintf("error listing eras: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "listing eras")
+		return
+	}
+
+	if err := render.RenderList(w, r, newEraListResponse(eras)); err != nil {
+		renderError(w, handler.logger, err, "rendering eras")
+		return
+	}
+}
+
+// Create is an HTTP method that validates the EraWriteRequest in the body and creates an entity.Era from it.
+// The created Era is rendered with a 201 status code and its URL in the Location header. If the Era is invalid,
+// or its range does not adjoin the ranges of the other Eras, then a 422 status code is returned along with the
+// invalid fields.
+func (handler *eraHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeEraWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating era")
+		return
+	}
+
+	e, err := handler.driver.CreateEra(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating era")
+		return
+	}
+
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(int(e.ID))))
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, newEraResponse(e)); err != nil {
+		renderError(w, handler.logger, err, "rendering era")
+		return
+	}
+}
+
+// Replace is an HTTP method that validates the EraWriteRequest in the body and replaces every attribute of the
+// entity.Era identified by the URL parameter with it. The updated Era is rendered. If the Era does not exist,
+// then a 404 status code is returned. If the Era is invalid, or its range does not adjoin the ranges of the
+// other Eras, then a 422 status code is returned along with the invalid fields.
+func (handler *eraHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating era")
+		return
+	}
+
+	params, err := decodeEraWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating era")
+		return
+	}
+
+	e, err := handler.driver.ReplaceEra(r.Context(), id, params)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating era")
+		return
+	}
+
+	if err := render.Render(w, r, newEraResponse(e)); err != nil {
+		renderError(w, handler.logger, err, "rendering era")
+		return
+	}
+}
+
+// ReplaceAll is an HTTP method that validates the array of EraBulkRequests in the body and replaces every
+// entity.Era with them, which allows the boundaries between Eras to be moved at once. The resulting Eras are
+// rendered. If any Era is invalid, or the ranges do not adjoin, then a 422 status code is returned along with
+// the invalid fields, which are prefixed by their index in the array.
+func (handler *eraHandler) ReplaceAll(w http.ResponseWriter, r *http.Request) {
+	var req []EraBulkRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		renderError(w, handler.logger, err, "replacing eras")
+		return
+	}
+
+	params := make([]era.BulkInput, len(req))
+	for i, e := range req {
+		params[i] = era.BulkInput{ID: e.ID, WriteInput: e.writeInput()}
+	}
+
+	eras, err := handler.driver.ReplaceAllEras(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "replacing eras")
 		return
 	}
 
 	if err := render.RenderList(w, r, newEraListResponse(eras)); err != nil {
-		handler.logger.Error(fmt.Sprintf("error rendering eras: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "rendering eras")
 		return
 	}
 }
+
+// Delete is an HTTP method that deletes the entity.Era identified by the URL parameter. A 204 status code is
+// returned on success. If the Era does not exist, then a 404 status code is returned. If deleting the Era would
+// leave a gap between the ranges of the remaining Eras, then a 409 status code is returned.
+func (handler *eraHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting era")
+		return
+	}
+
+	if err := handler.driver.DeleteEra(r.Context(), id); err != nil {
+		renderError(w, handler.logger, err, "deleting era")
+		return
+	}
+
+	render.NoContent(w, r)
+}
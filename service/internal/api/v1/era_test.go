@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi/openapitest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/driver/era/eratest"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
 	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -57,6 +59,7 @@ func TestEraHandler_List(t *testing.T) {
 		expectedHandler string
 		expectedBody    string
 		expectedCode    int
+		expectedAllow   string
 		expectedErr     error
 		sendRequest     func(string) (*http.Response, error)
 	}{
@@ -78,18 +81,38 @@ func TestEraHandler_List(t *testing.T) {
 			expectedHandler: "ListEras",
 			target:          "/",
 			driverReturn:    []entity.Era{mockEra},
-			expectedBody:    `{"message":"HTTP method POST is not allowed"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Method Not Allowed","status":405,"code":"method_not_allowed","detail":"HTTP method PATCH is not allowed"}`,
+			expectedCode:    405,
+			expectedAllow:   "GET, POST, PUT",
+			sendRequest: func(url string) (*http.Response, error) {
+				req, err := http.NewRequest(http.MethodPatch, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				return http.DefaultClient.Do(req)
+			},
+		},
+		"invalid http method for an era": {
+			expectedHandler: "ListEras",
+			target:          "/2",
+			driverReturn:    []entity.Era{mockEra},
+			expectedBody:    `{"type":"about:blank","title":"Method Not Allowed","status":405,"code":"method_not_allowed","detail":"HTTP method PATCH is not allowed"}`,
+			expectedCode:    405,
+			expectedAllow:   "PUT, DELETE",
 			sendRequest: func(url string) (*http.Response, error) {
-				return http.Post(url, "application/json", nil)
+				req, err := http.NewRequest(http.MethodPatch, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				return http.DefaultClient.Do(req)
 			},
 		},
 		"driver returns error": {
 			expectedHandler: "ListEras",
 			target:          "/",
 			driverReturn:    []entity.Era{},
-			expectedBody:    `{"message":"Internal Server Error"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode:    500,
 			expectedErr:     errors.New("mock error returned from driver"),
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -104,8 +127,7 @@ func TestEraHandler_List(t *testing.T) {
 
 			r := eraRoutes(&handler)
 
-			server := httptest.NewServer(r)
-			defer server.Close()
+			server := openapitest.NewServer(t, "/eras", r)
 
 			driverMock.
 				On(tt.expectedHandler, mock.MatchedBy(func(_ context.Context) bool { return true })).
@@ -117,7 +139,145 @@ func TestEraHandler_List(t *testing.T) {
 			body, err := ioutil.ReadAll(resp.Body)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedAllow, resp.Header.Get("Allow"))
 			assert.Equal(t, tt.expectedBody+"\n", string(body))
 		})
 	}
 }
+
+func TestEraHandler_Write(t *testing.T) {
+	var (
+		anyContext = mock.MatchedBy(func(_ context.Context) bool { return true })
+		modern     = entity.Era{ID: 2, Title: "Modern", MinYear: util.Int16Ptr(1970)}
+		modernJson = `{"id":2,"title":"Modern","minYear":1970}`
+		modernBody = `{"title":"Modern","minYear":1970}`
+		modernArgs = era.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1970)}
+	)
+
+	tests := map[string]struct {
+		method          string
+		target          string
+		body            string
+		expectedHandler string
+		expectedArgs    []interface{}
+		driverReturn    []interface{}
+		expectedBody    string
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		"create era": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            modernBody,
+			expectedHandler: "CreateEra",
+			expectedArgs:    []interface{}{anyContext, modernArgs},
+			driverReturn:    []interface{}{modern, nil},
+			expectedBody:    modernJson,
+			expectedCode:    201,
+			expectedHeaders: map[string]string{"Location": "/2"},
+		},
+		"replace era leaving a gap": {
+			method:          http.MethodPut,
+			target:          "/2",
+			body:            `{"title":"Modern","minYear":1980}`,
+			expectedHandler: "ReplaceEra",
+			expectedArgs:    []interface{}{anyContext, int32(2), era.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1980)}},
+			driverReturn: []interface{}{entity.Era{}, &entity.ValidationError{Fields: []entity.FieldError{
+				{Field: "minYear", Code: validation.CodeConflict, Message: `should be 1970 to follow era "Classic"`},
+			}}},
+			expectedBody: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"code":"invalid_entity","detail":"invalid entity provided","fields":[{"field":"minYear","code":"conflict","message":"should be 1970 to follow era \"Classic\""}]}`,
+			expectedCode: 422,
+		},
+		"replace all eras": {
+			method:          http.MethodPut,
+			target:          "/",
+			body:            `[{"id":2,"title":"Modern","minYear":1970}]`,
+			expectedHandler: "ReplaceAllEras",
+			expectedArgs:    []interface{}{anyContext, []era.BulkInput{{ID: util.Int32Ptr(2), WriteInput: modernArgs}}},
+			driverReturn:    []interface{}{[]entity.Era{modern}, nil},
+			expectedBody:    "[" + modernJson + "]",
+			expectedCode:    200,
+		},
+		"replace all eras leaving out any": {
+			method:          http.MethodPut,
+			target:          "/",
+			body:            `[{"id":2,"title":"Modern","minYear":1970}]`,
+			expectedHandler: "ReplaceAllEras",
+			expectedArgs:    []interface{}{anyContext, []era.BulkInput{{ID: util.Int32Ptr(2), WriteInput: modernArgs}}},
+			driverReturn: []interface{}{[]entity.Era(nil),
+				fmt.Errorf("%w: era 0 matches every value and cannot be left out", entity.ErrConflict)},
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"code":"conflict","detail":"conflict with the current state of the resources: ` +
+				`era 0 matches every value and cannot be left out"}`,
+			expectedCode: 409,
+		},
+		"replace all eras with nothing": {
+			method:          http.MethodPut,
+			target:          "/",
+			body:            `[]`,
+			expectedHandler: "ReplaceAllEras",
+			expectedArgs:    []interface{}{anyContext, []era.BulkInput{}},
+			driverReturn:    []interface{}{[]entity.Era(nil), fmt.Errorf("%w: at least one era is required", entity.ErrInvalidBody)},
+			expectedBody:    `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: at least one era is required"}`,
+			expectedCode:    400,
+		},
+		"replace all eras with malformed body": {
+			method:       http.MethodPut,
+			target:       "/",
+			body:         modernBody,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: json: cannot unmarshal object into Go value of type []v1.EraBulkRequest"}`,
+			expectedCode: 400,
+		},
+		"delete era": {
+			method:          http.MethodDelete,
+			target:          "/2",
+			expectedHandler: "DeleteEra",
+			expectedArgs:    []interface{}{anyContext, int32(2)},
+			driverReturn:    []interface{}{nil},
+			expectedCode:    204,
+		},
+		"delete era leaving a gap": {
+			method:          http.MethodDelete,
+			target:          "/2",
+			expectedHandler: "DeleteEra",
+			expectedArgs:    []interface{}{anyContext, int32(2)},
+			driverReturn: []interface{}{fmt.Errorf(`%w: deleting era 2 would leave a gap between era "Classic" and era "Contemporary"`,
+				entity.ErrConflict)},
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"code":"conflict","detail":"conflict with the current state of the resources: ` +
+				`deleting era 2 would leave a gap between era \"Classic\" and era \"Contemporary\""}`,
+			expectedCode: 409,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := eratest.DriverMock{}
+			handler := eraHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/eras", eraRoutes(&handler))
+
+			if tt.expectedHandler != "" {
+				driverMock.On(tt.expectedHandler, tt.expectedArgs...).Return(tt.driverReturn...)
+			}
+
+			req, err := http.NewRequest(tt.method, fmt.Sprintf("%s%s", server.URL, tt.target), strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.expectedBody != "" {
+				tt.expectedBody += "\n"
+			}
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
+			driverMock.AssertExpectations(t)
+		})
+	}
+}
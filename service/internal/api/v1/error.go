@@ -1,58 +1,175 @@
 package v1
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
-	"github.com/go-chi/render"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
 )
 
 const (
 	methodNotAllowed    = "HTTP method %s is not allowed"
 	internalServerError = "Internal Server Error"
+
+	// problemContentType is the media type of RFC 7807 problem details.
+	problemContentType = "application/problem+json"
+
+	// problemType is the type of every Problem. Their codes tell what kind of problem they are instead, so that
+	// their titles are those of their statuses.
+	problemType = "about:blank"
+
+	// clientClosedRequest is the title of StatusClientClosedRequest, which net/http has no text for.
+	clientClosedRequest = "Client Closed Request"
+)
+
+// StatusClientClosedRequest is the status, first used by nginx, of requests which the client gave up on before
+// they were served. The client never sees it, but it is logged and measured, so that canceled requests are not
+// mistaken for failures of the service.
+const StatusClientClosedRequest = 499
+
+// The codes of Problems, which tell clients what kind of problem they are. Unlike the detail of a Problem, they
+// are stable, so clients may act on them.
+const (
+	CodeInvalidQueryParam = "invalid_query_param"
+	CodeInvalidBody       = "invalid_body"
+	CodeInvalidEntity     = "invalid_entity"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeMethodNotAllowed  = "method_not_allowed"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeCanceled          = "canceled"
+	CodeTimeout           = "timeout"
+	CodeUnavailable       = "unavailable"
+	CodeInternal          = "internal"
 )
 
-// ErrorResponse is used to wrap errors and status codes for faulty requests and responses.
-type ErrorResponse struct {
+// Problem is an RFC 7807 problem details object, which describes why a request failed. It is rendered as
+// application/problem+json by RenderProblem.
+type Problem struct {
 	// Err is the internal error.
 	Err error `json:"-"`
 
-	// StatusCode is the status code attached to this particular error.
-	StatusCode int `json:"-"`
+	// Type is always about:blank.
+	Type string `json:"type"`
+
+	// Title is the text of the Status.
+	Title string `json:"title"`
+
+	// Status is the status code of the response.
+	Status int `json:"status"`
+
+	// Code is one of the codes above.
+	Code string `json:"code"`
+
+	// Detail is the message to be displayed to the client.
+	Detail string `json:"detail,omitempty"`
+
+	// Fields lists the invalid fields of an entity which failed validation, if any.
+	Fields []entity.FieldError `json:"fields,omitempty"`
+}
 
-	// ErrorString is the message to be displayed to the client.
-	ErrorString string `json:"message"`
+// newProblem creates a Problem of the status, titled by the text of the status.
+func newProblem(err error, status int, code, detail string) *Problem {
+	title := http.StatusText(status)
+	if status == StatusClientClosedRequest {
+		title = clientClosedRequest
+	}
+	return &Problem{Err: err, Type: problemType, Title: title, Status: status, Detail: detail, Code: code}
 }
 
-// Render sets the status code for the request.
-func (e *ErrorResponse) Render(_ http.ResponseWriter, r *http.Request) error {
-	render.Status(r, e.StatusCode)
-	return nil
+// problemKinds maps each kind of domain error to the status and code of its Problems. Errors are mapped by the
+// first kind which they wrap. The errors of the service, and cancellations, are detailed by their kind alone, so
+// that the failures of its dependencies are not disclosed.
+var problemKinds = []struct {
+	kind   error
+	status int
+	code   string
+	detail error
+}{
+	{kind: entity.ErrInvalidQueryParam, status: http.StatusBadRequest, code: CodeInvalidQueryParam},
+	{kind: entity.ErrInvalidBody, status: http.StatusBadRequest, code: CodeInvalidBody},
+	{kind: entity.ErrInvalidEntity, status: http.StatusUnprocessableEntity, code: CodeInvalidEntity},
+	{kind: entity.ErrNotFound, status: http.StatusNotFound, code: CodeNotFound},
+	{kind: entity.ErrConflict, status: http.StatusConflict, code: CodeConflict},
+	{kind: entity.ErrCanceled, status: StatusClientClosedRequest, code: CodeCanceled, detail: entity.ErrCanceled},
+	{kind: context.Canceled, status: StatusClientClosedRequest, code: CodeCanceled, detail: entity.ErrCanceled},
+	{kind: entity.ErrTimeout, status: http.StatusGatewayTimeout, code: CodeTimeout, detail: entity.ErrTimeout},
+	{kind: context.DeadlineExceeded, status: http.StatusGatewayTimeout, code: CodeTimeout, detail: entity.ErrTimeout},
+	{kind: entity.ErrUnavailable, status: http.StatusServiceUnavailable, code: CodeUnavailable, detail: entity.ErrUnavailable},
 }
 
-// ErrBadRequest converts an error to a ErrorResponse for easy rendering on the client-side.
-func ErrBadRequest(err error) render.Renderer {
-	return &ErrorResponse{
-		Err:         err,
-		StatusCode:  http.StatusBadRequest,
-		ErrorString: err.Error(),
+// NewProblem maps the error to the Problem which describes it to the client. Domain errors, such as
+// entity.ErrNotFound or entity.ErrUnavailable, are mapped to the status and code of their kind, and any other
+// error is an internal server error. If the error is an *entity.ValidationError, then its FieldErrors are
+// included in the Problem, which is detailed by its kind alone.
+func NewProblem(err error) *Problem {
+	for _, k := range problemKinds {
+		if !errors.Is(err, k.kind) {
+			continue
+		}
+
+		detail := err.Error()
+		if k.detail != nil {
+			detail = k.detail.Error()
+		}
+		p := newProblem(err, k.status, k.code, detail)
+
+		var validationErr *entity.ValidationError
+		if errors.As(err, &validationErr) {
+			p.Detail = k.kind.Error()
+			p.Fields = validationErr.Fields
+		}
+		return p
 	}
+	return newProblem(err, http.StatusInternalServerError, CodeInternal, internalServerError)
+}
+
+// RenderProblem writes the Problem, with its status, as application/problem+json.
+func RenderProblem(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
 }
 
-// ErrInternalServer returns a 400 status code with a string "Internal Server Error."
-// For the purposes of the challenge, everything is a 400. In a real-world application this would use
-// http.StatusInternalServerError.
-func ErrInternalServer(err error) render.Renderer {
-	return &ErrorResponse{
-		Err:         err,
-		StatusCode:  http.StatusBadRequest,
-		ErrorString: internalServerError,
+// renderError renders the error, which occurred while carrying out the action, such as "creating book", as the
+// Problem that NewProblem maps it to. The errors of the service are logged, since their Problems withhold their
+// cause.
+func renderError(w http.ResponseWriter, logger *zap.Logger, err error, action string) {
+	p := NewProblem(err)
+	switch {
+	case p.Status == StatusClientClosedRequest:
+		logger.Debug(fmt.Sprintf("canceled %s: %s", action, err))
+	case p.Status >= http.StatusInternalServerError:
+		logger.Error(fmt.Sprintf("error %s: %s", action, err))
 	}
+	RenderProblem(w, p)
+}
+
+// ErrUnauthorized converts an error to a Problem with a 401 status code, for requests which did not
+// authenticate themselves.
+func ErrUnauthorized(err error) *Problem {
+	return newProblem(err, http.StatusUnauthorized, CodeUnauthorized, err.Error())
+}
+
+// ErrForbidden converts an error to a Problem with a 403 status code, for requests which are not allowed to act
+// on the resources.
+func ErrForbidden(err error) *Problem {
+	return newProblem(err, http.StatusForbidden, CodeForbidden, err.Error())
+}
+
+// ErrMethodNotAllowed returns a Problem with a 405 status code, specifying what method was rejected.
+func ErrMethodNotAllowed(method string) *Problem {
+	err := fmt.Errorf(methodNotAllowed, method)
+	return newProblem(err, http.StatusMethodNotAllowed, CodeMethodNotAllowed, err.Error())
 }
 
-// ErrMethodNotAllowed returns a 400 status code with a string specifying what method was rejected.
-// For the purposes of the challenge, everything is a 400. In a real-world application this would use
-// http.StatusMethodNotAllowed.
-func ErrMethodNotAllowed(method string) render.Renderer {
-	return ErrBadRequest(fmt.Errorf(methodNotAllowed, method))
+// ErrRouteNotFound returns a Problem with a 404 status code, for requests which matched no route.
+func ErrRouteNotFound(path string) *Problem {
+	err := fmt.Errorf("%w: %s does not exist", entity.ErrNotFound, path)
+	return newProblem(err, http.StatusNotFound, CodeNotFound, err.Error())
 }
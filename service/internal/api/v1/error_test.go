@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewProblem(t *testing.T) {
+
+	tests := map[string]struct {
+		err    error
+		status int
+		code   string
+		detail string
+		fields []entity.FieldError
+	}{
+		"invalid query parameter": {
+			err:    fmt.Errorf("%w: limit is 0 but should be greater than 0", entity.ErrInvalidQueryParam),
+			status: http.StatusBadRequest,
+			code:   CodeInvalidQueryParam,
+			detail: "invalid URL query parameter provided: limit is 0 but should be greater than 0",
+		},
+		"invalid entity": {
+			err:    &entity.ValidationError{Fields: []entity.FieldError{{Field: "title", Code: validation.CodeRequired, Message: "is required"}}},
+			status: http.StatusUnprocessableEntity,
+			code:   CodeInvalidEntity,
+			detail: "invalid entity provided",
+			fields: []entity.FieldError{{Field: "title", Code: validation.CodeRequired, Message: "is required"}},
+		},
+		"not found": {
+			err:    fmt.Errorf("%w: book 9 does not exist", entity.ErrNotFound),
+			status: http.StatusNotFound,
+			code:   CodeNotFound,
+			detail: "resource not found: book 9 does not exist",
+		},
+		"conflict": {
+			err:    fmt.Errorf("%w: genre 1 is still referenced by books", entity.ErrConflict),
+			status: http.StatusConflict,
+			code:   CodeConflict,
+			detail: "conflict with the current state of the resources: genre 1 is still referenced by books",
+		},
+		"canceled": {
+			err:    errors.Wrap(context.Canceled, "unable to search books"),
+			status: StatusClientClosedRequest,
+			code:   CodeCanceled,
+			detail: "request canceled",
+		},
+		"timeout": {
+			err:    errors.Wrap(&entity.Error{Kind: entity.ErrTimeout, Err: errors.New("pq: canceling statement due to statement timeout")}, "unable to search books"),
+			status: http.StatusGatewayTimeout,
+			code:   CodeTimeout,
+			detail: "operation timed out",
+		},
+		"deadline exceeded": {
+			err:    errors.Wrap(context.DeadlineExceeded, "unable to search books"),
+			status: http.StatusGatewayTimeout,
+			code:   CodeTimeout,
+			detail: "operation timed out",
+		},
+		"unavailable": {
+			err:    &entity.Error{Kind: entity.ErrUnavailable, Err: errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")},
+			status: http.StatusServiceUnavailable,
+			code:   CodeUnavailable,
+			detail: "service unavailable",
+		},
+		"internal": {
+			err:    errors.New("pq: syntax error"),
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+			detail: "Internal Server Error",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := NewProblem(tt.err)
+			assert.Equal(t, tt.err, p.Err)
+			assert.Equal(t, "about:blank", p.Type)
+			assert.Equal(t, tt.status, p.Status)
+			assert.Equal(t, tt.code, p.Code)
+			assert.Equal(t, tt.detail, p.Detail)
+			assert.Equal(t, tt.fields, p.Fields)
+		})
+	}
+}
+
+func TestRenderError(t *testing.T) {
+
+	tests := map[string]struct {
+		err          error
+		expectedBody string
+		expectedCode int
+		logged       bool
+	}{
+		"client error is not logged": {
+			err:          fmt.Errorf("%w: book 9 does not exist", entity.ErrNotFound),
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: book 9 does not exist"}`,
+			expectedCode: http.StatusNotFound,
+		},
+		"canceled request is not logged as an error": {
+			err:          &entity.Error{Kind: entity.ErrCanceled, Err: context.Canceled},
+			expectedBody: `{"type":"about:blank","title":"Client Closed Request","status":499,"code":"canceled","detail":"request canceled"}`,
+			expectedCode: StatusClientClosedRequest,
+		},
+		"service error is logged": {
+			err:          &entity.Error{Kind: entity.ErrUnavailable, Err: errors.New("connection refused")},
+			expectedBody: `{"type":"about:blank","title":"Service Unavailable","status":503,"code":"unavailable","detail":"service unavailable"}`,
+			expectedCode: http.StatusServiceUnavailable,
+			logged:       true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.ErrorLevel)
+			w := httptest.NewRecorder()
+			renderError(w, zap.New(core), tt.err, "getting book")
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expectedBody+"\n", w.Body.String())
+			if tt.logged {
+				assert.Equal(t, 1, logs.FilterMessage("error getting book: "+tt.err.Error()).Len())
+			} else {
+				assert.Zero(t, logs.Len())
+			}
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package v1
 
 import (
-	"fmt"
 	"net/http"
+	"path"
+	"strconv"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
@@ -17,12 +18,17 @@ func genreRoutes(h *genreHandler) chi.Router {
 	r := chi.NewRouter()
 	r.Route("/", func(r chi.Router) {
 		r.Use(
-			cors.Handler(cors.Options{AllowedMethods: []string{"GET"}}),
+			cors.Handler(cors.Options{
+				AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+				ExposedHeaders: []string{"Location"},
+			}),
 		)
-		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-			_ = render.Render(w, r, ErrMethodNotAllowed(r.Method))
-		})
+		r.MethodNotAllowed(renderMethodNotAllowed)
 		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get(idParam, h.Get)
+		r.Put(idParam, h.Replace)
+		r.Delete(idParam, h.Delete)
 	})
 	return r
 }
@@ -31,6 +37,25 @@ func genreRoutes(h *genreHandler) chi.Router {
  * Request and Response payloads/models for the REST api.
  **********************************************************/
 
+// GenreWriteRequest is the request model used for writing entity.Genre types. It is decoded from the JSON
+// body of POST and PUT requests, which require every attribute.
+type GenreWriteRequest struct {
+	_ struct{}
+
+	Title *string `json:"title"`
+}
+
+// decodeGenreWriteRequest decodes the JSON body of the http.Request into a GenreWriteRequest, and maps it to
+// a genre.WriteInput. If the body is not valid JSON, then an error wrapping entity.ErrInvalidBody is returned.
+func decodeGenreWriteRequest(w http.ResponseWriter, r *http.Request) (genre.WriteInput, error) {
+	var req GenreWriteRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return genre.WriteInput{}, err
+	}
+
+	return genre.WriteInput{Title: req.Title}, nil
+}
+
 // GenreResponse is the response struct sent back to the client.
 // Currently it embeds a pointer to entity.Genre. In the future it would be
 // possible to separate the two models and perform mapping if necessary.
@@ -58,6 +83,41 @@ func newGenreListResponse(genres []entity.Genre) []render.Renderer {
 	return out
 }
 
+// GenreStatsResponse holds aggregates over the books of a genre.
+type GenreStatsResponse struct {
+	BookCount          int64    `json:"bookCount"`
+	AuthorCount        int64    `json:"authorCount"`
+	AverageRating      *float64 `json:"averageRating,omitempty"`
+	FirstYearPublished *int16   `json:"firstYearPublished,omitempty"`
+	LastYearPublished  *int16   `json:"lastYearPublished,omitempty"`
+}
+
+// GenreDetailResponse is the response struct sent back to the client for a single genre. It embeds
+// the entity.Genre along with aggregates over the genre's books.
+type GenreDetailResponse struct {
+	entity.Genre
+	Stats GenreStatsResponse `json:"stats"`
+}
+
+// newGenreDetailResponse accepts a genre.Detail and maps it into a GenreDetailResponse.
+func newGenreDetailResponse(detail genre.Detail) *GenreDetailResponse {
+	return &GenreDetailResponse{
+		Genre: detail.Genre,
+		Stats: GenreStatsResponse{
+			BookCount:          detail.Stats.BookCount,
+			AuthorCount:        detail.Stats.AuthorCount,
+			AverageRating:      detail.Stats.AverageRating,
+			FirstYearPublished: detail.Stats.FirstYearPublished,
+			LastYearPublished:  detail.Stats.LastYearPublished,
+		},
+	}
+}
+
+// Render is a stub for preprocessing the GenreDetailResponse model.
+func (gdr *GenreDetailResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 /*****************************
  * v1 Genre endpoint handlers
  *****************************/
@@ -83,14 +143,111 @@ func NewGenreHandler(driver genre.Driver, logger *zap.Logger) (*genreHandler, er
 func (handler *genreHandler) List(w http.ResponseWriter, r *http.Request) {
 	genres, err := handler.driver.ListGenres(r.Context())
 	if err != nil {
-		handler.logger.Error(fmt.Sprintf("error listing genres: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "listing genres")
 		return
 	}
 
 	if err := render.RenderList(w, r, newGenreListResponse(genres)); err != nil {
-		handler.logger.Error(fmt.Sprintf("error rendering eras: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "rendering genres")
 		return
 	}
 }
+
+// Get is an HTTP method that renders the entity.Genre identified by the URL parameter, along with aggregates
+// over the genre's books. If the Genre does not exist, then a 404 status code is returned.
+func (handler *genreHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "getting genre")
+		return
+	}
+
+	detail, err := handler.driver.GetGenre(r.Context(), id)
+	if err != nil {
+		renderError(w, handler.logger, err, "getting genre")
+		return
+	}
+
+	if err := render.Render(w, r, newGenreDetailResponse(detail)); err != nil {
+		renderError(w, handler.logger, err, "rendering genre")
+		return
+	}
+}
+
+// Create is an HTTP method that validates the GenreWriteRequest in the body and creates an entity.Genre from
+// it. The created Genre is rendered with a 201 status code and its URL in the Location header. If the Genre is
+// invalid, then a 422 status code is returned along with the invalid fields.
+func (handler *genreHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeGenreWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating genre")
+		return
+	}
+
+	g, err := handler.driver.CreateGenre(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating genre")
+		return
+	}
+
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(int(g.ID))))
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, newGenreResponse(g)); err != nil {
+		renderError(w, handler.logger, err, "rendering genre")
+		return
+	}
+}
+
+// Replace is an HTTP method that validates the GenreWriteRequest in the body and replaces every attribute of
+// the entity.Genre identified by the URL parameter with it. The updated Genre is rendered. If the Genre does
+// not exist, then a 404 status code is returned. If the Genre is invalid, then a 422 status code is returned
+// along with the invalid fields.
+func (handler *genreHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating genre")
+		return
+	}
+
+	params, err := decodeGenreWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating genre")
+		return
+	}
+
+	g, err := handler.driver.ReplaceGenre(r.Context(), id, params)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating genre")
+		return
+	}
+
+	if err := render.Render(w, r, newGenreResponse(g)); err != nil {
+		renderError(w, handler.logger, err, "rendering genre")
+		return
+	}
+}
+
+// Delete is an HTTP method that deletes the entity.Genre identified by the URL parameter. A 204 status code is
+// returned on success. If the Genre does not exist, then a 404 status code is returned. If Books still
+// reference the Genre, then a 409 status code is returned, unless the reassign-to query parameter identifies
+// another Genre to reassign them to.
+func (handler *genreHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting genre")
+		return
+	}
+
+	target, err := reassignTo(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting genre")
+		return
+	}
+
+	if err := handler.driver.DeleteGenre(r.Context(), id, target); err != nil {
+		renderError(w, handler.logger, err, "deleting genre")
+		return
+	}
+
+	render.NoContent(w, r)
+}
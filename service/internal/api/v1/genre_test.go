@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi/openapitest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre/genretest"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
@@ -58,6 +61,7 @@ func TestGenreHandler_List(t *testing.T) {
 		expectedHandler string
 		expectedBody    string
 		expectedCode    int
+		expectedAllow   string
 		expectedErr     error
 		sendRequest     func(string) (*http.Response, error)
 	}{
@@ -75,18 +79,23 @@ func TestGenreHandler_List(t *testing.T) {
 			expectedHandler: "ListGenres",
 			target:          "/",
 			driverReturn:    []entity.Genre{mockGenre},
-			expectedBody:    `{"message":"HTTP method POST is not allowed"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Method Not Allowed","status":405,"code":"method_not_allowed","detail":"HTTP method PATCH is not allowed"}`,
+			expectedCode:    405,
+			expectedAllow:   "GET, POST",
 			sendRequest: func(url string) (*http.Response, error) {
-				return http.Post(url, "application/json", nil)
+				req, err := http.NewRequest(http.MethodPatch, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				return http.DefaultClient.Do(req)
 			},
 		},
 		"driver returns error": {
 			expectedHandler: "ListGenres",
 			target:          "/",
 			driverReturn:    []entity.Genre{},
-			expectedBody:    `{"message":"Internal Server Error"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode:    500,
 			expectedErr:     errors.New("mock error returned from driver"),
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -101,8 +110,7 @@ func TestGenreHandler_List(t *testing.T) {
 
 			r := genreRoutes(&handler)
 
-			server := httptest.NewServer(r)
-			defer server.Close()
+			server := openapitest.NewServer(t, "/genres", r)
 
 			driverMock.
 				On(tt.expectedHandler, mock.MatchedBy(func(_ context.Context) bool { return true })).
@@ -114,7 +122,188 @@ func TestGenreHandler_List(t *testing.T) {
 			body, err := ioutil.ReadAll(resp.Body)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedAllow, resp.Header.Get("Allow"))
 			assert.Equal(t, tt.expectedBody+"\n", string(body))
 		})
 	}
 }
+
+func TestGenreHandler_Get(t *testing.T) {
+	tests := map[string]struct {
+		target       string
+		expectedID   int32
+		driverReturn genre.Detail
+		expectedBody string
+		expectedCode int
+		expectedErr  error
+	}{
+		"get genre": {
+			target:     "/2",
+			expectedID: 2,
+			driverReturn: genre.Detail{
+				Genre: entity.Genre{ID: 2, Title: "SciFi/Fantasy"},
+				Stats: genre.Stats{
+					BookCount:          12,
+					AuthorCount:        5,
+					AverageRating:      util.Float64Ptr(3.2),
+					FirstYearPublished: util.Int16Ptr(1851),
+					LastYearPublished:  util.Int16Ptr(2004),
+				},
+			},
+			expectedBody: `{"id":2,"title":"SciFi/Fantasy","stats":{"bookCount":12,"authorCount":5,` +
+				`"averageRating":3.2,"firstYearPublished":1851,"lastYearPublished":2004}}`,
+			expectedCode: 200,
+		},
+		"genre does not exist": {
+			target:       "/9",
+			expectedID:   9,
+			expectedBody: `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: genre 9 does not exist"}`,
+			expectedCode: 404,
+			expectedErr:  fmt.Errorf("%w: genre 9 does not exist", entity.ErrNotFound),
+		},
+		"driver returns error": {
+			target:       "/2",
+			expectedID:   2,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode: 500,
+			expectedErr:  errors.New("mock error returned from driver"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := genretest.DriverMock{}
+			handler := genreHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/genres", genreRoutes(&handler))
+
+			driverMock.
+				On("GetGenre", mock.MatchedBy(func(_ context.Context) bool { return true }), tt.expectedID).
+				Return(tt.driverReturn, tt.expectedErr)
+
+			resp, err := http.Get(fmt.Sprintf("%s%s", server.URL, tt.target))
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedBody+"\n", string(body))
+		})
+	}
+}
+
+func TestGenreHandler_Write(t *testing.T) {
+	var (
+		anyContext = mock.MatchedBy(func(_ context.Context) bool { return true })
+		poetry     = entity.Genre{ID: 8, Title: "Poetry"}
+		poetryJson = `{"id":8,"title":"Poetry"}`
+		poetryBody = `{"title":"Poetry"}`
+		poetryArgs = genre.WriteInput{Title: util.StringPtr("Poetry")}
+	)
+
+	tests := map[string]struct {
+		method          string
+		target          string
+		body            string
+		expectedHandler string
+		expectedArgs    []interface{}
+		driverReturn    []interface{}
+		expectedBody    string
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		"create genre": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            poetryBody,
+			expectedHandler: "CreateGenre",
+			expectedArgs:    []interface{}{anyContext, poetryArgs},
+			driverReturn:    []interface{}{poetry, nil},
+			expectedBody:    poetryJson,
+			expectedCode:    201,
+			expectedHeaders: map[string]string{"Location": "/8"},
+		},
+		"create invalid genre": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            `{}`,
+			expectedHandler: "CreateGenre",
+			expectedArgs:    []interface{}{anyContext, genre.WriteInput{}},
+			driverReturn: []interface{}{entity.Genre{}, &entity.ValidationError{Fields: []entity.FieldError{
+				{Field: "title", Code: validation.CodeRequired, Message: "is required"},
+			}}},
+			expectedBody: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"code":"invalid_entity","detail":"invalid entity provided","fields":[{"field":"title","code":"required","message":"is required"}]}`,
+			expectedCode: 422,
+		},
+		"replace genre with unknown field": {
+			method:       http.MethodPut,
+			target:       "/8",
+			body:         `{"name":"Poetry"}`,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_body","detail":"invalid request body provided: json: unknown field \"name\""}`,
+			expectedCode: 400,
+		},
+		"replace genre": {
+			method:          http.MethodPut,
+			target:          "/8",
+			body:            poetryBody,
+			expectedHandler: "ReplaceGenre",
+			expectedArgs:    []interface{}{anyContext, int32(8), poetryArgs},
+			driverReturn:    []interface{}{poetry, nil},
+			expectedBody:    poetryJson,
+			expectedCode:    200,
+		},
+		"delete referenced genre": {
+			method:          http.MethodDelete,
+			target:          "/1",
+			expectedHandler: "DeleteGenre",
+			expectedArgs:    []interface{}{anyContext, int32(1), (*int32)(nil)},
+			driverReturn: []interface{}{fmt.Errorf("%w: genre 1 still has 3 books, which must be reassigned to another genre",
+				entity.ErrConflict)},
+			expectedBody: `{"type":"about:blank","title":"Conflict","status":409,"code":"conflict","detail":"conflict with the current state of the resources: ` +
+				`genre 1 still has 3 books, which must be reassigned to another genre"}`,
+			expectedCode: 409,
+		},
+		"delete genre with reassignment": {
+			method:          http.MethodDelete,
+			target:          "/1?reassign-to=8",
+			expectedHandler: "DeleteGenre",
+			expectedArgs:    []interface{}{anyContext, int32(1), util.Int32Ptr(8)},
+			driverReturn:    []interface{}{nil},
+			expectedCode:    204,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := genretest.DriverMock{}
+			handler := genreHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/genres", genreRoutes(&handler))
+
+			if tt.expectedHandler != "" {
+				driverMock.On(tt.expectedHandler, tt.expectedArgs...).Return(tt.driverReturn...)
+			}
+
+			req, err := http.NewRequest(tt.method, fmt.Sprintf("%s%s", server.URL, tt.target), strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.expectedBody != "" {
+				tt.expectedBody += "\n"
+			}
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
+			driverMock.AssertExpectations(t)
+		})
+	}
+}
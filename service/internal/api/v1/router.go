@@ -1,19 +1,41 @@
 package v1
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
 	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/schema"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
-func NewRouter(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver, bd book.Driver, logger *zap.Logger) (*chi.Mux, error) {
-	bookHandler, err := NewBookHandler(bd, logger)
+// Options configure how the routes of the API treat their requests.
+type Options struct {
+	// StrictQuery rejects requests with query parameters which their route does not know, rather than ignoring
+	// them, so that misspelled parameters do not silently widen a search.
+	StrictQuery bool
+
+	// SpecValidation is the mode in which requests and their responses are validated against the OpenAPI spec of
+	// the API: SpecValidationOff, SpecValidationLog or SpecValidationReject. It is off if it is empty.
+	SpecValidation string
+}
+
+func NewRouter(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver, bd book.Driver, logger *zap.Logger, opts Options) (*chi.Mux, error) {
+	bookHandler, err := NewBookHandler(bd, logger, opts)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create v1 routes: %w", err)
 	}
@@ -38,7 +60,13 @@ func NewRouter(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver,
 		return nil, fmt.Errorf("unable to create v1 routes: %w", err)
 	}
 
+	validateSpec, err := specValidation(opts.SpecValidation, logger)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create v1 routes: %w", err)
+	}
+
 	r := chi.NewRouter()
+	r.Use(validateSpec)
 
 	r.Mount("/books", bookRoutes(bookHandler))
 	r.Mount("/authors", authorRoutes(authorHandler))
@@ -48,3 +76,173 @@ func NewRouter(ad author.Driver, sd size.Driver, gd genre.Driver, ed era.Driver,
 
 	return r, nil
 }
+
+const (
+	// idParam is the pattern of the URL parameter which identifies a single resource.
+	idParam = "/{id:[0-9]+}"
+
+	// reassignToParam is the URL query parameter which identifies the resource to reassign the Books of
+	// a deleted resource to.
+	reassignToParam = "reassign-to"
+
+	// maxBodyBytes is the largest request body accepted when writing a resource.
+	maxBodyBytes = 1 << 20
+)
+
+// routedMethods are the methods which the routes of the API may be registered for, in the order they are listed
+// in an Allow header.
+var routedMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// renderMethodNotAllowed renders the 405 Problem for a request whose method its route is not registered for. The Allow
+// header lists the methods which the route is registered for, as RFC 9110 requires.
+func renderMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Allow", strings.Join(allowedMethods(r), ", "))
+	RenderProblem(w, ErrMethodNotAllowed(r.Method))
+}
+
+// allowedMethods returns the routedMethods which the path of the http.Request is routed for, by matching it
+// against every route from the root of the router which is serving it.
+func allowedMethods(r *http.Request) []string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return nil
+	}
+
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+
+	var allowed []string
+	for _, method := range routedMethods {
+		if rctx.Routes.Match(chi.NewRouteContext(), method, path) {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// resourceID parses the ID of the resource requested by the http.Request from the URL parameter matched by
+// idParam. If the ID does not fit an int32, then no such resource can exist and an error wrapping
+// entity.ErrNotFound is returned.
+func resourceID(r *http.Request) (int32, error) {
+	param := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(param, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s does not exist", entity.ErrNotFound, param)
+	}
+	return int32(id), nil
+}
+
+// reassignTo parses the optional reassignToParam of the http.Request. If it is absent, then nil is returned. If
+// it is not a valid ID, then an error wrapping entity.ErrInvalidQueryParam is returned.
+func reassignTo(r *http.Request) (*int32, error) {
+	param := r.URL.Query().Get(reassignToParam)
+	if param == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(param, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an ID but is %q", entity.ErrInvalidQueryParam, reassignToParam, param)
+	}
+	target := int32(id)
+	return &target, nil
+}
+
+// decodeQuery decodes the query parameters of the http.Request into dst, a struct whose fields are tagged with
+// the names of their parameters, and records a violation in the validation.Validator for every parameter which
+// cannot be decoded. Unknown parameters are violations if strict, and are otherwise logged and ignored. If the
+// query cannot be parsed at all, then an error wrapping entity.ErrInvalidQueryParam is returned.
+func decodeQuery(r *http.Request, dst interface{}, strict bool, v *validation.Validator, logger *zap.Logger) error {
+	if err := r.ParseForm(); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidQueryParam, err)
+	}
+
+	err := schema.NewDecoder().Decode(dst, splitLists(dst, r.Form))
+	if err == nil {
+		return nil
+	}
+	var multiErr schema.MultiError
+	if !errors.As(err, &multiErr) {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidQueryParam, err)
+	}
+
+	// The errors are keyed by parameter, so they are sorted to be reported in a stable order.
+	keys := make([]string, 0, len(multiErr))
+	for k := range multiErr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		switch e := multiErr[k].(type) {
+		case schema.ConversionError:
+			v.Add(e.Key, validation.CodeInvalidType, "should be %s", typeName(e.Type))
+		case schema.UnknownKeyError:
+			if strict {
+				v.Add(e.Key, validation.CodeUnknown, "is not a known parameter")
+				continue
+			}
+			logger.Warn(fmt.Sprintf("ignoring unknown query parameter %s", e.Key))
+		default:
+			v.Add(k, validation.CodeInvalid, "%s", e)
+		}
+	}
+	return nil
+}
+
+// splitLists copies the query, splitting the comma-delimited values of the parameters which dst decodes into
+// slices, such as genres=1,2, so that they are decoded as if the parameter had been repeated, as in
+// genres=1&genres=2. Both forms may be mixed. The values of slices of strings must therefore not contain commas.
+func splitLists(dst interface{}, query url.Values) url.Values {
+	split := make(url.Values, len(query))
+	for k, v := range query {
+		split[k] = v
+	}
+
+	t := reflect.TypeOf(dst).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("schema"), ",")[0]
+		values, ok := query[name]
+		if !ok || f.Type.Kind() != reflect.Slice {
+			continue
+		}
+
+		var elems []string
+		for _, v := range values {
+			elems = append(elems, strings.Split(v, ",")...)
+		}
+		split[name] = elems
+	}
+	return split
+}
+
+// typeName describes the type of a query parameter to clients, such as "an integer" rather than "int16".
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "true or false"
+	}
+	return "a " + t.String()
+}
+
+// decodeBody decodes the JSON body of the http.Request into v, reading at most maxBodyBytes. If the body is
+// not valid JSON, or has fields which v does not, then an error wrapping entity.ErrInvalidBody is returned, so
+// that a misspelled field is not silently ignored.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %s", entity.ErrInvalidBody, err)
+	}
+	return nil
+}
@@ -47,7 +47,7 @@ func TestNewRouter(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			r, err := NewRouter(tt.authorDriver, tt.sizeDriver, tt.genreDriver, tt.eraDriver, tt.bookDriver, zap.NewNop())
+			r, err := NewRouter(tt.authorDriver, tt.sizeDriver, tt.genreDriver, tt.eraDriver, tt.bookDriver, zap.NewNop(), Options{})
 			tt.errAssertion(t, err)
 			tt.valAssertion(t, r)
 			if r != nil {
@@ -1,8 +1,9 @@
 package v1
 
 import (
-	"fmt"
 	"net/http"
+	"path"
+	"strconv"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
@@ -17,12 +18,17 @@ func sizeRoutes(h *sizeHandler) chi.Router {
 	r := chi.NewRouter()
 	r.Route("/", func(r chi.Router) {
 		r.Use(
-			cors.Handler(cors.Options{AllowedMethods: []string{"GET"}}),
+			cors.Handler(cors.Options{
+				AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
+				ExposedHeaders: []string{"Location"},
+			}),
 		)
-		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
-			_ = render.Render(w, r, ErrMethodNotAllowed(r.Method))
-		})
+		r.MethodNotAllowed(renderMethodNotAllowed)
 		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Put("/", h.ReplaceAll)
+		r.Put(idParam, h.Replace)
+		r.Delete(idParam, h.Delete)
 	})
 	return r
 }
@@ -31,6 +37,40 @@ func sizeRoutes(h *sizeHandler) chi.Router {
  * Request and Response payloads/models for the REST api.
  **********************************************************/
 
+// SizeWriteRequest is the request model used for writing entity.Size types. It is decoded from the JSON body of
+// POST and PUT requests. The title is required, whereas an omitted minPages or maxPages leaves that end of the
+// Size's range open.
+type SizeWriteRequest struct {
+	_ struct{}
+
+	Title    *string `json:"title"`
+	MinPages *int16  `json:"minPages"`
+	MaxPages *int16  `json:"maxPages"`
+}
+
+// SizeBulkRequest is an element of the JSON array in the body of a PUT request replacing every Size. If the id
+// is omitted, then a new Size is created.
+type SizeBulkRequest struct {
+	ID *int32 `json:"id"`
+
+	SizeWriteRequest
+}
+
+// writeInput maps the SizeWriteRequest to a size.WriteInput.
+func (req SizeWriteRequest) writeInput() size.WriteInput {
+	return size.WriteInput{Title: req.Title, MinPages: req.MinPages, MaxPages: req.MaxPages}
+}
+
+// decodeSizeWriteRequest decodes the JSON body of the http.Request into a SizeWriteRequest, and maps it to a
+// size.WriteInput. If the body is not valid JSON, then an error wrapping entity.ErrInvalidBody is returned.
+func decodeSizeWriteRequest(w http.ResponseWriter, r *http.Request) (size.WriteInput, error) {
+	var req SizeWriteRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		return size.WriteInput{}, err
+	}
+	return req.writeInput(), nil
+}
+
 // SizeResponse is the response struct sent back to the client.
 // Currently it embeds a pointer to entity.Size. In the future it would be
 // possible to separate the two models and perform mapping if necessary.
@@ -83,14 +123,112 @@ func NewSizeHandler(driver size.Driver, logger *zap.Logger) (*sizeHandler, error
 func (handler *sizeHandler) List(w http.ResponseWriter, r *http.Request) {
 	sizes, err := handler.driver.ListSizes(r.Context())
 	if err != nil {
-		handler.logger.Error(fmt.Sprintf("error listing sizes: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "listing sizes")
+		return
+	}
+
+	if err := render.RenderList(w, r, newSizeListResponse(sizes)); err != nil {
+		renderError(w, handler.logger, err, "rendering sizes")
+		return
+	}
+}
+
+// Create is an HTTP method that validates the SizeWriteRequest in the body and creates an entity.Size from it.
+// The created Size is rendered with a 201 status code and its URL in the Location header. If the Size is invalid,
+// or its range does not adjoin the ranges of the other Sizes, then a 422 status code is returned along with the
+// invalid fields.
+func (handler *sizeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	params, err := decodeSizeWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating size")
+		return
+	}
+
+	s, err := handler.driver.CreateSize(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "creating size")
+		return
+	}
+
+	w.Header().Set("Location", path.Join(r.URL.Path, strconv.Itoa(int(s.ID))))
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, newSizeResponse(s)); err != nil {
+		renderError(w, handler.logger, err, "rendering size")
+		return
+	}
+}
+
+// Replace is an HTTP method that validates the SizeWriteRequest in the body and replaces every attribute of the
+// entity.Size identified by the URL parameter with it. The updated Size is rendered. If the Size does not exist,
+// then a 404 status code is returned. If the Size is invalid, or its range does not adjoin the ranges of the
+// other Sizes, then a 422 status code is returned along with the invalid fields.
+func (handler *sizeHandler) Replace(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating size")
+		return
+	}
+
+	params, err := decodeSizeWriteRequest(w, r)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating size")
+		return
+	}
+
+	s, err := handler.driver.ReplaceSize(r.Context(), id, params)
+	if err != nil {
+		renderError(w, handler.logger, err, "updating size")
+		return
+	}
+
+	if err := render.Render(w, r, newSizeResponse(s)); err != nil {
+		renderError(w, handler.logger, err, "rendering size")
+		return
+	}
+}
+
+// ReplaceAll is an HTTP method that validates the array of SizeBulkRequests in the body and replaces every
+// entity.Size with them, which allows the boundaries between Sizes to be moved at once. The resulting Sizes are
+// rendered. If any Size is invalid, or the ranges do not adjoin, then a 422 status code is returned along with
+// the invalid fields, which are prefixed by their index in the array.
+func (handler *sizeHandler) ReplaceAll(w http.ResponseWriter, r *http.Request) {
+	var req []SizeBulkRequest
+	if err := decodeBody(w, r, &req); err != nil {
+		renderError(w, handler.logger, err, "replacing sizes")
+		return
+	}
+
+	params := make([]size.BulkInput, len(req))
+	for i, s := range req {
+		params[i] = size.BulkInput{ID: s.ID, WriteInput: s.writeInput()}
+	}
+
+	sizes, err := handler.driver.ReplaceAllSizes(r.Context(), params)
+	if err != nil {
+		renderError(w, handler.logger, err, "replacing sizes")
 		return
 	}
 
 	if err := render.RenderList(w, r, newSizeListResponse(sizes)); err != nil {
-		handler.logger.Error(fmt.Sprintf("error rendering sizes: %s", err))
-		_ = render.Render(w, r, ErrInternalServer(err))
+		renderError(w, handler.logger, err, "rendering sizes")
 		return
 	}
 }
+
+// Delete is an HTTP method that deletes the entity.Size identified by the URL parameter. A 204 status code is
+// returned on success. If the Size does not exist, then a 404 status code is returned. If deleting the Size would
+// leave a gap between the ranges of the remaining Sizes, then a 409 status code is returned.
+func (handler *sizeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := resourceID(r)
+	if err != nil {
+		renderError(w, handler.logger, err, "deleting size")
+		return
+	}
+
+	if err := handler.driver.DeleteSize(r.Context(), id); err != nil {
+		renderError(w, handler.logger, err, "deleting size")
+		return
+	}
+
+	render.NoContent(w, r)
+}
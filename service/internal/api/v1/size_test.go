@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi/openapitest"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/driver/size/sizetest"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
 	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -52,6 +54,7 @@ func TestSizeHandler_List(t *testing.T) {
 		expectedHandler string
 		expectedBody    string
 		expectedCode    int
+		expectedAllow   string
 		expectedErr     error
 		sendRequest     func(string) (*http.Response, error)
 	}{
@@ -72,18 +75,23 @@ func TestSizeHandler_List(t *testing.T) {
 		"invalid http method": {
 			expectedHandler: "ListSizes",
 			target:          "/",
-			expectedBody:    `{"message":"HTTP method POST is not allowed"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Method Not Allowed","status":405,"code":"method_not_allowed","detail":"HTTP method PATCH is not allowed"}`,
+			expectedCode:    405,
+			expectedAllow:   "GET, POST, PUT",
 			sendRequest: func(url string) (*http.Response, error) {
-				return http.Post(url, "application/json", nil)
+				req, err := http.NewRequest(http.MethodPatch, url, nil)
+				if err != nil {
+					return nil, err
+				}
+				return http.DefaultClient.Do(req)
 			},
 		},
 		"driver returns error": {
 			expectedHandler: "ListSizes",
 			target:          "/",
 			driverReturn:    []entity.Size{},
-			expectedBody:    `{"message":"Internal Server Error"}`,
-			expectedCode:    400,
+			expectedBody:    `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal","detail":"Internal Server Error"}`,
+			expectedCode:    500,
 			expectedErr:     errors.New("mock error returned from driver"),
 			sendRequest: func(url string) (*http.Response, error) {
 				return http.Get(url)
@@ -98,8 +106,7 @@ func TestSizeHandler_List(t *testing.T) {
 
 			r := sizeRoutes(&handler)
 
-			server := httptest.NewServer(r)
-			defer server.Close()
+			server := openapitest.NewServer(t, "/sizes", r)
 
 			driverMock.
 				On(tt.expectedHandler, mock.MatchedBy(func(_ context.Context) bool { return true })).
@@ -111,7 +118,109 @@ func TestSizeHandler_List(t *testing.T) {
 			body, err := ioutil.ReadAll(resp.Body)
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			assert.Equal(t, tt.expectedAllow, resp.Header.Get("Allow"))
 			assert.Equal(t, tt.expectedBody+"\n", string(body))
 		})
 	}
 }
+
+func TestSizeHandler_Write(t *testing.T) {
+	var (
+		anyContext  = mock.MatchedBy(func(_ context.Context) bool { return true })
+		novella     = entity.Size{ID: 3, Title: "Novella", MinPages: util.Int16Ptr(85), MaxPages: util.Int16Ptr(199)}
+		novellaJson = `{"id":3,"title":"Novella","minPages":85,"maxPages":199}`
+		novellaBody = `{"title":"Novella","minPages":85,"maxPages":199}`
+		novellaArgs = size.WriteInput{Title: util.StringPtr("Novella"), MinPages: util.Int16Ptr(85), MaxPages: util.Int16Ptr(199)}
+	)
+
+	tests := map[string]struct {
+		method          string
+		target          string
+		body            string
+		expectedHandler string
+		expectedArgs    []interface{}
+		driverReturn    []interface{}
+		expectedBody    string
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		"create size": {
+			method:          http.MethodPost,
+			target:          "/",
+			body:            novellaBody,
+			expectedHandler: "CreateSize",
+			expectedArgs:    []interface{}{anyContext, novellaArgs},
+			driverReturn:    []interface{}{novella, nil},
+			expectedBody:    novellaJson,
+			expectedCode:    201,
+			expectedHeaders: map[string]string{"Location": "/3"},
+		},
+		"replace size": {
+			method:          http.MethodPut,
+			target:          "/3",
+			body:            novellaBody,
+			expectedHandler: "ReplaceSize",
+			expectedArgs:    []interface{}{anyContext, int32(3), novellaArgs},
+			driverReturn:    []interface{}{novella, nil},
+			expectedBody:    novellaJson,
+			expectedCode:    200,
+		},
+		"replace all sizes with overlap": {
+			method:          http.MethodPut,
+			target:          "/",
+			body:            `[{"title":"Novella","minPages":85,"maxPages":199},{"title":"Novel","minPages":150}]`,
+			expectedHandler: "ReplaceAllSizes",
+			expectedArgs: []interface{}{anyContext, []size.BulkInput{
+				{WriteInput: novellaArgs},
+				{WriteInput: size.WriteInput{Title: util.StringPtr("Novel"), MinPages: util.Int16Ptr(150)}},
+			}},
+			driverReturn: []interface{}{[]entity.Size(nil), &entity.ValidationError{Fields: []entity.FieldError{
+				{Field: "[1].minPages", Code: validation.CodeConflict, Message: `should be 200 to follow size "Novella"`},
+			}}},
+			expectedBody: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"code":"invalid_entity","detail":"invalid entity provided","fields":[{"field":"[1].minPages","code":"conflict","message":"should be 200 to follow size \"Novella\""}]}`,
+			expectedCode: 422,
+		},
+		"delete missing size": {
+			method:          http.MethodDelete,
+			target:          "/9",
+			expectedHandler: "DeleteSize",
+			expectedArgs:    []interface{}{anyContext, int32(9)},
+			driverReturn:    []interface{}{fmt.Errorf("%w: size 9 does not exist", entity.ErrNotFound)},
+			expectedBody:    `{"type":"about:blank","title":"Not Found","status":404,"code":"not_found","detail":"resource not found: size 9 does not exist"}`,
+			expectedCode:    404,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driverMock := sizetest.DriverMock{}
+			handler := sizeHandler{driver: &driverMock, logger: zap.NewNop()}
+
+			server := openapitest.NewServer(t, "/sizes", sizeRoutes(&handler))
+
+			if tt.expectedHandler != "" {
+				driverMock.On(tt.expectedHandler, tt.expectedArgs...).Return(tt.driverReturn...)
+			}
+
+			req, err := http.NewRequest(tt.method, fmt.Sprintf("%s%s", server.URL, tt.target), strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			if tt.expectedBody != "" {
+				tt.expectedBody += "\n"
+			}
+			assert.Equal(t, tt.expectedBody, string(body))
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+			for k, v := range tt.expectedHeaders {
+				assert.Equal(t, v, resp.Header.Get(k))
+			}
+			driverMock.AssertExpectations(t)
+		})
+	}
+}
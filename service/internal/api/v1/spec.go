@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/LeviMatus/readcommend/service/internal/api/openapi"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// The modes of Options.SpecValidation, which validates the requests and responses of the API against its OpenAPI
+// spec, so that drift between the two is caught while developing the API.
+const (
+	// SpecValidationOff does not validate requests or responses. It is the mode of an empty SpecValidation.
+	SpecValidationOff = "off"
+
+	// SpecValidationLog logs the requests and responses which violate the spec, and serves them regardless.
+	SpecValidationLog = "log"
+
+	// SpecValidationReject rejects requests which violate the spec with a 400, as their handlers would, and
+	// replaces responses which violate it with a 500. Responses are held back until they have been validated.
+	SpecValidationReject = "reject"
+)
+
+// maxSpecBodyBytes is the largest response body which is validated against the spec. Larger bodies, such as those
+// of big exports, are served without being validated, rather than held in memory.
+const maxSpecBodyBytes = 1 << 20
+
+// specValidation returns middleware which validates the requests served by the next handler, and their
+// responses, against the OpenAPI spec in the mode, which is one of the SpecValidation modes.
+func specValidation(mode string, logger *zap.Logger) (func(http.Handler) http.Handler, error) {
+	switch mode {
+	case SpecValidationOff, "":
+		return func(next http.Handler) http.Handler { return next }, nil
+	case SpecValidationLog, SpecValidationReject:
+	default:
+		return nil, fmt.Errorf("spec validation is %q but should be %q, %q or %q",
+			mode, SpecValidationOff, SpecValidationLog, SpecValidationReject)
+	}
+
+	validator, err := openapi.New()
+	if err != nil {
+		return nil, err
+	}
+	reject := mode == SpecValidationReject
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Routes which the spec does not document are served as they are, and their responses are checked
+			// once they are known, since they are violations only if the API actually served them.
+			err := validator.ValidateRequest(r)
+			undocumented := errors.Is(err, openapi.ErrUndocumented)
+			if err != nil && !undocumented {
+				logger.Warn(fmt.Sprintf("request %s %s violates the OpenAPI spec: %s", r.Method, r.URL.Path, err))
+				if reject {
+					renderError(w, logger, err, "validating request against the OpenAPI spec")
+					return
+				}
+			}
+
+			rec := &specRecorder{ResponseWriter: w, hold: reject, status: http.StatusOK}
+			defer func() {
+				// A handler which aborts, such as an export which fails partway, has begun a response which it
+				// cannot finish. What it wrote is released before the panic is passed on, so that the response is
+				// cut short where the handler stopped rather than dropped. Other panics release nothing, so that
+				// a 500 can still be written in place of the response.
+				if rvr := recover(); rvr != nil {
+					if rvr == http.ErrAbortHandler {
+						rec.release()
+					}
+					panic(rvr)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			if rec.overflowed {
+				return
+			}
+
+			switch {
+			case undocumented && (rec.status == http.StatusNotFound || rec.status == http.StatusMethodNotAllowed):
+				err = nil
+			case undocumented:
+				err = fmt.Errorf("served with %d: %w", rec.status, err)
+			default:
+				err = validator.ValidateResponse(r, rec.status, w.Header(), rec.body.Bytes())
+			}
+			if err != nil {
+				logger.Error(fmt.Sprintf("response to %s %s violates the OpenAPI spec: %s", r.Method, r.URL.Path, err))
+				if reject {
+					RenderProblem(w, NewProblem(err))
+					return
+				}
+			}
+			rec.release()
+		})
+	}, nil
+}
+
+// specRecorder records the status and body of a response so that they can be validated against the spec. While
+// it holds the response, nothing is written until it is released, so that a violation can be replaced. Otherwise,
+// the response is written as it is recorded. Bodies larger than maxSpecBodyBytes are no longer recorded, and any
+// response which was held is written, since they are not validated.
+type specRecorder struct {
+	http.ResponseWriter
+	hold       bool
+	status     int
+	body       bytes.Buffer
+	overflowed bool
+}
+
+func (rec *specRecorder) WriteHeader(status int) {
+	rec.status = status
+	if !rec.hold {
+		rec.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (rec *specRecorder) Write(b []byte) (int, error) {
+	if rec.overflowed {
+		return rec.ResponseWriter.Write(b)
+	}
+	if rec.body.Len()+len(b) > maxSpecBodyBytes {
+		rec.overflowed = true
+		if rec.hold {
+			rec.ResponseWriter.WriteHeader(rec.status)
+			if _, err := rec.ResponseWriter.Write(rec.body.Bytes()); err != nil {
+				return 0, err
+			}
+		}
+		rec.body.Reset()
+		return rec.ResponseWriter.Write(b)
+	}
+
+	rec.body.Write(b)
+	if rec.hold {
+		return len(b), nil
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// release writes the response which was held, if any. A response which overflowed has already been written.
+func (rec *specRecorder) release() {
+	if !rec.hold || rec.overflowed {
+		return
+	}
+	rec.ResponseWriter.WriteHeader(rec.status)
+	_, _ = rec.ResponseWriter.Write(rec.body.Bytes())
+}
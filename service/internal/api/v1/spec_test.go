@@ -0,0 +1,213 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSpecValidation(t *testing.T) {
+	const (
+		eras        = `[{"id":1,"title":"Classic","maxYear":1969}]`
+		invalidEras = `[{"id":"1","title":"Classic"}]`
+	)
+
+	tests := map[string]struct {
+		mode           string
+		target         string
+		status         int
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectServed   bool
+		expectedLogs   map[zapcore.Level]int
+	}{
+		"off validates nothing": {
+			mode:           SpecValidationOff,
+			target:         "/api/v1/eras?limit=0",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           invalidEras,
+			expectedStatus: http.StatusOK,
+			expectedBody:   invalidEras,
+			expectServed:   true,
+		},
+		"conforming request and response": {
+			mode:           SpecValidationReject,
+			target:         "/api/v1/eras",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           eras,
+			expectedStatus: http.StatusOK,
+			expectedBody:   eras,
+			expectServed:   true,
+		},
+		"log serves an invalid request": {
+			mode:           SpecValidationLog,
+			target:         "/api/v1/books?min-pages=0",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           `[]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+			expectServed:   true,
+			expectedLogs:   map[zapcore.Level]int{zapcore.WarnLevel: 1},
+		},
+		"reject rejects an invalid request": {
+			mode:           SpecValidationReject,
+			target:         "/api/v1/books?min-pages=0",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `{"type":"about:blank","title":"Bad Request","status":400,"code":"invalid_query_param",` +
+				`"detail":"invalid URL query parameter provided","fields":[{"field":"min-pages","code":"invalid",` +
+				`"message":"number must be at least 1"}]}` + "\n",
+			expectedLogs: map[zapcore.Level]int{zapcore.WarnLevel: 1},
+		},
+		"log serves an invalid response": {
+			mode:           SpecValidationLog,
+			target:         "/api/v1/eras",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           invalidEras,
+			expectedStatus: http.StatusOK,
+			expectedBody:   invalidEras,
+			expectServed:   true,
+			expectedLogs:   map[zapcore.Level]int{zapcore.ErrorLevel: 1},
+		},
+		"reject replaces an invalid response": {
+			mode:           SpecValidationReject,
+			target:         "/api/v1/eras",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           invalidEras,
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal",` +
+				`"detail":"Internal Server Error"}` + "\n",
+			expectServed: true,
+			expectedLogs: map[zapcore.Level]int{zapcore.ErrorLevel: 1},
+		},
+		"reject replaces an undocumented media type": {
+			mode:           SpecValidationReject,
+			target:         "/api/v1/eras",
+			status:         http.StatusOK,
+			contentType:    "text/html",
+			body:           "<p>Classic</p>",
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"code":"internal",` +
+				`"detail":"Internal Server Error"}` + "\n",
+			expectServed: true,
+			expectedLogs: map[zapcore.Level]int{zapcore.ErrorLevel: 1},
+		},
+		"undocumented route which is not found": {
+			mode:           SpecValidationReject,
+			target:         "/api/v1/publishers",
+			status:         http.StatusNotFound,
+			contentType:    problemContentType,
+			body:           `{}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{}`,
+			expectServed:   true,
+		},
+		"undocumented route which is served": {
+			mode:           SpecValidationLog,
+			target:         "/api/v1/publishers",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           `[]`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+			expectServed:   true,
+			expectedLogs:   map[zapcore.Level]int{zapcore.ErrorLevel: 1},
+		},
+		"response too large to validate": {
+			mode:           SpecValidationReject,
+			target:         "/api/v1/eras",
+			status:         http.StatusOK,
+			contentType:    "application/json",
+			body:           strings.Repeat(" ", maxSpecBodyBytes+1),
+			expectedStatus: http.StatusOK,
+			expectedBody:   strings.Repeat(" ", maxSpecBodyBytes+1),
+			expectServed:   true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.DebugLevel)
+			validate, err := specValidation(tt.mode, zap.New(core))
+			require.NoError(t, err)
+
+			served := false
+			h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.expectServed, served)
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+
+			levels := map[zapcore.Level]int{}
+			for _, entry := range logs.All() {
+				levels[entry.Level]++
+			}
+			if tt.expectedLogs == nil {
+				assert.Empty(t, levels)
+			} else {
+				assert.Equal(t, tt.expectedLogs, levels)
+			}
+		})
+	}
+}
+
+func TestSpecValidation_Panic(t *testing.T) {
+	tests := map[string]struct {
+		panic        interface{}
+		expectedBody string
+	}{
+		"aborted response is released": {
+			panic:        http.ErrAbortHandler,
+			expectedBody: "id,title\n1,The Hobbit\n",
+		},
+		"other panics release nothing": {
+			panic: "mock panic from handler",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			validate, err := specValidation(SpecValidationReject, zap.NewNop())
+			require.NoError(t, err)
+
+			h := validate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+				_, _ = w.Write([]byte("id,title\n1,The Hobbit\n"))
+				panic(tt.panic)
+			}))
+
+			// The panic is passed on, so that the connection is closed before the response is complete.
+			w := httptest.NewRecorder()
+			assert.PanicsWithValue(t, tt.panic, func() {
+				h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/books/export", nil))
+			})
+			assert.Equal(t, tt.expectedBody, w.Body.String())
+		})
+	}
+}
+
+func TestSpecValidation_InvalidMode(t *testing.T) {
+	_, err := specValidation("warn", zap.NewNop())
+	assert.EqualError(t, err, `spec validation is "warn" but should be "off", "log" or "reject"`)
+}
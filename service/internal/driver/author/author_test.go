@@ -2,15 +2,21 @@ package author_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
 type inMemoryRepository struct {
-	resource map[int32]entity.Author
+	resource   map[int32]entity.Author
+	stats      map[int32]author.Stats
+	books      map[int32][]entity.Book
+	reassigned map[int32]int32
 }
 
 func (r *inMemoryRepository) List(_ context.Context) ([]entity.Author, error) {
@@ -21,6 +27,59 @@ func (r *inMemoryRepository) List(_ context.Context) ([]entity.Author, error) {
 	return data, nil
 }
 
+func (r *inMemoryRepository) Get(_ context.Context, id int32) (entity.Author, error) {
+	a, ok := r.resource[id]
+	if !ok {
+		return entity.Author{}, fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	return a, nil
+}
+
+func (r *inMemoryRepository) Stats(_ context.Context, id int32) (author.Stats, error) {
+	return r.stats[id], nil
+}
+
+func (r *inMemoryRepository) TopBooks(_ context.Context, id int32, limit uint64) ([]entity.Book, error) {
+	books := r.books[id]
+	if uint64(len(books)) > limit {
+		books = books[:limit]
+	}
+	return books, nil
+}
+
+func (r *inMemoryRepository) Create(_ context.Context, params author.WriteInput) (int32, error) {
+	var id int32
+	for existing := range r.resource {
+		if existing > id {
+			id = existing
+		}
+	}
+	id++
+	r.resource[id] = entity.Author{ID: id, FirstName: *params.FirstName, LastName: *params.LastName}
+	return id, nil
+}
+
+func (r *inMemoryRepository) Update(_ context.Context, id int32, params author.WriteInput) error {
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	r.resource[id] = entity.Author{ID: id, FirstName: *params.FirstName, LastName: *params.LastName}
+	return nil
+}
+
+func (r *inMemoryRepository) Delete(_ context.Context, id int32, reassignTo *int32) error {
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	if reassignTo != nil {
+		r.reassigned[id] = *reassignTo
+	} else if r.stats[id].BookCount > 0 {
+		return fmt.Errorf("%w: author %d is still referenced", entity.ErrConflict, id)
+	}
+	delete(r.resource, id)
+	return nil
+}
+
 func TestDriver_List(t *testing.T) {
 
 	a := entity.Author{ID: 1, FirstName: "John", LastName: "Tolkien"}
@@ -33,3 +92,114 @@ func TestDriver_List(t *testing.T) {
 	assert.Len(t, res, 1)
 	assert.Contains(t, res, a)
 }
+
+func TestDriver_Get(t *testing.T) {
+
+	a := entity.Author{ID: 1, FirstName: "John", LastName: "Tolkien"}
+	stats := author.Stats{
+		BookCount:          2,
+		AverageRating:      util.Float64Ptr(4.2),
+		FirstYearPublished: util.Int16Ptr(1937),
+		LastYearPublished:  util.Int16Ptr(1977),
+	}
+	books := []entity.Book{{ID: 2, Title: "The Hobbit", Rating: 4.5}, {ID: 1, Title: "The Silmarillion", Rating: 3.9}}
+
+	repo := inMemoryRepository{
+		resource: map[int32]entity.Author{1: a},
+		stats:    map[int32]author.Stats{1: stats},
+		books:    map[int32][]entity.Book{1: books},
+	}
+
+	driver := author.NewDriver(&repo)
+
+	res, err := driver.GetAuthor(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, author.Detail{Author: a, Stats: stats, TopBooks: books}, res)
+
+	_, err = driver.GetAuthor(context.Background(), 2)
+	assert.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestDriver_Write(t *testing.T) {
+
+	tolkien := entity.Author{ID: 1, FirstName: "John", LastName: "Tolkien"}
+	lewis := entity.Author{ID: 2, FirstName: "Clive", LastName: "Lewis"}
+
+	newRepo := func() *inMemoryRepository {
+		return &inMemoryRepository{
+			resource:   map[int32]entity.Author{1: tolkien, 2: lewis},
+			stats:      map[int32]author.Stats{1: {BookCount: 2}},
+			reassigned: map[int32]int32{},
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		repo := newRepo()
+		res, err := author.NewDriver(repo).CreateAuthor(context.Background(),
+			author.WriteInput{FirstName: util.StringPtr("Ursula"), LastName: util.StringPtr("Le Guin")})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Author{ID: 3, FirstName: "Ursula", LastName: "Le Guin"}, res)
+		assert.Equal(t, res, repo.resource[3])
+	})
+
+	t.Run("create invalid", func(t *testing.T) {
+		blank := " "
+		_, err := author.NewDriver(newRepo()).CreateAuthor(context.Background(), author.WriteInput{LastName: &blank})
+		var validationErr *entity.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []entity.FieldError{
+			{Field: "firstName", Code: validation.CodeRequired, Message: "is required"},
+			{Field: "lastName", Code: validation.CodeBlank, Message: "must not be blank"},
+		}, validationErr.Fields)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		res, err := author.NewDriver(newRepo()).ReplaceAuthor(context.Background(), 2,
+			author.WriteInput{FirstName: util.StringPtr("C. S."), LastName: util.StringPtr("Lewis")})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Author{ID: 2, FirstName: "C. S.", LastName: "Lewis"}, res)
+	})
+
+	t.Run("replace missing", func(t *testing.T) {
+		_, err := author.NewDriver(newRepo()).ReplaceAuthor(context.Background(), 9,
+			author.WriteInput{FirstName: util.StringPtr("C. S."), LastName: util.StringPtr("Lewis")})
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo()
+		assert.NoError(t, author.NewDriver(repo).DeleteAuthor(context.Background(), 2, nil))
+		assert.NotContains(t, repo.resource, int32(2))
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		err := author.NewDriver(newRepo()).DeleteAuthor(context.Background(), 9, nil)
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete referenced", func(t *testing.T) {
+		repo := newRepo()
+		err := author.NewDriver(repo).DeleteAuthor(context.Background(), 1, nil)
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.EqualError(t, err, "conflict with the current state of the resources: "+
+			"author 1 still has 2 books, which must be reassigned to another author")
+		assert.Contains(t, repo.resource, int32(1))
+	})
+
+	t.Run("delete referenced with reassignment", func(t *testing.T) {
+		repo := newRepo()
+		assert.NoError(t, author.NewDriver(repo).DeleteAuthor(context.Background(), 1, util.Int32Ptr(2)))
+		assert.NotContains(t, repo.resource, int32(1))
+		assert.Equal(t, map[int32]int32{1: 2}, repo.reassigned)
+	})
+
+	t.Run("reassignment to itself", func(t *testing.T) {
+		err := author.NewDriver(newRepo()).DeleteAuthor(context.Background(), 1, util.Int32Ptr(1))
+		assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+	})
+
+	t.Run("reassignment to missing author", func(t *testing.T) {
+		err := author.NewDriver(newRepo()).DeleteAuthor(context.Background(), 1, util.Int32Ptr(9))
+		assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+	})
+}
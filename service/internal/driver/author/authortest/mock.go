@@ -3,6 +3,7 @@ package authortest
 import (
 	"context"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,3 +18,27 @@ func (d *DriverMock) ListAuthors(ctx context.Context) ([]entity.Author, error) {
 	args := d.Called(ctx)
 	return args.Get(0).([]entity.Author), args.Error(1)
 }
+
+// GetAuthor is a mock routine that returns items as instructed.
+func (d *DriverMock) GetAuthor(ctx context.Context, id int32) (author.Detail, error) {
+	args := d.Called(ctx, id)
+	return args.Get(0).(author.Detail), args.Error(1)
+}
+
+// CreateAuthor is a mock routine that returns items as instructed.
+func (d *DriverMock) CreateAuthor(ctx context.Context, params author.WriteInput) (entity.Author, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).(entity.Author), args.Error(1)
+}
+
+// ReplaceAuthor is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceAuthor(ctx context.Context, id int32, params author.WriteInput) (entity.Author, error) {
+	args := d.Called(ctx, id, params)
+	return args.Get(0).(entity.Author), args.Error(1)
+}
+
+// DeleteAuthor is a mock routine that returns items as instructed.
+func (d *DriverMock) DeleteAuthor(ctx context.Context, id int32, reassignTo *int32) error {
+	args := d.Called(ctx, id, reassignTo)
+	return args.Error(0)
+}
@@ -6,12 +6,42 @@ import (
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 )
 
+// TopBooksLimit is the number of top-rated Books included in a Detail.
+const TopBooksLimit uint64 = 5
+
+// Stats are aggregates over the Books written by an Author.
+type Stats struct {
+	// BookCount is the number of Books written by the Author.
+	BookCount int64
+
+	// AverageRating is the average rating of the Author's Books. It is nil if the Author has no Books.
+	AverageRating *float64
+
+	// FirstYearPublished is the year the Author's earliest Book was published. It is nil if the Author
+	// has no Books.
+	FirstYearPublished *int16
+
+	// LastYearPublished is the year the Author's latest Book was published. It is nil if the Author
+	// has no Books.
+	LastYearPublished *int16
+}
+
+// Detail is a single Author along with aggregates over their Books.
+type Detail struct {
+	Author entity.Author
+
+	Stats Stats
+
+	// TopBooks holds up to TopBooksLimit of the Author's Books, from the best to the worst rated.
+	TopBooks []entity.Book
+}
+
 type driver struct {
 	repository Repository
 }
 
 // NewDriver creates a driver which wraps the repository. The wrapper
-// will perform business logic against the usecases of Size entity.
+// will perform business logic against the usecases of Author entity.
 func NewDriver(r Repository) *driver {
 	return &driver{repository: r}
 }
@@ -20,3 +50,25 @@ func NewDriver(r Repository) *driver {
 func (d *driver) ListAuthors(ctx context.Context) ([]entity.Author, error) {
 	return d.repository.List(ctx)
 }
+
+// GetAuthor fetches the entity.Author with the provided ID from the repository, along with the Stats and
+// top-rated Books of the Author. If the Author does not exist, then an error wrapping entity.ErrNotFound
+// is returned.
+func (d *driver) GetAuthor(ctx context.Context, id int32) (Detail, error) {
+	a, err := d.repository.Get(ctx, id)
+	if err != nil {
+		return Detail{}, err
+	}
+
+	stats, err := d.repository.Stats(ctx, id)
+	if err != nil {
+		return Detail{}, err
+	}
+
+	books, err := d.repository.TopBooks(ctx, id, TopBooksLimit)
+	if err != nil {
+		return Detail{}, err
+	}
+
+	return Detail{Author: a, Stats: stats, TopBooks: books}, nil
+}
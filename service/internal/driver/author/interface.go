@@ -10,10 +10,48 @@ import (
 type Repository interface {
 	// List should return all Authors if there are no errors.
 	List(ctx context.Context) ([]entity.Author, error)
+
+	// Get should return the Author with the provided ID. If it does not exist, then an error wrapping
+	// entity.ErrNotFound should be returned.
+	Get(ctx context.Context, id int32) (entity.Author, error)
+
+	// Stats should aggregate the Books written by the Author with the provided ID.
+	Stats(ctx context.Context, id int32) (Stats, error)
+
+	// TopBooks should return up to limit of the Books written by the Author with the provided ID, from the
+	// best to the worst rated. Books with equal ratings should be ordered by ascending ID.
+	TopBooks(ctx context.Context, id int32, limit uint64) ([]entity.Book, error)
+
+	// Create should create an Author from the WriteInput and return its newly assigned ID.
+	Create(ctx context.Context, params WriteInput) (int32, error)
+
+	// Update should replace the attributes of the Author with the provided ID. If it does not exist, then an
+	// error wrapping entity.ErrNotFound should be returned.
+	Update(ctx context.Context, id int32, params WriteInput) error
+
+	// Delete should delete the Author with the provided ID. If reassignTo is not nil, then the Books of the
+	// Author should first be reassigned to the Author with that ID, all at once. If the Author does not exist,
+	// then an error wrapping entity.ErrNotFound should be returned. If Books still reference the Author, then
+	// an error wrapping entity.ErrConflict should be returned.
+	Delete(ctx context.Context, id int32, reassignTo *int32) error
 }
 
 // Driver is an interface described the contract required to satisfy business usecases.
 type Driver interface {
 	// ListAuthors should fetch all Authors and performs intermediary business logic, if any.
 	ListAuthors(ctx context.Context) ([]entity.Author, error)
+
+	// GetAuthor should fetch a single Author by its ID, along with the Stats and top-rated Books
+	// of the Author, and perform intermediary business logic, if any.
+	GetAuthor(ctx context.Context, id int32) (Detail, error)
+
+	// CreateAuthor should validate the WriteInput and create an Author from it.
+	CreateAuthor(ctx context.Context, params WriteInput) (entity.Author, error)
+
+	// ReplaceAuthor should validate the WriteInput and replace the attributes of an existing Author with it.
+	ReplaceAuthor(ctx context.Context, id int32, params WriteInput) (entity.Author, error)
+
+	// DeleteAuthor should delete an existing Author, unless Books still reference it and they are not
+	// reassigned to another Author.
+	DeleteAuthor(ctx context.Context, id int32, reassignTo *int32) error
 }
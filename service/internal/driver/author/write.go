@@ -0,0 +1,95 @@
+package author
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/pkg/errors"
+)
+
+// WriteInput is an input parameter for CreateAuthor and ReplaceAuthor. It holds the attributes of an Author to
+// be written, which are all required.
+type WriteInput struct {
+	_ struct{}
+
+	FirstName *string
+	LastName  *string
+}
+
+// CreateAuthor validates the WriteInput and creates an Author from it. The repository assigns the Author's ID.
+// The created entity.Author is returned. If the WriteInput is invalid, then an *entity.ValidationError is
+// returned.
+func (d *driver) CreateAuthor(ctx context.Context, params WriteInput) (entity.Author, error) {
+	if err := validate(params); err != nil {
+		return entity.Author{}, err
+	}
+
+	id, err := d.repository.Create(ctx, params)
+	if err != nil {
+		return entity.Author{}, err
+	}
+
+	return d.repository.Get(ctx, id)
+}
+
+// ReplaceAuthor validates the WriteInput and replaces every attribute of the Author with the provided ID with
+// it. The updated entity.Author is returned. If the Author does not exist, then an error wrapping
+// entity.ErrNotFound is returned. If the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) ReplaceAuthor(ctx context.Context, id int32, params WriteInput) (entity.Author, error) {
+	if err := validate(params); err != nil {
+		return entity.Author{}, err
+	}
+
+	if err := d.repository.Update(ctx, id, params); err != nil {
+		return entity.Author{}, err
+	}
+
+	return d.repository.Get(ctx, id)
+}
+
+// DeleteAuthor deletes the Author with the provided ID. If the Author does not exist, then an error wrapping
+// entity.ErrNotFound is returned. If Books still reference the Author, then they are reassigned to the Author
+// identified by reassignTo. If reassignTo is nil, then an error wrapping entity.ErrConflict is returned instead.
+// If reassignTo does not identify another existing Author, then an error wrapping entity.ErrInvalidQueryParam
+// is returned.
+func (d *driver) DeleteAuthor(ctx context.Context, id int32, reassignTo *int32) error {
+	if _, err := d.repository.Get(ctx, id); err != nil {
+		return err
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign the books of author %d to itself", entity.ErrInvalidQueryParam, id)
+		}
+		if _, err := d.repository.Get(ctx, *reassignTo); errors.Is(err, entity.ErrNotFound) {
+			return fmt.Errorf("%w: author %d does not exist", entity.ErrInvalidQueryParam, *reassignTo)
+		} else if err != nil {
+			return err
+		}
+		return d.repository.Delete(ctx, id, reassignTo)
+	}
+
+	stats, err := d.repository.Stats(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stats.BookCount > 0 {
+		return fmt.Errorf("%w: author %d still has %d books, which must be reassigned to another author",
+			entity.ErrConflict, id, stats.BookCount)
+	}
+
+	return d.repository.Delete(ctx, id, nil)
+}
+
+// validate checks every attribute of the WriteInput, which are all required. If any attribute is invalid, then
+// an *entity.ValidationError listing all of them is returned.
+func validate(params WriteInput) error {
+	v := validation.New(entity.ErrInvalidEntity)
+	v.Required("firstName", params.FirstName != nil)
+	v.NotBlank("firstName", params.FirstName)
+	v.Required("lastName", params.LastName != nil)
+	v.NotBlank("lastName", params.LastName)
+	return v.Err()
+}
@@ -2,10 +2,19 @@ package book_test
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"testing"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -13,14 +22,102 @@ type inMemoryRepository struct {
 	resource map[int32]entity.Book
 }
 
-func (r *inMemoryRepository) Search(_ context.Context, _ book.SearchInput) ([]entity.Book, error) {
+func (r *inMemoryRepository) Search(_ context.Context, params book.SearchInput) ([]entity.Book, error) {
 	var data []entity.Book
-	for _, a := range r.resource {
-		data = append(data, a)
+	for _, b := range r.resource {
+		if params.After != nil && (b.Rating > params.After.Rating ||
+			(b.Rating == params.After.Rating && b.ID <= params.After.ID)) {
+			continue
+		}
+		data = append(data, b)
+	}
+
+	sort.Slice(data, func(i, j int) bool {
+		if data[i].Rating != data[j].Rating {
+			return data[i].Rating > data[j].Rating
+		}
+		return data[i].ID < data[j].ID
+	})
+
+	if params.Limit != nil && uint64(len(data)) > *params.Limit {
+		data = data[:*params.Limit]
 	}
 	return data, nil
 }
 
+func (r *inMemoryRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) error {
+	books, err := r.Search(ctx, params)
+	if err != nil {
+		return err
+	}
+	for _, b := range books {
+		if err := each(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) Get(_ context.Context, id int32) (entity.Book, error) {
+	b, ok := r.resource[id]
+	if !ok {
+		return entity.Book{}, fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	return b, nil
+}
+
+func (r *inMemoryRepository) Create(_ context.Context, params book.WriteInput) (int32, error) {
+	var id int32
+	for existing := range r.resource {
+		if existing > id {
+			id = existing
+		}
+	}
+	id++
+
+	r.resource[id] = entity.Book{ID: id}
+	return id, r.Update(context.Background(), id, params)
+}
+
+func (r *inMemoryRepository) Update(_ context.Context, id int32, params book.WriteInput) error {
+	b, ok := r.resource[id]
+	if !ok {
+		return fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	if params.Title != nil {
+		b.Title = *params.Title
+	}
+	if params.YearPublished != nil {
+		b.YearPublished = *params.YearPublished
+	}
+	if params.Rating != nil {
+		b.Rating = *params.Rating
+	}
+	if params.Pages != nil {
+		b.Pages = *params.Pages
+	}
+	if params.AuthorID != nil {
+		b.Author = entity.Author{ID: *params.AuthorID}
+	}
+	if params.GenreID != nil {
+		b.Genre = entity.Genre{ID: *params.GenreID}
+	}
+	r.resource[id] = b
+	return nil
+}
+
+func (r *inMemoryRepository) Delete(_ context.Context, id int32) error {
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	delete(r.resource, id)
+	return nil
+}
+
+func (r *inMemoryRepository) Facets(_ context.Context, _ book.FacetInput) (book.Facets, error) {
+	return book.Facets{}, nil
+}
+
 func TestDriver_Search(t *testing.T) {
 
 	b := entity.Book{
@@ -42,9 +139,601 @@ func TestDriver_Search(t *testing.T) {
 
 	repo := inMemoryRepository{resource: map[int32]entity.Book{1: b}}
 
-	driver := book.NewDriver(&repo)
+	driver := book.NewDriver(&repo, nil, nil, nil, nil, book.Pagination{})
 	res, err := driver.SearchBooks(context.Background(), book.SearchInput{})
 	assert.NoError(t, err)
-	assert.Len(t, res, 1)
-	assert.Contains(t, res, b)
+	assert.Len(t, res.Books, 1)
+	assert.Contains(t, res.Books, b)
+	assert.False(t, res.HasMore)
+	assert.Nil(t, res.NextCursor)
+}
+
+func TestDriver_Get(t *testing.T) {
+	b := entity.Book{ID: 1, Title: "The Silmarillion", Rating: 3.9}
+
+	repo := inMemoryRepository{resource: map[int32]entity.Book{1: b}}
+	driver := book.NewDriver(&repo, nil, nil, nil, nil, book.Pagination{})
+
+	res, err := driver.GetBook(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, b, res)
+
+	_, err = driver.GetBook(context.Background(), 2)
+	assert.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+// authorRepository only implements Get of the author.Repository.
+type authorRepository struct {
+	author.Repository
+	ids map[int32]bool
+}
+
+func (r authorRepository) Get(_ context.Context, id int32) (entity.Author, error) {
+	if !r.ids[id] {
+		return entity.Author{}, fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	return entity.Author{ID: id}, nil
+}
+
+// genreRepository only implements Get of the genre.Repository.
+type genreRepository struct {
+	genre.Repository
+	ids map[int32]bool
+}
+
+func (r genreRepository) Get(_ context.Context, id int32) (entity.Genre, error) {
+	if !r.ids[id] {
+		return entity.Genre{}, fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	return entity.Genre{ID: id}, nil
+}
+
+func TestDriver_Write(t *testing.T) {
+	var (
+		authors = authorRepository{ids: map[int32]bool{1: true}}
+		genres  = genreRepository{ids: map[int32]bool{2: true}}
+		hobbit  = entity.Book{
+			ID:            1,
+			Title:         "The Hobbit",
+			YearPublished: 1937,
+			Rating:        4.3,
+			Pages:         310,
+			Author:        entity.Author{ID: 1},
+			Genre:         entity.Genre{ID: 2},
+		}
+		input = book.WriteInput{
+			Title:         util.StringPtr("The Silmarillion"),
+			YearPublished: util.Int16Ptr(1977),
+			Rating:        util.Float32Ptr(3.9),
+			Pages:         util.Int16Ptr(365),
+			AuthorID:      util.Int32Ptr(1),
+			GenreID:       util.Int32Ptr(2),
+		}
+		silmarillion = entity.Book{
+			Title:         "The Silmarillion",
+			YearPublished: 1977,
+			Rating:        3.9,
+			Pages:         365,
+			Author:        entity.Author{ID: 1},
+			Genre:         entity.Genre{ID: 2},
+		}
+	)
+
+	newDriver := func() (book.Driver, *inMemoryRepository) {
+		repo := &inMemoryRepository{resource: map[int32]entity.Book{1: hobbit}}
+		return book.NewDriver(repo, authors, genres, nil, nil, book.Pagination{}), repo
+	}
+
+	t.Run("create book", func(t *testing.T) {
+		driver, repo := newDriver()
+		res, err := driver.CreateBook(context.Background(), input)
+		assert.NoError(t, err)
+
+		expected := silmarillion
+		expected.ID = 2
+		assert.Equal(t, expected, res)
+		assert.Equal(t, expected, repo.resource[2])
+	})
+
+	t.Run("create invalid book", func(t *testing.T) {
+		driver, repo := newDriver()
+		_, err := driver.CreateBook(context.Background(), book.WriteInput{
+			Title:         util.StringPtr("  "),
+			YearPublished: util.Int16Ptr(1700),
+			Rating:        util.Float32Ptr(5.5),
+			Pages:         util.Int16Ptr(0),
+			AuthorID:      util.Int32Ptr(9),
+		})
+		assert.ErrorIs(t, err, entity.ErrInvalidEntity)
+
+		var validationErr *entity.ValidationError
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equal(t, []entity.FieldError{
+			{Field: "title", Code: validation.CodeBlank, Message: "must not be blank"},
+			{Field: "yearPublished", Code: validation.CodeOutOfRange, Message: "is 1700 but should be in range [1800,2100]"},
+			{Field: "rating", Code: validation.CodeOutOfRange, Message: "is 5.5 but should be in range [0,5]"},
+			{Field: "pages", Code: validation.CodeOutOfRange, Message: "is 0 but should be in range [1,10000]"},
+			{Field: "authorId", Code: validation.CodeNotFound, Message: "author 9 does not exist"},
+			{Field: "genreId", Code: validation.CodeRequired, Message: "is required"},
+		}, validationErr.Fields)
+		assert.Len(t, repo.resource, 1)
+	})
+
+	t.Run("replace book", func(t *testing.T) {
+		driver, repo := newDriver()
+		res, err := driver.ReplaceBook(context.Background(), 1, input)
+		assert.NoError(t, err)
+
+		expected := silmarillion
+		expected.ID = 1
+		assert.Equal(t, expected, res)
+		assert.Equal(t, expected, repo.resource[1])
+	})
+
+	t.Run("replace book requires every attribute", func(t *testing.T) {
+		driver, _ := newDriver()
+		_, err := driver.ReplaceBook(context.Background(), 1, book.WriteInput{Title: util.StringPtr("The Hobbit")})
+		assert.ErrorIs(t, err, entity.ErrInvalidEntity)
+	})
+
+	t.Run("replace missing book", func(t *testing.T) {
+		driver, _ := newDriver()
+		_, err := driver.ReplaceBook(context.Background(), 9, input)
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("update book", func(t *testing.T) {
+		driver, repo := newDriver()
+		res, err := driver.UpdateBook(context.Background(), 1, book.WriteInput{Rating: util.Float32Ptr(4.8)})
+		assert.NoError(t, err)
+
+		expected := hobbit
+		expected.Rating = 4.8
+		assert.Equal(t, expected, res)
+		assert.Equal(t, expected, repo.resource[1])
+	})
+
+	t.Run("update book with invalid attribute", func(t *testing.T) {
+		driver, repo := newDriver()
+		_, err := driver.UpdateBook(context.Background(), 1, book.WriteInput{GenreID: util.Int32Ptr(9)})
+		assert.EqualError(t, err, "invalid entity provided: genreId genre 9 does not exist")
+		assert.Equal(t, hobbit, repo.resource[1])
+	})
+
+	t.Run("update missing book", func(t *testing.T) {
+		driver, _ := newDriver()
+		_, err := driver.UpdateBook(context.Background(), 9, book.WriteInput{Rating: util.Float32Ptr(4.8)})
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete book", func(t *testing.T) {
+		driver, repo := newDriver()
+		assert.NoError(t, driver.DeleteBook(context.Background(), 1))
+		assert.Empty(t, repo.resource)
+		assert.ErrorIs(t, driver.DeleteBook(context.Background(), 1), entity.ErrNotFound)
+	})
+}
+
+func TestDriver_SearchPages(t *testing.T) {
+	repo := inMemoryRepository{resource: map[int32]entity.Book{
+		1: {ID: 1, Rating: 4.5},
+		2: {ID: 2, Rating: 3.9},
+		3: {ID: 3, Rating: 4.5},
+		4: {ID: 4, Rating: 3.9},
+		5: {ID: 5, Rating: 2.1},
+	}}
+
+	tests := map[string]struct {
+		pagination    book.Pagination
+		input         book.SearchInput
+		expectedIDs   []int32
+		expectHasMore assert.BoolAssertionFunc
+		expectCursor  *book.Cursor
+	}{
+		"first page using the default limit": {
+			pagination:    book.Pagination{DefaultLimit: 2},
+			expectedIDs:   []int32{1, 3},
+			expectHasMore: assert.True,
+			expectCursor:  &book.Cursor{Sort: "-rating,id", Rating: 4.5, ID: 3},
+		},
+		"second page breaks rating ties by id": {
+			pagination:    book.Pagination{DefaultLimit: 2},
+			input:         book.SearchInput{After: &book.Cursor{Sort: "-rating,id", Rating: 4.5, ID: 3}},
+			expectedIDs:   []int32{2, 4},
+			expectHasMore: assert.True,
+			expectCursor:  &book.Cursor{Sort: "-rating,id", Rating: 3.9, ID: 4},
+		},
+		"last page": {
+			pagination:    book.Pagination{DefaultLimit: 2},
+			input:         book.SearchInput{After: &book.Cursor{Sort: "-rating,id", Rating: 3.9, ID: 4}},
+			expectedIDs:   []int32{5},
+			expectHasMore: assert.False,
+		},
+		"requested limit is capped by the maximum": {
+			pagination:    book.Pagination{DefaultLimit: 1, MaxLimit: 3},
+			input:         book.SearchInput{Limit: util.Uint64Ptr(50)},
+			expectedIDs:   []int32{1, 3, 2},
+			expectHasMore: assert.True,
+			expectCursor:  &book.Cursor{Sort: "-rating,id", Rating: 3.9, ID: 2},
+		},
+		"requested limit fits exactly": {
+			input:         book.SearchInput{Limit: util.Uint64Ptr(5)},
+			expectedIDs:   []int32{1, 3, 2, 4, 5},
+			expectHasMore: assert.False,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			driver := book.NewDriver(&repo, nil, nil, nil, nil, tt.pagination)
+			res, err := driver.SearchBooks(context.Background(), tt.input)
+			assert.NoError(t, err)
+
+			var ids []int32
+			for _, b := range res.Books {
+				ids = append(ids, b.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			tt.expectHasMore(t, res.HasMore)
+			assert.Equal(t, tt.expectCursor, res.NextCursor)
+		})
+	}
+}
+
+// recordingRepository records the SearchInput and FacetInput it is called with.
+type recordingRepository struct {
+	params      book.SearchInput
+	facetParams book.FacetInput
+}
+
+func (r *recordingRepository) Search(_ context.Context, params book.SearchInput) ([]entity.Book, error) {
+	r.params = params
+	return nil, nil
+}
+
+func (r *recordingRepository) Export(_ context.Context, params book.SearchInput, _ func(entity.Book) error) error {
+	r.params = params
+	return nil
+}
+
+func (r *recordingRepository) Get(_ context.Context, _ int32) (entity.Book, error) {
+	return entity.Book{}, nil
+}
+
+func (r *recordingRepository) Create(_ context.Context, _ book.WriteInput) (int32, error) {
+	return 0, nil
+}
+
+func (r *recordingRepository) Update(_ context.Context, _ int32, _ book.WriteInput) error {
+	return nil
+}
+
+func (r *recordingRepository) Delete(_ context.Context, _ int32) error {
+	return nil
+}
+
+func (r *recordingRepository) Facets(_ context.Context, params book.FacetInput) (book.Facets, error) {
+	r.facetParams = params
+	return book.Facets{}, nil
+}
+
+func TestDriver_SearchSort(t *testing.T) {
+	tests := map[string]struct {
+		input  book.SearchInput
+		expect book.Sort
+	}{
+		"no sort and no query": {
+			expect: book.Sort{},
+		},
+		"query defaults to relevance": {
+			input:  book.SearchInput{Query: util.StringPtr("hobbit")},
+			expect: book.RelevanceSort,
+		},
+		"query with explicit sort": {
+			input: book.SearchInput{
+				Query: util.StringPtr("hobbit"),
+				Sort:  book.Sort{{Field: book.SortByTitle}},
+			},
+			expect: book.Sort{{Field: book.SortByTitle}},
+		},
+		"relevance is dropped without a query": {
+			input: book.SearchInput{
+				Sort: book.Sort{{Field: book.SortByRelevance, Descending: true}, {Field: book.SortByPages}},
+			},
+			expect: book.Sort{{Field: book.SortByPages}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := recordingRepository{}
+			_, err := book.NewDriver(&repo, nil, nil, nil, nil, book.Pagination{}).SearchBooks(context.Background(), tt.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expect, repo.params.Sort)
+		})
+	}
+}
+
+// eraRepository only implements List of the era.Repository.
+type eraRepository struct {
+	era.Repository
+	eras []entity.Era
+}
+
+func (r eraRepository) List(_ context.Context) ([]entity.Era, error) {
+	return r.eras, nil
+}
+
+// sizeRepository only implements List of the size.Repository.
+type sizeRepository struct {
+	size.Repository
+	sizes []entity.Size
+}
+
+func (r sizeRepository) List(_ context.Context) ([]entity.Size, error) {
+	return r.sizes, nil
+}
+
+func TestDriver_SearchRanges(t *testing.T) {
+	eras := eraRepository{eras: []entity.Era{
+		{ID: 0, Title: "Any"},
+		{ID: 1, Title: "Classic", MaxYear: util.Int16Ptr(1969)},
+		{ID: 2, Title: "Modern", MinYear: util.Int16Ptr(1970)},
+	}}
+	sizes := sizeRepository{sizes: []entity.Size{
+		{ID: 0, Title: "Any"},
+		{ID: 1, Title: "Short story", MaxPages: util.Int16Ptr(34)},
+		{ID: 3, Title: "Novella", MinPages: util.Int16Ptr(85), MaxPages: util.Int16Ptr(199)},
+	}}
+
+	tests := map[string]struct {
+		input        book.SearchInput
+		expectYears  []book.Range
+		expectPages  []book.Range
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"no eras or sizes": {
+			errAssertion: assert.NoError,
+		},
+		"eras and sizes are resolved into ranges": {
+			input: book.SearchInput{EraIDs: []int16{1}, SizeIDs: []int16{1, 3}},
+			expectYears: []book.Range{
+				{Max: util.Int16Ptr(1969)},
+			},
+			expectPages: []book.Range{
+				{Max: util.Int16Ptr(34)},
+				{Min: util.Int16Ptr(85), Max: util.Int16Ptr(199)},
+			},
+			errAssertion: assert.NoError,
+		},
+		"any era or size is not a filter": {
+			input:        book.SearchInput{EraIDs: []int16{2, 0}, SizeIDs: []int16{0}},
+			errAssertion: assert.NoError,
+		},
+		"unknown era": {
+			input:        book.SearchInput{EraIDs: []int16{0, 9}},
+			errAssertion: assert.Error,
+		},
+		"unknown size": {
+			input:        book.SearchInput{SizeIDs: []int16{2}},
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := recordingRepository{}
+			_, err := book.NewDriver(&repo, nil, nil, eras, sizes, book.Pagination{}).SearchBooks(context.Background(), tt.input)
+			tt.errAssertion(t, err)
+			if err != nil {
+				assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+				return
+			}
+			assert.Equal(t, tt.expectYears, repo.params.YearRanges)
+			assert.Equal(t, tt.expectPages, repo.params.PageRanges)
+		})
+	}
+}
+
+func TestDriver_Export(t *testing.T) {
+
+	repo := inMemoryRepository{resource: map[int32]entity.Book{}}
+	for id := int32(1); id <= int32(book.DefaultPageSize)+5; id++ {
+		repo.resource[id] = entity.Book{ID: id, Rating: float32(id % 5)}
+	}
+	driver := book.NewDriver(&repo, nil, nil, nil, nil, book.Pagination{})
+
+	t.Run("every book is exported", func(t *testing.T) {
+		var exported []int32
+		err := driver.ExportBooks(context.Background(), book.SearchInput{}, func(b entity.Book) error {
+			exported = append(exported, b.ID)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Len(t, exported, len(repo.resource))
+	})
+
+	t.Run("limit is honoured", func(t *testing.T) {
+		var exported int
+		err := driver.ExportBooks(context.Background(), book.SearchInput{Limit: util.Uint64Ptr(3)}, func(entity.Book) error {
+			exported++
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, exported)
+	})
+
+	t.Run("error stops the export", func(t *testing.T) {
+		var exported int
+		stop := errors.New("unable to write book")
+		err := driver.ExportBooks(context.Background(), book.SearchInput{}, func(entity.Book) error {
+			exported++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, exported)
+	})
+
+	t.Run("sort and ranges are resolved", func(t *testing.T) {
+		var recorded recordingRepository
+		eras := eraRepository{eras: []entity.Era{{ID: 1, Title: "Classic", MaxYear: util.Int16Ptr(1969)}}}
+		err := book.NewDriver(&recorded, nil, nil, eras, nil, book.Pagination{}).ExportBooks(context.Background(),
+			book.SearchInput{EraIDs: []int16{1}, Sort: book.Sort{{Field: book.SortByRelevance}}}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, book.Sort{}, recorded.params.Sort)
+		assert.Equal(t, []book.Range{{Max: util.Int16Ptr(1969)}}, recorded.params.YearRanges)
+		assert.Nil(t, recorded.params.Limit)
+	})
+}
+
+func TestDriver_CountFacets(t *testing.T) {
+	eras := eraRepository{eras: []entity.Era{{ID: 1, Title: "Classic", MaxYear: util.Int16Ptr(1969)}}}
+	sizes := sizeRepository{sizes: []entity.Size{{ID: 1, Title: "Short story", MaxPages: util.Int16Ptr(34)}}}
+
+	input := book.SearchInput{
+		Query:            util.StringPtr("tolkien"),
+		MinYearPublished: util.Int16Ptr(1900),
+		MaxPages:         util.Int16Ptr(400),
+		GenreIDs:         []int16{2},
+		AuthorIDs:        []int16{42},
+		EraIDs:           []int16{1},
+		SizeIDs:          []int16{1},
+		Sort:             book.DefaultSort,
+		After:            &book.Cursor{Sort: "-rating,id", Rating: 4.5, ID: 7},
+		Limit:            util.Uint64Ptr(10),
+	}
+
+	yearRanges := []book.Range{{Max: util.Int16Ptr(1969)}}
+	pageRanges := []book.Range{{Max: util.Int16Ptr(34)}}
+
+	repo := recordingRepository{}
+	_, err := book.NewDriver(&repo, nil, nil, eras, sizes, book.Pagination{}).CountFacets(context.Background(), input)
+	assert.NoError(t, err)
+
+	assert.Equal(t, book.FacetInput{
+		Genres: book.SearchInput{
+			Query:            input.Query,
+			MinYearPublished: input.MinYearPublished,
+			MaxPages:         input.MaxPages,
+			AuthorIDs:        input.AuthorIDs,
+			EraIDs:           input.EraIDs,
+			SizeIDs:          input.SizeIDs,
+			YearRanges:       yearRanges,
+			PageRanges:       pageRanges,
+		},
+		Authors: book.SearchInput{
+			Query:            input.Query,
+			MinYearPublished: input.MinYearPublished,
+			MaxPages:         input.MaxPages,
+			GenreIDs:         input.GenreIDs,
+			EraIDs:           input.EraIDs,
+			SizeIDs:          input.SizeIDs,
+			YearRanges:       yearRanges,
+			PageRanges:       pageRanges,
+		},
+		Eras: book.SearchInput{
+			Query:      input.Query,
+			MaxPages:   input.MaxPages,
+			GenreIDs:   input.GenreIDs,
+			AuthorIDs:  input.AuthorIDs,
+			SizeIDs:    input.SizeIDs,
+			PageRanges: pageRanges,
+		},
+		Sizes: book.SearchInput{
+			Query:            input.Query,
+			MinYearPublished: input.MinYearPublished,
+			GenreIDs:         input.GenreIDs,
+			AuthorIDs:        input.AuthorIDs,
+			EraIDs:           input.EraIDs,
+			YearRanges:       yearRanges,
+		},
+	}, repo.facetParams)
+
+	t.Run("unknown era", func(t *testing.T) {
+		_, err := book.NewDriver(&repo, nil, nil, eras, sizes, book.Pagination{}).
+			CountFacets(context.Background(), book.SearchInput{EraIDs: []int16{5}})
+		assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+	})
+}
+
+func TestCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		c := book.Cursor{Sort: "title,id", Title: "The Hobbit", ID: 57}
+		actual, err := book.DecodeCursor(c.Encode())
+		assert.NoError(t, err)
+		assert.Equal(t, &c, actual)
+	})
+
+	for name, token := range map[string]string{
+		"not base64":        "%%%",
+		"not json":          "bm90IGpzb24",
+		"missing id":        book.Cursor{Sort: "-rating,id", Rating: 4.71}.Encode(),
+		"rating over range": book.Cursor{Sort: "-rating,id", Rating: 5.5, ID: 1}.Encode(),
+		"unknown sort":      book.Cursor{Sort: "-author,id", ID: 1}.Encode(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := book.DecodeCursor(token)
+			assert.ErrorIs(t, err, book.ErrInvalidCursor)
+			assert.Nil(t, actual)
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := map[string]struct {
+		expr         string
+		expect       book.Sort
+		expectKeys   string
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"single descending field": {
+			expr:         "-rating",
+			expect:       book.Sort{{Field: book.SortByRating, Descending: true}},
+			expectKeys:   "-rating,id",
+			errAssertion: assert.NoError,
+		},
+		"multiple fields with explicit directions": {
+			expr: "-rating,+year_published, title",
+			expect: book.Sort{
+				{Field: book.SortByRating, Descending: true},
+				{Field: book.SortByYearPublished},
+				{Field: book.SortByTitle},
+			},
+			expectKeys:   "-rating,year_published,title,id",
+			errAssertion: assert.NoError,
+		},
+		"id is not repeated as a tie-breaker": {
+			expr:         "-id,title",
+			expect:       book.Sort{{Field: book.SortByID, Descending: true}, {Field: book.SortByTitle}},
+			expectKeys:   "-id",
+			errAssertion: assert.NoError,
+		},
+		"field is not whitelisted": {
+			expr:         "-rating,author",
+			errAssertion: assert.Error,
+		},
+		"field appears twice": {
+			expr:         "-rating,rating",
+			errAssertion: assert.Error,
+		},
+		"empty element": {
+			expr:         "-rating,,pages",
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := book.ParseSort(tt.expr)
+			tt.errAssertion(t, err)
+			assert.Equal(t, tt.expect, actual)
+			if err == nil {
+				assert.Equal(t, tt.expectKeys, actual.Keys().String())
+			}
+		})
+	}
+
+	t.Run("empty sort uses the default", func(t *testing.T) {
+		assert.Equal(t, "-rating,id", book.Sort{}.Keys().String())
+	})
 }
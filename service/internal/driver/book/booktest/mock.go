@@ -14,7 +14,54 @@ type DriverMock struct {
 }
 
 // SearchBooks is a mock routine that returns items as instructed.
-func (d *DriverMock) SearchBooks(ctx context.Context, params book.SearchInput) ([]entity.Book, error) {
+func (d *DriverMock) SearchBooks(ctx context.Context, params book.SearchInput) (book.Page, error) {
 	args := d.Called(ctx, params)
-	return args.Get(0).([]entity.Book), args.Error(1)
+	return args.Get(0).(book.Page), args.Error(1)
+}
+
+// GetBook is a mock routine that returns items as instructed.
+func (d *DriverMock) GetBook(ctx context.Context, id int32) (entity.Book, error) {
+	args := d.Called(ctx, id)
+	return args.Get(0).(entity.Book), args.Error(1)
+}
+
+// CountFacets is a mock routine that returns items as instructed.
+func (d *DriverMock) CountFacets(ctx context.Context, params book.SearchInput) (book.Facets, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).(book.Facets), args.Error(1)
+}
+
+// ExportBooks is a mock routine that calls each with the Books it is instructed to return.
+func (d *DriverMock) ExportBooks(ctx context.Context, params book.SearchInput, each func(entity.Book) error) error {
+	args := d.Called(ctx, params)
+	for _, b := range args.Get(0).([]entity.Book) {
+		if err := each(b); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+// CreateBook is a mock routine that returns items as instructed.
+func (d *DriverMock) CreateBook(ctx context.Context, params book.WriteInput) (entity.Book, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).(entity.Book), args.Error(1)
+}
+
+// ReplaceBook is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceBook(ctx context.Context, id int32, params book.WriteInput) (entity.Book, error) {
+	args := d.Called(ctx, id, params)
+	return args.Get(0).(entity.Book), args.Error(1)
+}
+
+// UpdateBook is a mock routine that returns items as instructed.
+func (d *DriverMock) UpdateBook(ctx context.Context, id int32, params book.WriteInput) (entity.Book, error) {
+	args := d.Called(ctx, id, params)
+	return args.Get(0).(entity.Book), args.Error(1)
+}
+
+// DeleteBook is a mock routine that returns items as instructed.
+func (d *DriverMock) DeleteBook(ctx context.Context, id int32) error {
+	args := d.Called(ctx, id)
+	return args.Error(0)
 }
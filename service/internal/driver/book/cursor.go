@@ -0,0 +1,110 @@
+package book
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCursor occurs when a cursor token cannot be decoded.
+var ErrInvalidCursor = errors.New("cursor is malformed")
+
+// Cursor marks the last Book of a Page. It is used for keyset pagination: a search resumed
+// from a Cursor continues with the Book immediately following it in the order given by Sort.
+// Only the attributes of the Book which are part of the Sort are retained.
+type Cursor struct {
+	// Sort is the expression of the Sort.Keys the Page was ordered by. A Cursor may only be
+	// used to resume a search with the same order.
+	Sort string `json:"s"`
+
+	// Relevance is the relevance of the last Book on the previous Page to the search query.
+	Relevance float64 `json:"v,omitempty"`
+
+	// Rating is the rating of the last Book on the previous Page.
+	Rating float32 `json:"r,omitempty"`
+
+	// YearPublished is the year the last Book on the previous Page was published.
+	YearPublished int16 `json:"y,omitempty"`
+
+	// Pages is the page count of the last Book on the previous Page.
+	Pages int16 `json:"p,omitempty"`
+
+	// Title is the title of the last Book on the previous Page.
+	Title string `json:"t,omitempty"`
+
+	// ID is the ID of the last Book on the previous Page. It breaks ties between all other keys.
+	ID int32 `json:"i"`
+}
+
+// newCursor creates a Cursor pointing at the provided entity.Book, in the order given by the Sort.
+func newCursor(b entity.Book, sort Sort) *Cursor {
+	keys := sort.Keys()
+	c := Cursor{Sort: keys.String(), ID: b.ID}
+	for _, k := range keys {
+		switch k.Field {
+		case SortByRelevance:
+			c.Relevance = b.Relevance
+		case SortByRating:
+			c.Rating = b.Rating
+		case SortByYearPublished:
+			c.YearPublished = b.YearPublished
+		case SortByPages:
+			c.Pages = b.Pages
+		case SortByTitle:
+			c.Title = b.Title
+		}
+	}
+	return &c
+}
+
+// Value returns the value of the Cursor's Book for the provided SortField.
+func (c Cursor) Value(f SortField) interface{} {
+	switch f {
+	case SortByRelevance:
+		return c.Relevance
+	case SortByRating:
+		return c.Rating
+	case SortByYearPublished:
+		return c.YearPublished
+	case SortByPages:
+		return c.Pages
+	case SortByTitle:
+		return c.Title
+	default:
+		return c.ID
+	}
+}
+
+// Matches returns true if the Cursor was created for a Page ordered by the provided Sort.
+func (c Cursor) Matches(sort Sort) bool {
+	return c.Sort == sort.Keys().String()
+}
+
+// Encode serializes the Cursor into an opaque, URL-safe token that can be handed to clients.
+func (c Cursor) Encode() string {
+	// Marshalling a struct of strings and numbers cannot fail.
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a token created by Cursor.Encode. If the token is not a valid cursor,
+// then ErrInvalidCursor is returned.
+func DecodeCursor(token string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID < 1 || c.Rating < 0 || c.Rating > 5 {
+		return nil, ErrInvalidCursor
+	}
+
+	if _, err := ParseSort(c.Sort); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
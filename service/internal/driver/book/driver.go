@@ -2,17 +2,53 @@ package book
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 )
 
+const (
+	// DefaultPageSize is the number of Books returned in a Page when no Limit is requested.
+	DefaultPageSize uint64 = 20
+
+	// MaxPageSize is the largest number of Books that may be returned in a single Page.
+	MaxPageSize uint64 = 100
+
+	// MinPages is the smallest page count a Book may have, or be searched for.
+	MinPages int16 = 1
+
+	// MaxPages is the largest page count a Book may have, or be searched for.
+	MaxPages int16 = 10000
+
+	// MinYearPublished is the earliest year a Book may have been published in, or be searched for.
+	MinYearPublished int16 = 1800
+
+	// MaxYearPublished is the latest year a Book may have been published in, or be searched for.
+	MaxYearPublished int16 = 2100
+
+	// MinRating is the lowest rating a Book may have.
+	MinRating float32 = 0
+
+	// MaxRating is the highest rating a Book may have.
+	MaxRating float32 = 5
+)
+
 // SearchInput is a input parameter for SearchBooks.
 type SearchInput struct {
 	_ struct{}
 
-	// Title is used to search for books by title (ignored if nil).
+	// Title is used to search for books whose title is exactly equal to it (ignored if nil).
 	Title *string
 
+	// Query performs a ranked full-text search across the title and author name of books. Close matches,
+	// such as misspellings, are included as well (ignored if nil). Each Book's relevance to the Query is
+	// made available as a SortField.
+	Query *string
+
 	// MaxYearPublished filters Books published later than the specified year (ignored if nil).
 	MaxYearPublished *int16
 
@@ -31,21 +67,240 @@ type SearchInput struct {
 	// AuthorIDs includes Books whose Author's ID falls into _any_ of the included AuthorIDs (ignored if nil).
 	AuthorIDs []int16
 
-	// Limit specifies a maximum number of Books to be returned. If not specified, there will be no limit.
+	// EraIDs includes Books published within _any_ of the included Eras (ignored if nil). SearchBooks
+	// resolves them into YearRanges, so they are ignored by a Repository.
+	EraIDs []int16
+
+	// SizeIDs includes Books whose page count falls within _any_ of the included Sizes (ignored if nil).
+	// SearchBooks resolves them into PageRanges, so they are ignored by a Repository.
+	SizeIDs []int16
+
+	// YearRanges includes Books published within _any_ of the included Ranges (ignored if nil).
+	YearRanges []Range
+
+	// PageRanges includes Books whose page count falls within _any_ of the included Ranges (ignored if nil).
+	PageRanges []Range
+
+	// Sort specifies the order of the Books. If empty, RelevanceSort is used when there is a Query, and
+	// DefaultSort otherwise. Either way, Books with equal sort keys are ordered by ascending ID (see Sort.Keys).
+	Sort Sort
+
+	// After resumes the search with the Book following the one marked by the Cursor (ignored if nil).
+	After *Cursor
+
+	// Limit specifies a maximum number of Books to be returned. When passed to SearchBooks, the driver's
+	// Pagination is applied to it. When passed to a Repository, nil means there is no limit.
 	Limit *uint64
 }
 
+// Range is an inclusive range of values. A nil bound leaves that end of the Range open.
+type Range struct {
+	Min *int16
+	Max *int16
+}
+
+// unbounded returns true if the Range has neither a Min nor a Max, meaning it includes every value.
+func (r Range) unbounded() bool {
+	return r.Min == nil && r.Max == nil
+}
+
+// Pagination bounds the number of Books returned in a single Page.
+type Pagination struct {
+	// DefaultLimit is used when SearchInput.Limit is nil. If zero, DefaultPageSize is used.
+	DefaultLimit uint64
+
+	// MaxLimit caps any SearchInput.Limit that exceeds it. If zero, MaxPageSize is used.
+	MaxLimit uint64
+}
+
+// Page is a single page of Books found by SearchBooks.
+type Page struct {
+	// Books holds the Books on this Page, in the order given by SearchInput.Sort.
+	Books []entity.Book
+
+	// NextCursor can be used as SearchInput.After to fetch the next Page. It is nil if HasMore is false.
+	NextCursor *Cursor
+
+	// HasMore is true if there are more Books after this Page.
+	HasMore bool
+}
+
 type driver struct {
 	repository Repository
+	authors    author.Repository
+	genres     genre.Repository
+	eras       era.Repository
+	sizes      size.Repository
+	pagination Pagination
 }
 
 // NewDriver creates a driver which wraps the repository. The wrapper
-// will perform business logic against the usecases of Size entity.
-func NewDriver(r Repository) *driver {
-	return &driver{repository: r}
+// will perform business logic against the usecases of Book entity. The author and genre
+// repositories are used to validate the references of written Books. The era and size
+// repositories are used to resolve SearchInput.EraIDs and SearchInput.SizeIDs.
+func NewDriver(r Repository, authors author.Repository, genres genre.Repository, eras era.Repository, sizes size.Repository, p Pagination) *driver {
+	if p.MaxLimit == 0 {
+		p.MaxLimit = MaxPageSize
+	}
+	if p.DefaultLimit == 0 {
+		p.DefaultLimit = DefaultPageSize
+	}
+	if p.DefaultLimit > p.MaxLimit {
+		p.DefaultLimit = p.MaxLimit
+	}
+	return &driver{repository: r, authors: authors, genres: genres, eras: eras, sizes: sizes, pagination: p}
 }
 
-// SearchBooks searches for entity.Book types from the repository and returns them.
-func (d *driver) SearchBooks(ctx context.Context, params SearchInput) ([]entity.Book, error) {
-	return d.repository.Search(ctx, params)
+// SearchBooks searches for a Page of entity.Book types from the repository and returns it. The size of
+// the Page is bounded by the driver's Pagination. One more Book than fits on the Page is requested from
+// the repository so that HasMore can be determined without a separate count.
+func (d *driver) SearchBooks(ctx context.Context, params SearchInput) (Page, error) {
+	limit := d.pagination.DefaultLimit
+	if params.Limit != nil && *params.Limit > 0 {
+		limit = *params.Limit
+		if limit > d.pagination.MaxLimit {
+			limit = d.pagination.MaxLimit
+		}
+	}
+
+	probe := limit + 1
+	params.Limit = &probe
+	params.Sort = sortFor(params)
+
+	params, err := d.resolveRanges(ctx, params)
+	if err != nil {
+		return Page{}, err
+	}
+
+	books, err := d.repository.Search(ctx, params)
+	if err != nil {
+		return Page{}, err
+	}
+
+	if uint64(len(books)) <= limit {
+		return Page{Books: books}, nil
+	}
+
+	books = books[:limit]
+	return Page{
+		Books:      books,
+		NextCursor: newCursor(books[len(books)-1], params.Sort),
+		HasMore:    true,
+	}, nil
+}
+
+// ExportBooks calls each with every entity.Book from the repository which matches the search parameters, in
+// the order of their Sort. Unlike SearchBooks, the Books are not paginated: every one is exported unless a
+// Limit is given. If each returns an error, then the export stops and the error is returned.
+func (d *driver) ExportBooks(ctx context.Context, params SearchInput, each func(entity.Book) error) error {
+	params.Sort = sortFor(params)
+
+	params, err := d.resolveRanges(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	return d.repository.Export(ctx, params, each)
+}
+
+// GetBook fetches the entity.Book with the provided ID from the repository and returns it.
+func (d *driver) GetBook(ctx context.Context, id int32) (entity.Book, error) {
+	return d.repository.Get(ctx, id)
+}
+
+// resolveRanges resolves the EraIDs and SizeIDs of the SearchInput into YearRanges and PageRanges, which
+// are added to those already present. The updated SearchInput is returned.
+func (d *driver) resolveRanges(ctx context.Context, params SearchInput) (SearchInput, error) {
+	if len(params.EraIDs) > 0 {
+		ranges, err := d.yearRanges(ctx, params.EraIDs)
+		if err != nil {
+			return params, err
+		}
+		params.YearRanges = append(params.YearRanges, ranges...)
+	}
+
+	if len(params.SizeIDs) > 0 {
+		ranges, err := d.pageRanges(ctx, params.SizeIDs)
+		if err != nil {
+			return params, err
+		}
+		params.PageRanges = append(params.PageRanges, ranges...)
+	}
+
+	return params, nil
+}
+
+// yearRanges resolves the IDs of entity.Era types into the Ranges of years they span. If an ID does not
+// exist, then an error wrapping entity.ErrInvalidQueryParam is returned. If any of the Eras is unbounded,
+// such as "Any", then all years are included and no Ranges are returned.
+func (d *driver) yearRanges(ctx context.Context, ids []int16) ([]Range, error) {
+	eras, err := d.eras.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve eras: %w", err)
+	}
+
+	byID := make(map[int32]Range, len(eras))
+	for _, e := range eras {
+		byID[e.ID] = Range{Min: e.MinYear, Max: e.MaxYear}
+	}
+
+	return resolveRanges("era", ids, byID)
+}
+
+// pageRanges resolves the IDs of entity.Size types into the Ranges of page counts they span. If an ID does
+// not exist, then an error wrapping entity.ErrInvalidQueryParam is returned. If any of the Sizes is
+// unbounded, such as "Any", then all page counts are included and no Ranges are returned.
+func (d *driver) pageRanges(ctx context.Context, ids []int16) ([]Range, error) {
+	sizes, err := d.sizes.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve sizes: %w", err)
+	}
+
+	byID := make(map[int32]Range, len(sizes))
+	for _, s := range sizes {
+		byID[s.ID] = Range{Min: s.MinPages, Max: s.MaxPages}
+	}
+
+	return resolveRanges("size", ids, byID)
+}
+
+// resolveRanges looks up the Range of each of the ids. The kind names the type of the IDs for error messages.
+func resolveRanges(kind string, ids []int16, byID map[int32]Range) ([]Range, error) {
+	var (
+		ranges    = make([]Range, 0, len(ids))
+		unbounded bool
+	)
+
+	for _, id := range ids {
+		r, ok := byID[int32(id)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %d does not exist", entity.ErrInvalidQueryParam, kind, id)
+		}
+		unbounded = unbounded || r.unbounded()
+		ranges = append(ranges, r)
+	}
+
+	if unbounded {
+		return nil, nil
+	}
+	return ranges, nil
+}
+
+// sortFor returns the Sort to search with. If no Sort is requested, RelevanceSort is used for queries.
+// SortByRelevance is meaningless without a Query, so it is dropped if there is none.
+func sortFor(params SearchInput) Sort {
+	if params.Query != nil {
+		if len(params.Sort) == 0 {
+			return RelevanceSort
+		}
+		return params.Sort
+	}
+
+	sort := make(Sort, 0, len(params.Sort))
+	for _, k := range params.Sort {
+		if k.Field != SortByRelevance {
+			sort = append(sort, k)
+		}
+	}
+	return sort
 }
@@ -0,0 +1,56 @@
+package book
+
+import (
+	"context"
+)
+
+// FacetCount is the number of Books which fall into a single bucket of a facet, such as a Genre.
+type FacetCount struct {
+	// ID is the ID of the bucket, such as the ID of an entity.Genre.
+	ID int32
+
+	// Count is the number of Books in the bucket.
+	Count int64
+}
+
+// Facets holds the number of Books which match a search for every bucket of each facet.
+type Facets struct {
+	Genres  []FacetCount
+	Authors []FacetCount
+	Eras    []FacetCount
+	Sizes   []FacetCount
+}
+
+// FacetInput is an input parameter for a Repository's Facets. It holds the SearchInput that
+// Books are counted with for each facet.
+type FacetInput struct {
+	Genres  SearchInput
+	Authors SearchInput
+	Eras    SearchInput
+	Sizes   SearchInput
+}
+
+// CountFacets counts the Books which match the search parameters for every genre, author, era and size.
+// Each facet is counted with its own filter left out, so that the counts show how many Books would match
+// if a different bucket of that facet were selected instead. Years are the filter of the era facet and
+// page counts are the filter of the size facet, whether they are given as IDs or as bounds. Ordering and
+// pagination parameters are ignored.
+func (d *driver) CountFacets(ctx context.Context, params SearchInput) (Facets, error) {
+	params.Sort, params.After, params.Limit = nil, nil, nil
+
+	params, err := d.resolveRanges(ctx, params)
+	if err != nil {
+		return Facets{}, err
+	}
+
+	input := FacetInput{Genres: params, Authors: params, Eras: params, Sizes: params}
+
+	input.Genres.GenreIDs = nil
+	input.Authors.AuthorIDs = nil
+	input.Eras.EraIDs, input.Eras.YearRanges = nil, nil
+	input.Eras.MinYearPublished, input.Eras.MaxYearPublished = nil, nil
+	input.Sizes.SizeIDs, input.Sizes.PageRanges = nil, nil
+	input.Sizes.MinPages, input.Sizes.MaxPages = nil, nil
+
+	return d.repository.Facets(ctx, input)
+}
@@ -9,11 +9,57 @@ import (
 // Repository states the required methods from the persistence layer to satisfy business requirements.
 type Repository interface {
 	// Search should accepts SearchInput items and returns a slice of entity.Book types if no error.
+	// Results must be ordered by the SearchInput's Sort.Keys.
 	Search(ctx context.Context, params SearchInput) ([]entity.Book, error)
+
+	// Get should return the Book with the provided ID. If it does not exist, then an error wrapping
+	// entity.ErrNotFound should be returned.
+	Get(ctx context.Context, id int32) (entity.Book, error)
+
+	// Create should insert a Book with every attribute of the WriteInput and return its assigned ID.
+	Create(ctx context.Context, params WriteInput) (int32, error)
+
+	// Update should set the attributes of the Book with the provided ID which are set in the WriteInput. If
+	// the Book does not exist, then an error wrapping entity.ErrNotFound should be returned.
+	Update(ctx context.Context, id int32, params WriteInput) error
+
+	// Delete should delete the Book with the provided ID. If the Book does not exist, then an error wrapping
+	// entity.ErrNotFound should be returned.
+	Delete(ctx context.Context, id int32) error
+
+	// Facets should count the Books matching each facet's SearchInput, for every bucket of that facet.
+	Facets(ctx context.Context, params FacetInput) (Facets, error)
+
+	// Export should call each with every Book that Search would return, in the same order, without holding
+	// them all in memory at once. The Books must be read from a single consistent snapshot. If each returns an
+	// error, then the export should stop and return it.
+	Export(ctx context.Context, params SearchInput, each func(entity.Book) error) error
 }
 
 // Driver is an interface described the contract required to satisfy business usecases.
 type Driver interface {
-	// SearchBooks should fetch all entity.Book types and perform intermediary business logic, if any.
-	SearchBooks(ctx context.Context, params SearchInput) ([]entity.Book, error)
+	// SearchBooks should fetch a Page of entity.Book types and perform intermediary business logic, if any.
+	SearchBooks(ctx context.Context, params SearchInput) (Page, error)
+
+	// GetBook should fetch a single entity.Book by its ID and perform intermediary business logic, if any.
+	GetBook(ctx context.Context, id int32) (entity.Book, error)
+
+	// CreateBook should validate and create a Book, and return it with its assigned ID.
+	CreateBook(ctx context.Context, params WriteInput) (entity.Book, error)
+
+	// ReplaceBook should validate and replace every attribute of an existing Book, and return it.
+	ReplaceBook(ctx context.Context, id int32, params WriteInput) (entity.Book, error)
+
+	// UpdateBook should update the provided attributes of an existing Book, validate the result, and return it.
+	UpdateBook(ctx context.Context, id int32, params WriteInput) (entity.Book, error)
+
+	// DeleteBook should delete an existing Book.
+	DeleteBook(ctx context.Context, id int32) error
+
+	// CountFacets should count the Books matching the SearchInput for every genre, author, era and size.
+	CountFacets(ctx context.Context, params SearchInput) (Facets, error)
+
+	// ExportBooks should call each with every entity.Book matching the SearchInput, unpaginated, as they are
+	// read from a consistent snapshot.
+	ExportBooks(ctx context.Context, params SearchInput, each func(entity.Book) error) error
 }
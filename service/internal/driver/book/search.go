@@ -0,0 +1,143 @@
+package book
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+)
+
+// rangeSeparator separates the bounds of a range parameter, such as pages=100..300.
+const rangeSeparator = ".."
+
+// SearchParams are the parameters of a search as they are given, such as by the query of the API's /books
+// endpoint or the flags of the search command, before they are validated by ValidateSearch. Parameters which
+// were not given are nil.
+type SearchParams struct {
+	_ struct{}
+
+	Title            *string
+	Query            *string
+	MaxYearPublished *int16
+	MinYearPublished *int16
+	MaxPages         *int16
+	MinPages         *int16
+
+	// Pages and Year are ranges, such as 100..300 or 1970.., which are shorthands for MinPages and MaxPages,
+	// and MinYearPublished and MaxYearPublished. They cannot be used together with the fields they stand for.
+	Pages *string
+	Year  *string
+
+	GenreIDs  []int16
+	AuthorIDs []int16
+	EraIDs    []int16
+	SizeIDs   []int16
+	Limit     *uint64
+	Sort      *string
+	Cursor    *string
+}
+
+// ValidateSearch validates the SearchParams and maps them to a SearchInput. Every violation is added to the
+// validation.Validator, under the name of the API's query parameter, such as "min-pages" or "q", so that every
+// interface rejects the same parameters with the same FieldErrors. The SearchInput must not be used if the
+// validation.Validator has errors.
+//
+// Bounds outside of their ranges are invalid, as are lower bounds greater than their upper bounds. The pages and
+// year ranges are parsed into the bounds they stand for. The Query must not be blank, the Sort must only
+// reference whitelisted fields, and the Cursor must have been issued for the same sort.
+func ValidateSearch(v *validation.Validator, p SearchParams) SearchInput {
+	minPages, maxPages := rangeFields(v, "pages", p.Pages, "min-pages", &p.MinPages, "max-pages", &p.MaxPages)
+	v.Int16InRange(minPages, p.MinPages, MinPages, MaxPages)
+	v.Int16InRange(maxPages, p.MaxPages, MinPages, MaxPages)
+	v.Int16Range(minPages, p.MinPages, maxPages, p.MaxPages)
+
+	minYear, maxYear := rangeFields(v, "year", p.Year, "min-year", &p.MinYearPublished,
+		"max-year", &p.MaxYearPublished)
+	v.Int16InRange(minYear, p.MinYearPublished, MinYearPublished, MaxYearPublished)
+	v.Int16InRange(maxYear, p.MaxYearPublished, MinYearPublished, MaxYearPublished)
+	v.Int16Range(minYear, p.MinYearPublished, maxYear, p.MaxYearPublished)
+	v.Uint64AtLeast("limit", p.Limit, 1)
+	v.NotBlank("q", p.Query)
+
+	input := SearchInput{
+		Title:            p.Title,
+		Query:            p.Query,
+		MaxYearPublished: p.MaxYearPublished,
+		MinYearPublished: p.MinYearPublished,
+		MaxPages:         p.MaxPages,
+		MinPages:         p.MinPages,
+		GenreIDs:         p.GenreIDs,
+		AuthorIDs:        p.AuthorIDs,
+		EraIDs:           p.EraIDs,
+		SizeIDs:          p.SizeIDs,
+		Limit:            p.Limit,
+	}
+
+	if p.Sort != nil {
+		sort, err := ParseSort(*p.Sort)
+		if v.Check(err == nil, "sort", validation.CodeInvalid, "%s", err) {
+			input.Sort = sort
+			v.Check(p.Query != nil || !sort.Has(SortByRelevance), "sort", validation.CodeConflict,
+				"cannot sort by relevance without q")
+		}
+	}
+
+	// Queries are ordered by relevance unless otherwise requested. This must be known before
+	// the cursor is checked against the sort.
+	if p.Query != nil && len(input.Sort) == 0 && !v.Invalid("sort") {
+		input.Sort = RelevanceSort
+	}
+
+	if p.Cursor != nil {
+		after, err := DecodeCursor(*p.Cursor)
+		if v.Check(err == nil, "cursor", validation.CodeInvalid, "%s", err) && !v.Invalid("sort") {
+			v.Check(after.Matches(input.Sort), "cursor", validation.CodeConflict,
+				"was issued for sort %s", after.Sort)
+		}
+		input.After = after
+	}
+
+	return input
+}
+
+// rangeFields parses the range parameter, such as pages=100..300, into the bounds which it is a shorthand for,
+// and returns the names of the fields which the bounds were given by, to record their violations against. If
+// the range is not set, then the bounds are left as they are. It cannot be used together with the bounds.
+func rangeFields(v *validation.Validator, field string, value *string, minField string, min **int16,
+	maxField string, max **int16) (string, string) {
+	if value == nil {
+		return minField, maxField
+	}
+	if !v.Check(*min == nil && *max == nil, field, validation.CodeConflict,
+		"cannot be used together with %s or %s", minField, maxField) {
+		return minField, maxField
+	}
+	*min, *max = int16Range(v, field, *value)
+	return field, field
+}
+
+// int16Range parses the value of a range parameter, such as pages=100..300, into its inclusive bounds. Either
+// bound may be omitted, as in year=1970.. or year=..1980, to leave the range open on that side, but not both.
+// If the value is not a range of integers, then a violation of the field is recorded in the
+// validation.Validator and both bounds are nil.
+func int16Range(v *validation.Validator, field, value string) (min, max *int16) {
+	bounds := strings.Split(value, rangeSeparator)
+	if !v.Check(len(bounds) == 2 && bounds[0]+bounds[1] != "", field, validation.CodeInvalid,
+		"is %q but should be a range such as 100..300, 100.. or ..300", value) {
+		return nil, nil
+	}
+
+	parsed := make([]*int16, len(bounds))
+	for i, b := range bounds {
+		if b == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(b, 10, 16)
+		if !v.Check(err == nil, field, validation.CodeInvalidType, "is %q but its bounds should be integers", value) {
+			return nil, nil
+		}
+		bound := int16(n)
+		parsed[i] = &bound
+	}
+	return parsed[0], parsed[1]
+}
@@ -0,0 +1,154 @@
+package book
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SortField is a whitelisted attribute of a Book that search results may be ordered by.
+type SortField string
+
+const (
+	// SortByRelevance orders Books by how well they match SearchInput.Query. It may only be used
+	// when a Query is provided.
+	SortByRelevance SortField = "relevance"
+
+	// SortByRating orders Books by their rating.
+	SortByRating SortField = "rating"
+
+	// SortByYearPublished orders Books by the year in which they were published.
+	SortByYearPublished SortField = "year_published"
+
+	// SortByPages orders Books by their page count.
+	SortByPages SortField = "pages"
+
+	// SortByTitle orders Books by a library-style sort key of their title, which ignores
+	// case and leading articles such as "The", "A" and "An".
+	SortByTitle SortField = "title"
+
+	// SortByID orders Books by their ID. It is used as the tie-breaker of every Sort.
+	SortByID SortField = "id"
+)
+
+// sortFields lists every SortField which may be requested, in the order they are documented.
+var sortFields = []SortField{SortByRelevance, SortByRating, SortByYearPublished, SortByPages, SortByTitle, SortByID}
+
+// ErrInvalidSort occurs when a sort expression cannot be parsed.
+var ErrInvalidSort = errors.New("sort is invalid")
+
+// SortKey is a single SortField and the direction in which it is ordered.
+type SortKey struct {
+	Field      SortField
+	Descending bool
+}
+
+// String returns the SortKey as it is written in a sort expression, such as "-rating".
+func (k SortKey) String() string {
+	if k.Descending {
+		return "-" + string(k.Field)
+	}
+	return string(k.Field)
+}
+
+// Sort is an ordered list of SortKeys. Earlier keys take precedence over later ones.
+type Sort []SortKey
+
+// DefaultSort orders Books from the best to the worst rated.
+var DefaultSort = Sort{{Field: SortByRating, Descending: true}}
+
+// RelevanceSort orders Books from the most to the least relevant to SearchInput.Query, and then from
+// the best to the worst rated. It is the default when a Query is provided.
+var RelevanceSort = Sort{{Field: SortByRelevance, Descending: true}, {Field: SortByRating, Descending: true}}
+
+// ParseSort parses a comma-delimited sort expression such as "-rating,year_published,title".
+// Each element is a SortField, optionally prefixed by "-" for descending or "+" for ascending
+// order. Fields which are not whitelisted, or which appear more than once, result in an error
+// wrapping ErrInvalidSort.
+func ParseSort(expr string) (Sort, error) {
+	var (
+		sort = Sort{}
+		seen = map[SortField]struct{}{}
+	)
+
+	for _, element := range strings.Split(expr, ",") {
+		element = strings.TrimSpace(element)
+
+		var key SortKey
+		switch {
+		case strings.HasPrefix(element, "-"):
+			key.Descending = true
+			element = element[1:]
+		case strings.HasPrefix(element, "+"):
+			element = element[1:]
+		}
+		key.Field = SortField(element)
+
+		if !key.Field.valid() {
+			return nil, fmt.Errorf("%w: %q is not one of %s", ErrInvalidSort, element, sortFieldList())
+		}
+		if _, ok := seen[key.Field]; ok {
+			return nil, fmt.Errorf("%w: %q appears more than once", ErrInvalidSort, element)
+		}
+
+		seen[key.Field] = struct{}{}
+		sort = append(sort, key)
+	}
+
+	return sort, nil
+}
+
+// Keys returns the SortKeys which fully determine the order of search results. If the Sort is empty,
+// DefaultSort is used. Unless the Sort already orders by SortByID, ascending ID is appended as a
+// tie-breaker so that the order is deterministic.
+func (s Sort) Keys() Sort {
+	if len(s) == 0 {
+		s = DefaultSort
+	}
+
+	keys := make(Sort, 0, len(s)+1)
+	for _, k := range s {
+		keys = append(keys, k)
+		if k.Field == SortByID {
+			return keys
+		}
+	}
+	return append(keys, SortKey{Field: SortByID})
+}
+
+// Has returns true if the Sort orders by the provided SortField.
+func (s Sort) Has(f SortField) bool {
+	for _, k := range s {
+		if k.Field == f {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the Sort as a comma-delimited sort expression.
+func (s Sort) String() string {
+	elements := make([]string, len(s))
+	for i, k := range s {
+		elements[i] = k.String()
+	}
+	return strings.Join(elements, ",")
+}
+
+func (f SortField) valid() bool {
+	for _, field := range sortFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func sortFieldList() string {
+	names := make([]string, len(sortFields))
+	for i, f := range sortFields {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,136 @@
+package book
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/pkg/errors"
+)
+
+// WriteInput is an input parameter for CreateBook, ReplaceBook and UpdateBook. It holds the attributes of
+// a Book to be written. CreateBook and ReplaceBook require every attribute, whereas UpdateBook leaves the
+// attributes which are nil unchanged.
+type WriteInput struct {
+	_ struct{}
+
+	Title         *string
+	YearPublished *int16
+	Rating        *float32
+	Pages         *int16
+	AuthorID      *int32
+	GenreID       *int32
+}
+
+// CreateBook validates the WriteInput and creates a Book from it. The repository assigns the Book's ID. The
+// created entity.Book is returned. If the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) CreateBook(ctx context.Context, params WriteInput) (entity.Book, error) {
+	if err := d.validate(ctx, params); err != nil {
+		return entity.Book{}, err
+	}
+
+	id, err := d.repository.Create(ctx, params)
+	if err != nil {
+		return entity.Book{}, err
+	}
+
+	return d.repository.Get(ctx, id)
+}
+
+// ReplaceBook validates the WriteInput and replaces every attribute of the Book with the provided ID with it.
+// The updated entity.Book is returned. If the Book does not exist, then an error wrapping entity.ErrNotFound is
+// returned. If the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) ReplaceBook(ctx context.Context, id int32, params WriteInput) (entity.Book, error) {
+	if err := d.validate(ctx, params); err != nil {
+		return entity.Book{}, err
+	}
+
+	if err := d.repository.Update(ctx, id, params); err != nil {
+		return entity.Book{}, err
+	}
+
+	return d.repository.Get(ctx, id)
+}
+
+// UpdateBook updates the attributes of the Book with the provided ID which are set in the WriteInput. The
+// result is validated as a whole before it is written. The updated entity.Book is returned. If the Book does
+// not exist, then an error wrapping entity.ErrNotFound is returned. If the result is invalid, then an
+// *entity.ValidationError is returned.
+func (d *driver) UpdateBook(ctx context.Context, id int32, params WriteInput) (entity.Book, error) {
+	b, err := d.repository.Get(ctx, id)
+	if err != nil {
+		return entity.Book{}, err
+	}
+
+	merged := WriteInput{
+		Title:         &b.Title,
+		YearPublished: &b.YearPublished,
+		Rating:        &b.Rating,
+		Pages:         &b.Pages,
+		AuthorID:      &b.Author.ID,
+		GenreID:       &b.Genre.ID,
+	}
+	if params.Title != nil {
+		merged.Title = params.Title
+	}
+	if params.YearPublished != nil {
+		merged.YearPublished = params.YearPublished
+	}
+	if params.Rating != nil {
+		merged.Rating = params.Rating
+	}
+	if params.Pages != nil {
+		merged.Pages = params.Pages
+	}
+	if params.AuthorID != nil {
+		merged.AuthorID = params.AuthorID
+	}
+	if params.GenreID != nil {
+		merged.GenreID = params.GenreID
+	}
+
+	return d.ReplaceBook(ctx, id, merged)
+}
+
+// DeleteBook deletes the Book with the provided ID. If the Book does not exist, then an error wrapping
+// entity.ErrNotFound is returned.
+func (d *driver) DeleteBook(ctx context.Context, id int32) error {
+	return d.repository.Delete(ctx, id)
+}
+
+// validate checks every attribute of the WriteInput, which are all required. The Author and Genre must exist.
+// If any attribute is invalid, then an *entity.ValidationError listing all of them is returned.
+func (d *driver) validate(ctx context.Context, params WriteInput) error {
+	v := validation.New(entity.ErrInvalidEntity)
+
+	v.Required("title", params.Title != nil)
+	v.NotBlank("title", params.Title)
+
+	v.Required("yearPublished", params.YearPublished != nil)
+	v.Int16InRange("yearPublished", params.YearPublished, MinYearPublished, MaxYearPublished)
+
+	v.Required("rating", params.Rating != nil)
+	v.Float32InRange("rating", params.Rating, MinRating, MaxRating)
+
+	v.Required("pages", params.Pages != nil)
+	v.Int16InRange("pages", params.Pages, MinPages, MaxPages)
+
+	if v.Required("authorId", params.AuthorID != nil) {
+		if _, err := d.authors.Get(ctx, *params.AuthorID); errors.Is(err, entity.ErrNotFound) {
+			v.Add("authorId", validation.CodeNotFound, "author %d does not exist", *params.AuthorID)
+		} else if err != nil {
+			return fmt.Errorf("unable to validate author: %w", err)
+		}
+	}
+
+	if v.Required("genreId", params.GenreID != nil) {
+		if _, err := d.genres.Get(ctx, *params.GenreID); errors.Is(err, entity.ErrNotFound) {
+			v.Add("genreId", validation.CodeNotFound, "genre %d does not exist", *params.GenreID)
+		} else if err != nil {
+			return fmt.Errorf("unable to validate genre: %w", err)
+		}
+	}
+
+	return v.Err()
+}
@@ -0,0 +1,143 @@
+// Package bucket checks the ranges of buckets, such as Eras and Sizes, which together partition the values of an
+// attribute of Books so that every value falls into exactly one bucket.
+package bucket
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+)
+
+// Bucket is a titled, inclusive range of values. A nil bound leaves that end of the range open. A Bucket without
+// any bounds, such as the "Any" Era, matches every value and so takes no part in the partition.
+type Bucket struct {
+	Title string
+	Min   *int16
+	Max   *int16
+}
+
+// bounded reports whether the Bucket takes part in the partition.
+func (b Bucket) bounded() bool {
+	return b.Min != nil || b.Max != nil
+}
+
+// Conflict is a pair of Buckets, by their index, which are adjacent in the partition but whose ranges either
+// overlap or leave a gap between them.
+type Conflict struct {
+	Lower int
+	Upper int
+}
+
+// Names describes a kind of Bucket, such as "era", and the JSON fields of its bounds, such as "minYear" and
+// "maxYear". It is used to word FieldErrors.
+type Names struct {
+	Kind string
+	Min  string
+	Max  string
+}
+
+// Conflicts orders the bounded Buckets by their ranges and returns every Conflict between adjacent ones.
+func Conflicts(buckets []Bucket) []Conflict {
+	var conflicts []Conflict
+	order := partition(buckets)
+	for p := 1; p < len(order); p++ {
+		if !adjoins(buckets[order[p-1]], buckets[order[p]]) {
+			conflicts = append(conflicts, Conflict{Lower: order[p-1], Upper: order[p]})
+		}
+	}
+	return conflicts
+}
+
+// Removal returns the Conflict which removing the Bucket at index i would introduce, which is a gap between its
+// neighbours in the partition. If its removal leaves the partition intact, then nil is returned.
+func Removal(buckets []Bucket, i int) *Conflict {
+	order := partition(buckets)
+	for p := 1; p < len(order)-1; p++ {
+		if order[p] != i {
+			continue
+		}
+
+		lower, upper := order[p-1], order[p+1]
+		if adjoins(buckets[lower], buckets[i]) && adjoins(buckets[i], buckets[upper]) &&
+			!adjoins(buckets[lower], buckets[upper]) {
+			return &Conflict{Lower: lower, Upper: upper}
+		}
+	}
+	return nil
+}
+
+// Validate checks the range of the Bucket at index i: its Min may not exceed its Max, and it must adjoin its
+// neighbours in the partition formed by all of the buckets. The violations recorded with the Validator say which
+// bound should be changed, and to what. The prefix is prepended to the names of their fields.
+func Validate(v *validation.Validator, n Names, buckets []Bucket, i int, prefix string) {
+	b := buckets[i]
+	if b.Min != nil && b.Max != nil && *b.Min > *b.Max {
+		v.Add(prefix+n.Max, validation.CodeMinExceedsMax, "is %d but should not be less than %s %d", *b.Max, n.Min, *b.Min)
+		return
+	}
+
+	for _, c := range Conflicts(buckets) {
+		switch i {
+		case c.Upper:
+			lower := buckets[c.Lower]
+			msg := fmt.Sprintf("overlaps %s %q, which has no %s", n.Kind, lower.Title, n.Max)
+			if lower.Max != nil {
+				msg = fmt.Sprintf("should be %d to follow %s %q", int32(*lower.Max)+1, n.Kind, lower.Title)
+			}
+			v.Add(prefix+n.Min, validation.CodeConflict, "%s", msg)
+		case c.Lower:
+			upper := buckets[c.Upper]
+			msg := fmt.Sprintf("overlaps %s %q, which has no %s", n.Kind, upper.Title, n.Min)
+			if upper.Min != nil {
+				msg = fmt.Sprintf("should be %d to precede %s %q", int32(*upper.Min)-1, n.Kind, upper.Title)
+			}
+			v.Add(prefix+n.Max, validation.CodeConflict, "%s", msg)
+		}
+	}
+}
+
+// partition returns the indexes of the bounded Buckets, ordered by their ranges. An open Min sorts first and an
+// open Max sorts last.
+func partition(buckets []Bucket) []int {
+	var order []int
+	for i, b := range buckets {
+		if b.bounded() {
+			order = append(order, i)
+		}
+	}
+
+	sort.SliceStable(order, func(x, y int) bool {
+		a, b := buckets[order[x]], buckets[order[y]]
+		if cmp := compare(a.Min, b.Min, -1); cmp != 0 {
+			return cmp < 0
+		}
+		return compare(a.Max, b.Max, 1) < 0
+	})
+	return order
+}
+
+// compare orders two bounds, where a nil bound is ordered by open: -1 before every value and 1 after.
+func compare(a, b *int16, open int) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return open
+	case b == nil:
+		return -open
+	case *a < *b:
+		return -1
+	case *a > *b:
+		return 1
+	}
+	return 0
+}
+
+// adjoins reports whether the upper Bucket starts right after the lower Bucket ends.
+func adjoins(lower, upper Bucket) bool {
+	if lower.Max == nil || upper.Min == nil {
+		return false
+	}
+	return int32(*upper.Min) == int32(*lower.Max)+1
+}
@@ -0,0 +1,152 @@
+package bucket_test
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/bucket"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var names = bucket.Names{Kind: "size", Min: "minPages", Max: "maxPages"}
+
+// sizes mirrors the seeded Sizes: an unbounded "Any" followed by a contiguous partition.
+func sizes() []bucket.Bucket {
+	return []bucket.Bucket{
+		{Title: "Any"},
+		{Title: "Short story", Max: util.Int16Ptr(34)},
+		{Title: "Novelette", Min: util.Int16Ptr(35), Max: util.Int16Ptr(84)},
+		{Title: "Novella", Min: util.Int16Ptr(85), Max: util.Int16Ptr(199)},
+		{Title: "Novel", Min: util.Int16Ptr(200)},
+	}
+}
+
+func TestConflicts(t *testing.T) {
+
+	tests := map[string]struct {
+		modify func([]bucket.Bucket) []bucket.Bucket
+		expect []bucket.Conflict
+	}{
+		"contiguous partition": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket { return b },
+		},
+		"unordered partition": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				return []bucket.Bucket{b[4], b[2], b[0], b[3], b[1]}
+			},
+		},
+		"overlap": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				b[3].Min = util.Int16Ptr(80)
+				return b
+			},
+			expect: []bucket.Conflict{{Lower: 2, Upper: 3}},
+		},
+		"gap": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				b[4].Min = util.Int16Ptr(250)
+				return b
+			},
+			expect: []bucket.Conflict{{Lower: 3, Upper: 4}},
+		},
+		"open ranges overlap": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				return append(b, bucket.Bucket{Title: "Epic", Min: util.Int16Ptr(1000)})
+			},
+			expect: []bucket.Conflict{{Lower: 4, Upper: 5}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, bucket.Conflicts(tt.modify(sizes())))
+		})
+	}
+}
+
+func TestRemoval(t *testing.T) {
+
+	tests := map[string]struct {
+		index  int
+		expect *bucket.Conflict
+	}{
+		"unbounded bucket": {
+			index: 0,
+		},
+		"first bucket": {
+			index: 1,
+		},
+		"last bucket": {
+			index: 4,
+		},
+		"middle bucket leaves a gap": {
+			index:  2,
+			expect: &bucket.Conflict{Lower: 1, Upper: 3},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, bucket.Removal(sizes(), tt.index))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	tests := map[string]struct {
+		modify func([]bucket.Bucket) []bucket.Bucket
+		index  int
+		prefix string
+		expect []entity.FieldError
+	}{
+		"valid bucket": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket { return b },
+			index:  3,
+		},
+		"inverted range": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				b[3].Max = util.Int16Ptr(10)
+				return b
+			},
+			index:  3,
+			expect: []entity.FieldError{{Field: "maxPages", Code: validation.CodeMinExceedsMax, Message: "is 10 but should not be less than minPages 85"}},
+		},
+		"overlaps both neighbours": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				b[3].Min, b[3].Max = util.Int16Ptr(80), util.Int16Ptr(210)
+				return b
+			},
+			index: 3,
+			expect: []entity.FieldError{
+				{Field: "minPages", Code: validation.CodeConflict, Message: `should be 85 to follow size "Novelette"`},
+				{Field: "maxPages", Code: validation.CodeConflict, Message: `should be 199 to precede size "Novel"`},
+			},
+		},
+		"conflicts of other buckets are ignored": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				b[4].Min = util.Int16Ptr(250)
+				return b
+			},
+			index: 2,
+		},
+		"open range with prefix": {
+			modify: func(b []bucket.Bucket) []bucket.Bucket {
+				return append(b, bucket.Bucket{Title: "Epic", Min: util.Int16Ptr(1000)})
+			},
+			index:  5,
+			prefix: "[5].",
+			expect: []entity.FieldError{{Field: "[5].minPages", Code: validation.CodeConflict, Message: `overlaps size "Novel", which has no maxPages`}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := validation.New(entity.ErrInvalidEntity)
+			bucket.Validate(v, names, tt.modify(sizes()), tt.index, tt.prefix)
+			assert.Equal(t, tt.expect, v.Fields())
+		})
+	}
+}
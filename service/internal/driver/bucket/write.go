@@ -0,0 +1,178 @@
+package bucket
+
+import (
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+)
+
+// AnyID is the ID of the "Any" Bucket, which the migrations seed for both Eras and Sizes. It has no bounds, so it
+// matches every value, and searches rely on it. It can therefore be neither bounded, deleted, nor left out of a
+// replacement.
+const AnyID int32 = 0
+
+// Input holds the attributes of a Bucket to be written, such as those of an era.WriteInput. The Title is
+// required.
+type Input struct {
+	Title *string
+	Min   *int16
+	Max   *int16
+}
+
+// Stored is a Bucket which has been written, with its ID.
+type Stored struct {
+	ID int32
+
+	Bucket
+}
+
+// Replacement is an element of a set which replaces every Stored Bucket. If the ID is nil, then a new Bucket is
+// created from the Input. Otherwise the Stored Bucket with the ID is replaced with it.
+type Replacement struct {
+	ID *int32
+
+	Input
+}
+
+// CheckCreate checks the Input of a new Bucket against the Stored ones. If it is invalid, then an
+// *entity.ValidationError is returned.
+func CheckCreate(n Names, stored []Stored, in Input) error {
+	v := validation.New(entity.ErrInvalidEntity)
+	buckets := append(toBuckets(stored), in.bucket())
+	title(v, in, "")
+	Validate(v, n, buckets, len(buckets)-1, "")
+	return v.Err()
+}
+
+// CheckUpdate checks the Input which replaces the Stored Bucket with the ID against the other Stored ones. If
+// the Bucket does not exist, then an error wrapping entity.ErrNotFound is returned. If the Input is invalid,
+// then an *entity.ValidationError is returned.
+func CheckUpdate(n Names, stored []Stored, id int32, in Input) error {
+	i := indexOf(stored, id)
+	if i < 0 {
+		return fmt.Errorf("%w: %s %d does not exist", entity.ErrNotFound, n.Kind, id)
+	}
+
+	v := validation.New(entity.ErrInvalidEntity)
+	title(v, in, "")
+	if unbounded(v, n, id, in, "") {
+		buckets := toBuckets(stored)
+		buckets[i] = in.bucket()
+		Validate(v, n, buckets, i, "")
+	}
+	return v.Err()
+}
+
+// CheckDelete checks that the Stored Bucket with the ID may be deleted. If it does not exist, then an error
+// wrapping entity.ErrNotFound is returned. If it is the "Any" Bucket, or deleting it would leave a gap between
+// the ranges of the remaining Buckets, then an error wrapping entity.ErrConflict is returned instead.
+func CheckDelete(n Names, stored []Stored, id int32) error {
+	i := indexOf(stored, id)
+	if i < 0 {
+		return fmt.Errorf("%w: %s %d does not exist", entity.ErrNotFound, n.Kind, id)
+	}
+	if id == AnyID {
+		return fmt.Errorf("%w: %s %d matches every value and cannot be deleted", entity.ErrConflict, n.Kind, id)
+	}
+
+	if c := Removal(toBuckets(stored), i); c != nil {
+		return fmt.Errorf("%w: deleting %s %d would leave a gap between %s %q and %s %q",
+			entity.ErrConflict, n.Kind, id, n.Kind, stored[c.Lower].Title, n.Kind, stored[c.Upper].Title)
+	}
+	return nil
+}
+
+// CheckReplaceAll checks a set of Replacements for every Stored Bucket. If the set is empty, then an error
+// wrapping entity.ErrInvalidBody is returned. If it leaves out the "Any" Bucket, then an error wrapping
+// entity.ErrConflict is returned. If any Replacement is invalid, then an *entity.ValidationError is returned,
+// whose fields are prefixed by the index of the invalid Replacement, such as "[1].minYear".
+func CheckReplaceAll(n Names, stored []Stored, replacements []Replacement) error {
+	if len(replacements) == 0 {
+		return fmt.Errorf("%w: at least one %s is required", entity.ErrInvalidBody, n.Kind)
+	}
+
+	buckets := make([]Bucket, len(replacements))
+	for i, r := range replacements {
+		buckets[i] = r.bucket()
+		if r.ID != nil && *r.ID == AnyID {
+			// Bounds given to the "Any" Bucket are reported by unbounded, so they take no part in the partition.
+			buckets[i] = Bucket{Title: buckets[i].Title}
+		}
+	}
+
+	v := validation.New(entity.ErrInvalidEntity)
+	seen := map[int32]bool{}
+	for i, r := range replacements {
+		prefix := fmt.Sprintf("[%d].", i)
+		if r.ID != nil {
+			switch {
+			case indexOf(stored, *r.ID) < 0:
+				v.Add(prefix+"id", validation.CodeNotFound, "%s %d does not exist", n.Kind, *r.ID)
+			case seen[*r.ID]:
+				v.Add(prefix+"id", validation.CodeConflict, "%s %d is repeated", n.Kind, *r.ID)
+			}
+			seen[*r.ID] = true
+		}
+
+		title(v, r.Input, prefix)
+		if r.ID == nil || unbounded(v, n, *r.ID, r.Input, prefix) {
+			Validate(v, n, buckets, i, prefix)
+		}
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	if indexOf(stored, AnyID) >= 0 && !seen[AnyID] {
+		return fmt.Errorf("%w: %s %d matches every value and cannot be left out", entity.ErrConflict, n.Kind, AnyID)
+	}
+	return nil
+}
+
+// title checks that the Input has a title which is not blank. The prefix is prepended to the name of the field.
+func title(v *validation.Validator, in Input, prefix string) {
+	v.Required(prefix+"title", in.Title != nil)
+	v.NotBlank(prefix+"title", in.Title)
+}
+
+// unbounded checks that the Input leaves both ends of its range open if it is written to the "Any" Bucket. It
+// returns false if either end is bounded, so that the range is not checked against the other Buckets as well.
+func unbounded(v *validation.Validator, n Names, id int32, in Input, prefix string) bool {
+	if id != AnyID {
+		return true
+	}
+
+	ok := v.Check(in.Min == nil, prefix+n.Min, validation.CodeConflict,
+		"must be omitted, as %s %d matches every value", n.Kind, AnyID)
+	return v.Check(in.Max == nil, prefix+n.Max, validation.CodeConflict,
+		"must be omitted, as %s %d matches every value", n.Kind, AnyID) && ok
+}
+
+// bucket maps the Input to a Bucket.
+func (in Input) bucket() Bucket {
+	b := Bucket{Min: in.Min, Max: in.Max}
+	if in.Title != nil {
+		b.Title = *in.Title
+	}
+	return b
+}
+
+// toBuckets returns the Buckets of the Stored ones, by index.
+func toBuckets(stored []Stored) []Bucket {
+	buckets := make([]Bucket, len(stored))
+	for i, s := range stored {
+		buckets[i] = s.Bucket
+	}
+	return buckets
+}
+
+// indexOf returns the index of the Stored Bucket with the ID, or -1 if there is none.
+func indexOf(stored []Stored, id int32) int {
+	for i, s := range stored {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
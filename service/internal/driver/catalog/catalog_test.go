@@ -0,0 +1,568 @@
+package catalog_test
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type inMemoryRepository struct {
+	records []catalog.Record
+	upsert  bool
+}
+
+func (r *inMemoryRepository) Import(_ context.Context, records catalog.Source, upsert bool) (catalog.Summary, error) {
+	r.upsert = upsert
+	for records.Next() {
+		r.records = append(r.records, records.Record())
+	}
+	if err := records.Err(); err != nil {
+		r.records = nil
+		return catalog.Summary{}, err
+	}
+	return catalog.Summary{Created: int64(len(r.records))}, nil
+}
+
+// readAll reads every Record of the catalog, along with every LineError.
+func readAll(t *testing.T, in, format string) ([]catalog.Record, []*catalog.LineError) {
+	t.Helper()
+
+	r, err := catalog.NewReader(strings.NewReader(in), format)
+	require.NoError(t, err)
+
+	var (
+		records []catalog.Record
+		invalid []*catalog.LineError
+	)
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, invalid
+		}
+		var lineErr *catalog.LineError
+		if errors.As(err, &lineErr) {
+			invalid = append(invalid, lineErr)
+			continue
+		}
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestNewReader(t *testing.T) {
+
+	tests := map[string]struct {
+		in           string
+		format       string
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"csv": {
+			in:           "title,author_first_name,author_last_name,genre,year_published,pages,rating\n",
+			format:       catalog.FormatCSV,
+			errAssertion: assert.NoError,
+		},
+		"csv with a byte order mark and extra columns": {
+			in:           "\ufeffid,title,author_first_name,author_last_name,genre,year_published,pages,rating\n",
+			format:       catalog.FormatCSV,
+			errAssertion: assert.NoError,
+		},
+		"csv lacks columns": {
+			in:           "title,author_first_name,author_last_name,genre\n",
+			format:       catalog.FormatCSV,
+			errAssertion: assert.Error,
+		},
+		"csv repeats a column": {
+			in:     "title,author_first_name,author_last_name,genre,year_published,pages,rating,title\n",
+			format: catalog.FormatCSV,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.EqualError(t, err, `header row names the column "title" more than once`)
+			},
+		},
+		"csv is empty": {
+			in:           "",
+			format:       catalog.FormatCSV,
+			errAssertion: assert.Error,
+		},
+		"jsonl": {
+			in:           "",
+			format:       catalog.FormatJSONL,
+			errAssertion: assert.NoError,
+		},
+		"json": {
+			in:           "\ufeff [\n",
+			format:       catalog.FormatJSON,
+			errAssertion: assert.NoError,
+		},
+		"json is not an array": {
+			in:           `{"title": "Dune"}`,
+			format:       catalog.FormatJSON,
+			errAssertion: assert.Error,
+		},
+		"json is empty": {
+			in:           "",
+			format:       catalog.FormatJSON,
+			errAssertion: assert.Error,
+		},
+		"unknown format": {
+			in:           "",
+			format:       "xml",
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := catalog.NewReader(strings.NewReader(tt.in), tt.format)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestReader_Read(t *testing.T) {
+
+	dune := catalog.Record{
+		Title:           "Dune",
+		YearPublished:   1965,
+		Rating:          4.5,
+		Pages:           412,
+		AuthorFirstName: "Frank",
+		AuthorLastName:  "Herbert",
+		Genre:           "Science Fiction",
+	}
+	at := func(rec catalog.Record, line int) catalog.Record {
+		rec.Line = line
+		return rec
+	}
+
+	tests := map[string]struct {
+		in      string
+		format  string
+		expect  []catalog.Record
+		invalid map[int][]string
+	}{
+		"csv": {
+			format: catalog.FormatCSV,
+			in: "title,author_first_name,author_last_name,genre,year_published,pages,rating\n" +
+				"Dune,Frank,Herbert,Science Fiction,1965,412,4.5\n" +
+				"\n" +
+				"\"Dune, Again\",Frank,Herbert,Science Fiction,1965,412,4.5",
+			expect: []catalog.Record{at(dune, 2), func() catalog.Record {
+				rec := at(dune, 4)
+				rec.Title = "Dune, Again"
+				return rec
+			}()},
+		},
+		"csv with a line break in a quoted field": {
+			format: catalog.FormatCSV,
+			in: "title,author_first_name,author_last_name,genre,year_published,pages,rating\r\n" +
+				"\"Dune\nMessiah\",Frank,Herbert,Science Fiction,1965,412,4.5\r\n" +
+				"Dune,Frank,Herbert,Science Fiction,1965,412,four\r\n",
+			expect: []catalog.Record{func() catalog.Record {
+				rec := at(dune, 2)
+				rec.Title = "Dune\nMessiah"
+				return rec
+			}()},
+			invalid: map[int][]string{4: {"rating"}},
+		},
+		"csv with invalid rows": {
+			format: catalog.FormatCSV,
+			in: "title,author_first_name,author_last_name,genre,year_published,pages,rating\n" +
+				",Frank,Herbert,Science Fiction,1700,0,4.5\n" +
+				"Dune,Frank,Herbert\n" +
+				"Dune,Frank,Herbert,Science Fiction,1965,412,4.5\n",
+			expect:  []catalog.Record{at(dune, 4)},
+			invalid: map[int][]string{2: {"title", "year_published", "pages"}, 3: {""}},
+		},
+		"jsonl": {
+			format: catalog.FormatJSONL,
+			in: `{"title":"Dune","yearPublished":1965,"rating":4.5,"pages":412,` +
+				`"author":{"id":1,"firstName":"Frank","lastName":"Herbert"},"genre":{"id":2,"title":"Science Fiction"}}` + "\n" +
+				"\n" +
+				`{"title":"Dune","yearPublished":"1965","rating":6,"author":{"firstName":"Frank","lastName":"Herbert"},` +
+				`"genre":{"title":"Science Fiction"}}` + "\n" +
+				`{"title":` + "\n",
+			expect:  []catalog.Record{at(dune, 1)},
+			invalid: map[int][]string{3: {"yearPublished", "pages", "rating"}, 4: {""}},
+		},
+		"json": {
+			format: catalog.FormatJSON,
+			in: "[\n" +
+				`  {"title": "Dune", "yearPublished": 1965, "rating": 4.5, "pages": 412,` + "\n" +
+				`   "author": {"firstName": "Frank", "lastName": "Herbert"}, "genre": {"title": "Science Fiction"}},` + "\n" +
+				`  {"title": "Dune", "yearPublished": 1965, "rating": 4.5, "pages": 412, "author": {}, "genre": {}},` + "\n" +
+				"\n" +
+				`  {` + "\n" +
+				`    "title": "Dune", "yearPublished": 1965, "rating": 4.5, "pages": 412,` + "\n" +
+				`    "author": {"firstName": "Frank", "lastName": "Herbert"}, "genre": {"title": "Science Fiction"}` + "\n" +
+				"  }\n" +
+				"]\n",
+			expect:  []catalog.Record{at(dune, 2), at(dune, 6)},
+			invalid: map[int][]string{4: {"author.firstName", "author.lastName", "genre.title"}},
+		},
+		"json is malformed": {
+			format: catalog.FormatJSON,
+			in: "[\n" +
+				`  {"title": "Dune", "yearPublished": 1965, "rating": 4.5, "pages": 412,` +
+				`"author": {"firstName": "Frank", "lastName": "Herbert"}, "genre": {"title": "Science Fiction"}},` + "\n" +
+				`  {"title": }` + "\n" +
+				`  {"title": "Never read"}` + "\n" +
+				"]\n",
+			expect:  []catalog.Record{at(dune, 2)},
+			invalid: map[int][]string{3: {""}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			records, invalid := readAll(t, tt.in, tt.format)
+			assert.Equal(t, tt.expect, records)
+
+			actual := make(map[int][]string)
+			for _, lineErr := range invalid {
+				for _, f := range lineErr.Fields {
+					actual[lineErr.Line] = append(actual[lineErr.Line], f.Field)
+				}
+			}
+			for line, fields := range tt.invalid {
+				assert.ElementsMatch(t, fields, actual[line], "invalid fields of line %d", line)
+			}
+			assert.Len(t, actual, len(tt.invalid))
+		})
+	}
+}
+
+func TestDriver_Import(t *testing.T) {
+
+	const (
+		header  = "title,author_first_name,author_last_name,genre,year_published,pages,rating\n"
+		valid   = "Dune,Frank,Herbert,Science Fiction,1965,412,4.5\n"
+		invalid = "Dune,Frank,Herbert,Science Fiction,1965,412,9\n"
+	)
+
+	tests := map[string]struct {
+		in           string
+		opts         catalog.Options
+		expect       catalog.Summary
+		imported     int
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"imports every record": {
+			in:           header + valid + valid,
+			opts:         catalog.Options{Upsert: true},
+			expect:       catalog.Summary{Records: 2, Created: 2},
+			imported:     2,
+			errAssertion: assert.NoError,
+		},
+		"dry run imports nothing": {
+			in:           header + valid,
+			opts:         catalog.Options{DryRun: true},
+			expect:       catalog.Summary{Records: 1},
+			errAssertion: assert.NoError,
+		},
+		"invalid records import nothing": {
+			in:     header + invalid + valid + invalid,
+			expect: catalog.Summary{Records: 1},
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				var importErr *catalog.ImportError
+				return assert.True(t, errors.As(err, &importErr)) &&
+					assert.Len(t, importErr.Lines, 2) &&
+					assert.True(t, errors.Is(err, entity.ErrInvalidEntity))
+			},
+		},
+		"dry run reports invalid records": {
+			in:     header + valid + invalid,
+			opts:   catalog.Options{DryRun: true},
+			expect: catalog.Summary{Records: 1},
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.True(t, errors.Is(err, entity.ErrInvalidEntity))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := catalog.NewReader(strings.NewReader(tt.in), catalog.FormatCSV)
+			require.NoError(t, err)
+
+			var repo inMemoryRepository
+			summary, err := catalog.NewDriver(&repo).Import(context.Background(), r, tt.opts)
+			tt.errAssertion(t, err)
+
+			errs := summary.Errors
+			summary.Errors = nil
+			assert.Equal(t, tt.expect, summary)
+			if err != nil {
+				assert.NotEmpty(t, errs)
+			}
+			assert.Len(t, repo.records, tt.imported)
+			assert.Equal(t, tt.opts.Upsert && !tt.opts.DryRun, repo.upsert)
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+
+	books := []entity.Book{
+		{
+			ID:            1,
+			Title:         "Dune, Part One",
+			YearPublished: 1965,
+			Rating:        4.25,
+			Pages:         412,
+			Genre:         entity.Genre{ID: 4, Title: "Sci-Fi"},
+			Author:        entity.Author{ID: 5, FirstName: "Frank", LastName: "Herbert"},
+		},
+		{
+			ID:            2,
+			Title:         "Eye of the World",
+			YearPublished: 1990,
+			Rating:        4.5,
+			Pages:         782,
+			Genre:         entity.Genre{ID: 2, Title: "Fantasy"},
+			Author:        entity.Author{ID: 3, FirstName: "Robert", LastName: "Jordan"},
+		},
+	}
+
+	tests := map[string]struct {
+		format string
+		books  []entity.Book
+		expect string
+	}{
+		"csv": {
+			format: catalog.FormatCSV,
+			books:  books,
+			expect: "id,title,author_id,author_first_name,author_last_name,genre_id,genre,year_published,pages,rating\n" +
+				"1,\"Dune, Part One\",5,Frank,Herbert,4,Sci-Fi,1965,412,4.25\n" +
+				"2,Eye of the World,3,Robert,Jordan,2,Fantasy,1990,782,4.50\n",
+		},
+		"csv without books": {
+			format: catalog.FormatCSV,
+			expect: "id,title,author_id,author_first_name,author_last_name,genre_id,genre,year_published,pages,rating\n",
+		},
+		"json": {
+			format: catalog.FormatJSON,
+			books:  books,
+			expect: "[\n  {\n    \"id\": 1,\n    \"title\": \"Dune, Part One\",\n    \"yearPublished\": 1965,\n" +
+				"    \"rating\": 4.25,\n    \"pages\": 412,\n    \"genre\": {\n      \"id\": 4,\n      \"title\": \"Sci-Fi\"\n    },\n" +
+				"    \"author\": {\n      \"id\": 5,\n      \"firstName\": \"Frank\",\n      \"lastName\": \"Herbert\"\n    }\n  },\n" +
+				"  {\n    \"id\": 2,\n    \"title\": \"Eye of the World\",\n    \"yearPublished\": 1990,\n" +
+				"    \"rating\": 4.5,\n    \"pages\": 782,\n    \"genre\": {\n      \"id\": 2,\n      \"title\": \"Fantasy\"\n    },\n" +
+				"    \"author\": {\n      \"id\": 3,\n      \"firstName\": \"Robert\",\n      \"lastName\": \"Jordan\"\n    }\n  }\n]\n",
+		},
+		"json without books": {
+			format: catalog.FormatJSON,
+			expect: "[]\n",
+		},
+		"jsonl": {
+			format: catalog.FormatJSONL,
+			books:  books[:1],
+			expect: `{"id":1,"title":"Dune, Part One","yearPublished":1965,"rating":4.25,"pages":412,` +
+				`"genre":{"id":4,"title":"Sci-Fi"},"author":{"id":5,"firstName":"Frank","lastName":"Herbert"}}` + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf strings.Builder
+			w, err := catalog.NewWriter(&buf, tt.format)
+			require.NoError(t, err)
+			for _, b := range tt.books {
+				require.NoError(t, w.Write(b))
+			}
+			require.NoError(t, w.Close())
+			assert.Equal(t, tt.expect, buf.String())
+
+			// Every catalog that is written can be read back.
+			records, invalid := readAll(t, buf.String(), tt.format)
+			assert.Empty(t, invalid)
+			require.Len(t, records, len(tt.books))
+			for i, rec := range records {
+				b := tt.books[i]
+				assert.Equal(t, b.Title, rec.Title)
+				assert.Equal(t, b.Author.FirstName+" "+b.Author.LastName, rec.AuthorFirstName+" "+rec.AuthorLastName)
+				assert.Equal(t, b.Genre.Title, rec.Genre)
+				assert.Equal(t, b.Rating, rec.Rating)
+			}
+		})
+	}
+
+	_, err := catalog.NewWriter(&strings.Builder{}, "xml")
+	assert.Error(t, err)
+}
+
+// generateAll generates every Record of the catalog described by the GenerateOptions.
+func generateAll(t *testing.T, opts catalog.GenerateOptions) []catalog.Record {
+	t.Helper()
+
+	g, err := catalog.NewGenerator(opts)
+	require.NoError(t, err)
+
+	var records []catalog.Record
+	for g.Next() {
+		records = append(records, g.Record())
+	}
+	require.NoError(t, g.Err())
+	return records
+}
+
+func TestNewGenerator(t *testing.T) {
+
+	tests := map[string]struct {
+		opts   catalog.GenerateOptions
+		fields []string
+	}{
+		"valid": {
+			opts: catalog.GenerateOptions{Books: 10, Authors: 10, Genres: 1},
+		},
+		"no books": {
+			opts:   catalog.GenerateOptions{Books: 0, Authors: 1, Genres: 1},
+			fields: []string{"books", "authors", "genres"},
+		},
+		"no authors or genres": {
+			opts:   catalog.GenerateOptions{Books: 10, Authors: 0, Genres: -1},
+			fields: []string{"authors", "genres"},
+		},
+		"more authors than books": {
+			opts:   catalog.GenerateOptions{Books: 10, Authors: 11, Genres: 2},
+			fields: []string{"authors"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			g, err := catalog.NewGenerator(tt.opts)
+			if tt.fields == nil {
+				assert.NoError(t, err)
+				assert.NotNil(t, g)
+				return
+			}
+
+			var validationErr *entity.ValidationError
+			require.True(t, errors.As(err, &validationErr))
+			assert.True(t, errors.Is(err, entity.ErrInvalidEntity))
+
+			var fields []string
+			for _, f := range validationErr.Fields {
+				fields = append(fields, f.Field)
+			}
+			assert.Equal(t, tt.fields, fields)
+		})
+	}
+}
+
+func TestGenerator(t *testing.T) {
+
+	int16Ptr := func(v int16) *int16 { return &v }
+	opts := catalog.GenerateOptions{
+		Books:   5000,
+		Authors: 800,
+		Genres:  30,
+		Seed:    42,
+		YearRanges: []book.Range{
+			{Max: int16Ptr(1849)},
+			{Min: int16Ptr(1850), Max: int16Ptr(1899)},
+			{Min: int16Ptr(2024)},
+		},
+		PageRanges: []book.Range{
+			{Max: int16Ptr(9)},
+			{Min: int16Ptr(5000)},
+		},
+	}
+	records := generateAll(t, opts)
+	require.Len(t, records, opts.Books)
+
+	t.Run("deterministic", func(t *testing.T) {
+		assert.Equal(t, records, generateAll(t, opts))
+
+		reseeded := opts
+		reseeded.Seed++
+		assert.NotEqual(t, records, generateAll(t, reseeded))
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		for i, rec := range records {
+			assert.Equal(t, i+1, rec.Line)
+			assert.NotEmpty(t, rec.Title)
+			assert.True(t, rec.YearPublished >= book.MinYearPublished && rec.YearPublished <= catalog.LatestGeneratedYear,
+				"year %d of line %d", rec.YearPublished, rec.Line)
+			assert.True(t, rec.Pages >= book.MinPages && rec.Pages <= book.MaxPages, "pages %d of line %d", rec.Pages, rec.Line)
+			assert.True(t, rec.Rating >= book.MinRating && rec.Rating <= book.MaxRating, "rating %g of line %d", rec.Rating, rec.Line)
+		}
+	})
+
+	t.Run("every author and genre has books", func(t *testing.T) {
+		authors := map[string]int{}
+		genres := map[string]int{}
+		for _, rec := range records {
+			authors[rec.AuthorFirstName+" "+rec.AuthorLastName]++
+			genres[rec.Genre]++
+		}
+		assert.Len(t, authors, opts.Authors)
+		assert.Len(t, genres, opts.Genres)
+
+		// The books per author follow a power law: the most prolific tenth of authors write most of the books.
+		counts := make([]int, 0, len(authors))
+		for _, n := range authors {
+			counts = append(counts, n)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+		var prolific int
+		for _, n := range counts[:len(counts)/10] {
+			prolific += n
+		}
+		assert.Greater(t, prolific, opts.Books/2)
+		assert.Greater(t, counts[0], 10*counts[len(counts)/2])
+	})
+
+	t.Run("every range has books", func(t *testing.T) {
+		within := func(r book.Range, v int16) bool {
+			return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+		}
+		for _, r := range opts.YearRanges {
+			assert.True(t, within(r, records[0].YearPublished) || within(r, records[1].YearPublished) ||
+				within(r, records[2].YearPublished))
+		}
+		for _, r := range opts.PageRanges {
+			assert.True(t, within(r, records[0].Pages) || within(r, records[1].Pages))
+		}
+	})
+
+	t.Run("ratings are skewed", func(t *testing.T) {
+		var sum float64
+		var low int
+		for _, rec := range records {
+			sum += float64(rec.Rating)
+			if rec.Rating < 2.5 {
+				low++
+			}
+		}
+		assert.InDelta(t, 3.8, sum/float64(len(records)), 0.1)
+		assert.Less(t, low, len(records)/10)
+	})
+}
+
+func TestDriver_Generate(t *testing.T) {
+
+	var repo inMemoryRepository
+	d := catalog.NewDriver(&repo)
+
+	summary, err := d.Generate(context.Background(), catalog.GenerateOptions{Books: 20, Authors: 5, Genres: 3, Seed: 1})
+	require.NoError(t, err)
+	assert.Equal(t, catalog.Summary{Records: 20, Created: 20}, summary)
+	assert.Len(t, repo.records, 20)
+	assert.False(t, repo.upsert)
+
+	repo = inMemoryRepository{}
+	_, err = d.Generate(context.Background(), catalog.GenerateOptions{Books: 1, Authors: 2, Genres: 1})
+	assert.True(t, errors.Is(err, entity.ErrInvalidEntity))
+	assert.Empty(t, repo.records)
+}
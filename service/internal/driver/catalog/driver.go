@@ -0,0 +1,134 @@
+// Package catalog imports books in bulk, along with their authors and genres, from CSV, JSON or JSON Lines files,
+// exports them to the same formats, and generates synthetic catalogs. Authors and genres are named rather than
+// identified, so that files do not depend on the IDs of a database.
+package catalog
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Options are the options of an Import.
+type Options struct {
+	// DryRun only validates the Records, and writes nothing.
+	DryRun bool
+
+	// Upsert updates the Book with the title and Author of a Record, if there is one, rather than inserting
+	// another.
+	Upsert bool
+}
+
+// Summary describes the outcome of an Import.
+type Summary struct {
+	// Records is the number of valid Records which were read.
+	Records int
+
+	// Created and Updated are the numbers of Books which were inserted and updated.
+	Created int64
+	Updated int64
+
+	// AuthorsCreated and GenresCreated are the numbers of Authors and Genres which were created, since no
+	// existing one had the name of a Record.
+	AuthorsCreated int64
+	GenresCreated  int64
+
+	// Errors holds every invalid Record, in the order they were read.
+	Errors []*LineError
+}
+
+type driver struct {
+	repository Repository
+}
+
+// NewDriver creates a driver which wraps the repository. The wrapper
+// will perform business logic against the usecases of importing a catalog.
+func NewDriver(r Repository) *driver {
+	return &driver{repository: r}
+}
+
+// Import reads and validates every Record of the Reader. Unless opts.DryRun is set, the valid Records are
+// streamed to the repository as they are read, which imports them in a single transaction. If any Record is
+// invalid, then every LineError is listed in the Summary and an *ImportError is returned, in which case nothing
+// is imported. Other errors, such as those reading the file, are returned as they are.
+func (d *driver) Import(ctx context.Context, r *Reader, opts Options) (Summary, error) {
+	src := &validSource{reader: r}
+
+	var (
+		summary Summary
+		err     error
+	)
+	if opts.DryRun {
+		for src.Next() {
+		}
+		err = src.Err()
+	} else {
+		summary, err = d.repository.Import(ctx, src, opts.Upsert)
+	}
+
+	summary.Records = src.records
+	summary.Errors = src.invalid
+	return summary, err
+}
+
+// Generate imports a synthetic catalog, which a Generator generates from the GenerateOptions. Since every
+// generated Record is valid, the Summary has no Errors. If the GenerateOptions are invalid, then an
+// *entity.ValidationError is returned and nothing is imported.
+func (d *driver) Generate(ctx context.Context, opts GenerateOptions) (Summary, error) {
+	gen, err := NewGenerator(opts)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary, err := d.repository.Import(ctx, gen, false)
+	summary.Records = gen.generated
+	return summary, err
+}
+
+// validSource is a Source over the valid Records of a Reader. Invalid Records are skipped and collected, so that
+// every one of them is reported. Once the Reader is exhausted, Err returns an *ImportError if any was invalid.
+type validSource struct {
+	reader  *Reader
+	current Record
+	records int
+	invalid []*LineError
+	err     error
+}
+
+// Next reads Records until a valid one is found, and reports whether there is one.
+func (s *validSource) Next() bool {
+	for s.err == nil {
+		rec, err := s.reader.Read()
+		var lineErr *LineError
+		switch {
+		case err == io.EOF:
+			return false
+		case errors.As(err, &lineErr):
+			s.invalid = append(s.invalid, lineErr)
+		case err != nil:
+			s.err = err
+		default:
+			s.current = rec
+			s.records++
+			return true
+		}
+	}
+	return false
+}
+
+// Record returns the valid Record which Next advanced to.
+func (s *validSource) Record() Record {
+	return s.current
+}
+
+// Err returns the error which stopped the Reader, or an *ImportError listing every invalid Record.
+func (s *validSource) Err() error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.invalid) > 0 {
+		return &ImportError{Lines: s.invalid}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package catalog
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+)
+
+// LatestGeneratedYear is the latest year a generated Book is published in. It is fixed, rather than the current
+// year, so that a seed generates the same catalog whenever it is run.
+const LatestGeneratedYear int16 = 2025
+
+// The shapes of the distributions which generated Books are drawn from.
+const (
+	// authorSkew and genreSkew are the exponents of the power laws which Books are spread across Authors and
+	// Genres by, and authorOffset and genreOffset flatten their heads. Authors are skewed more heavily, so that
+	// a few are prolific and most have a handful of Books.
+	authorSkew   = 1.3
+	authorOffset = 10
+	genreSkew    = 1.1
+	genreOffset  = 5
+
+	// ratingShapeA and ratingShapeB are the shapes of the Kumaraswamy distribution of ratings, which skews
+	// them towards the upper end of the scale, with a mean of about 3.8.
+	ratingShapeA = 5
+	ratingShapeB = 2
+
+	// medianPages and pagesSpread are the median and the standard deviation of the logarithm of the
+	// log-normal distribution of page counts.
+	medianPages = 280
+	pagesSpread = 0.75
+
+	// meanAge is the mean number of years before LatestGeneratedYear that Books are published, which follows
+	// an exponential distribution. About a fifth of Books are published before 1970.
+	meanAge = 35
+)
+
+// GenerateOptions describe a synthetic catalog.
+type GenerateOptions struct {
+	// Books, Authors and Genres are the numbers of each to generate. Every Author and Genre has at least one
+	// Book, so neither may outnumber the Books.
+	Books   int
+	Authors int
+	Genres  int
+
+	// Seed seeds the random numbers which the catalog is drawn from. A Seed always generates the same catalog
+	// from the same options.
+	Seed int64
+
+	// YearRanges and PageRanges are the buckets of years and page counts, such as those of eras and sizes,
+	// which must each hold at least one Book. A Book is generated within each of them before any others.
+	YearRanges []book.Range
+	PageRanges []book.Range
+}
+
+// validate checks that the GenerateOptions describe a catalog which can be generated.
+func (o GenerateOptions) validate() error {
+	v := validation.New(entity.ErrInvalidEntity)
+	for _, f := range []struct {
+		name  string
+		value int
+	}{{"books", o.Books}, {"authors", o.Authors}, {"genres", o.Genres}} {
+		v.Check(f.value >= 1, f.name, validation.CodeOutOfRange, "is %d but should be greater than 0", f.value)
+	}
+	v.Check(o.Authors <= o.Books, "authors", validation.CodeConflict,
+		"is %d but should not be greater than books, which is %d", o.Authors, o.Books)
+	v.Check(o.Genres <= o.Books, "genres", validation.CodeConflict,
+		"is %d but should not be greater than books, which is %d", o.Genres, o.Books)
+	return v.Err()
+}
+
+// Generator is a Source of a synthetic catalog, which is generated one Record at a time so that catalogs of
+// any size can be streamed. Ratings are skewed towards good reviews, the number of Books per Author and Genre
+// follows a power law, and publication years and page counts spread across every era and size.
+type Generator struct {
+	opts GenerateOptions
+	rng  *rand.Rand
+
+	authors [][2]string
+	genres  []string
+
+	// authorRank and genreRank draw the Author and Genre of each Book once every one has a Book.
+	authorRank *rand.Zipf
+	genreRank  *rand.Zipf
+
+	// generated is the number of Records generated so far.
+	generated int
+	current   Record
+}
+
+// NewGenerator creates a Generator of the catalog described by the GenerateOptions. If they are invalid, then an
+// *entity.ValidationError is returned.
+func NewGenerator(opts GenerateOptions) (*Generator, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	g := &Generator{
+		opts:       opts,
+		rng:        rng,
+		authors:    authorNames(rng, opts.Authors),
+		genres:     genreNames(opts.Genres),
+		authorRank: rand.NewZipf(rng, authorSkew, authorOffset, uint64(opts.Authors-1)),
+		genreRank:  rand.NewZipf(rng, genreSkew, genreOffset, uint64(opts.Genres-1)),
+	}
+	return g, nil
+}
+
+// Next generates the next Record, and reports whether there is one.
+func (g *Generator) Next() bool {
+	if g.generated == g.opts.Books {
+		return false
+	}
+	i := g.generated
+	g.generated++
+
+	// The first Books give every Author, Genre, era and size a Book. The rest are drawn from the distributions.
+	author := i
+	if i >= len(g.authors) {
+		author = int(g.authorRank.Uint64())
+	}
+	genre := i
+	if i >= len(g.genres) {
+		genre = int(g.genreRank.Uint64())
+	}
+
+	year := g.year()
+	if i < len(g.opts.YearRanges) {
+		year = g.within(g.opts.YearRanges[i], book.MinYearPublished, LatestGeneratedYear)
+	}
+	pages := g.pages()
+	if i < len(g.opts.PageRanges) {
+		pages = g.within(g.opts.PageRanges[i], book.MinPages, book.MaxPages)
+	}
+
+	g.current = Record{
+		Line:            i + 1,
+		Title:           g.title(),
+		YearPublished:   year,
+		Rating:          g.rating(),
+		Pages:           pages,
+		AuthorFirstName: g.authors[author][0],
+		AuthorLastName:  g.authors[author][1],
+		Genre:           g.genres[genre],
+	}
+	return true
+}
+
+// Record returns the Record which Next generated.
+func (g *Generator) Record() Record {
+	return g.current
+}
+
+// Err returns nil, since generating a Record cannot fail.
+func (g *Generator) Err() error {
+	return nil
+}
+
+// rating draws a rating from a Kumaraswamy distribution, rounded to two decimal places.
+func (g *Generator) rating() float32 {
+	x := math.Pow(1-math.Pow(1-g.rng.Float64(), 1/float64(ratingShapeB)), 1/float64(ratingShapeA))
+	return float32(math.Round(x*float64(book.MaxRating)*100) / 100)
+}
+
+// pages draws a page count from a log-normal distribution.
+func (g *Generator) pages() int16 {
+	p := math.Round(math.Exp(math.Log(medianPages) + pagesSpread*g.rng.NormFloat64()))
+	return int16(math.Max(float64(book.MinPages), math.Min(p, float64(book.MaxPages))))
+}
+
+// year draws a publication year from an exponential distribution of ages.
+func (g *Generator) year() int16 {
+	y := float64(LatestGeneratedYear) - math.Floor(g.rng.ExpFloat64()*meanAge)
+	return int16(math.Max(float64(book.MinYearPublished), y))
+}
+
+// within draws a value uniformly from the book.Range. Its bounds are kept within [min,max], and an open upper
+// bound is taken to be twice its lower bound, so that values are not drawn from an implausibly wide Range.
+func (g *Generator) within(r book.Range, min, max int16) int16 {
+	lo, hi := min, max
+	if r.Min != nil && *r.Min > lo {
+		lo = *r.Min
+	}
+	if r.Max != nil && *r.Max < hi {
+		hi = *r.Max
+	} else if r.Max == nil && r.Min != nil && int(*r.Min)*2 < int(hi) {
+		hi = *r.Min * 2
+	}
+	if lo > hi {
+		return lo
+	}
+	return lo + int16(g.rng.Intn(int(hi-lo)+1))
+}
+
+// title composes a title from one of the titlePatterns.
+func (g *Generator) title() string {
+	pick := func(words []string) string { return words[g.rng.Intn(len(words))] }
+
+	switch g.rng.Intn(5) {
+	case 0:
+		return fmt.Sprintf("The %s %s", pick(titleAdjectives), pick(titleNouns))
+	case 1:
+		return fmt.Sprintf("%s of the %s %s", pick(titleNouns), pick(titleAdjectives), pick(titleNouns))
+	case 2:
+		return fmt.Sprintf("A %s in %s", pick(titleNouns), pick(titlePlaces))
+	case 3:
+		return fmt.Sprintf("%s %ss", pick(titleAdjectives), pick(titleNouns))
+	default:
+		return fmt.Sprintf("The %s of %s", pick(titleNouns), pick(titlePlaces))
+	}
+}
+
+// authorNames returns n distinct author names, as first and last names. The names are shuffled by the rng, and
+// once every combination has been used, the last names are numbered.
+func authorNames(rng *rand.Rand, n int) [][2]string {
+	firsts := append([]string(nil), firstNames...)
+	lasts := append([]string(nil), lastNames...)
+	rng.Shuffle(len(firsts), func(i, j int) { firsts[i], firsts[j] = firsts[j], firsts[i] })
+	rng.Shuffle(len(lasts), func(i, j int) { lasts[i], lasts[j] = lasts[j], lasts[i] })
+
+	names := make([][2]string, n)
+	combinations := len(firsts) * len(lasts)
+	for i := range names {
+		first := firsts[i%len(firsts)]
+		last := lasts[(i/len(firsts))%len(lasts)]
+		if i >= combinations {
+			last = fmt.Sprintf("%s %d", last, i/combinations+1)
+		}
+		names[i] = [2]string{first, last}
+	}
+	return names
+}
+
+// genreNames returns n distinct genre titles. Once every genre has been used, they are numbered.
+func genreNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = genreTitles[i%len(genreTitles)]
+		if i >= len(genreTitles) {
+			names[i] = fmt.Sprintf("%s %d", names[i], i/len(genreTitles)+1)
+		}
+	}
+	return names
+}
+
+// The words which generated titles and names are made of.
+var (
+	titleAdjectives = []string{"Silent", "Broken", "Hidden", "Last", "Golden", "Burning", "Forgotten", "Distant",
+		"Crimson", "Quiet", "Wandering", "Endless", "Hollow", "Bright", "Lost", "Winter", "Secret", "Savage",
+		"Gentle", "Iron", "Little", "Restless", "Shattered", "Velvet", "Wild", "Midnight", "Paper", "Glass",
+		"Northern", "Sleeping"}
+
+	titleNouns = []string{"Garden", "River", "Crown", "Letter", "House", "Shadow", "Orchard", "Storm", "Daughter",
+		"Kingdom", "Harbor", "Promise", "Mirror", "Road", "Forest", "Witness", "Island", "Lantern", "Song",
+		"Winter", "Machine", "Bridge", "Tide", "Clockmaker", "Library", "Sparrow", "Map", "Inheritance", "Stranger",
+		"Fire", "Horizon", "Cartographer", "Orphan", "Voyage", "Signal"}
+
+	titlePlaces = []string{"Paris", "the North", "Avalon", "the Valley", "Lisbon", "the City", "Babylon",
+		"the Desert", "Kyoto", "the Marshes", "Samarkand", "the Highlands", "Venice", "the Dark", "Alexandria",
+		"the South Seas", "Prague", "the Moon"}
+
+	firstNames = []string{"Ada", "Alan", "Amara", "Beatrix", "Caleb", "Clara", "Dmitri", "Elena", "Emeka", "Farah",
+		"Felix", "Greta", "Hiro", "Ines", "Isaac", "Jonah", "Kaia", "Leon", "Lucia", "Malik", "Margot", "Nadia",
+		"Niall", "Olive", "Oscar", "Priya", "Quentin", "Rosa", "Santiago", "Selma", "Tobias", "Una", "Viktor",
+		"Wren", "Xavier", "Yara", "Zadie", "Hana", "Mateo", "Sofia"}
+
+	lastNames = []string{"Abbott", "Achebe", "Baptiste", "Brandt", "Castellanos", "Chen", "Dalton", "Devereux",
+		"Eriksen", "Fairweather", "Ferrante", "Gallagher", "Haddad", "Hartley", "Ibsen", "Ishikawa", "Jansen",
+		"Kowalski", "Kuznetsov", "Lindqvist", "Lowell", "Mbeki", "Moreau", "Nakamura", "Novak", "Okafor",
+		"Oyelaran", "Pemberton", "Petrov", "Quigley", "Ramos", "Rasmussen", "Sandoval", "Schreiber", "Takahashi",
+		"Thorne", "Underwood", "Valdez", "Vance", "Whitlock", "Winterbourne", "Xu", "Yamamoto", "Yilmaz",
+		"Zamora", "Zielinski", "Albright", "Beaumont", "Carrow", "Draycott", "Everly", "Fenwick", "Galloway",
+		"Holloway", "Ingram", "Kesler", "Marchetti", "Osei", "Prescott", "Rutherford"}
+
+	genreTitles = []string{"Literary Fiction", "Mystery", "Thriller", "Romance", "Science Fiction", "Fantasy",
+		"Horror", "Historical Fiction", "Young Adult", "Childrens", "Biography", "Memoir", "History", "Poetry",
+		"Travel", "Philosophy", "Science", "Self Help", "Humor", "Graphic Novels", "Crime", "Adventure",
+		"Short Stories", "Essays"}
+)
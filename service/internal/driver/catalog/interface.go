@@ -0,0 +1,35 @@
+package catalog
+
+import (
+	"context"
+)
+
+// Source yields the Records of a catalog one at a time. Next advances to the next Record, which Record then
+// returns, and reports whether there is one. Once Next returns false, Err reports why the Records ended early,
+// if they did.
+type Source interface {
+	Next() bool
+	Record() Record
+	Err() error
+}
+
+// Repository states the required methods from the persistence layer to satisfy business requirements.
+type Repository interface {
+	// Import should write the Book of every Record of the Source in a single transaction. Authors and Genres
+	// should be matched by name, and created if no such Author or Genre exists. If upsert is true, then a Book
+	// with the title and Author of a Record should be updated rather than inserted, and of several Records
+	// with the same title and Author, the last one should be written. If the Source reports an error once it
+	// is exhausted, then nothing should be written and that error should be returned.
+	Import(ctx context.Context, records Source, upsert bool) (Summary, error)
+}
+
+// Driver is an interface described the contract required to satisfy business usecases.
+type Driver interface {
+	// Import should validate every Record read by the Reader and, unless the Options ask for a dry run,
+	// import them all at once. If any Record is invalid, then none should be imported.
+	Import(ctx context.Context, r *Reader, opts Options) (Summary, error)
+
+	// Generate should import a synthetic catalog described by the GenerateOptions, which is the same whenever
+	// it is generated from the same options.
+	Generate(ctx context.Context, opts GenerateOptions) (Summary, error)
+}
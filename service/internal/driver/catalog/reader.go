@@ -0,0 +1,476 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormatCSV is CSV with a header row which names the columns. The columns are those printed by
+	// `readcommend search --output csv`, of which title, author_first_name, author_last_name, genre,
+	// year_published, pages and rating are required. Other columns, such as IDs, are ignored.
+	FormatCSV = "csv"
+
+	// FormatJSON is a JSON array of objects, each shaped as a Book is by the API, as printed by
+	// `readcommend search --output json`. The IDs of the Book, its author and its genre are ignored.
+	FormatJSON = "json"
+
+	// FormatJSONL is JSON Lines: a JSON object per line, shaped as a Book is by the API. The IDs of the Book,
+	// its author and its genre are ignored.
+	FormatJSONL = "jsonl"
+)
+
+// csvNames are the columns of a CSV catalog.
+var csvNames = fieldNames{
+	title:           "title",
+	yearPublished:   "year_published",
+	rating:          "rating",
+	pages:           "pages",
+	authorFirstName: "author_first_name",
+	authorLastName:  "author_last_name",
+	genre:           "genre",
+}
+
+// jsonNames are the paths of the fields of a JSON Lines catalog.
+var jsonNames = fieldNames{
+	title:           "title",
+	yearPublished:   "yearPublished",
+	rating:          "rating",
+	pages:           "pages",
+	authorFirstName: "author.firstName",
+	authorLastName:  "author.lastName",
+	genre:           "genre.title",
+}
+
+// Reader reads the Records of a catalog one at a time, so that files of any size can be streamed. Blank lines
+// are skipped.
+type Reader struct {
+	format string
+	names  fieldNames
+	lines  *bufio.Reader
+
+	// line is the number of lines read so far.
+	line int
+
+	// columns is the index of every column of a CSV catalog, by name.
+	columns map[string]int
+
+	// array decodes the elements of a JSON catalog, and counter finds the lines they begin on. Once the array
+	// has ended, or cannot be decoded any further, array is nil.
+	array   *json.Decoder
+	counter *lineCounter
+}
+
+// NewReader creates a Reader of the catalog in the format, which is FormatCSV, FormatJSON or FormatJSONL. The
+// header row of a CSV catalog is read straight away, and an error is returned if it lacks a required column.
+// Likewise, an error is returned if a JSON catalog does not start an array.
+func NewReader(r io.Reader, format string) (*Reader, error) {
+	reader := &Reader{format: format, lines: bufio.NewReader(r)}
+
+	switch format {
+	case FormatCSV:
+		reader.names = csvNames
+		if err := reader.readHeader(); err != nil {
+			return nil, err
+		}
+	case FormatJSON:
+		reader.names = jsonNames
+		if err := reader.readArrayStart(); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		reader.names = jsonNames
+	default:
+		return nil, fmt.Errorf("format is %q but should be %s, %s or %s", format, FormatCSV, FormatJSON, FormatJSONL)
+	}
+	return reader, nil
+}
+
+// Read returns the next Record. If it is invalid, then a *LineError is returned, and reading may continue with
+// the next one. Once every Record has been read, io.EOF is returned.
+func (r *Reader) Read() (Record, error) {
+	var (
+		rec    Record
+		fields []entity.FieldError
+		err    error
+	)
+	switch r.format {
+	case FormatCSV:
+		rec, fields, err = r.readCSV()
+	case FormatJSON:
+		rec, fields, err = r.readArrayElement()
+	default:
+		rec, fields, err = r.readJSONLine()
+	}
+	if err != nil {
+		return Record{}, err
+	}
+
+	// Fields which could not be decoded are not validated again.
+	for _, f := range rec.validate(r.names) {
+		if !hasField(fields, f.Field) {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) > 0 {
+		return Record{}, &LineError{Line: rec.Line, Fields: fields}
+	}
+	return rec, nil
+}
+
+// readHeader reads the header row of a CSV catalog, and indexes its columns.
+func (r *Reader) readHeader() error {
+	header, _, err := r.nextCSV()
+	if err == io.EOF {
+		return errors.New("catalog is empty, but should start with a header row")
+	}
+	if err != nil {
+		return err
+	}
+
+	r.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if _, ok := r.columns[name]; ok {
+			return fmt.Errorf("header row names the column %q more than once", name)
+		}
+		r.columns[name] = i
+	}
+
+	var missing []string
+	for _, name := range []string{csvNames.title, csvNames.authorFirstName, csvNames.authorLastName, csvNames.genre,
+		csvNames.yearPublished, csvNames.pages, csvNames.rating} {
+		if _, ok := r.columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("header row lacks the columns %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// readCSV decodes the next row of a CSV catalog into a Record. Fields which cannot be decoded are returned as
+// FieldErrors.
+func (r *Reader) readCSV() (Record, []entity.FieldError, error) {
+	row, line, err := r.nextCSV()
+	if err != nil {
+		return Record{}, nil, err
+	}
+	if len(row) != len(r.columns) {
+		return Record{}, nil, &LineError{Line: line, Fields: []entity.FieldError{{
+			Code:    validation.CodeInvalid,
+			Message: fmt.Sprintf("has %d columns but the header row has %d", len(row), len(r.columns)),
+		}}}
+	}
+
+	column := func(name string) string { return row[r.columns[name]] }
+	rec := Record{
+		Line:            line,
+		Title:           column(csvNames.title),
+		AuthorFirstName: column(csvNames.authorFirstName),
+		AuthorLastName:  column(csvNames.authorLastName),
+		Genre:           column(csvNames.genre),
+	}
+
+	var fields []entity.FieldError
+	for _, n := range []struct {
+		name  string
+		value *int16
+	}{{csvNames.yearPublished, &rec.YearPublished}, {csvNames.pages, &rec.Pages}} {
+		v, err := strconv.ParseInt(strings.TrimSpace(column(n.name)), 10, 16)
+		if err != nil {
+			fields = append(fields, entity.FieldError{
+				Field:   n.name,
+				Code:    validation.CodeInvalidType,
+				Message: fmt.Sprintf("is %q but should be a whole number", column(n.name)),
+			})
+		}
+		*n.value = int16(v)
+	}
+
+	rating, err := strconv.ParseFloat(strings.TrimSpace(column(csvNames.rating)), 32)
+	if err != nil {
+		fields = append(fields, entity.FieldError{
+			Field:   csvNames.rating,
+			Code:    validation.CodeInvalidType,
+			Message: fmt.Sprintf("is %q but should be a number", column(csvNames.rating)),
+		})
+	}
+	rec.Rating = float32(rating)
+
+	return rec, fields, nil
+}
+
+// nextCSV returns the fields of the next row of a CSV catalog and the line it begins on. A row continues onto the
+// following lines while a quoted field is open, so that fields may hold line breaks.
+func (r *Reader) nextCSV() ([]string, int, error) {
+	var (
+		text  string
+		start int
+	)
+	for {
+		s, err := r.readLine()
+		if err == io.EOF && s == "" && text == "" {
+			return nil, 0, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+
+		if text == "" {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			start = r.line
+		}
+		text += s
+
+		// Quotes come in pairs, including escaped ones, unless a quoted field is still open.
+		if strings.Count(text, `"`)%2 == 0 || err == io.EOF {
+			break
+		}
+	}
+
+	row, err := csv.NewReader(strings.NewReader(text)).Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			err = parseErr.Err
+		}
+		return nil, start, &LineError{Line: start, Fields: []entity.FieldError{{
+			Code:    validation.CodeInvalid,
+			Message: fmt.Sprintf("is not valid CSV: %s", err),
+		}}}
+	}
+	return row, start, nil
+}
+
+// jsonRecord is a Record as it is shaped in a JSON Lines catalog. Its fields are pointers, so that those which
+// are missing can be told apart from zero values.
+type jsonRecord struct {
+	Title         *string  `json:"title"`
+	YearPublished *int16   `json:"yearPublished"`
+	Rating        *float32 `json:"rating"`
+	Pages         *int16   `json:"pages"`
+	Author        struct {
+		FirstName *string `json:"firstName"`
+		LastName  *string `json:"lastName"`
+	} `json:"author"`
+	Genre struct {
+		Title *string `json:"title"`
+	} `json:"genre"`
+}
+
+// readArrayStart reads the opening bracket of a JSON catalog. A byte order mark before it is dropped.
+func (r *Reader) readArrayStart() error {
+	if bom, _ := r.lines.Peek(len("\ufeff")); string(bom) == "\ufeff" {
+		_, _ = r.lines.Discard(len(bom))
+	}
+
+	r.counter = &lineCounter{r: r.lines}
+	r.array = json.NewDecoder(r.counter)
+
+	tok, err := r.array.Token()
+	if err == io.EOF {
+		return errors.New("catalog is empty, but should be a JSON array")
+	}
+	if err != nil {
+		return fmt.Errorf("catalog is not valid JSON: %w", err)
+	}
+	if tok != json.Delim('[') {
+		return errors.New("catalog should be a JSON array")
+	}
+	return nil
+}
+
+// readArrayElement decodes the next element of a JSON catalog into a Record. Fields which are missing or cannot
+// be decoded are returned as FieldErrors. If the array is malformed, then the Reader cannot go on, so the
+// *LineError returned for it is followed by io.EOF.
+func (r *Reader) readArrayElement() (Record, []entity.FieldError, error) {
+	if r.array == nil {
+		return Record{}, nil, io.EOF
+	}
+
+	malformed := func(offset int64, format string, args ...interface{}) (Record, []entity.FieldError, error) {
+		line := r.counter.lineAt(offset)
+		r.array = nil
+		return Record{}, nil, &LineError{Line: line, Fields: []entity.FieldError{{
+			Code:    validation.CodeInvalid,
+			Message: fmt.Sprintf(format, args...),
+		}}}
+	}
+
+	if !r.array.More() {
+		// Consume the closing bracket, and check that nothing follows it.
+		if _, err := r.array.Token(); err != nil {
+			return malformed(r.array.InputOffset(), "is not valid JSON: %s", err)
+		}
+		if _, err := r.array.Token(); err != io.EOF {
+			return malformed(r.array.InputOffset(), "continues after the end of the array")
+		}
+		r.array = nil
+		return Record{}, nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := r.array.Decode(&raw); err != nil {
+		// Syntax errors note how far into the catalog they were found, which is after the offending byte.
+		offset := r.array.InputOffset()
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = syntaxErr.Offset - 1
+		}
+		return malformed(offset, "is not valid JSON: %s", err)
+	}
+
+	// The element ends where the decoder stopped, so it begins as many bytes before as it holds.
+	return decodeJSON(raw, r.counter.lineAt(r.array.InputOffset()-int64(len(raw))))
+}
+
+// readJSONLine decodes the next line of a JSON Lines catalog into a Record. Fields which are missing or cannot be
+// decoded are returned as FieldErrors.
+func (r *Reader) readJSONLine() (Record, []entity.FieldError, error) {
+	var s string
+	for strings.TrimSpace(s) == "" {
+		var err error
+		s, err = r.readLine()
+		if err == io.EOF && strings.TrimSpace(s) == "" {
+			return Record{}, nil, io.EOF
+		}
+		if err != nil && err != io.EOF {
+			return Record{}, nil, err
+		}
+	}
+	return decodeJSON([]byte(s), r.line)
+}
+
+// decodeJSON decodes a JSON object, which begins on the line, into a Record. Fields which are missing or cannot
+// be decoded are returned as FieldErrors.
+func decodeJSON(data []byte, line int) (Record, []entity.FieldError, error) {
+	var (
+		in     jsonRecord
+		fields []entity.FieldError
+	)
+	if err := json.Unmarshal(data, &in); err != nil {
+		// A value of the wrong type only spoils its own field, whereas malformed JSON spoils the whole line.
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return Record{}, nil, &LineError{Line: line, Fields: []entity.FieldError{{
+				Code:    validation.CodeInvalid,
+				Message: fmt.Sprintf("is not valid JSON: %s", err),
+			}}}
+		}
+		fields = append(fields, entity.FieldError{
+			Field:   typeErr.Field,
+			Code:    validation.CodeInvalidType,
+			Message: fmt.Sprintf("is a JSON %s but should be a %s", typeErr.Value, typeErr.Type),
+		})
+	}
+
+	rec := Record{Line: line}
+	required := func(name string, present bool) {
+		if !present && !hasField(fields, name) {
+			fields = append(fields, entity.FieldError{Field: name, Code: validation.CodeRequired, Message: "is required"})
+		}
+	}
+
+	required(jsonNames.title, in.Title != nil)
+	if in.Title != nil {
+		rec.Title = *in.Title
+	}
+	required(jsonNames.yearPublished, in.YearPublished != nil)
+	if in.YearPublished != nil {
+		rec.YearPublished = *in.YearPublished
+	}
+	required(jsonNames.rating, in.Rating != nil)
+	if in.Rating != nil {
+		rec.Rating = *in.Rating
+	}
+	required(jsonNames.pages, in.Pages != nil)
+	if in.Pages != nil {
+		rec.Pages = *in.Pages
+	}
+	required(jsonNames.authorFirstName, in.Author.FirstName != nil)
+	if in.Author.FirstName != nil {
+		rec.AuthorFirstName = *in.Author.FirstName
+	}
+	required(jsonNames.authorLastName, in.Author.LastName != nil)
+	if in.Author.LastName != nil {
+		rec.AuthorLastName = *in.Author.LastName
+	}
+	required(jsonNames.genre, in.Genre.Title != nil)
+	if in.Genre.Title != nil {
+		rec.Genre = *in.Genre.Title
+	}
+
+	return rec, fields, nil
+}
+
+// readLine reads the next line, including its line break, and counts it. A byte order mark at the start of
+// the catalog is dropped. At the end of the catalog, the last line is returned along with io.EOF.
+func (r *Reader) readLine() (string, error) {
+	s, err := r.lines.ReadString('\n')
+	if s != "" {
+		r.line++
+		if r.line == 1 {
+			s = strings.TrimPrefix(s, "\ufeff")
+		}
+	}
+	return s, err
+}
+
+// lineCounter notes where the line breaks read through it are, so that the line of an offset into a catalog can
+// be found after it has been read.
+type lineCounter struct {
+	r io.Reader
+
+	// read is the number of bytes read so far.
+	read int64
+
+	// breaks are the offsets of the line breaks which have been read, but not yet passed by lineAt.
+	breaks []int64
+
+	// line is the number of line breaks passed by lineAt.
+	line int
+}
+
+// Read reads from the underlying io.Reader, noting any line breaks.
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			c.breaks = append(c.breaks, c.read+int64(i))
+		}
+	}
+	c.read += int64(n)
+	return n, err
+}
+
+// lineAt returns the line of the byte at the offset, counting from 1. Offsets must be looked up in order.
+func (c *lineCounter) lineAt(offset int64) int {
+	for len(c.breaks) > 0 && c.breaks[0] < offset {
+		c.breaks = c.breaks[1:]
+		c.line++
+	}
+	return c.line + 1
+}
+
+// hasField reports whether any of the FieldErrors is for the named field.
+func hasField(fields []entity.FieldError, name string) bool {
+	for _, f := range fields {
+		if f.Field == name {
+			return true
+		}
+	}
+	return false
+}
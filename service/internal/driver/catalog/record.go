@@ -0,0 +1,84 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+)
+
+// Record is a Book read from a catalog, whose Author and Genre are named rather than identified.
+type Record struct {
+	// Line is the line of the file on which the Record begins, counting from 1.
+	Line int
+
+	Title         string
+	YearPublished int16
+	Rating        float32
+	Pages         int16
+
+	AuthorFirstName string
+	AuthorLastName  string
+	Genre           string
+}
+
+// LineError describes why the Record on a line of a catalog is invalid.
+type LineError struct {
+	Line int
+
+	// Fields holds every invalid field of the Record. If the line could not be decoded at all, then it holds
+	// a single FieldError whose Field is empty.
+	Fields []entity.FieldError
+}
+
+// Error lists the FieldErrors of the line.
+func (e *LineError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = strings.TrimSpace(fmt.Sprintf("%s %s", f.Field, f.Message))
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, strings.Join(fields, "; "))
+}
+
+// ImportError holds every LineError found while importing a catalog. It wraps entity.ErrInvalidEntity.
+type ImportError struct {
+	Lines []*LineError
+}
+
+// Error counts the invalid lines.
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("%s: %d invalid lines", entity.ErrInvalidEntity, len(e.Lines))
+}
+
+// Unwrap returns entity.ErrInvalidEntity.
+func (e *ImportError) Unwrap() error {
+	return entity.ErrInvalidEntity
+}
+
+// validate checks every attribute of the Record as book.WriteInput is checked when a Book is written, along with
+// the names of its Author and Genre. The fields are named as they are in the format the Record was read from.
+func (rec Record) validate(names fieldNames) []entity.FieldError {
+	v := validation.New(entity.ErrInvalidEntity)
+	v.NotBlank(names.title, &rec.Title)
+	v.NotBlank(names.authorFirstName, &rec.AuthorFirstName)
+	v.NotBlank(names.authorLastName, &rec.AuthorLastName)
+	v.NotBlank(names.genre, &rec.Genre)
+
+	v.Int16InRange(names.yearPublished, &rec.YearPublished, book.MinYearPublished, book.MaxYearPublished)
+	v.Float32InRange(names.rating, &rec.Rating, book.MinRating, book.MaxRating)
+	v.Int16InRange(names.pages, &rec.Pages, book.MinPages, book.MaxPages)
+	return v.Fields()
+}
+
+// fieldNames are the names of the fields of a Record in a format.
+type fieldNames struct {
+	title           string
+	yearPublished   string
+	rating          string
+	pages           string
+	authorFirstName string
+	authorLastName  string
+	genre           string
+}
@@ -0,0 +1,112 @@
+package catalog
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+)
+
+// csvHeader is the header row of a CSV catalog written by a Writer. The IDs are written for reference, and
+// ignored when the catalog is read.
+var csvHeader = []string{"id", csvNames.title, "author_id", csvNames.authorFirstName, csvNames.authorLastName,
+	"genre_id", csvNames.genre, csvNames.yearPublished, csvNames.pages, csvNames.rating}
+
+// Writer writes Books as a catalog, one at a time, so that catalogs of any size can be streamed. Every catalog
+// it writes can be read by a Reader of the same format. Nothing is written until the first Book or Close, and
+// the catalog is only complete once it has been closed.
+type Writer struct {
+	format string
+	w      *bufio.Writer
+	csv    *csv.Writer
+
+	// books is the number of Books written so far.
+	books int
+}
+
+// NewWriter creates a Writer of a catalog in the format, which is FormatCSV, FormatJSON or FormatJSONL.
+func NewWriter(w io.Writer, format string) (*Writer, error) {
+	writer := &Writer{format: format, w: bufio.NewWriter(w)}
+
+	switch format {
+	case FormatCSV:
+		writer.csv = csv.NewWriter(writer.w)
+	case FormatJSON, FormatJSONL:
+	default:
+		return nil, fmt.Errorf("format is %q but should be %s, %s or %s", format, FormatCSV, FormatJSON, FormatJSONL)
+	}
+	return writer, nil
+}
+
+// Write writes the Book. Writes are buffered, so an error may not be returned until a later Write or Close.
+func (w *Writer) Write(b entity.Book) error {
+	defer func() { w.books++ }()
+
+	switch w.format {
+	case FormatCSV:
+		if w.books == 0 {
+			_ = w.csv.Write(csvHeader)
+		}
+		_ = w.csv.Write([]string{
+			strconv.Itoa(int(b.ID)),
+			b.Title,
+			strconv.Itoa(int(b.Author.ID)),
+			b.Author.FirstName,
+			b.Author.LastName,
+			strconv.Itoa(int(b.Genre.ID)),
+			b.Genre.Title,
+			strconv.Itoa(int(b.YearPublished)),
+			strconv.Itoa(int(b.Pages)),
+			strconv.FormatFloat(float64(b.Rating), 'f', 2, 32),
+		})
+		return w.csv.Error()
+
+	case FormatJSON:
+		// The array is indented as json.Encoder would indent it, were every Book known up front.
+		data, err := json.MarshalIndent(b, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to encode book %d: %w", b.ID, err)
+		}
+		sep := ",\n  "
+		if w.books == 0 {
+			sep = "[\n  "
+		}
+		_, _ = w.w.WriteString(sep)
+		_, err = w.w.Write(data)
+		return err
+
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("unable to encode book %d: %w", b.ID, err)
+		}
+		_, _ = w.w.Write(data)
+		return w.w.WriteByte('\n')
+	}
+}
+
+// Close completes the catalog and flushes it. A CSV catalog without Books still has its header row, and a JSON
+// one is an empty array. Close does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	switch w.format {
+	case FormatCSV:
+		if w.books == 0 {
+			_ = w.csv.Write(csvHeader)
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	case FormatJSON:
+		end := "\n]\n"
+		if w.books == 0 {
+			end = "[]\n"
+		}
+		_, _ = w.w.WriteString(end)
+	}
+	return w.w.Flush()
+}
@@ -2,10 +2,13 @@ package era_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,6 +24,79 @@ func (r *inMemoryRepository) List(_ context.Context) ([]entity.Era, error) {
 	return data, nil
 }
 
+func (r *inMemoryRepository) Create(ctx context.Context, params era.WriteInput,
+	check func([]entity.Era) error) (int32, error) {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return 0, err
+	}
+	var id int32
+	for existing := range r.resource {
+		if existing > id {
+			id = existing
+		}
+	}
+	id++
+	r.resource[id] = entity.Era{ID: id, Title: *params.Title, MinYear: params.MinYear, MaxYear: params.MaxYear}
+	return id, nil
+}
+
+func (r *inMemoryRepository) Update(ctx context.Context, id int32, params era.WriteInput,
+	check func([]entity.Era) error) error {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return err
+	}
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: era %d does not exist", entity.ErrNotFound, id)
+	}
+	r.resource[id] = entity.Era{ID: id, Title: *params.Title, MinYear: params.MinYear, MaxYear: params.MaxYear}
+	return nil
+}
+
+func (r *inMemoryRepository) Delete(ctx context.Context, id int32, check func([]entity.Era) error) error {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return err
+	}
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: era %d does not exist", entity.ErrNotFound, id)
+	}
+	delete(r.resource, id)
+	return nil
+}
+
+func (r *inMemoryRepository) ReplaceAll(ctx context.Context, params []era.BulkInput,
+	check func([]entity.Era) error) ([]entity.Era, error) {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return nil, err
+	}
+
+	kept := map[int32]bool{}
+	for _, p := range params {
+		if p.ID != nil {
+			kept[*p.ID] = true
+		}
+	}
+	for id := range r.resource {
+		if !kept[id] {
+			delete(r.resource, id)
+		}
+	}
+
+	eras := make([]entity.Era, len(params))
+	for i, p := range params {
+		if p.ID == nil {
+			id, _ := r.Create(ctx, p.WriteInput, func([]entity.Era) error { return nil })
+			p.ID = &id
+		}
+		eras[i] = entity.Era{ID: *p.ID, Title: *p.Title, MinYear: p.MinYear, MaxYear: p.MaxYear}
+		r.resource[*p.ID] = eras[i]
+	}
+	return eras, nil
+}
+
 func TestDriver_List(t *testing.T) {
 
 	e := entity.Era{ID: 1, Title: "Modern"}
@@ -33,3 +109,167 @@ func TestDriver_List(t *testing.T) {
 	assert.Len(t, res, 1)
 	assert.Contains(t, res, e)
 }
+
+func TestDriver_Write(t *testing.T) {
+
+	newRepo := func() *inMemoryRepository {
+		return &inMemoryRepository{resource: map[int32]entity.Era{
+			0: {ID: 0, Title: "Any"},
+			1: {ID: 1, Title: "Classic", MaxYear: util.Int16Ptr(1969)},
+			2: {ID: 2, Title: "Modern", MinYear: util.Int16Ptr(1970)},
+		}}
+	}
+
+	validationFields := func(t *testing.T, err error) []entity.FieldError {
+		var validationErr *entity.ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			return validationErr.Fields
+		}
+		return nil
+	}
+
+	t.Run("create", func(t *testing.T) {
+		repo := newRepo()
+		repo.resource[2] = entity.Era{ID: 2, Title: "Modern", MinYear: util.Int16Ptr(1970), MaxYear: util.Int16Ptr(1999)}
+
+		res, err := era.NewDriver(repo).CreateEra(context.Background(),
+			era.WriteInput{Title: util.StringPtr("Contemporary"), MinYear: util.Int16Ptr(2000)})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Era{ID: 3, Title: "Contemporary", MinYear: util.Int16Ptr(2000)}, res)
+		assert.Equal(t, res, repo.resource[3])
+	})
+
+	t.Run("create overlapping", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).CreateEra(context.Background(),
+			era.WriteInput{Title: util.StringPtr("Contemporary"), MinYear: util.Int16Ptr(2000)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "minYear", Code: validation.CodeConflict, Message: `overlaps era "Modern", which has no maxYear`},
+		}, validationFields(t, err))
+	})
+
+	t.Run("create inverted range", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).CreateEra(context.Background(),
+			era.WriteInput{MinYear: util.Int16Ptr(2000), MaxYear: util.Int16Ptr(1990)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "title", Code: validation.CodeRequired, Message: "is required"},
+			{Field: "maxYear", Code: validation.CodeMinExceedsMax, Message: "is 1990 but should not be less than minYear 2000"},
+		}, validationFields(t, err))
+	})
+
+	t.Run("replace title", func(t *testing.T) {
+		res, err := era.NewDriver(newRepo()).ReplaceEra(context.Background(), 1,
+			era.WriteInput{Title: util.StringPtr("Classics"), MaxYear: util.Int16Ptr(1969)})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Era{ID: 1, Title: "Classics", MaxYear: util.Int16Ptr(1969)}, res)
+	})
+
+	t.Run("replace leaving a gap", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).ReplaceEra(context.Background(), 1,
+			era.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1959)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "maxYear", Code: validation.CodeConflict, Message: `should be 1969 to precede era "Modern"`},
+		}, validationFields(t, err))
+	})
+
+	t.Run("replace missing", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).ReplaceEra(context.Background(), 9,
+			era.WriteInput{Title: util.StringPtr("Future")})
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo()
+		assert.NoError(t, era.NewDriver(repo).DeleteEra(context.Background(), 2))
+		assert.NotContains(t, repo.resource, int32(2))
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		err := era.NewDriver(newRepo()).DeleteEra(context.Background(), 9)
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("replace bounding any", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).ReplaceEra(context.Background(), 0,
+			era.WriteInput{Title: util.StringPtr("Any"), MaxYear: util.Int16Ptr(1959)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "maxYear", Code: validation.CodeConflict, Message: "must be omitted, as era 0 matches every value"},
+		}, validationFields(t, err))
+	})
+
+	t.Run("delete any", func(t *testing.T) {
+		repo := newRepo()
+		err := era.NewDriver(repo).DeleteEra(context.Background(), 0)
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.Contains(t, repo.resource, int32(0))
+	})
+
+	t.Run("delete leaving a gap", func(t *testing.T) {
+		repo := newRepo()
+		repo.resource[2] = entity.Era{ID: 2, Title: "Modern", MinYear: util.Int16Ptr(1970), MaxYear: util.Int16Ptr(1999)}
+		repo.resource[3] = entity.Era{ID: 3, Title: "Contemporary", MinYear: util.Int16Ptr(2000)}
+
+		err := era.NewDriver(repo).DeleteEra(context.Background(), 2)
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.EqualError(t, err, "conflict with the current state of the resources: "+
+			`deleting era 2 would leave a gap between era "Classic" and era "Contemporary"`)
+		assert.Contains(t, repo.resource, int32(2))
+	})
+
+	t.Run("replace all", func(t *testing.T) {
+		repo := newRepo()
+		res, err := era.NewDriver(repo).ReplaceAllEras(context.Background(), []era.BulkInput{
+			{ID: util.Int32Ptr(0), WriteInput: era.WriteInput{Title: util.StringPtr("Any")}},
+			{ID: util.Int32Ptr(1), WriteInput: era.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1959)}},
+			{WriteInput: era.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1960)}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.Era{
+			{ID: 0, Title: "Any"},
+			{ID: 1, Title: "Classic", MaxYear: util.Int16Ptr(1959)},
+			{ID: 2, Title: "Modern", MinYear: util.Int16Ptr(1960)},
+		}, res)
+		assert.Len(t, repo.resource, 3)
+	})
+
+	t.Run("replace all invalid", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).ReplaceAllEras(context.Background(), []era.BulkInput{
+			{ID: util.Int32Ptr(1), WriteInput: era.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1959)}},
+			{ID: util.Int32Ptr(1), WriteInput: era.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1970)}},
+			{ID: util.Int32Ptr(9), WriteInput: era.WriteInput{Title: util.StringPtr("Any")}},
+		})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "[0].maxYear", Code: validation.CodeConflict, Message: `should be 1969 to precede era "Modern"`},
+			{Field: "[1].id", Code: validation.CodeConflict, Message: "era 1 is repeated"},
+			{Field: "[1].minYear", Code: validation.CodeConflict, Message: `should be 1960 to follow era "Classic"`},
+			{Field: "[2].id", Code: validation.CodeNotFound, Message: "era 9 does not exist"},
+		}, validationFields(t, err))
+	})
+
+	t.Run("replace all with nothing", func(t *testing.T) {
+		repo := newRepo()
+		_, err := era.NewDriver(repo).ReplaceAllEras(context.Background(), []era.BulkInput{})
+		assert.ErrorIs(t, err, entity.ErrInvalidBody)
+		assert.Len(t, repo.resource, 3)
+	})
+
+	t.Run("replace all leaving out any", func(t *testing.T) {
+		repo := newRepo()
+		_, err := era.NewDriver(repo).ReplaceAllEras(context.Background(), []era.BulkInput{
+			{ID: util.Int32Ptr(1), WriteInput: era.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1959)}},
+			{ID: util.Int32Ptr(2), WriteInput: era.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1960)}},
+		})
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.Contains(t, repo.resource, int32(0))
+	})
+
+	t.Run("replace all bounding any", func(t *testing.T) {
+		_, err := era.NewDriver(newRepo()).ReplaceAllEras(context.Background(), []era.BulkInput{
+			{ID: util.Int32Ptr(0), WriteInput: era.WriteInput{Title: util.StringPtr("Any"), MinYear: util.Int16Ptr(1)}},
+			{ID: util.Int32Ptr(1), WriteInput: era.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1959)}},
+			{ID: util.Int32Ptr(2), WriteInput: era.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1960)}},
+		})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "[0].minYear", Code: validation.CodeConflict, Message: "must be omitted, as era 0 matches every value"},
+		}, validationFields(t, err))
+	})
+}
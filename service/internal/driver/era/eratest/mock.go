@@ -3,6 +3,7 @@ package eratest
 import (
 	"context"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,3 +18,27 @@ func (d *DriverMock) ListEras(ctx context.Context) ([]entity.Era, error) {
 	args := d.Called(ctx)
 	return args.Get(0).([]entity.Era), args.Error(1)
 }
+
+// CreateEra is a mock routine that returns items as instructed.
+func (d *DriverMock) CreateEra(ctx context.Context, params era.WriteInput) (entity.Era, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).(entity.Era), args.Error(1)
+}
+
+// ReplaceEra is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceEra(ctx context.Context, id int32, params era.WriteInput) (entity.Era, error) {
+	args := d.Called(ctx, id, params)
+	return args.Get(0).(entity.Era), args.Error(1)
+}
+
+// DeleteEra is a mock routine that returns items as instructed.
+func (d *DriverMock) DeleteEra(ctx context.Context, id int32) error {
+	args := d.Called(ctx, id)
+	return args.Error(0)
+}
+
+// ReplaceAllEras is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceAllEras(ctx context.Context, params []era.BulkInput) ([]entity.Era, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).([]entity.Era), args.Error(1)
+}
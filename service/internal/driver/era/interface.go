@@ -10,10 +10,41 @@ import (
 type Repository interface {
 	// List should return all Eras.
 	List(ctx context.Context) ([]entity.Era, error)
+
+	// Create should create an Era from the WriteInput and return its newly assigned ID. Within the same
+	// transaction, and before it is written, check should be called with every Era. If it returns an error, then
+	// no Era should be created and that error should be returned. Concurrent writes should not interleave.
+	Create(ctx context.Context, params WriteInput, check func([]entity.Era) error) (int32, error)
+
+	// Update should replace the attributes of the Era with the provided ID. If it does not exist, then an
+	// error wrapping entity.ErrNotFound should be returned. check should be called as it is by Create.
+	Update(ctx context.Context, id int32, params WriteInput, check func([]entity.Era) error) error
+
+	// Delete should delete the Era with the provided ID. If it does not exist, then an error wrapping
+	// entity.ErrNotFound should be returned. check should be called as it is by Create.
+	Delete(ctx context.Context, id int32, check func([]entity.Era) error) error
+
+	// ReplaceAll should atomically replace every Era with those in the BulkInput, and return them in the
+	// same order with their IDs. Within the same transaction, and before anything is written, check should be
+	// called with every Era. If it returns an error, then no Era should be changed and that error should be
+	// returned. Concurrent calls should not interleave, so that each checks the Era the other has written.
+	ReplaceAll(ctx context.Context, params []BulkInput, check func([]entity.Era) error) ([]entity.Era, error)
 }
 
 // Driver is an interface described the contract required to satisfy business usecases.
 type Driver interface {
 	// ListEras should fetch all Eras and perform intermediary business logic, if any.
 	ListEras(ctx context.Context) ([]entity.Era, error)
+
+	// CreateEra should validate the WriteInput and create an Era from it.
+	CreateEra(ctx context.Context, params WriteInput) (entity.Era, error)
+
+	// ReplaceEra should validate the WriteInput and replace the attributes of an existing Era with it.
+	ReplaceEra(ctx context.Context, id int32, params WriteInput) (entity.Era, error)
+
+	// DeleteEra should delete an existing Era, unless doing so leaves a gap between the remaining Eras.
+	DeleteEra(ctx context.Context, id int32) error
+
+	// ReplaceAllEras should validate the BulkInput and replace every Era with it.
+	ReplaceAllEras(ctx context.Context, params []BulkInput) ([]entity.Era, error)
 }
@@ -0,0 +1,108 @@
+package era
+
+import (
+	"context"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/bucket"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+)
+
+// names words the FieldErrors about the ranges of Eras.
+var names = bucket.Names{Kind: "era", Min: "minYear", Max: "maxYear"}
+
+// WriteInput is an input parameter for CreateEra and ReplaceEra. It holds the attributes of an Era to be written.
+// The Title is required. A nil MinYear or MaxYear leaves that end of the Era's range open.
+type WriteInput struct {
+	_ struct{}
+
+	Title   *string
+	MinYear *int16
+	MaxYear *int16
+}
+
+// BulkInput is an element of the input parameter for ReplaceAllEras. If the ID is nil, then a new Era is
+// created from the WriteInput. Otherwise the existing Era with the ID is replaced with it.
+type BulkInput struct {
+	ID *int32
+
+	WriteInput
+}
+
+// CreateEra validates the WriteInput and creates an Era from it. The repository assigns the Era's ID. The range
+// of the new Era must adjoin the ranges of the existing ones, which are read within the transaction which
+// creates it, so that concurrent writes cannot interleave. The created entity.Era is returned. If the
+// WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) CreateEra(ctx context.Context, params WriteInput) (entity.Era, error) {
+	id, err := d.repository.Create(ctx, params, func(eras []entity.Era) error {
+		return bucket.CheckCreate(names, toStored(eras), params.input())
+	})
+	if err != nil {
+		return entity.Era{}, err
+	}
+
+	return params.era(id), nil
+}
+
+// ReplaceEra validates the WriteInput and replaces every attribute of the Era with the provided ID with it. The
+// new range must adjoin the ranges of the other Eras, and the "Any" Era must stay unbounded. As with
+// CreateEra, this is checked within the transaction which writes it. The updated
+// entity.Era is returned. If the Era does not exist, then an error wrapping entity.ErrNotFound is returned. If
+// the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) ReplaceEra(ctx context.Context, id int32, params WriteInput) (entity.Era, error) {
+	err := d.repository.Update(ctx, id, params, func(eras []entity.Era) error {
+		return bucket.CheckUpdate(names, toStored(eras), id, params.input())
+	})
+	if err != nil {
+		return entity.Era{}, err
+	}
+
+	return params.era(id), nil
+}
+
+// DeleteEra deletes the Era with the provided ID. If the Era does not exist, then an error wrapping
+// entity.ErrNotFound is returned. If the Era is "Any", or deleting it would leave a gap between the ranges of
+// the remaining Eras, then an error wrapping entity.ErrConflict is returned instead. As with CreateEra, this
+// is checked within the transaction which deletes it.
+func (d *driver) DeleteEra(ctx context.Context, id int32) error {
+	return d.repository.Delete(ctx, id, func(eras []entity.Era) error {
+		return bucket.CheckDelete(names, toStored(eras), id)
+	})
+}
+
+// ReplaceAllEras validates the BulkInput and replaces every Era with it, so that the boundaries between Eras
+// can be moved at once. Existing Eras which are not in the BulkInput are deleted, except for "Any", which must be
+// kept. The BulkInput is validated against the Eras within the transaction which replaces them, so that
+// concurrent replacements cannot interleave. The resulting entity.Eras are returned in the order of the
+// BulkInput. If the BulkInput is empty, then an error wrapping entity.ErrInvalidBody is returned, and if it
+// leaves out "Any", then an error wrapping entity.ErrConflict is returned. If the BulkInput is otherwise
+// invalid, then an *entity.ValidationError is returned, whose fields are prefixed by the index of the invalid
+// element, such as "[1].minYear".
+func (d *driver) ReplaceAllEras(ctx context.Context, params []BulkInput) ([]entity.Era, error) {
+	replacements := make([]bucket.Replacement, len(params))
+	for i, p := range params {
+		replacements[i] = bucket.Replacement{ID: p.ID, Input: p.input()}
+	}
+
+	return d.repository.ReplaceAll(ctx, params, func(eras []entity.Era) error {
+		return bucket.CheckReplaceAll(names, toStored(eras), replacements)
+	})
+}
+
+// input maps the WriteInput to a bucket.Input.
+func (p WriteInput) input() bucket.Input {
+	return bucket.Input{Title: p.Title, Min: p.MinYear, Max: p.MaxYear}
+}
+
+// era maps the WriteInput to an entity.Era with the provided ID.
+func (p WriteInput) era(id int32) entity.Era {
+	return entity.Era{ID: id, Title: *p.Title, MinYear: p.MinYear, MaxYear: p.MaxYear}
+}
+
+// toStored maps the entity.Eras to bucket.Stored, by index.
+func toStored(eras []entity.Era) []bucket.Stored {
+	stored := make([]bucket.Stored, len(eras))
+	for i, e := range eras {
+		stored[i] = bucket.Stored{ID: e.ID, Bucket: bucket.Bucket{Title: e.Title, Min: e.MinYear, Max: e.MaxYear}}
+	}
+	return stored
+}
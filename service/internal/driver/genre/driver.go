@@ -6,12 +6,39 @@ import (
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 )
 
+// Stats are aggregates over the Books of a Genre.
+type Stats struct {
+	// BookCount is the number of Books of the Genre.
+	BookCount int64
+
+	// AuthorCount is the number of distinct Authors who wrote Books of the Genre.
+	AuthorCount int64
+
+	// AverageRating is the average rating of the Genre's Books. It is nil if the Genre has no Books.
+	AverageRating *float64
+
+	// FirstYearPublished is the year the Genre's earliest Book was published. It is nil if the Genre
+	// has no Books.
+	FirstYearPublished *int16
+
+	// LastYearPublished is the year the Genre's latest Book was published. It is nil if the Genre
+	// has no Books.
+	LastYearPublished *int16
+}
+
+// Detail is a single Genre along with aggregates over its Books.
+type Detail struct {
+	Genre entity.Genre
+
+	Stats Stats
+}
+
 type driver struct {
 	repository Repository
 }
 
 // NewDriver creates a driver which wraps the repository. The wrapper
-// will perform business logic against the usecases of Size entity.
+// will perform business logic against the usecases of Genre entity.
 func NewDriver(r Repository) *driver {
 	return &driver{repository: r}
 }
@@ -20,3 +47,19 @@ func NewDriver(r Repository) *driver {
 func (d *driver) ListGenres(ctx context.Context) ([]entity.Genre, error) {
 	return d.repository.List(ctx)
 }
+
+// GetGenre fetches the entity.Genre with the provided ID from the repository, along with its Stats.
+// If the Genre does not exist, then an error wrapping entity.ErrNotFound is returned.
+func (d *driver) GetGenre(ctx context.Context, id int32) (Detail, error) {
+	g, err := d.repository.Get(ctx, id)
+	if err != nil {
+		return Detail{}, err
+	}
+
+	stats, err := d.repository.Stats(ctx, id)
+	if err != nil {
+		return Detail{}, err
+	}
+
+	return Detail{Genre: g, Stats: stats}, nil
+}
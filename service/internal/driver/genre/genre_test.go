@@ -2,15 +2,20 @@ package genre_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
 type inMemoryRepository struct {
-	resource map[int32]entity.Genre
+	resource   map[int32]entity.Genre
+	stats      map[int32]genre.Stats
+	reassigned map[int32]int32
 }
 
 func (r *inMemoryRepository) List(_ context.Context) ([]entity.Genre, error) {
@@ -21,6 +26,51 @@ func (r *inMemoryRepository) List(_ context.Context) ([]entity.Genre, error) {
 	return data, nil
 }
 
+func (r *inMemoryRepository) Get(_ context.Context, id int32) (entity.Genre, error) {
+	g, ok := r.resource[id]
+	if !ok {
+		return entity.Genre{}, fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	return g, nil
+}
+
+func (r *inMemoryRepository) Stats(_ context.Context, id int32) (genre.Stats, error) {
+	return r.stats[id], nil
+}
+
+func (r *inMemoryRepository) Create(_ context.Context, params genre.WriteInput) (int32, error) {
+	var id int32
+	for existing := range r.resource {
+		if existing > id {
+			id = existing
+		}
+	}
+	id++
+	r.resource[id] = entity.Genre{ID: id, Title: *params.Title}
+	return id, nil
+}
+
+func (r *inMemoryRepository) Update(_ context.Context, id int32, params genre.WriteInput) error {
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	r.resource[id] = entity.Genre{ID: id, Title: *params.Title}
+	return nil
+}
+
+func (r *inMemoryRepository) Delete(_ context.Context, id int32, reassignTo *int32) error {
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	if reassignTo != nil {
+		r.reassigned[id] = *reassignTo
+	} else if r.stats[id].BookCount > 0 {
+		return fmt.Errorf("%w: genre %d is still referenced", entity.ErrConflict, id)
+	}
+	delete(r.resource, id)
+	return nil
+}
+
 func TestDriver_List(t *testing.T) {
 
 	g := entity.Genre{ID: 1, Title: "SciFi/Fantasy"}
@@ -33,3 +83,101 @@ func TestDriver_List(t *testing.T) {
 	assert.Len(t, res, 1)
 	assert.Contains(t, res, g)
 }
+
+func TestDriver_Get(t *testing.T) {
+
+	g := entity.Genre{ID: 1, Title: "SciFi/Fantasy"}
+	stats := genre.Stats{
+		BookCount:          12,
+		AuthorCount:        5,
+		AverageRating:      util.Float64Ptr(3.2),
+		FirstYearPublished: util.Int16Ptr(1851),
+		LastYearPublished:  util.Int16Ptr(2004),
+	}
+
+	repo := inMemoryRepository{
+		resource: map[int32]entity.Genre{1: g},
+		stats:    map[int32]genre.Stats{1: stats},
+	}
+
+	driver := genre.NewDriver(&repo)
+
+	res, err := driver.GetGenre(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, genre.Detail{Genre: g, Stats: stats}, res)
+
+	_, err = driver.GetGenre(context.Background(), 2)
+	assert.ErrorIs(t, err, entity.ErrNotFound)
+}
+
+func TestDriver_Write(t *testing.T) {
+
+	fantasy := entity.Genre{ID: 1, Title: "SciFi/Fantasy"}
+	fiction := entity.Genre{ID: 2, Title: "Fiction"}
+
+	newRepo := func() *inMemoryRepository {
+		return &inMemoryRepository{
+			resource:   map[int32]entity.Genre{1: fantasy, 2: fiction},
+			stats:      map[int32]genre.Stats{1: {BookCount: 3}},
+			reassigned: map[int32]int32{},
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		repo := newRepo()
+		res, err := genre.NewDriver(repo).CreateGenre(context.Background(), genre.WriteInput{Title: util.StringPtr("Poetry")})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Genre{ID: 3, Title: "Poetry"}, res)
+		assert.Equal(t, res, repo.resource[3])
+	})
+
+	t.Run("create invalid", func(t *testing.T) {
+		_, err := genre.NewDriver(newRepo()).CreateGenre(context.Background(), genre.WriteInput{})
+		var validationErr *entity.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []entity.FieldError{{Field: "title", Code: validation.CodeRequired, Message: "is required"}}, validationErr.Fields)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		res, err := genre.NewDriver(newRepo()).ReplaceGenre(context.Background(), 2,
+			genre.WriteInput{Title: util.StringPtr("Literary Fiction")})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Genre{ID: 2, Title: "Literary Fiction"}, res)
+	})
+
+	t.Run("replace missing", func(t *testing.T) {
+		_, err := genre.NewDriver(newRepo()).ReplaceGenre(context.Background(), 9,
+			genre.WriteInput{Title: util.StringPtr("Poetry")})
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo()
+		assert.NoError(t, genre.NewDriver(repo).DeleteGenre(context.Background(), 2, nil))
+		assert.NotContains(t, repo.resource, int32(2))
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		err := genre.NewDriver(newRepo()).DeleteGenre(context.Background(), 9, nil)
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete referenced", func(t *testing.T) {
+		repo := newRepo()
+		err := genre.NewDriver(repo).DeleteGenre(context.Background(), 1, nil)
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.Contains(t, repo.resource, int32(1))
+	})
+
+	t.Run("delete referenced with reassignment", func(t *testing.T) {
+		repo := newRepo()
+		assert.NoError(t, genre.NewDriver(repo).DeleteGenre(context.Background(), 1, util.Int32Ptr(2)))
+		assert.NotContains(t, repo.resource, int32(1))
+		assert.Equal(t, map[int32]int32{1: 2}, repo.reassigned)
+	})
+
+	t.Run("reassignment to missing genre", func(t *testing.T) {
+		err := genre.NewDriver(newRepo()).DeleteGenre(context.Background(), 1, util.Int32Ptr(9))
+		assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+	})
+}
@@ -3,6 +3,7 @@ package genretest
 import (
 	"context"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,3 +18,27 @@ func (d *DriverMock) ListGenres(ctx context.Context) ([]entity.Genre, error) {
 	args := d.Called(ctx)
 	return args.Get(0).([]entity.Genre), args.Error(1)
 }
+
+// GetGenre is a mock routine that returns items as instructed.
+func (d *DriverMock) GetGenre(ctx context.Context, id int32) (genre.Detail, error) {
+	args := d.Called(ctx, id)
+	return args.Get(0).(genre.Detail), args.Error(1)
+}
+
+// CreateGenre is a mock routine that returns items as instructed.
+func (d *DriverMock) CreateGenre(ctx context.Context, params genre.WriteInput) (entity.Genre, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).(entity.Genre), args.Error(1)
+}
+
+// ReplaceGenre is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceGenre(ctx context.Context, id int32, params genre.WriteInput) (entity.Genre, error) {
+	args := d.Called(ctx, id, params)
+	return args.Get(0).(entity.Genre), args.Error(1)
+}
+
+// DeleteGenre is a mock routine that returns items as instructed.
+func (d *DriverMock) DeleteGenre(ctx context.Context, id int32, reassignTo *int32) error {
+	args := d.Called(ctx, id, reassignTo)
+	return args.Error(0)
+}
@@ -10,10 +10,44 @@ import (
 type Repository interface {
 	// List should returns all Genres if there are no errors.
 	List(ctx context.Context) ([]entity.Genre, error)
+
+	// Get should return the Genre with the provided ID. If it does not exist, then an error wrapping
+	// entity.ErrNotFound should be returned.
+	Get(ctx context.Context, id int32) (entity.Genre, error)
+
+	// Stats should aggregate the Books of the Genre with the provided ID.
+	Stats(ctx context.Context, id int32) (Stats, error)
+
+	// Create should create a Genre from the WriteInput and return its newly assigned ID.
+	Create(ctx context.Context, params WriteInput) (int32, error)
+
+	// Update should replace the attributes of the Genre with the provided ID. If it does not exist, then an
+	// error wrapping entity.ErrNotFound should be returned.
+	Update(ctx context.Context, id int32, params WriteInput) error
+
+	// Delete should delete the Genre with the provided ID. If reassignTo is not nil, then the Books of the
+	// Genre should first be reassigned to the Genre with that ID, all at once. If the Genre does not exist,
+	// then an error wrapping entity.ErrNotFound should be returned. If Books still reference the Genre, then
+	// an error wrapping entity.ErrConflict should be returned.
+	Delete(ctx context.Context, id int32, reassignTo *int32) error
 }
 
 // Driver is an interface described the contract required to satisfy business usecases.
 type Driver interface {
 	// ListGenres should fetch all Genres and perform intermediary business logic, if any.
 	ListGenres(ctx context.Context) ([]entity.Genre, error)
+
+	// GetGenre should fetch a single Genre by its ID, along with the Stats of the Genre, and
+	// perform intermediary business logic, if any.
+	GetGenre(ctx context.Context, id int32) (Detail, error)
+
+	// CreateGenre should validate the WriteInput and create a Genre from it.
+	CreateGenre(ctx context.Context, params WriteInput) (entity.Genre, error)
+
+	// ReplaceGenre should validate the WriteInput and replace the attributes of an existing Genre with it.
+	ReplaceGenre(ctx context.Context, id int32, params WriteInput) (entity.Genre, error)
+
+	// DeleteGenre should delete an existing Genre, unless Books still reference it and they are not
+	// reassigned to another Genre.
+	DeleteGenre(ctx context.Context, id int32, reassignTo *int32) error
 }
@@ -0,0 +1,91 @@
+package genre
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/pkg/errors"
+)
+
+// WriteInput is an input parameter for CreateGenre and ReplaceGenre. It holds the attributes of a Genre to be
+// written, which are all required.
+type WriteInput struct {
+	_ struct{}
+
+	Title *string
+}
+
+// CreateGenre validates the WriteInput and creates a Genre from it. The repository assigns the Genre's ID. The
+// created entity.Genre is returned. If the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) CreateGenre(ctx context.Context, params WriteInput) (entity.Genre, error) {
+	if err := validate(params); err != nil {
+		return entity.Genre{}, err
+	}
+
+	id, err := d.repository.Create(ctx, params)
+	if err != nil {
+		return entity.Genre{}, err
+	}
+
+	return d.repository.Get(ctx, id)
+}
+
+// ReplaceGenre validates the WriteInput and replaces every attribute of the Genre with the provided ID with it.
+// The updated entity.Genre is returned. If the Genre does not exist, then an error wrapping entity.ErrNotFound
+// is returned. If the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) ReplaceGenre(ctx context.Context, id int32, params WriteInput) (entity.Genre, error) {
+	if err := validate(params); err != nil {
+		return entity.Genre{}, err
+	}
+
+	if err := d.repository.Update(ctx, id, params); err != nil {
+		return entity.Genre{}, err
+	}
+
+	return d.repository.Get(ctx, id)
+}
+
+// DeleteGenre deletes the Genre with the provided ID. If the Genre does not exist, then an error wrapping
+// entity.ErrNotFound is returned. If Books still reference the Genre, then they are reassigned to the Genre
+// identified by reassignTo. If reassignTo is nil, then an error wrapping entity.ErrConflict is returned instead.
+// If reassignTo does not identify another existing Genre, then an error wrapping entity.ErrInvalidQueryParam
+// is returned.
+func (d *driver) DeleteGenre(ctx context.Context, id int32, reassignTo *int32) error {
+	if _, err := d.repository.Get(ctx, id); err != nil {
+		return err
+	}
+
+	if reassignTo != nil {
+		if *reassignTo == id {
+			return fmt.Errorf("%w: cannot reassign the books of genre %d to itself", entity.ErrInvalidQueryParam, id)
+		}
+		if _, err := d.repository.Get(ctx, *reassignTo); errors.Is(err, entity.ErrNotFound) {
+			return fmt.Errorf("%w: genre %d does not exist", entity.ErrInvalidQueryParam, *reassignTo)
+		} else if err != nil {
+			return err
+		}
+		return d.repository.Delete(ctx, id, reassignTo)
+	}
+
+	stats, err := d.repository.Stats(ctx, id)
+	if err != nil {
+		return err
+	}
+	if stats.BookCount > 0 {
+		return fmt.Errorf("%w: genre %d still has %d books, which must be reassigned to another genre",
+			entity.ErrConflict, id, stats.BookCount)
+	}
+
+	return d.repository.Delete(ctx, id, nil)
+}
+
+// validate checks every attribute of the WriteInput, which are all required. If any attribute is invalid, then
+// an *entity.ValidationError listing all of them is returned.
+func validate(params WriteInput) error {
+	v := validation.New(entity.ErrInvalidEntity)
+	v.Required("title", params.Title != nil)
+	v.NotBlank("title", params.Title)
+	return v.Err()
+}
@@ -10,10 +10,41 @@ import (
 type Repository interface {
 	// List should return all Sizes.
 	List(ctx context.Context) ([]entity.Size, error)
+
+	// Create should create a Size from the WriteInput and return its newly assigned ID. Within the same
+	// transaction, and before it is written, check should be called with every Size. If it returns an error, then
+	// no Size should be created and that error should be returned. Concurrent writes should not interleave.
+	Create(ctx context.Context, params WriteInput, check func([]entity.Size) error) (int32, error)
+
+	// Update should replace the attributes of the Size with the provided ID. If it does not exist, then an
+	// error wrapping entity.ErrNotFound should be returned. check should be called as it is by Create.
+	Update(ctx context.Context, id int32, params WriteInput, check func([]entity.Size) error) error
+
+	// Delete should delete the Size with the provided ID. If it does not exist, then an error wrapping
+	// entity.ErrNotFound should be returned. check should be called as it is by Create.
+	Delete(ctx context.Context, id int32, check func([]entity.Size) error) error
+
+	// ReplaceAll should atomically replace every Size with those in the BulkInput, and return them in the
+	// same order with their IDs. Within the same transaction, and before anything is written, check should be
+	// called with every Size. If it returns an error, then no Size should be changed and that error should be
+	// returned. Concurrent calls should not interleave, so that each checks the Size the other has written.
+	ReplaceAll(ctx context.Context, params []BulkInput, check func([]entity.Size) error) ([]entity.Size, error)
 }
 
 // Driver is an interface described the contract required to satisfy business usecases.
 type Driver interface {
 	// ListSizes should fetch all Sizes an perform intermediary business logic, if any.
 	ListSizes(ctx context.Context) ([]entity.Size, error)
+
+	// CreateSize should validate the WriteInput and create a Size from it.
+	CreateSize(ctx context.Context, params WriteInput) (entity.Size, error)
+
+	// ReplaceSize should validate the WriteInput and replace the attributes of an existing Size with it.
+	ReplaceSize(ctx context.Context, id int32, params WriteInput) (entity.Size, error)
+
+	// DeleteSize should delete an existing Size, unless doing so leaves a gap between the remaining Sizes.
+	DeleteSize(ctx context.Context, id int32) error
+
+	// ReplaceAllSizes should validate the BulkInput and replace every Size with it.
+	ReplaceAllSizes(ctx context.Context, params []BulkInput) ([]entity.Size, error)
 }
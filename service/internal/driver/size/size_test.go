@@ -2,10 +2,13 @@ package size_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/validation"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,6 +24,79 @@ func (r *inMemoryRepository) List(_ context.Context) ([]entity.Size, error) {
 	return data, nil
 }
 
+func (r *inMemoryRepository) Create(ctx context.Context, params size.WriteInput,
+	check func([]entity.Size) error) (int32, error) {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return 0, err
+	}
+	var id int32
+	for existing := range r.resource {
+		if existing > id {
+			id = existing
+		}
+	}
+	id++
+	r.resource[id] = entity.Size{ID: id, Title: *params.Title, MinPages: params.MinPages, MaxPages: params.MaxPages}
+	return id, nil
+}
+
+func (r *inMemoryRepository) Update(ctx context.Context, id int32, params size.WriteInput,
+	check func([]entity.Size) error) error {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return err
+	}
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: size %d does not exist", entity.ErrNotFound, id)
+	}
+	r.resource[id] = entity.Size{ID: id, Title: *params.Title, MinPages: params.MinPages, MaxPages: params.MaxPages}
+	return nil
+}
+
+func (r *inMemoryRepository) Delete(ctx context.Context, id int32, check func([]entity.Size) error) error {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return err
+	}
+	if _, ok := r.resource[id]; !ok {
+		return fmt.Errorf("%w: size %d does not exist", entity.ErrNotFound, id)
+	}
+	delete(r.resource, id)
+	return nil
+}
+
+func (r *inMemoryRepository) ReplaceAll(ctx context.Context, params []size.BulkInput,
+	check func([]entity.Size) error) ([]entity.Size, error) {
+	current, _ := r.List(ctx)
+	if err := check(current); err != nil {
+		return nil, err
+	}
+
+	kept := map[int32]bool{}
+	for _, p := range params {
+		if p.ID != nil {
+			kept[*p.ID] = true
+		}
+	}
+	for id := range r.resource {
+		if !kept[id] {
+			delete(r.resource, id)
+		}
+	}
+
+	sizes := make([]entity.Size, len(params))
+	for i, p := range params {
+		if p.ID == nil {
+			id, _ := r.Create(ctx, p.WriteInput, func([]entity.Size) error { return nil })
+			p.ID = &id
+		}
+		sizes[i] = entity.Size{ID: *p.ID, Title: *p.Title, MinPages: p.MinPages, MaxPages: p.MaxPages}
+		r.resource[*p.ID] = sizes[i]
+	}
+	return sizes, nil
+}
+
 func TestDriver_List(t *testing.T) {
 
 	s := entity.Size{ID: 1, Title: "Any"}
@@ -33,3 +109,167 @@ func TestDriver_List(t *testing.T) {
 	assert.Len(t, res, 1)
 	assert.Contains(t, res, s)
 }
+
+func TestDriver_Write(t *testing.T) {
+
+	newRepo := func() *inMemoryRepository {
+		return &inMemoryRepository{resource: map[int32]entity.Size{
+			0: {ID: 0, Title: "Any"},
+			1: {ID: 1, Title: "Short story", MaxPages: util.Int16Ptr(84)},
+			2: {ID: 2, Title: "Novelette", MinPages: util.Int16Ptr(85)},
+		}}
+	}
+
+	validationFields := func(t *testing.T, err error) []entity.FieldError {
+		var validationErr *entity.ValidationError
+		if assert.ErrorAs(t, err, &validationErr) {
+			return validationErr.Fields
+		}
+		return nil
+	}
+
+	t.Run("create", func(t *testing.T) {
+		repo := newRepo()
+		repo.resource[2] = entity.Size{ID: 2, Title: "Novelette", MinPages: util.Int16Ptr(85), MaxPages: util.Int16Ptr(199)}
+
+		res, err := size.NewDriver(repo).CreateSize(context.Background(),
+			size.WriteInput{Title: util.StringPtr("Novel"), MinPages: util.Int16Ptr(200)})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Size{ID: 3, Title: "Novel", MinPages: util.Int16Ptr(200)}, res)
+		assert.Equal(t, res, repo.resource[3])
+	})
+
+	t.Run("create overlapping", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).CreateSize(context.Background(),
+			size.WriteInput{Title: util.StringPtr("Novel"), MinPages: util.Int16Ptr(200)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "minPages", Code: validation.CodeConflict, Message: `overlaps size "Novelette", which has no maxPages`},
+		}, validationFields(t, err))
+	})
+
+	t.Run("create inverted range", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).CreateSize(context.Background(),
+			size.WriteInput{MinPages: util.Int16Ptr(200), MaxPages: util.Int16Ptr(190)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "title", Code: validation.CodeRequired, Message: "is required"},
+			{Field: "maxPages", Code: validation.CodeMinExceedsMax, Message: "is 190 but should not be less than minPages 200"},
+		}, validationFields(t, err))
+	})
+
+	t.Run("replace title", func(t *testing.T) {
+		res, err := size.NewDriver(newRepo()).ReplaceSize(context.Background(), 1,
+			size.WriteInput{Title: util.StringPtr("Short stories"), MaxPages: util.Int16Ptr(84)})
+		assert.NoError(t, err)
+		assert.Equal(t, entity.Size{ID: 1, Title: "Short stories", MaxPages: util.Int16Ptr(84)}, res)
+	})
+
+	t.Run("replace leaving a gap", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).ReplaceSize(context.Background(), 1,
+			size.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(74)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "maxPages", Code: validation.CodeConflict, Message: `should be 84 to precede size "Novelette"`},
+		}, validationFields(t, err))
+	})
+
+	t.Run("replace missing", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).ReplaceSize(context.Background(), 9,
+			size.WriteInput{Title: util.StringPtr("Epic")})
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := newRepo()
+		assert.NoError(t, size.NewDriver(repo).DeleteSize(context.Background(), 2))
+		assert.NotContains(t, repo.resource, int32(2))
+	})
+
+	t.Run("delete missing", func(t *testing.T) {
+		err := size.NewDriver(newRepo()).DeleteSize(context.Background(), 9)
+		assert.ErrorIs(t, err, entity.ErrNotFound)
+	})
+
+	t.Run("replace bounding any", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).ReplaceSize(context.Background(), 0,
+			size.WriteInput{Title: util.StringPtr("Any"), MaxPages: util.Int16Ptr(74)})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "maxPages", Code: validation.CodeConflict, Message: "must be omitted, as size 0 matches every value"},
+		}, validationFields(t, err))
+	})
+
+	t.Run("delete any", func(t *testing.T) {
+		repo := newRepo()
+		err := size.NewDriver(repo).DeleteSize(context.Background(), 0)
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.Contains(t, repo.resource, int32(0))
+	})
+
+	t.Run("delete leaving a gap", func(t *testing.T) {
+		repo := newRepo()
+		repo.resource[2] = entity.Size{ID: 2, Title: "Novelette", MinPages: util.Int16Ptr(85), MaxPages: util.Int16Ptr(199)}
+		repo.resource[3] = entity.Size{ID: 3, Title: "Novel", MinPages: util.Int16Ptr(200)}
+
+		err := size.NewDriver(repo).DeleteSize(context.Background(), 2)
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.EqualError(t, err, "conflict with the current state of the resources: "+
+			`deleting size 2 would leave a gap between size "Short story" and size "Novel"`)
+		assert.Contains(t, repo.resource, int32(2))
+	})
+
+	t.Run("replace all", func(t *testing.T) {
+		repo := newRepo()
+		res, err := size.NewDriver(repo).ReplaceAllSizes(context.Background(), []size.BulkInput{
+			{ID: util.Int32Ptr(0), WriteInput: size.WriteInput{Title: util.StringPtr("Any")}},
+			{ID: util.Int32Ptr(1), WriteInput: size.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(74)}},
+			{WriteInput: size.WriteInput{Title: util.StringPtr("Novelette"), MinPages: util.Int16Ptr(75)}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []entity.Size{
+			{ID: 0, Title: "Any"},
+			{ID: 1, Title: "Short story", MaxPages: util.Int16Ptr(74)},
+			{ID: 2, Title: "Novelette", MinPages: util.Int16Ptr(75)},
+		}, res)
+		assert.Len(t, repo.resource, 3)
+	})
+
+	t.Run("replace all invalid", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).ReplaceAllSizes(context.Background(), []size.BulkInput{
+			{ID: util.Int32Ptr(1), WriteInput: size.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(74)}},
+			{ID: util.Int32Ptr(1), WriteInput: size.WriteInput{Title: util.StringPtr("Novelette"), MinPages: util.Int16Ptr(85)}},
+			{ID: util.Int32Ptr(9), WriteInput: size.WriteInput{Title: util.StringPtr("Any")}},
+		})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "[0].maxPages", Code: validation.CodeConflict, Message: `should be 84 to precede size "Novelette"`},
+			{Field: "[1].id", Code: validation.CodeConflict, Message: "size 1 is repeated"},
+			{Field: "[1].minPages", Code: validation.CodeConflict, Message: `should be 75 to follow size "Short story"`},
+			{Field: "[2].id", Code: validation.CodeNotFound, Message: "size 9 does not exist"},
+		}, validationFields(t, err))
+	})
+
+	t.Run("replace all with nothing", func(t *testing.T) {
+		repo := newRepo()
+		_, err := size.NewDriver(repo).ReplaceAllSizes(context.Background(), []size.BulkInput{})
+		assert.ErrorIs(t, err, entity.ErrInvalidBody)
+		assert.Len(t, repo.resource, 3)
+	})
+
+	t.Run("replace all leaving out any", func(t *testing.T) {
+		repo := newRepo()
+		_, err := size.NewDriver(repo).ReplaceAllSizes(context.Background(), []size.BulkInput{
+			{ID: util.Int32Ptr(1), WriteInput: size.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(74)}},
+			{ID: util.Int32Ptr(2), WriteInput: size.WriteInput{Title: util.StringPtr("Novelette"), MinPages: util.Int16Ptr(75)}},
+		})
+		assert.ErrorIs(t, err, entity.ErrConflict)
+		assert.Contains(t, repo.resource, int32(0))
+	})
+
+	t.Run("replace all bounding any", func(t *testing.T) {
+		_, err := size.NewDriver(newRepo()).ReplaceAllSizes(context.Background(), []size.BulkInput{
+			{ID: util.Int32Ptr(0), WriteInput: size.WriteInput{Title: util.StringPtr("Any"), MinPages: util.Int16Ptr(1)}},
+			{ID: util.Int32Ptr(1), WriteInput: size.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(74)}},
+			{ID: util.Int32Ptr(2), WriteInput: size.WriteInput{Title: util.StringPtr("Novelette"), MinPages: util.Int16Ptr(75)}},
+		})
+		assert.Equal(t, []entity.FieldError{
+			{Field: "[0].minPages", Code: validation.CodeConflict, Message: "must be omitted, as size 0 matches every value"},
+		}, validationFields(t, err))
+	})
+}
@@ -3,6 +3,7 @@ package sizetest
 import (
 	"context"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,3 +18,27 @@ func (d *DriverMock) ListSizes(ctx context.Context) ([]entity.Size, error) {
 	args := d.Called(ctx)
 	return args.Get(0).([]entity.Size), args.Error(1)
 }
+
+// CreateSize is a mock routine that returns items as instructed.
+func (d *DriverMock) CreateSize(ctx context.Context, params size.WriteInput) (entity.Size, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).(entity.Size), args.Error(1)
+}
+
+// ReplaceSize is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceSize(ctx context.Context, id int32, params size.WriteInput) (entity.Size, error) {
+	args := d.Called(ctx, id, params)
+	return args.Get(0).(entity.Size), args.Error(1)
+}
+
+// DeleteSize is a mock routine that returns items as instructed.
+func (d *DriverMock) DeleteSize(ctx context.Context, id int32) error {
+	args := d.Called(ctx, id)
+	return args.Error(0)
+}
+
+// ReplaceAllSizes is a mock routine that returns items as instructed.
+func (d *DriverMock) ReplaceAllSizes(ctx context.Context, params []size.BulkInput) ([]entity.Size, error) {
+	args := d.Called(ctx, params)
+	return args.Get(0).([]entity.Size), args.Error(1)
+}
@@ -0,0 +1,108 @@
+package size
+
+import (
+	"context"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/bucket"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+)
+
+// names words the FieldErrors about the ranges of Sizes.
+var names = bucket.Names{Kind: "size", Min: "minPages", Max: "maxPages"}
+
+// WriteInput is an input parameter for CreateSize and ReplaceSize. It holds the attributes of a Size to be written.
+// The Title is required. A nil MinPages or MaxPages leaves that end of the Size's range open.
+type WriteInput struct {
+	_ struct{}
+
+	Title    *string
+	MinPages *int16
+	MaxPages *int16
+}
+
+// BulkInput is an element of the input parameter for ReplaceAllSizes. If the ID is nil, then a new Size is
+// created from the WriteInput. Otherwise the existing Size with the ID is replaced with it.
+type BulkInput struct {
+	ID *int32
+
+	WriteInput
+}
+
+// CreateSize validates the WriteInput and creates a Size from it. The repository assigns the Size's ID. The range
+// of the new Size must adjoin the ranges of the existing ones, which are read within the transaction which
+// creates it, so that concurrent writes cannot interleave. The created entity.Size is returned. If the
+// WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) CreateSize(ctx context.Context, params WriteInput) (entity.Size, error) {
+	id, err := d.repository.Create(ctx, params, func(sizes []entity.Size) error {
+		return bucket.CheckCreate(names, toStored(sizes), params.input())
+	})
+	if err != nil {
+		return entity.Size{}, err
+	}
+
+	return params.size(id), nil
+}
+
+// ReplaceSize validates the WriteInput and replaces every attribute of the Size with the provided ID with it. The
+// new range must adjoin the ranges of the other Sizes, and the "Any" Size must stay unbounded. As with
+// CreateSize, this is checked within the transaction which writes it. The updated
+// entity.Size is returned. If the Size does not exist, then an error wrapping entity.ErrNotFound is returned. If
+// the WriteInput is invalid, then an *entity.ValidationError is returned.
+func (d *driver) ReplaceSize(ctx context.Context, id int32, params WriteInput) (entity.Size, error) {
+	err := d.repository.Update(ctx, id, params, func(sizes []entity.Size) error {
+		return bucket.CheckUpdate(names, toStored(sizes), id, params.input())
+	})
+	if err != nil {
+		return entity.Size{}, err
+	}
+
+	return params.size(id), nil
+}
+
+// DeleteSize deletes the Size with the provided ID. If the Size does not exist, then an error wrapping
+// entity.ErrNotFound is returned. If the Size is "Any", or deleting it would leave a gap between the ranges of
+// the remaining Sizes, then an error wrapping entity.ErrConflict is returned instead. As with CreateSize, this
+// is checked within the transaction which deletes it.
+func (d *driver) DeleteSize(ctx context.Context, id int32) error {
+	return d.repository.Delete(ctx, id, func(sizes []entity.Size) error {
+		return bucket.CheckDelete(names, toStored(sizes), id)
+	})
+}
+
+// ReplaceAllSizes validates the BulkInput and replaces every Size with it, so that the boundaries between Sizes
+// can be moved at once. Existing Sizes which are not in the BulkInput are deleted, except for "Any", which must be
+// kept. The BulkInput is validated against the Sizes within the transaction which replaces them, so that
+// concurrent replacements cannot interleave. The resulting entity.Sizes are returned in the order of the
+// BulkInput. If the BulkInput is empty, then an error wrapping entity.ErrInvalidBody is returned, and if it
+// leaves out "Any", then an error wrapping entity.ErrConflict is returned. If the BulkInput is otherwise
+// invalid, then an *entity.ValidationError is returned, whose fields are prefixed by the index of the invalid
+// element, such as "[1].minPages".
+func (d *driver) ReplaceAllSizes(ctx context.Context, params []BulkInput) ([]entity.Size, error) {
+	replacements := make([]bucket.Replacement, len(params))
+	for i, p := range params {
+		replacements[i] = bucket.Replacement{ID: p.ID, Input: p.input()}
+	}
+
+	return d.repository.ReplaceAll(ctx, params, func(sizes []entity.Size) error {
+		return bucket.CheckReplaceAll(names, toStored(sizes), replacements)
+	})
+}
+
+// input maps the WriteInput to a bucket.Input.
+func (p WriteInput) input() bucket.Input {
+	return bucket.Input{Title: p.Title, Min: p.MinPages, Max: p.MaxPages}
+}
+
+// size maps the WriteInput to an entity.Size with the provided ID.
+func (p WriteInput) size(id int32) entity.Size {
+	return entity.Size{ID: id, Title: *p.Title, MinPages: p.MinPages, MaxPages: p.MaxPages}
+}
+
+// toStored maps the entity.Sizes to bucket.Stored, by index.
+func toStored(sizes []entity.Size) []bucket.Stored {
+	stored := make([]bucket.Stored, len(sizes))
+	for i, e := range sizes {
+		stored[i] = bucket.Stored{ID: e.ID, Bucket: bucket.Bucket{Title: e.Title, Min: e.MinPages, Max: e.MaxPages}}
+	}
+	return stored
+}
@@ -23,4 +23,8 @@ type Book struct {
 
 	// Author is the author/writer of the Book.
 	Author Author `json:"author"`
+
+	// Relevance scores how well the Book matched a search query. It is only set when
+	// the Book was found by a query.
+	Relevance float64 `json:"relevance,omitempty"`
 }
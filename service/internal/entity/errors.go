@@ -2,9 +2,110 @@ package entity
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 )
 
 var (
 	// ErrInvalidQueryParam occurs when an invalid parameter range or type was provided.
 	ErrInvalidQueryParam = errors.New("invalid URL query parameter provided")
+
+	// ErrInvalidBody occurs when a request body cannot be decoded.
+	ErrInvalidBody = errors.New("invalid request body provided")
+
+	// ErrInvalidEntity occurs when an entity to be written fails validation.
+	ErrInvalidEntity = errors.New("invalid entity provided")
+
+	// ErrNotFound occurs when a requested resource does not exist.
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrConflict occurs when a write would leave the resources in an inconsistent state, such as when deleting
+	// a resource which others still reference.
+	ErrConflict = errors.New("conflict with the current state of the resources")
+
+	// ErrCanceled occurs when the client gave up on a request, such as by closing its connection, before it was
+	// served.
+	ErrCanceled = errors.New("request canceled")
+
+	// ErrTimeout occurs when an operation, such as a query, did not complete in time.
+	ErrTimeout = errors.New("operation timed out")
+
+	// ErrUnavailable occurs when a dependency of the service, such as the database, cannot be reached or cannot
+	// take on more work. The request may succeed if it is retried later.
+	ErrUnavailable = errors.New("service unavailable")
 )
+
+// IsClientError returns true if the error wraps one of the errors above which are caused by the request, such
+// as a missing or invalid entity or a request which was canceled, rather than by a failure of the service.
+func IsClientError(err error) bool {
+	for _, target := range []error{ErrInvalidQueryParam, ErrInvalidBody, ErrInvalidEntity, ErrNotFound, ErrConflict, ErrCanceled} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Error is an error of one of the kinds above, such as ErrUnavailable, which was caused by another error, such as
+// a failed connection to the database. errors.Is matches it to its Kind, and errors.As can still reach its cause.
+type Error struct {
+	// Kind is one of the errors above.
+	Kind error
+
+	// Err is the cause of the error.
+	Err error
+}
+
+// Error describes the Kind of the Error and its cause.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+}
+
+// Is returns true if the target is the Kind of the Error.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// Unwrap returns the cause of the Error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes why a single field of an entity is invalid.
+type FieldError struct {
+	// Field is the name of the invalid field, as it is named in JSON or in the query.
+	Field string `json:"field"`
+
+	// Code tells what is wrong with the field, such as "out_of_range". Unlike the Message, it is stable, so
+	// clients may act on it. It is empty where no code has been assigned.
+	Code string `json:"code,omitempty"`
+
+	// Message describes why the field is invalid.
+	Message string `json:"message"`
+}
+
+// ValidationError holds every FieldError found while validating an entity or the parameters of a request. It
+// wraps its Kind, which is ErrInvalidEntity unless it is set.
+type ValidationError struct {
+	// Kind is the error which the ValidationError wraps, such as ErrInvalidQueryParam.
+	Kind error
+
+	Fields []FieldError
+}
+
+// Error lists the FieldErrors of the ValidationError.
+func (e *ValidationError) Error() string {
+	fields := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		fields[i] = fmt.Sprintf("%s %s", f.Field, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Unwrap(), strings.Join(fields, "; "))
+}
+
+// Unwrap returns the Kind of the ValidationError, or ErrInvalidEntity if it has none.
+func (e *ValidationError) Unwrap() error {
+	if e.Kind == nil {
+		return ErrInvalidEntity
+	}
+	return e.Kind
+}
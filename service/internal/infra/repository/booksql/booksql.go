@@ -0,0 +1,454 @@
+// Package booksql builds the SQL of book searches and facet counts, which filter Books in the same way on every
+// database. What differs between databases, such as placeholders and how queries are matched, is described by a
+// Dialect.
+package booksql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	sq "github.com/Masterminds/squirrel"
+	"go.uber.org/zap"
+)
+
+// Dialect describes the SQL of a database wherever book searches depend on it. Every expression refers to the
+// book table joined with the author table.
+type Dialect struct {
+	// Name of the database, such as "postgres", which is logged.
+	Name string
+
+	// Placeholder formats the placeholders of every statement.
+	Placeholder sq.PlaceholderFormat
+
+	// TitleSortKey is a SQL expression template that computes a library-style sort key for a title, which is
+	// substituted for its %s. The key is lower-cased and has any leading article ("the", "a" or "an") removed.
+	TitleSortKey string
+
+	// SearchMatch is a SQL predicate which includes Books that match book.SearchInput.Query, either through
+	// full-text search or, to tolerate typos, through similarity. Every placeholder takes the query.
+	SearchMatch string
+
+	// SearchRelevance is a SQL expression which ranks how well a Book matches book.SearchInput.Query. It must be
+	// rounded so that it can be compared exactly when resuming from a book.Cursor. Every placeholder takes the
+	// query.
+	SearchRelevance string
+
+	// Relevance and Rating convert the values held by a book.Cursor to arguments which compare exactly against
+	// SearchRelevance and the rating column.
+	Relevance func(float64) interface{}
+	Rating    func(float32) interface{}
+
+	// StageImport inserts every Record of the Source into the ImportTable, within the transaction, until the
+	// Source is exhausted. If it is nil, then the Records are inserted one at a time by InsertStaged.
+	StageImport func(ctx context.Context, tx *sql.Tx, records catalog.Source) error
+}
+
+// Queryer is satisfied by both *sql.DB and *sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Searcher searches the Books of a database, and counts their facets, in the database's Dialect.
+type Searcher struct {
+	db      Queryer
+	dialect Dialect
+	logger  *zap.Logger
+}
+
+// NewSearcher creates a Searcher over the database, which speaks the Dialect.
+func NewSearcher(db Queryer, dialect Dialect, logger *zap.Logger) *Searcher {
+	return &Searcher{db: db, dialect: dialect, logger: logger}
+}
+
+// Search selects the Books which match every filter of the search parameters, ordered by the keys of its Sort
+// and resumed after its Cursor. If a Query is given, then the Relevance of each Book is set. If the query fails
+// or encounters an error while cursing through the result set, then an error is returned.
+func (s *Searcher) Search(ctx context.Context, params book.SearchInput) ([]entity.Book, error) {
+	var books []entity.Book
+	err := s.Each(ctx, params, func(b entity.Book) error {
+		books = append(books, b)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug(fmt.Sprintf("found %d books in %s repository", len(books), s.dialect.Name))
+
+	return books, nil
+}
+
+// Each selects the Books that Search would, and calls each with every one of them as it is scanned, so that
+// they need not all be held in memory. If each returns an error, then no more Books are scanned and the error
+// is returned.
+func (s *Searcher) Each(ctx context.Context, params book.SearchInput, each func(entity.Book) error) error {
+	d := s.dialect
+
+	/*
+	 * Start building SQL query
+	 */
+	builder := d.SelectBooks()
+
+	if params.Query != nil {
+		builder = builder.Column(sq.Alias(sq.Expr(d.SearchRelevance, queryArgs(d.SearchRelevance, *params.Query)...), "relevance"))
+	}
+
+	keys := params.Sort.Keys()
+	builder = d.orderBy(builder, keys, params)
+	builder = d.whereMatches(builder, params)
+
+	if params.After != nil {
+		builder = d.whereAfter(builder, keys, params)
+	}
+
+	if params.Limit != nil {
+		builder = builder.Limit(*params.Limit)
+	}
+
+	query, values, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+	s.logger.Debug(fmt.Sprintf("search book query: %s\n search book values: %v\n", query, values))
+	/*
+	 * Finish building SQL query
+	 */
+
+	rows, err := s.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to get books: %w", err)
+	}
+	defer rows.Close()
+
+	// Iterate over result-set, map each row to an entity.Book, and hand it to each.
+	for rows.Next() {
+		var b entity.Book
+		dest := BookDest(&b)
+		if params.Query != nil {
+			dest = append(dest, &b.Relevance)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return fmt.Errorf("unable to scan data into b: %w", err)
+		}
+		if err = each(b); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Export calls each with every Book that matches the search parameters, as Searcher.Each does. The Books are
+// selected in a read-only, repeatable-read transaction, so that they form a consistent snapshot however long
+// the export takes.
+func Export(ctx context.Context, db *sql.DB, dialect Dialect, logger *zap.Logger, params book.SearchInput, each func(entity.Book) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var exported int
+	err = NewSearcher(tx, dialect, logger).Each(ctx, params, func(b entity.Book) error {
+		exported++
+		return each(b)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+
+	logger.Debug(fmt.Sprintf("exported %d books from %s repository", exported, dialect.Name))
+	return nil
+}
+
+// Facets counts the Books which match the search parameters of each facet in the book.FacetInput. Each facet is
+// counted with a single aggregate query, which includes every bucket of the facet, even if no Books fall into
+// it. If a query fails or encounters an error while cursing through a result set, then an error is returned.
+func (s *Searcher) Facets(ctx context.Context, params book.FacetInput) (book.Facets, error) {
+	var (
+		facets book.Facets
+		err    error
+	)
+
+	facets.Genres, err = s.countFacet(ctx, params.Genres, "genre", "matched.genre_id = genre.id")
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	facets.Authors, err = s.countFacet(ctx, params.Authors, "author", "matched.author_id = author.id")
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	facets.Eras, err = s.countFacet(ctx, params.Eras, "era",
+		"(era.min_year IS NULL OR matched.year_published >= era.min_year) AND "+
+			"(era.max_year IS NULL OR matched.year_published <= era.max_year)")
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	facets.Sizes, err = s.countFacet(ctx, params.Sizes, "size",
+		"(size.min_pages IS NULL OR matched.pages >= size.min_pages) AND "+
+			"(size.max_pages IS NULL OR matched.pages <= size.max_pages)")
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	return facets, nil
+}
+
+// countFacet counts the Books which match the search parameters for every row of the facet's table. The
+// matching Books are selected in a subquery, aliased "matched", which is joined to the table on the condition.
+func (s *Searcher) countFacet(ctx context.Context, params book.SearchInput, table, on string) ([]book.FacetCount, error) {
+	d := s.dialect
+
+	matched, matchedValues, err := d.whereMatches(
+		sq.Select("book.id", "book.genre_id", "book.author_id", "book.year_published", "book.pages").
+			From("book").
+			LeftJoin("author ON book.author_id = author.id"),
+		params).
+		PlaceholderFormat(sq.Question).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(d.Placeholder).
+		Select(table+".id", "count(matched.id)").
+		From(table).
+		LeftJoin(fmt.Sprintf("(%s) AS matched ON %s", matched, on), matchedValues...).
+		GroupBy(table + ".id").
+		OrderBy(table + ".id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+	s.logger.Debug(fmt.Sprintf("count %s facet query: %s\n count %s facet values: %v\n", table, query, table, values))
+
+	rows, err := s.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count %s facet: %w", table, err)
+	}
+	defer rows.Close()
+
+	var counts []book.FacetCount
+
+	// Iterate over result-set, map to book.FacetCount, and place in resulting slice.
+	for rows.Next() {
+		var c book.FacetCount
+		if err = rows.Scan(&c.ID, &c.Count); err != nil {
+			return nil, fmt.Errorf("unable to scan data into %s facet: %w", table, err)
+		}
+		counts = append(counts, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// SelectBooks returns a query builder which selects the columns of Books, joined with their Author and Genre,
+// in the order expected by BookDest.
+func (d Dialect) SelectBooks() sq.SelectBuilder {
+	return sq.StatementBuilder.PlaceholderFormat(d.Placeholder).
+		Select("book.id", "book.title", "year_published", "rating",
+			"pages", "author.id", "first_name", "last_name", "genre.id", "genre.title").
+		From("book").
+		LeftJoin("author ON book.author_id = author.id").
+		LeftJoin("genre ON book.genre_id = genre.id")
+}
+
+// BookDest returns the scan destinations for the columns selected by SelectBooks.
+func BookDest(b *entity.Book) []interface{} {
+	return []interface{}{&b.ID,
+		&b.Title,
+		&b.YearPublished,
+		&b.Rating,
+		&b.Pages,
+		&b.Author.ID,
+		&b.Author.FirstName,
+		&b.Author.LastName,
+		&b.Genre.ID,
+		&b.Genre.Title}
+}
+
+// queryArgs returns the arguments of an expression whose placeholders all take the query.
+func queryArgs(expr, query string) []interface{} {
+	args := make([]interface{}, strings.Count(expr, "?"))
+	for i := range args {
+		args[i] = query
+	}
+	return args
+}
+
+// whereMatches accepts a query builder which selects from book, joined with author, and adds SQL WHERE
+// clauses for every filter of the search parameters. Ordering and pagination are not applied. The mutated
+// builder is returned.
+func (d Dialect) whereMatches(builder sq.SelectBuilder, params book.SearchInput) sq.SelectBuilder {
+	if params.Query != nil {
+		builder = builder.PlaceholderFormat(d.Placeholder).Where(d.SearchMatch, queryArgs(d.SearchMatch, *params.Query)...)
+	}
+
+	builder = d.whereInt16In(builder, "author_id", params.AuthorIDs)
+	builder = d.whereInt16In(builder, "genre_id", params.GenreIDs)
+
+	if params.Title != nil {
+		builder = builder.PlaceholderFormat(d.Placeholder).Where(sq.Eq{"book.title": *params.Title})
+	}
+
+	builder = d.whereInt16Between(builder, "pages", params.MinPages, params.MaxPages)
+	builder = d.whereInt16Between(builder, "year_published", params.MinYearPublished, params.MaxYearPublished)
+	builder = d.whereInt16InRanges(builder, "pages", params.PageRanges)
+	builder = d.whereInt16InRanges(builder, "year_published", params.YearRanges)
+
+	return builder
+}
+
+// sortColumn returns the SQL expression, and its arguments, which orders Books by the provided book.SortField.
+func (d Dialect) sortColumn(f book.SortField, params book.SearchInput) (string, []interface{}) {
+	switch f {
+	case book.SortByRelevance:
+		return d.SearchRelevance, queryArgs(d.SearchRelevance, *params.Query)
+	case book.SortByRating:
+		return "rating", nil
+	case book.SortByYearPublished:
+		return "year_published", nil
+	case book.SortByPages:
+		return "pages", nil
+	case book.SortByTitle:
+		return fmt.Sprintf(d.TitleSortKey, "book.title"), nil
+	default:
+		return "book.id", nil
+	}
+}
+
+// sortValue returns the SQL expression and argument to compare the sortColumn of the provided book.SortField
+// against the value held by a book.Cursor. Titles are compared by their sort key. The rating and relevance
+// are converted by the Dialect so that they compare exactly.
+func (d Dialect) sortValue(f book.SortField, after *book.Cursor) (string, interface{}) {
+	switch f {
+	case book.SortByRelevance:
+		return "?", d.Relevance(after.Relevance)
+	case book.SortByRating:
+		return "?", d.Rating(after.Rating)
+	case book.SortByTitle:
+		return fmt.Sprintf(d.TitleSortKey, "?"), after.Title
+	default:
+		return "?", after.Value(f)
+	}
+}
+
+// orderBy accepts a query builder, the book.SortKeys to order by and the search parameters. An ORDER BY
+// clause is added for each key, in order. The mutated builder is returned.
+func (d Dialect) orderBy(builder sq.SelectBuilder, keys book.Sort, params book.SearchInput) sq.SelectBuilder {
+	for _, k := range keys {
+		col, args := d.sortColumn(k.Field, params)
+		if k.Descending {
+			col += " DESC"
+		}
+		builder = builder.OrderByClause(col, args...)
+	}
+	return builder
+}
+
+// whereAfter accepts a query builder, the book.SortKeys the results are ordered by and the search parameters,
+// which include a book.Cursor. A SQL WHERE clause is added which only includes records that follow the Cursor
+// in that order. Because the keys may be sorted in different directions, a row comparison cannot be used.
+// Instead, the predicate is expanded so that a record follows the Cursor if it is past it on some key, and
+// equal on all keys before that one.
+func (d Dialect) whereAfter(builder sq.SelectBuilder, keys book.Sort, params book.SearchInput) sq.SelectBuilder {
+	compare := func(f book.SortField, op string) sq.Sqlizer {
+		col, args := d.sortColumn(f, params)
+		expr, arg := d.sortValue(f, params.After)
+		return sq.Expr(fmt.Sprintf("%s %s %s", col, op, expr), append(args, arg)...)
+	}
+
+	var predicate sq.Or
+	for i, k := range keys {
+		var clause sq.And
+		for _, prev := range keys[:i] {
+			clause = append(clause, compare(prev.Field, "="))
+		}
+
+		if k.Descending {
+			clause = append(clause, compare(k.Field, "<"))
+		} else {
+			clause = append(clause, compare(k.Field, ">"))
+		}
+
+		predicate = append(predicate, clause)
+	}
+
+	return builder.PlaceholderFormat(d.Placeholder).Where(predicate)
+}
+
+// whereInt16In accepts a query builder, a target column, and a slice of int16s.
+// If the slice is non-empty, then it a SQL WHERE clause section will be added for
+// records with col values IN the provided ints slice. The mutated builder is returned.
+func (d Dialect) whereInt16In(builder sq.SelectBuilder, col string, ints []int16) sq.SelectBuilder {
+	if len(ints) > 0 {
+		var values = make([]interface{}, len(ints))
+		var placeholders = make([]interface{}, len(ints))
+		for i := range values {
+			values[i] = ints[i]
+			placeholders[i] = "?"
+		}
+		return builder.
+			PlaceholderFormat(d.Placeholder).
+			Where(
+				fmt.Sprintf("%s IN (%s)", col, strings.Trim(strings.Join(strings.Fields(fmt.Sprint(placeholders)), ","), "[]")),
+				values...,
+			)
+	}
+
+	return builder
+}
+
+// whereInt16Between accepts a query builder, a target column, and pointers to int16
+// for the min and max values of the range. Because the builder dependency does not support
+// BETWEEN semantics, this is done as two WHERE clause filters. Its possible
+// to let min or max be nil. In such a scenario, the query would only provide a lower
+// or upper bound.
+func (d Dialect) whereInt16Between(builder sq.SelectBuilder, col string, min *int16, max *int16) sq.SelectBuilder {
+	if min != nil {
+		builder = builder.PlaceholderFormat(d.Placeholder).Where(sq.GtOrEq{col: *min})
+	}
+
+	if max != nil {
+		builder = builder.PlaceholderFormat(d.Placeholder).Where(sq.LtOrEq{col: *max})
+	}
+
+	return builder
+}
+
+// whereInt16InRanges accepts a query builder, a target column, and a slice of book.Range. If the slice is
+// non-empty, then a SQL WHERE clause section will be added for records with col values that fall within
+// _any_ of the ranges. Each range may leave its lower or upper bound open. The mutated builder is returned.
+func (d Dialect) whereInt16InRanges(builder sq.SelectBuilder, col string, ranges []book.Range) sq.SelectBuilder {
+	if len(ranges) == 0 {
+		return builder
+	}
+
+	var predicate sq.Or
+	for _, r := range ranges {
+		var clause sq.And
+		if r.Min != nil {
+			clause = append(clause, sq.GtOrEq{col: *r.Min})
+		}
+		if r.Max != nil {
+			clause = append(clause, sq.LtOrEq{col: *r.Max})
+		}
+		predicate = append(predicate, clause)
+	}
+
+	return builder.PlaceholderFormat(d.Placeholder).Where(predicate)
+}
@@ -0,0 +1,44 @@
+package booksql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Tables are the tables which the repositories read and write.
+var Tables = []string{"author", "genre", "era", "size", "book"}
+
+// CheckTables queries every one of the Tables without reading any rows, which works alike on every database. If
+// any of them cannot be queried, such as because it does not exist, then an error naming them is returned.
+func CheckTables(ctx context.Context, db *sql.DB) error {
+	var missing []string
+	for _, table := range Tables {
+		if err := queryTable(ctx, db, table); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			missing = append(missing, table)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("unable to query tables: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// queryTable queries the table without reading any rows. Some drivers only compile the query once its rows are
+// read, such as when the schema changed since the connection last read it, so the rows are read as well.
+func queryTable(ctx context.Context, db *sql.DB, table string) error {
+	rows, err := db.QueryContext(ctx, "SELECT 1 FROM "+table+" WHERE 1 = 0")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+	}
+	return rows.Err()
+}
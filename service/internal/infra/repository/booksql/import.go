@@ -0,0 +1,181 @@
+package booksql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ImportTable is the temporary table which the Records of a catalog are staged in, with the ImportColumns,
+// before they are merged into the book table.
+const ImportTable = "book_import"
+
+// ImportColumns are the columns of the ImportTable which a Dialect's StageImport fills, in the order of the
+// values returned by ImportValues.
+var ImportColumns = []string{"line", "title", "year_published", "rating", "pages",
+	"author_first_name", "author_last_name", "genre"}
+
+// The statements which merge the staged Records into the author, genre and book tables. They run in the order
+// they are declared.
+const (
+	createImportTable = `CREATE TEMPORARY TABLE ` + ImportTable + ` (
+  line INTEGER NOT NULL,
+  title TEXT NOT NULL,
+  year_published SMALLINT NOT NULL,
+  rating NUMERIC(3, 2) NOT NULL,
+  pages SMALLINT NOT NULL,
+  author_first_name TEXT NOT NULL,
+  author_last_name TEXT NOT NULL,
+  genre TEXT NOT NULL,
+  author_id INTEGER,
+  genre_id INTEGER
+)`
+
+	// Authors and Genres are created in the order they first appear in the catalog.
+	createImportAuthors = `INSERT INTO author (first_name, last_name)
+SELECT author_first_name, author_last_name FROM ` + ImportTable + ` i
+WHERE NOT EXISTS (SELECT 1 FROM author WHERE author.first_name = i.author_first_name AND author.last_name = i.author_last_name)
+GROUP BY author_first_name, author_last_name
+ORDER BY min(line)`
+
+	createImportGenres = `INSERT INTO genre (title)
+SELECT genre FROM ` + ImportTable + ` i
+WHERE NOT EXISTS (SELECT 1 FROM genre WHERE genre.title = i.genre)
+GROUP BY genre
+ORDER BY min(line)`
+
+	// If several Authors or Genres share a name, then the first one is used. The names are resolved with a join,
+	// rather than a lookup per Record, so that large catalogs are resolved in one pass.
+	resolveImportAuthors = `UPDATE ` + ImportTable + ` SET author_id = a.id
+FROM (SELECT first_name, last_name, min(id) AS id FROM author GROUP BY first_name, last_name) a
+WHERE a.first_name = ` + ImportTable + `.author_first_name AND a.last_name = ` + ImportTable + `.author_last_name`
+
+	resolveImportGenres = `UPDATE ` + ImportTable + ` SET genre_id = g.id
+FROM (SELECT title, min(id) AS id FROM genre GROUP BY title) g
+WHERE g.title = ` + ImportTable + `.genre`
+
+	// Of several Records with the same title and Author, only the last one is upserted.
+	dropImportDuplicates = `DELETE FROM ` + ImportTable + `
+WHERE line < (SELECT max(line) FROM ` + ImportTable + ` later WHERE later.title = ` + ImportTable + `.title AND later.author_id = ` + ImportTable + `.author_id)`
+
+	updateImportBooks = `UPDATE book SET year_published = i.year_published, rating = i.rating, pages = i.pages, genre_id = i.genre_id
+FROM ` + ImportTable + ` i
+WHERE book.title = i.title AND book.author_id = i.author_id`
+
+	insertImportBooks = `INSERT INTO book (title, year_published, rating, pages, author_id, genre_id)
+SELECT title, year_published, rating, pages, author_id, genre_id FROM ` + ImportTable + ` i`
+
+	// In upsert mode, Books which were just updated are not inserted as well.
+	insertImportBooksMissing = insertImportBooks + `
+WHERE NOT EXISTS (SELECT 1 FROM book WHERE book.title = i.title AND book.author_id = i.author_id)`
+
+	dropImportTable = `DROP TABLE ` + ImportTable
+)
+
+// importStep is a statement which merges the staged Records. If count is not nil, then the number of rows it
+// affects is stored in count.
+type importStep struct {
+	action string
+	query  string
+	count  *int64
+}
+
+// ImportValues returns the values of the ImportColumns for the Record, in the Dialect.
+func (d Dialect) ImportValues(rec catalog.Record) []interface{} {
+	return []interface{}{rec.Line, rec.Title, rec.YearPublished, d.Rating(rec.Rating), rec.Pages,
+		rec.AuthorFirstName, rec.AuthorLastName, rec.Genre}
+}
+
+// Import writes the Book of every Record of the Source in a single transaction, as catalog.Repository
+// describes. The Records are staged in the ImportTable by the Dialect's StageImport, and then merged into the
+// author, genre and book tables with a handful of statements, however many Records there are.
+func (d Dialect) Import(ctx context.Context, db *sql.DB, records catalog.Source, upsert bool) (catalog.Summary, error) {
+	var summary catalog.Summary
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return summary, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, createImportTable); err != nil {
+		return summary, fmt.Errorf("unable to create %s table: %w", ImportTable, err)
+	}
+
+	stage := d.StageImport
+	if stage == nil {
+		stage = d.InsertStaged
+	}
+	if err := stage(ctx, tx, records); err != nil {
+		return summary, err
+	}
+
+	// The Source is only known to be valid once it has been exhausted.
+	if err := records.Err(); err != nil {
+		return summary, err
+	}
+
+	steps := []importStep{
+		{"create authors", createImportAuthors, &summary.AuthorsCreated},
+		{"create genres", createImportGenres, &summary.GenresCreated},
+		{"resolve authors", resolveImportAuthors, nil},
+		{"resolve genres", resolveImportGenres, nil},
+	}
+	if upsert {
+		steps = append(steps,
+			importStep{"drop duplicate books", dropImportDuplicates, nil},
+			importStep{"update books", updateImportBooks, &summary.Updated},
+			importStep{"insert books", insertImportBooksMissing + "\nORDER BY line", &summary.Created})
+	} else {
+		steps = append(steps, importStep{"insert books", insertImportBooks + "\nORDER BY line", &summary.Created})
+	}
+	steps = append(steps, importStep{"drop " + ImportTable + " table", dropImportTable, nil})
+
+	for _, s := range steps {
+		res, err := tx.ExecContext(ctx, s.query)
+		if err != nil {
+			return catalog.Summary{}, fmt.Errorf("unable to %s: %w", s.action, err)
+		}
+		if s.count == nil {
+			continue
+		}
+		if *s.count, err = res.RowsAffected(); err != nil {
+			return catalog.Summary{}, fmt.Errorf("unable to count affected rows: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return catalog.Summary{}, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return summary, nil
+}
+
+// InsertStaged is a StageImport which inserts the Records into the ImportTable one at a time, with a prepared
+// statement.
+func (d Dialect) InsertStaged(ctx context.Context, tx *sql.Tx, records catalog.Source) error {
+	query, _, err := sq.StatementBuilder.PlaceholderFormat(d.Placeholder).
+		Insert(ImportTable).
+		Columns(ImportColumns...).
+		Values(make([]interface{}, len(ImportColumns))...).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("unable to prepare staging of books: %w", err)
+	}
+	defer stmt.Close()
+
+	for records.Next() {
+		rec := records.Record()
+		if _, err := stmt.ExecContext(ctx, d.ImportValues(rec)...); err != nil {
+			return fmt.Errorf("unable to stage book on line %d: %w", rec.Line, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package dberror translates the errors of databases into the domain errors of entity, so that a request which
+// failed because it was canceled, because the database was too slow or because it could not be reached can be
+// told apart from any other failure. Translators wrap a repository of each entity in a decorator, which
+// translates the errors of its methods.
+package dberror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+)
+
+// Translator translates the errors of one database, such as its error codes, into entity.ErrTimeout or
+// entity.ErrUnavailable. It returns errors which it does not recognise as they are. Errors which every
+// database/sql driver shares, such as context errors, are translated before it is called.
+type Translator func(error) error
+
+// translate returns the error, which a method called with the context returned, as a domain error. Errors which
+// are domain errors already are returned as they are, as are those which cannot be told apart from any other
+// failure.
+func (t Translator) translate(ctx context.Context, err error) error {
+	if err == nil || entity.IsClientError(err) || errors.Is(err, entity.ErrTimeout) || errors.Is(err, entity.ErrUnavailable) {
+		return err
+	}
+
+	// The context is checked first, since drivers report a canceled query in their own ways.
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled), errors.Is(err, context.Canceled):
+		return &entity.Error{Kind: entity.ErrCanceled, Err: err}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded), errors.Is(err, context.DeadlineExceeded):
+		return &entity.Error{Kind: entity.ErrTimeout, Err: err}
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return &entity.Error{Kind: entity.ErrUnavailable, Err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return &entity.Error{Kind: entity.ErrTimeout, Err: err}
+		}
+		return &entity.Error{Kind: entity.ErrUnavailable, Err: err}
+	}
+
+	if t == nil {
+		return err
+	}
+	return t(err)
+}
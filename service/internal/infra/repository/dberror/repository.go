@@ -0,0 +1,230 @@
+package dberror
+
+import (
+	"context"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+)
+
+// translator translates the errors of the methods of one repository.
+type translator struct {
+	translate Translator
+}
+
+// done translates the error that errp points to, once a method called with the context has returned it. It is
+// deferred by each method of a decorator, which names its error result.
+func (t translator) done(ctx context.Context, errp *error) {
+	*errp = t.translate.translate(ctx, *errp)
+}
+
+// Books wraps the book.Repository in a decorator which translates the errors of its methods. Errors returned by
+// the callback of Export are translated too.
+func (t Translator) Books(repo book.Repository) book.Repository {
+	return bookRepository{next: repo, translator: translator{translate: t}}
+}
+
+// Authors wraps the author.Repository in a decorator which translates the errors of its methods.
+func (t Translator) Authors(repo author.Repository) author.Repository {
+	return authorRepository{next: repo, translator: translator{translate: t}}
+}
+
+// Genres wraps the genre.Repository in a decorator which translates the errors of its methods.
+func (t Translator) Genres(repo genre.Repository) genre.Repository {
+	return genreRepository{next: repo, translator: translator{translate: t}}
+}
+
+// Eras wraps the era.Repository in a decorator which translates the errors of its methods.
+func (t Translator) Eras(repo era.Repository) era.Repository {
+	return eraRepository{next: repo, translator: translator{translate: t}}
+}
+
+// Sizes wraps the size.Repository in a decorator which translates the errors of its methods.
+func (t Translator) Sizes(repo size.Repository) size.Repository {
+	return sizeRepository{next: repo, translator: translator{translate: t}}
+}
+
+type bookRepository struct {
+	next book.Repository
+	translator
+}
+
+func (r bookRepository) Search(ctx context.Context, params book.SearchInput) (_ []entity.Book, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Search(ctx, params)
+}
+
+func (r bookRepository) Get(ctx context.Context, id int32) (_ entity.Book, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r bookRepository) Create(ctx context.Context, params book.WriteInput) (_ int32, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r bookRepository) Update(ctx context.Context, id int32, params book.WriteInput) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r bookRepository) Delete(ctx context.Context, id int32) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r bookRepository) Facets(ctx context.Context, params book.FacetInput) (_ book.Facets, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Facets(ctx, params)
+}
+
+func (r bookRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Export(ctx, params, each)
+}
+
+type authorRepository struct {
+	next author.Repository
+	translator
+}
+
+func (r authorRepository) List(ctx context.Context) (_ []entity.Author, err error) {
+	defer r.done(ctx, &err)
+	return r.next.List(ctx)
+}
+
+func (r authorRepository) Get(ctx context.Context, id int32) (_ entity.Author, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r authorRepository) Stats(ctx context.Context, id int32) (_ author.Stats, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Stats(ctx, id)
+}
+
+func (r authorRepository) TopBooks(ctx context.Context, id int32, limit uint64) (_ []entity.Book, err error) {
+	defer r.done(ctx, &err)
+	return r.next.TopBooks(ctx, id, limit)
+}
+
+func (r authorRepository) Create(ctx context.Context, params author.WriteInput) (_ int32, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r authorRepository) Update(ctx context.Context, id int32, params author.WriteInput) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r authorRepository) Delete(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Delete(ctx, id, reassignTo)
+}
+
+type genreRepository struct {
+	next genre.Repository
+	translator
+}
+
+func (r genreRepository) List(ctx context.Context) (_ []entity.Genre, err error) {
+	defer r.done(ctx, &err)
+	return r.next.List(ctx)
+}
+
+func (r genreRepository) Get(ctx context.Context, id int32) (_ entity.Genre, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r genreRepository) Stats(ctx context.Context, id int32) (_ genre.Stats, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Stats(ctx, id)
+}
+
+func (r genreRepository) Create(ctx context.Context, params genre.WriteInput) (_ int32, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r genreRepository) Update(ctx context.Context, id int32, params genre.WriteInput) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r genreRepository) Delete(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Delete(ctx, id, reassignTo)
+}
+
+type eraRepository struct {
+	next era.Repository
+	translator
+}
+
+func (r eraRepository) List(ctx context.Context) (_ []entity.Era, err error) {
+	defer r.done(ctx, &err)
+	return r.next.List(ctx)
+}
+
+func (r eraRepository) Create(ctx context.Context, params era.WriteInput,
+	check func([]entity.Era) error) (_ int32, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Create(ctx, params, check)
+}
+
+func (r eraRepository) Update(ctx context.Context, id int32, params era.WriteInput,
+	check func([]entity.Era) error) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Update(ctx, id, params, check)
+}
+
+func (r eraRepository) Delete(ctx context.Context, id int32, check func([]entity.Era) error) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Delete(ctx, id, check)
+}
+
+func (r eraRepository) ReplaceAll(ctx context.Context, params []era.BulkInput,
+	check func([]entity.Era) error) (_ []entity.Era, err error) {
+	defer r.done(ctx, &err)
+	return r.next.ReplaceAll(ctx, params, check)
+}
+
+type sizeRepository struct {
+	next size.Repository
+	translator
+}
+
+func (r sizeRepository) List(ctx context.Context) (_ []entity.Size, err error) {
+	defer r.done(ctx, &err)
+	return r.next.List(ctx)
+}
+
+func (r sizeRepository) Create(ctx context.Context, params size.WriteInput,
+	check func([]entity.Size) error) (_ int32, err error) {
+	defer r.done(ctx, &err)
+	return r.next.Create(ctx, params, check)
+}
+
+func (r sizeRepository) Update(ctx context.Context, id int32, params size.WriteInput,
+	check func([]entity.Size) error) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Update(ctx, id, params, check)
+}
+
+func (r sizeRepository) Delete(ctx context.Context, id int32, check func([]entity.Size) error) (err error) {
+	defer r.done(ctx, &err)
+	return r.next.Delete(ctx, id, check)
+}
+
+func (r sizeRepository) ReplaceAll(ctx context.Context, params []size.BulkInput,
+	check func([]entity.Size) error) (_ []entity.Size, err error) {
+	defer r.done(ctx, &err)
+	return r.next.ReplaceAll(ctx, params, check)
+}
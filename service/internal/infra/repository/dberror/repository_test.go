@@ -0,0 +1,99 @@
+package dberror
+
+import (
+	"context"
+	"database/sql/driver"
+	"net"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// getBookRepository is a book.Repository whose Get returns its book and error, and which implements no other
+// method.
+type getBookRepository struct {
+	book.Repository
+	book entity.Book
+	err  error
+}
+
+func (r getBookRepository) Get(context.Context, int32) (entity.Book, error) {
+	return r.book, r.err
+}
+
+// errDatabase is an error which only the test Translator recognises.
+var errDatabase = errors.New("database is read-only")
+
+// translateDatabase is a Translator which reports errDatabase as the database being unavailable.
+func translateDatabase(err error) error {
+	if errors.Is(err, errDatabase) {
+		return &entity.Error{Kind: entity.ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+func TestTranslator_Books(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := map[string]struct {
+		ctx  context.Context
+		err  error
+		kind error
+	}{
+		"query succeeds": {},
+		"entity does not exist": {
+			err:  errors.Wrap(entity.ErrNotFound, "book 1"),
+			kind: entity.ErrNotFound,
+		},
+		"request is canceled": {
+			ctx:  canceled,
+			err:  errors.New("pq: canceling statement due to user request"),
+			kind: entity.ErrCanceled,
+		},
+		"deadline is exceeded": {
+			err:  errors.Wrap(context.DeadlineExceeded, "unable to get book"),
+			kind: entity.ErrTimeout,
+		},
+		"connection is bad": {
+			err:  errors.Wrap(driver.ErrBadConn, "unable to get book"),
+			kind: entity.ErrUnavailable,
+		},
+		"connection is refused": {
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")},
+			kind: entity.ErrUnavailable,
+		},
+		"database error is translated": {
+			err:  errors.Wrap(errDatabase, "unable to get book"),
+			kind: entity.ErrUnavailable,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+
+			want := entity.Book{ID: 1}
+			got, err := Translator(translateDatabase).Books(getBookRepository{book: want, err: tt.err}).Get(ctx, 1)
+			assert.Equal(t, want, got)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.kind)
+			assert.ErrorIs(t, err, tt.err, "the cause of the error is kept")
+		})
+	}
+}
+
+func TestTranslator_Books_Unrecognised(t *testing.T) {
+	err := errors.New("syntax error")
+	_, got := Translator(nil).Books(getBookRepository{err: err}).Get(context.Background(), 1)
+	assert.Equal(t, err, got)
+}
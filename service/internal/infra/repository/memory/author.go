@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type authorRepository struct {
+	store  *Store
+	logger *zap.Logger
+}
+
+// NewAuthorRepository accepts a pointer to a Store. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in an authorRepository and a pointer to it is returned.
+func NewAuthorRepository(store *Store, logger *zap.Logger) (*authorRepository, error) {
+	if store == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &authorRepository{
+		store:  store,
+		logger: logger,
+	}, nil
+}
+
+// List returns all Authors in the Store, ordered by ID.
+func (r *authorRepository) List(_ context.Context) ([]entity.Author, error) {
+	r.logger.Debug("listing authors from memory repository")
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var authors []entity.Author
+	for _, a := range r.store.authors {
+		authors = append(authors, a)
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].ID < authors[j].ID })
+
+	r.logger.Debug(fmt.Sprintf("found %d authors in memory repository", len(authors)))
+
+	return authors, nil
+}
+
+// Get returns the Author with the provided ID. If no such Author exists, then an error wrapping
+// entity.ErrNotFound is returned.
+func (r *authorRepository) Get(_ context.Context, id int32) (entity.Author, error) {
+	r.logger.Debug(fmt.Sprintf("getting author %d from memory repository", id))
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	a, ok := r.store.authors[id]
+	if !ok {
+		return entity.Author{}, fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	return a, nil
+}
+
+// Stats aggregates the Books written by the Author with the provided ID. The average rating is rounded
+// to two decimal places, like the ratings themselves.
+func (r *authorRepository) Stats(_ context.Context, id int32) (author.Stats, error) {
+	r.logger.Debug(fmt.Sprintf("aggregating books of author %d from memory repository", id))
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	agg := r.store.aggregate(id, func(b record) int32 { return b.AuthorID })
+	return author.Stats{
+		BookCount:          agg.BookCount,
+		AverageRating:      agg.AverageRating,
+		FirstYearPublished: agg.FirstYearPublished,
+		LastYearPublished:  agg.LastYearPublished,
+	}, nil
+}
+
+// TopBooks returns up to limit of the Books written by the Author with the provided ID, from the best to the
+// worst rated. Books with equal ratings are ordered by ascending ID.
+func (r *authorRepository) TopBooks(_ context.Context, id int32, limit uint64) ([]entity.Book, error) {
+	r.logger.Debug(fmt.Sprintf("listing top books of author %d from memory repository", id))
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var books []entity.Book
+	for _, rec := range r.store.books {
+		if rec.AuthorID == id {
+			books = append(books, r.store.book(rec))
+		}
+	}
+	sort.Slice(books, func(i, j int) bool {
+		if books[i].Rating != books[j].Rating {
+			return books[i].Rating > books[j].Rating
+		}
+		return books[i].ID < books[j].ID
+	})
+
+	if uint64(len(books)) > limit {
+		books = books[:limit]
+	}
+	return books, nil
+}
+
+// Create stores an Author with the attributes of the author.WriteInput, which must all be set. The ID assigned
+// to the Author is returned.
+func (r *authorRepository) Create(_ context.Context, params author.WriteInput) (int32, error) {
+	r.logger.Debug("creating author in memory repository")
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := r.store.nextID("author")
+	r.store.authors[id] = entity.Author{ID: id, FirstName: *params.FirstName, LastName: *params.LastName}
+
+	r.logger.Debug(fmt.Sprintf("created author %d in memory repository", id))
+	return id, nil
+}
+
+// Update sets the attributes of the Author with the provided ID to those of the author.WriteInput, which must
+// all be set. If no such Author exists, then an error wrapping entity.ErrNotFound is returned.
+func (r *authorRepository) Update(_ context.Context, id int32, params author.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating author %d in memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.authors[id]; !ok {
+		return fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	r.store.authors[id] = entity.Author{ID: id, FirstName: *params.FirstName, LastName: *params.LastName}
+	return nil
+}
+
+// Delete deletes the Author with the provided ID. If reassignTo is not nil, then the Author's Books are first
+// reassigned to the Author with that ID, all at once. If no such Author exists, then an error wrapping
+// entity.ErrNotFound is returned. If Books still reference the Author, then an error wrapping
+// entity.ErrConflict is returned.
+func (r *authorRepository) Delete(_ context.Context, id int32, reassignTo *int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting author %d from memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.authors[id]; !ok {
+		return fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+
+	if reassignTo != nil {
+		if _, ok := r.store.authors[*reassignTo]; !ok {
+			return fmt.Errorf("unable to reassign books of author: author %d does not exist", *reassignTo)
+		}
+		r.store.reassign(id, *reassignTo, func(b *record) *int32 { return &b.AuthorID })
+	}
+
+	if r.store.references(id, func(b record) int32 { return b.AuthorID }) > 0 {
+		return fmt.Errorf("%w: author %d is still referenced by books", entity.ErrConflict, id)
+	}
+
+	delete(r.store.authors, id)
+	return nil
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuthorRepository_Stats(t *testing.T) {
+
+	r, err := NewAuthorRepository(newStore(t), zap.NewNop())
+	require.NoError(t, err)
+
+	avg := 3.98
+	tests := map[string]struct {
+		id     int32
+		expect author.Stats
+	}{
+		"author with books": {
+			id: 1,
+			expect: author.Stats{
+				BookCount:          3,
+				AverageRating:      &avg,
+				FirstYearPublished: int16Ptr(1968),
+				LastYearPublished:  int16Ptr(1990),
+			},
+		},
+		"author without books": {
+			id:     3,
+			expect: author.Stats{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := r.Stats(context.Background(), tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, actual)
+		})
+	}
+}
+
+func TestAuthorRepository_TopBooks(t *testing.T) {
+
+	r, err := NewAuthorRepository(newStore(t), zap.NewNop())
+	require.NoError(t, err)
+
+	actual, err := r.TopBooks(context.Background(), 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2}, ids(actual))
+}
+
+func TestAuthorRepository_Delete(t *testing.T) {
+
+	tests := map[string]struct {
+		id           int32
+		reassignTo   *int32
+		errAssertion assert.ErrorAssertionFunc
+		remaining    int
+	}{
+		"author without books": {
+			id:           3,
+			errAssertion: assert.NoError,
+			remaining:    2,
+		},
+		"author with books": {
+			id: 1,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrConflict)
+			},
+			remaining: 3,
+		},
+		"books are reassigned": {
+			id:           1,
+			reassignTo:   int32Ptr(3),
+			errAssertion: assert.NoError,
+			remaining:    2,
+		},
+		"reassigned to missing author": {
+			id:           1,
+			reassignTo:   int32Ptr(42),
+			errAssertion: assert.Error,
+			remaining:    3,
+		},
+		"missing author": {
+			id: 42,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrNotFound)
+			},
+			remaining: 3,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := newStore(t)
+			r, err := NewAuthorRepository(store, zap.NewNop())
+			require.NoError(t, err)
+
+			tt.errAssertion(t, r.Delete(context.Background(), tt.id, tt.reassignTo))
+
+			authors, err := r.List(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, authors, tt.remaining)
+			if tt.reassignTo != nil && tt.remaining == 2 {
+				assert.Equal(t, 3, store.references(*tt.reassignTo, func(b record) int32 { return b.AuthorID }))
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/textsearch"
+	"go.uber.org/zap"
+)
+
+type bookRepository struct {
+	store  *Store
+	logger *zap.Logger
+}
+
+// NewBookRepository accepts a pointer to a Store. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in a bookRepository and a pointer to it is returned.
+func NewBookRepository(store *Store, logger *zap.Logger) (*bookRepository, error) {
+	if store == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &bookRepository{
+		store:  store,
+		logger: logger,
+	}, nil
+}
+
+// Search finds the Books in the Store which match every filter of the search parameters, ordered by the keys
+// of its Sort. If a Query is given, then the Relevance of each Book is set.
+func (r *bookRepository) Search(_ context.Context, params book.SearchInput) ([]entity.Book, error) {
+	r.logger.Debug("searching books from memory repository")
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	m := newMatcher(params)
+	var books []entity.Book
+	for _, rec := range r.store.books {
+		b := r.store.book(rec)
+		if !m.matches(b) {
+			continue
+		}
+		if m.query != nil {
+			b.Relevance = m.query.Relevance(textsearch.NewDocument(searchDocument(b)))
+		}
+		books = append(books, b)
+	}
+
+	keys := params.Sort.Keys()
+	sort.Slice(books, func(i, j int) bool { return compareBooks(books[i], books[j], keys) < 0 })
+
+	if params.After != nil {
+		after := cursorBook(params.After)
+		i := sort.Search(len(books), func(i int) bool { return compareBooks(books[i], after, keys) > 0 })
+		books = books[i:]
+	}
+
+	if params.Limit != nil && uint64(len(books)) > *params.Limit {
+		books = books[:*params.Limit]
+	}
+
+	r.logger.Debug(fmt.Sprintf("found %d books in memory repository", len(books)))
+
+	return books, nil
+}
+
+// Export calls each with every Book in the Store which Search would find, in the same order. The Books are
+// found while the Store is locked, so they are a consistent snapshot, but each is called once it is unlocked so
+// that slow consumers do not hold up writes. If each returns an error, then the export stops and it is returned.
+func (r *bookRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) error {
+	r.logger.Debug("exporting books from memory repository")
+
+	books, err := r.Search(ctx, params)
+	if err != nil {
+		return err
+	}
+	for _, b := range books {
+		if err := each(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the Book with the provided ID, along with its Author and Genre. If no such Book exists, then an
+// error wrapping entity.ErrNotFound is returned.
+func (r *bookRepository) Get(_ context.Context, id int32) (entity.Book, error) {
+	r.logger.Debug(fmt.Sprintf("getting book %d from memory repository", id))
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	rec, ok := r.store.books[id]
+	if !ok {
+		return entity.Book{}, fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	return r.store.book(rec), nil
+}
+
+// Create stores a Book with the attributes of the book.WriteInput, all of which must be set, and returns the
+// ID assigned to it. If its Author or Genre does not exist, then an error is returned.
+func (r *bookRepository) Create(_ context.Context, params book.WriteInput) (int32, error) {
+	r.logger.Debug("creating book in memory repository")
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec := record{
+		Title:         *params.Title,
+		YearPublished: *params.YearPublished,
+		Rating:        roundRating(*params.Rating),
+		Pages:         *params.Pages,
+		AuthorID:      *params.AuthorID,
+		GenreID:       *params.GenreID,
+	}
+	if err := r.store.checkReferences(rec); err != nil {
+		return 0, fmt.Errorf("unable to create book: %w", err)
+	}
+
+	rec.ID = r.store.nextID("book")
+	r.store.books[rec.ID] = rec
+
+	r.logger.Debug(fmt.Sprintf("created book %d in memory repository", rec.ID))
+	return rec.ID, nil
+}
+
+// Update sets the attributes of the Book with the provided ID which are set in the book.WriteInput. If no such
+// Book exists, then an error wrapping entity.ErrNotFound is returned. If its Author or Genre would not exist,
+// then an error is returned.
+func (r *bookRepository) Update(_ context.Context, id int32, params book.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating book %d in memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	rec, ok := r.store.books[id]
+	if !ok {
+		return fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+
+	if params.Title != nil {
+		rec.Title = *params.Title
+	}
+	if params.YearPublished != nil {
+		rec.YearPublished = *params.YearPublished
+	}
+	if params.Rating != nil {
+		rec.Rating = roundRating(*params.Rating)
+	}
+	if params.Pages != nil {
+		rec.Pages = *params.Pages
+	}
+	if params.AuthorID != nil {
+		rec.AuthorID = *params.AuthorID
+	}
+	if params.GenreID != nil {
+		rec.GenreID = *params.GenreID
+	}
+	if err := r.store.checkReferences(rec); err != nil {
+		return fmt.Errorf("unable to update book: %w", err)
+	}
+
+	r.store.books[id] = rec
+	return nil
+}
+
+// Delete deletes the Book with the provided ID. If no such Book exists, then an error wrapping entity.ErrNotFound
+// is returned.
+func (r *bookRepository) Delete(_ context.Context, id int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting book %d from memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.books[id]; !ok {
+		return fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	delete(r.store.books, id)
+	return nil
+}
+
+// Facets counts the Books in the Store which match the search parameters of each facet in the book.FacetInput,
+// for every bucket of the facet, even if no Books fall into it. The counts are ordered by the ID of the bucket.
+func (r *bookRepository) Facets(_ context.Context, params book.FacetInput) (book.Facets, error) {
+	r.logger.Debug("counting book facets from memory repository")
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var facets book.Facets
+
+	for id := range r.store.genres {
+		facets.Genres = append(facets.Genres, r.countFacet(params.Genres, id, func(b entity.Book) bool {
+			return b.Genre.ID == id
+		}))
+	}
+
+	for id := range r.store.authors {
+		facets.Authors = append(facets.Authors, r.countFacet(params.Authors, id, func(b entity.Book) bool {
+			return b.Author.ID == id
+		}))
+	}
+
+	for id, e := range r.store.eras {
+		within := book.Range{Min: e.MinYear, Max: e.MaxYear}
+		facets.Eras = append(facets.Eras, r.countFacet(params.Eras, id, func(b entity.Book) bool {
+			return inRange(b.YearPublished, within)
+		}))
+	}
+
+	for id, s := range r.store.sizes {
+		within := book.Range{Min: s.MinPages, Max: s.MaxPages}
+		facets.Sizes = append(facets.Sizes, r.countFacet(params.Sizes, id, func(b entity.Book) bool {
+			return inRange(b.Pages, within)
+		}))
+	}
+
+	for _, counts := range [][]book.FacetCount{facets.Genres, facets.Authors, facets.Eras, facets.Sizes} {
+		sort.Slice(counts, func(i, j int) bool { return counts[i].ID < counts[j].ID })
+	}
+
+	return facets, nil
+}
+
+// countFacet counts the Books which match the search parameters and fall into the bucket with the ID. The Store
+// must be locked for reading.
+func (r *bookRepository) countFacet(params book.SearchInput, id int32, bucket func(entity.Book) bool) book.FacetCount {
+	m := newMatcher(params)
+	c := book.FacetCount{ID: id}
+	for _, rec := range r.store.books {
+		if b := r.store.book(rec); bucket(b) && m.matches(b) {
+			c.Count++
+		}
+	}
+	return c
+}
+
+// checkReferences checks that the Author and Genre of the record exist, as foreign keys would. The Store must be
+// locked for reading.
+func (s *Store) checkReferences(rec record) error {
+	if _, ok := s.authors[rec.AuthorID]; !ok {
+		return fmt.Errorf("author %d does not exist", rec.AuthorID)
+	}
+	if _, ok := s.genres[rec.GenreID]; !ok {
+		return fmt.Errorf("genre %d does not exist", rec.GenreID)
+	}
+	return nil
+}
+
+// matcher applies every filter of a book.SearchInput to Books. Ordering and pagination are not applied.
+type matcher struct {
+	params book.SearchInput
+	query  *textsearch.Query
+}
+
+// newMatcher parses the Query of the search parameters, if any, so that it is only parsed once per search.
+func newMatcher(params book.SearchInput) matcher {
+	m := matcher{params: params}
+	if params.Query != nil {
+		m.query = textsearch.Parse(*params.Query)
+	}
+	return m
+}
+
+// matches reports whether the Book matches every filter of the search parameters.
+func (m matcher) matches(b entity.Book) bool {
+	p := m.params
+
+	if m.query != nil && !m.query.Matches(textsearch.NewDocument(searchDocument(b))) {
+		return false
+	}
+	if len(p.AuthorIDs) > 0 && !containsID(p.AuthorIDs, b.Author.ID) {
+		return false
+	}
+	if len(p.GenreIDs) > 0 && !containsID(p.GenreIDs, b.Genre.ID) {
+		return false
+	}
+	if p.Title != nil && b.Title != *p.Title {
+		return false
+	}
+	if !inRange(b.Pages, book.Range{Min: p.MinPages, Max: p.MaxPages}) {
+		return false
+	}
+	if !inRange(b.YearPublished, book.Range{Min: p.MinYearPublished, Max: p.MaxYearPublished}) {
+		return false
+	}
+	if len(p.PageRanges) > 0 && !inAnyRange(b.Pages, p.PageRanges) {
+		return false
+	}
+	if len(p.YearRanges) > 0 && !inAnyRange(b.YearPublished, p.YearRanges) {
+		return false
+	}
+	return true
+}
+
+// searchDocument is the text of a Book which book.SearchInput.Query is matched against: its title and the name
+// of its author.
+func searchDocument(b entity.Book) string {
+	return strings.Join([]string{b.Title, b.Author.FirstName, b.Author.LastName}, " ")
+}
+
+// containsID reports whether the IDs contain the ID.
+func containsID(ids []int16, id int32) bool {
+	for _, i := range ids {
+		if int32(i) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange reports whether the value falls within the book.Range, whose bounds are inclusive and may be open.
+func inRange(v int16, r book.Range) bool {
+	return (r.Min == nil || v >= *r.Min) && (r.Max == nil || v <= *r.Max)
+}
+
+// inAnyRange reports whether the value falls within any of the ranges.
+func inAnyRange(v int16, ranges []book.Range) bool {
+	for _, r := range ranges {
+		if inRange(v, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareBooks orders two Books by the book.SortKeys. It returns a negative number if a comes first, a positive
+// number if b comes first and 0 if they are equal on every key.
+func compareBooks(a, b entity.Book, keys book.Sort) int {
+	for _, k := range keys {
+		var c int
+		switch k.Field {
+		case book.SortByRelevance:
+			c = compareFloat(a.Relevance, b.Relevance)
+		case book.SortByRating:
+			c = compareFloat(float64(a.Rating), float64(b.Rating))
+		case book.SortByYearPublished:
+			c = int(a.YearPublished) - int(b.YearPublished)
+		case book.SortByPages:
+			c = int(a.Pages) - int(b.Pages)
+		case book.SortByTitle:
+			c = strings.Compare(textsearch.TitleSortKey(a.Title), textsearch.TitleSortKey(b.Title))
+		default:
+			c = int(a.ID) - int(b.ID)
+		}
+		if k.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareFloat returns -1, 0 or 1 as a is less than, equal to or greater than b.
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// cursorBook returns a Book with the attributes held by the book.Cursor, so that it can be compared with others.
+func cursorBook(c *book.Cursor) entity.Book {
+	return entity.Book{
+		ID:            c.ID,
+		Title:         c.Title,
+		YearPublished: c.YearPublished,
+		Rating:        c.Rating,
+		Pages:         c.Pages,
+		Relevance:     c.Relevance,
+	}
+}
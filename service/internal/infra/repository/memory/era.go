@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	eradriver "github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type eraRepository struct {
+	store  *Store
+	logger *zap.Logger
+}
+
+// NewEraRepository accepts a pointer to a Store. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in an eraRepository and a pointer to it is returned.
+func NewEraRepository(store *Store, logger *zap.Logger) (*eraRepository, error) {
+	if store == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &eraRepository{
+		store:  store,
+		logger: logger,
+	}, nil
+}
+
+// List returns all Eras in the Store, ordered by ID.
+func (r *eraRepository) List(_ context.Context) ([]entity.Era, error) {
+	r.logger.Debug("listing eras from memory repository")
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	eras := r.list()
+
+	r.logger.Debug(fmt.Sprintf("found %d eras in memory repository", len(eras)))
+
+	return eras, nil
+}
+
+// Create stores an Era with the attributes of the era.WriteInput. A nil bound leaves that end of its range
+// open. The Store stays locked from check until the Era is stored. The ID assigned to the Era is returned. If
+// check returns an error, then it is returned and no Era is created.
+func (r *eraRepository) Create(_ context.Context, params eradriver.WriteInput,
+	check func([]entity.Era) error) (int32, error) {
+	r.logger.Debug("creating era in memory repository")
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return 0, err
+	}
+
+	id := r.store.nextID("era")
+	r.store.eras[id] = toEra(id, params)
+	return id, nil
+}
+
+// Update sets the attributes of the Era with the provided ID to those of the era.WriteInput. The Store stays
+// locked from check until the Era is updated. If check returns an error, then it is returned and no Era is
+// changed. If no such Era exists, then an error wrapping entity.ErrNotFound is returned.
+func (r *eraRepository) Update(_ context.Context, id int32, params eradriver.WriteInput,
+	check func([]entity.Era) error) error {
+	r.logger.Debug(fmt.Sprintf("updating era %d in memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return err
+	}
+
+	if _, ok := r.store.eras[id]; !ok {
+		return fmt.Errorf("%w: era %d does not exist", entity.ErrNotFound, id)
+	}
+	r.store.eras[id] = toEra(id, params)
+	return nil
+}
+
+// Delete deletes the Era with the provided ID. The Store stays locked from check until the Era is deleted. If
+// check returns an error, then it is returned and no Era is deleted. If no such Era exists, then an error
+// wrapping entity.ErrNotFound is returned.
+func (r *eraRepository) Delete(_ context.Context, id int32, check func([]entity.Era) error) error {
+	r.logger.Debug(fmt.Sprintf("deleting era %d from memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return err
+	}
+
+	if _, ok := r.store.eras[id]; !ok {
+		return fmt.Errorf("%w: era %d does not exist", entity.ErrNotFound, id)
+	}
+	delete(r.store.eras, id)
+	return nil
+}
+
+// ReplaceAll replaces every Era with those of the era.BulkInput at once. Eras whose IDs are not in the
+// era.BulkInput are deleted, those whose IDs are in it are updated, and elements without an ID are created.
+// The Store stays locked from check until the Eras are replaced. The resulting Eras are returned in the order
+// of the era.BulkInput. If check returns an error, then it is returned and no Era is changed. If an ID does
+// not identify an existing Era, then an error wrapping entity.ErrNotFound is returned and no Era is changed.
+func (r *eraRepository) ReplaceAll(_ context.Context, params []eradriver.BulkInput,
+	check func([]entity.Era) error) ([]entity.Era, error) {
+	r.logger.Debug(fmt.Sprintf("replacing all eras in memory repository with %d eras", len(params)))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return nil, err
+	}
+
+	for _, p := range params {
+		if p.ID == nil {
+			continue
+		}
+		if _, ok := r.store.eras[*p.ID]; !ok {
+			return nil, fmt.Errorf("%w: era %d does not exist", entity.ErrNotFound, *p.ID)
+		}
+	}
+
+	replaced := map[int32]entity.Era{}
+	eras := make([]entity.Era, len(params))
+	for i, p := range params {
+		var id int32
+		if p.ID == nil {
+			id = r.store.nextID("era")
+		} else {
+			id = *p.ID
+		}
+		eras[i] = toEra(id, p.WriteInput)
+		replaced[id] = eras[i]
+	}
+
+	r.store.eras = replaced
+	return eras, nil
+}
+
+// list returns all Eras in the Store, ordered by ID. The Store must be locked.
+func (r *eraRepository) list() []entity.Era {
+	var eras []entity.Era
+	for _, e := range r.store.eras {
+		eras = append(eras, e)
+	}
+	sort.Slice(eras, func(i, j int) bool { return eras[i].ID < eras[j].ID })
+	return eras
+}
+
+// toEra maps the era.WriteInput to an entity.Era with the provided ID.
+func toEra(id int32, params eradriver.WriteInput) entity.Era {
+	return entity.Era{ID: id, Title: *params.Title, MinYear: params.MinYear, MaxYear: params.MaxYear}
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	eradriver "github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEraRepository_ReplaceAll(t *testing.T) {
+
+	tests := map[string]struct {
+		params       []eradriver.BulkInput
+		check        func([]entity.Era) error
+		expect       []entity.Era
+		errAssertion assert.ErrorAssertionFunc
+		list         []entity.Era
+	}{
+		"update, create and delete": {
+			params: []eradriver.BulkInput{
+				{ID: int32Ptr(2), WriteInput: eradriver.WriteInput{Title: stringPtr("Modern"), MinYear: int16Ptr(1950)}},
+				{WriteInput: eradriver.WriteInput{Title: stringPtr("Classic"), MaxYear: int16Ptr(1949)}},
+			},
+			expect: []entity.Era{
+				{ID: 2, Title: "Modern", MinYear: int16Ptr(1950)},
+				{ID: 3, Title: "Classic", MaxYear: int16Ptr(1949)},
+			},
+			errAssertion: assert.NoError,
+			list: []entity.Era{
+				{ID: 2, Title: "Modern", MinYear: int16Ptr(1950)},
+				{ID: 3, Title: "Classic", MaxYear: int16Ptr(1949)},
+			},
+		},
+		"missing era changes nothing": {
+			params: []eradriver.BulkInput{
+				{WriteInput: eradriver.WriteInput{Title: stringPtr("Any")}},
+				{ID: int32Ptr(42), WriteInput: eradriver.WriteInput{Title: stringPtr("Modern")}},
+			},
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrNotFound)
+			},
+			list: newFixture().Eras,
+		},
+		"rejected eras change nothing": {
+			params: []eradriver.BulkInput{
+				{WriteInput: eradriver.WriteInput{Title: stringPtr("Any")}},
+			},
+			check: func([]entity.Era) error { return entity.ErrConflict },
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrConflict)
+			},
+			list: newFixture().Eras,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewEraRepository(newStore(t), zap.NewNop())
+			require.NoError(t, err)
+
+			var checked []entity.Era
+			check := func(eras []entity.Era) error {
+				checked = eras
+				if tt.check != nil {
+					return tt.check(eras)
+				}
+				return nil
+			}
+
+			actual, err := r.ReplaceAll(context.Background(), tt.params, check)
+			assert.Equal(t, newFixture().Eras, checked)
+			tt.errAssertion(t, err)
+			assert.Equal(t, tt.expect, actual)
+
+			list, err := r.List(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.list, list)
+		})
+	}
+}
+
+func TestEraRepository_Write(t *testing.T) {
+
+	ctx := context.Background()
+	r, err := NewEraRepository(newStore(t), zap.NewNop())
+	require.NoError(t, err)
+
+	pass := func([]entity.Era) error { return nil }
+	reject := func(eras []entity.Era) error {
+		assert.Len(t, eras, 3)
+		return entity.ErrConflict
+	}
+
+	id, err := r.Create(ctx, eradriver.WriteInput{Title: stringPtr("Future"), MinYear: int16Ptr(2100)}, pass)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), id)
+
+	_, err = r.Create(ctx, eradriver.WriteInput{Title: stringPtr("Past")}, reject)
+	assert.ErrorIs(t, err, entity.ErrConflict)
+
+	farFuture := eradriver.WriteInput{Title: stringPtr("Far future"), MinYear: int16Ptr(3000)}
+	require.NoError(t, r.Update(ctx, id, farFuture, pass))
+	assert.ErrorIs(t, r.Update(ctx, 42, eradriver.WriteInput{Title: stringPtr("Past")}, pass), entity.ErrNotFound)
+	assert.ErrorIs(t, r.Update(ctx, id, eradriver.WriteInput{Title: stringPtr("Past")}, reject), entity.ErrConflict)
+
+	assert.ErrorIs(t, r.Delete(ctx, id, reject), entity.ErrConflict)
+	eras, err := r.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Era{ID: id, Title: "Far future", MinYear: int16Ptr(3000)}, eras[2])
+
+	require.NoError(t, r.Delete(ctx, id, pass))
+	assert.ErrorIs(t, r.Delete(ctx, id, pass), entity.ErrNotFound)
+}
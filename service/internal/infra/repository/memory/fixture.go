@@ -0,0 +1,321 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture holds the rows a Store is seeded with. Its fields are named as the API names them in JSON, in both
+// JSON and YAML fixture files.
+type Fixture struct {
+	Eras    []entity.Era    `json:"eras"`
+	Sizes   []entity.Size   `json:"sizes"`
+	Genres  []entity.Genre  `json:"genres"`
+	Authors []entity.Author `json:"authors"`
+	Books   []FixtureBook   `json:"books"`
+}
+
+// FixtureBook is a Book of a Fixture, which references its Author and Genre by ID.
+type FixtureBook struct {
+	ID            int32   `json:"id"`
+	Title         string  `json:"title"`
+	YearPublished int16   `json:"yearPublished"`
+	Rating        float32 `json:"rating"`
+	Pages         int16   `json:"pages"`
+	AuthorID      int32   `json:"authorId"`
+	GenreID       int32   `json:"genreId"`
+}
+
+// LoadFixture reads a Fixture from a file. Files ending in ".json" are decoded as JSON, those ending in ".yaml"
+// or ".yml" as YAML, and those ending in ".sql" by ParseSQL.
+func LoadFixture(path string) (Fixture, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("unable to read fixture: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return decodeJSON(b)
+	case ".yaml", ".yml":
+		// YAML is converted to JSON, so that both are decoded by the same field names.
+		var v interface{}
+		if err := yaml.Unmarshal(b, &v); err != nil {
+			return Fixture{}, fmt.Errorf("unable to decode fixture: %w", err)
+		}
+		if b, err = json.Marshal(v); err != nil {
+			return Fixture{}, fmt.Errorf("unable to decode fixture: %w", err)
+		}
+		return decodeJSON(b)
+	case ".sql":
+		return ParseSQL(string(b))
+	}
+	return Fixture{}, fmt.Errorf("fixture %s should be a .json, .yaml, .yml or .sql file", path)
+}
+
+// decodeJSON decodes a Fixture from JSON. Unknown fields are rejected, so that misspelled ones are noticed.
+func decodeJSON(b []byte) (Fixture, error) {
+	var f Fixture
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return Fixture{}, fmt.Errorf("unable to decode fixture: %w", err)
+	}
+	return f, nil
+}
+
+// fixtureTables maps the tables whose INSERT statements ParseSQL reads to the fields of a Fixture.
+var fixtureTables = map[string]string{
+	"era":    "eras",
+	"size":   "sizes",
+	"genre":  "genres",
+	"author": "authors",
+	"book":   "books",
+}
+
+// ParseSQL reads a Fixture from the INSERT statements of a SQL script, such as the migrations and seed data of
+// the postgres package. Every other statement is skipped, as are INSERT statements into other tables. Only
+// multi-row VALUES lists of string, number and NULL literals are supported. Column names are mapped from
+// snake_case to the camelCase JSON field names, so that "min_year" sets MinYear.
+func ParseSQL(script string) (Fixture, error) {
+	rows := map[string][]map[string]json.RawMessage{}
+
+	p := &sqlParser{src: script}
+	for {
+		table, columns, values, err := p.nextInsert()
+		if err != nil {
+			return Fixture{}, err
+		}
+		if table == "" {
+			break
+		}
+
+		field, ok := fixtureTables[table]
+		if !ok {
+			continue
+		}
+		for _, v := range values {
+			if len(v) != len(columns) {
+				return Fixture{}, fmt.Errorf("insert into %s has %d columns but a row of %d values", table, len(columns), len(v))
+			}
+			row := map[string]json.RawMessage{}
+			for i, c := range columns {
+				row[camelCase(c)] = v[i]
+			}
+			rows[field] = append(rows[field], row)
+		}
+	}
+
+	b, err := json.Marshal(rows)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("unable to convert SQL rows: %w", err)
+	}
+	return decodeJSON(b)
+}
+
+// camelCase converts a snake_case column name to camelCase, and an "_id" suffix to "Id".
+func camelCase(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// sqlParser scans a SQL script for INSERT statements.
+type sqlParser struct {
+	src string
+	pos int
+}
+
+// nextInsert finds the next INSERT statement and returns its table, columns and rows of values, each of which
+// is a JSON literal. If there are no more INSERT statements, then the table is empty.
+func (p *sqlParser) nextInsert() (string, []string, [][]json.RawMessage, error) {
+	for {
+		word := p.word()
+		if word == "" {
+			if p.pos >= len(p.src) {
+				return "", nil, nil, nil
+			}
+			// Skip anything which is not a keyword, such as a literal or punctuation.
+			if _, err := p.literal(); err != nil {
+				p.pos++
+			}
+			continue
+		}
+		if !strings.EqualFold(word, "insert") {
+			continue
+		}
+		if !strings.EqualFold(p.word(), "into") {
+			continue
+		}
+
+		table := strings.ToLower(p.word())
+		columns, err := p.columns()
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("unable to parse insert into %s: %w", table, err)
+		}
+		if !strings.EqualFold(p.word(), "values") {
+			return "", nil, nil, fmt.Errorf("unable to parse insert into %s: only VALUES lists are supported", table)
+		}
+
+		var rows [][]json.RawMessage
+		for {
+			row, err := p.row()
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("unable to parse insert into %s: %w", table, err)
+			}
+			rows = append(rows, row)
+			if !p.consume(',') {
+				break
+			}
+		}
+		return table, columns, rows, nil
+	}
+}
+
+// columns parses a parenthesized list of column names.
+func (p *sqlParser) columns() ([]string, error) {
+	if !p.consume('(') {
+		return nil, fmt.Errorf("expected a list of columns at offset %d", p.pos)
+	}
+	var columns []string
+	for {
+		c := p.word()
+		if c == "" {
+			return nil, fmt.Errorf("expected a column at offset %d", p.pos)
+		}
+		columns = append(columns, strings.ToLower(c))
+		if p.consume(')') {
+			return columns, nil
+		}
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected ',' or ')' at offset %d", p.pos)
+		}
+	}
+}
+
+// row parses a parenthesized list of literals.
+func (p *sqlParser) row() ([]json.RawMessage, error) {
+	if !p.consume('(') {
+		return nil, fmt.Errorf("expected a row of values at offset %d", p.pos)
+	}
+	var row []json.RawMessage
+	for {
+		v, err := p.literal()
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, v)
+		if p.consume(')') {
+			return row, nil
+		}
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected ',' or ')' at offset %d", p.pos)
+		}
+	}
+}
+
+// literal parses a string, number or NULL literal into JSON.
+func (p *sqlParser) literal() (json.RawMessage, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("expected a value at the end of the script")
+	}
+
+	switch c := p.src[p.pos]; {
+	case c == '\'':
+		var sb strings.Builder
+		for p.pos++; p.pos < len(p.src); p.pos++ {
+			if p.src[p.pos] == '\'' {
+				// A quote is escaped by doubling it.
+				if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+					sb.WriteByte('\'')
+					p.pos++
+					continue
+				}
+				p.pos++
+				b, _ := json.Marshal(sb.String())
+				return b, nil
+			}
+			sb.WriteByte(p.src[p.pos])
+		}
+		return nil, fmt.Errorf("unterminated string")
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := p.pos
+		for p.pos++; p.pos < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0; p.pos++ {
+		}
+		n := p.src[start:p.pos]
+		if !json.Valid([]byte(n)) {
+			// JSON does not allow numbers such as ".5" or "1.", which SQL does.
+			n = strings.TrimSuffix(n, ".")
+			if strings.HasPrefix(n, ".") || strings.HasPrefix(n, "-.") {
+				n = strings.Replace(n, ".", "0.", 1)
+			}
+		}
+		if !json.Valid([]byte(n)) {
+			return nil, fmt.Errorf("invalid number %q", p.src[start:p.pos])
+		}
+		return json.RawMessage(n), nil
+	}
+
+	start := p.pos
+	if w := p.word(); strings.EqualFold(w, "null") {
+		return json.RawMessage("null"), nil
+	}
+	p.pos = start
+	return nil, fmt.Errorf("expected a string, number or NULL at offset %d", p.pos)
+}
+
+// word parses an identifier or keyword. If there is none, then it is empty.
+func (p *sqlParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := rune(p.src[p.pos])
+		if r != '_' && !unicode.IsLetter(r) && !(p.pos > start && unicode.IsDigit(r)) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// consume skips the byte if it is next, and reports whether it was.
+func (p *sqlParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpace skips whitespace and comments.
+func (p *sqlParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch {
+		case unicode.IsSpace(rune(p.src[p.pos])):
+			p.pos++
+		case strings.HasPrefix(p.src[p.pos:], "--"):
+			end := strings.IndexByte(p.src[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.src)
+			} else {
+				p.pos += end + 1
+			}
+		default:
+			return
+		}
+	}
+}
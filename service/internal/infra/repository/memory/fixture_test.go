@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSQL(t *testing.T) {
+
+	tests := map[string]struct {
+		script       string
+		expect       Fixture
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"inserts into every table": {
+			script: `
+				-- Eras, with an open-ended range.
+				INSERT INTO era (id, title, min_year, max_year) VALUES (1, 'Classic', NULL, 1969), (2, 'Modern', 1970, NULL)
+				ON CONFLICT (id) DO NOTHING;
+				INSERT INTO size (id, title, min_pages, max_pages) VALUES (1, 'Any', NULL, NULL);
+				INSERT INTO genre (id, title) VALUES (1, 'Children''s');
+				INSERT INTO author (id, first_name, last_name) VALUES (7, 'Ursula', 'Le Guin');
+				INSERT INTO book (id, title, year_published, rating, pages, genre_id, author_id)
+				VALUES (3, 'A Wizard of Earthsea', 1968, 4.50, 183, 1, 7);
+				SELECT setval('book_id_seq', (SELECT max(id) FROM book));`,
+			expect: Fixture{
+				Eras: []entity.Era{
+					{ID: 1, Title: "Classic", MaxYear: int16Ptr(1969)},
+					{ID: 2, Title: "Modern", MinYear: int16Ptr(1970)},
+				},
+				Sizes:   []entity.Size{{ID: 1, Title: "Any"}},
+				Genres:  []entity.Genre{{ID: 1, Title: "Children's"}},
+				Authors: []entity.Author{{ID: 7, FirstName: "Ursula", LastName: "Le Guin"}},
+				Books: []FixtureBook{
+					{ID: 3, Title: "A Wizard of Earthsea", YearPublished: 1968, Rating: 4.5, Pages: 183, AuthorID: 7, GenreID: 1},
+				},
+			},
+			errAssertion: assert.NoError,
+		},
+		"other statements and tables are skipped": {
+			script: `CREATE TABLE IF NOT EXISTS thing (id INTEGER, note TEXT DEFAULT 'insert into');
+				INSERT INTO thing (id) VALUES (1);`,
+			expect:       Fixture{},
+			errAssertion: assert.NoError,
+		},
+		"row does not match columns": {
+			script:       `INSERT INTO genre (id, title) VALUES (1);`,
+			errAssertion: assert.Error,
+		},
+		"unknown column": {
+			script:       `INSERT INTO genre (id, name) VALUES (1, 'Fantasy');`,
+			errAssertion: assert.Error,
+		},
+		"unterminated string": {
+			script:       `INSERT INTO genre (id, title) VALUES (1, 'Fantasy);`,
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseSQL(tt.script)
+			tt.errAssertion(t, err)
+			assert.Equal(t, tt.expect, actual)
+		})
+	}
+}
+
+func TestLoadFixture(t *testing.T) {
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	expect := Fixture{
+		Genres:  []entity.Genre{{ID: 1, Title: "Fantasy"}},
+		Authors: []entity.Author{{ID: 1, FirstName: "Ursula", LastName: "Le Guin"}},
+		Books: []FixtureBook{
+			{ID: 1, Title: "A Wizard of Earthsea", YearPublished: 1968, Rating: 4.5, Pages: 183, AuthorID: 1, GenreID: 1},
+		},
+	}
+
+	tests := map[string]struct {
+		path         string
+		expect       Fixture
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"json": {
+			path: write("fixture.json", `{
+				"genres": [{"id": 1, "title": "Fantasy"}],
+				"authors": [{"id": 1, "firstName": "Ursula", "lastName": "Le Guin"}],
+				"books": [{"id": 1, "title": "A Wizard of Earthsea", "yearPublished": 1968, "rating": 4.5,
+					"pages": 183, "authorId": 1, "genreId": 1}]
+			}`),
+			expect:       expect,
+			errAssertion: assert.NoError,
+		},
+		"yaml": {
+			path: write("fixture.yaml", `
+genres:
+  - {id: 1, title: Fantasy}
+authors:
+  - {id: 1, firstName: Ursula, lastName: Le Guin}
+books:
+  - id: 1
+    title: A Wizard of Earthsea
+    yearPublished: 1968
+    rating: 4.5
+    pages: 183
+    authorId: 1
+    genreId: 1
+`),
+			expect:       expect,
+			errAssertion: assert.NoError,
+		},
+		"sql": {
+			path: write("fixture.sql", `
+				INSERT INTO genre (id, title) VALUES (1, 'Fantasy');
+				INSERT INTO author (id, first_name, last_name) VALUES (1, 'Ursula', 'Le Guin');
+				INSERT INTO book (id, title, year_published, rating, pages, author_id, genre_id)
+				VALUES (1, 'A Wizard of Earthsea', 1968, 4.5, 183, 1, 1);`),
+			expect:       expect,
+			errAssertion: assert.NoError,
+		},
+		"misspelled field": {
+			path:         write("misspelled.json", `{"genre": [{"id": 1, "title": "Fantasy"}]}`),
+			errAssertion: assert.Error,
+		},
+		"unsupported extension": {
+			path:         write("fixture.txt", ``),
+			errAssertion: assert.Error,
+		},
+		"missing file": {
+			path:         filepath.Join(dir, "missing.json"),
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := LoadFixture(tt.path)
+			tt.errAssertion(t, err)
+			assert.Equal(t, tt.expect, actual)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type genreRepository struct {
+	store  *Store
+	logger *zap.Logger
+}
+
+// NewGenreRepository accepts a pointer to a Store. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in a genreRepository and a pointer to it is returned.
+func NewGenreRepository(store *Store, logger *zap.Logger) (*genreRepository, error) {
+	if store == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &genreRepository{
+		store:  store,
+		logger: logger,
+	}, nil
+}
+
+// List returns all Genres in the Store, ordered by ID.
+func (r *genreRepository) List(_ context.Context) ([]entity.Genre, error) {
+	r.logger.Debug("listing genres from memory repository")
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var genres []entity.Genre
+	for _, g := range r.store.genres {
+		genres = append(genres, g)
+	}
+	sort.Slice(genres, func(i, j int) bool { return genres[i].ID < genres[j].ID })
+
+	r.logger.Debug(fmt.Sprintf("found %d genres in memory repository", len(genres)))
+
+	return genres, nil
+}
+
+// Get returns the Genre with the provided ID. If no such Genre exists, then an error wrapping
+// entity.ErrNotFound is returned.
+func (r *genreRepository) Get(_ context.Context, id int32) (entity.Genre, error) {
+	r.logger.Debug(fmt.Sprintf("getting genre %d from memory repository", id))
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	g, ok := r.store.genres[id]
+	if !ok {
+		return entity.Genre{}, fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	return g, nil
+}
+
+// Stats aggregates the Books of the Genre with the provided ID. The average rating is rounded to two
+// decimal places, like the ratings themselves.
+func (r *genreRepository) Stats(_ context.Context, id int32) (genre.Stats, error) {
+	r.logger.Debug(fmt.Sprintf("aggregating books of genre %d from memory repository", id))
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	agg := r.store.aggregate(id, func(b record) int32 { return b.GenreID })
+	return genre.Stats{
+		BookCount:          agg.BookCount,
+		AuthorCount:        agg.AuthorCount,
+		AverageRating:      agg.AverageRating,
+		FirstYearPublished: agg.FirstYearPublished,
+		LastYearPublished:  agg.LastYearPublished,
+	}, nil
+}
+
+// Create stores a Genre with the attributes of the genre.WriteInput, which must all be set. The ID assigned to
+// the Genre is returned.
+func (r *genreRepository) Create(_ context.Context, params genre.WriteInput) (int32, error) {
+	r.logger.Debug("creating genre in memory repository")
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	id := r.store.nextID("genre")
+	r.store.genres[id] = entity.Genre{ID: id, Title: *params.Title}
+
+	r.logger.Debug(fmt.Sprintf("created genre %d in memory repository", id))
+	return id, nil
+}
+
+// Update sets the attributes of the Genre with the provided ID to those of the genre.WriteInput, which must all
+// be set. If no such Genre exists, then an error wrapping entity.ErrNotFound is returned.
+func (r *genreRepository) Update(_ context.Context, id int32, params genre.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating genre %d in memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.genres[id]; !ok {
+		return fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	r.store.genres[id] = entity.Genre{ID: id, Title: *params.Title}
+	return nil
+}
+
+// Delete deletes the Genre with the provided ID. If reassignTo is not nil, then the Genre's Books are first
+// reassigned to the Genre with that ID, all at once. If no such Genre exists, then an error wrapping
+// entity.ErrNotFound is returned. If Books still reference the Genre, then an error wrapping
+// entity.ErrConflict is returned.
+func (r *genreRepository) Delete(_ context.Context, id int32, reassignTo *int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting genre %d from memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.genres[id]; !ok {
+		return fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+
+	if reassignTo != nil {
+		if _, ok := r.store.genres[*reassignTo]; !ok {
+			return fmt.Errorf("unable to reassign books of genre: genre %d does not exist", *reassignTo)
+		}
+		r.store.reassign(id, *reassignTo, func(b *record) *int32 { return &b.GenreID })
+	}
+
+	if r.store.references(id, func(b record) int32 { return b.GenreID }) > 0 {
+		return fmt.Errorf("%w: genre %d is still referenced by books", entity.ErrConflict, id)
+	}
+
+	delete(r.store.genres, id)
+	return nil
+}
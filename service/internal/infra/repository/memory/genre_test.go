@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGenreRepository_Stats(t *testing.T) {
+
+	r, err := NewGenreRepository(newStore(t), zap.NewNop())
+	require.NoError(t, err)
+
+	// (4.7 + 4.5 + 3.25) / 3 = 4.15
+	avg := 4.15
+	actual, err := r.Stats(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, genre.Stats{
+		BookCount:          3,
+		AuthorCount:        2,
+		AverageRating:      &avg,
+		FirstYearPublished: int16Ptr(1926),
+		LastYearPublished:  int16Ptr(1990),
+	}, actual)
+}
+
+func TestGenreRepository_Write(t *testing.T) {
+
+	ctx := context.Background()
+	r, err := NewGenreRepository(newStore(t), zap.NewNop())
+	require.NoError(t, err)
+
+	id, err := r.Create(ctx, genre.WriteInput{Title: stringPtr("Horror")})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), id)
+
+	require.NoError(t, r.Update(ctx, id, genre.WriteInput{Title: stringPtr("Gothic")}))
+	g, err := r.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Genre{ID: id, Title: "Gothic"}, g)
+
+	assert.ErrorIs(t, r.Update(ctx, 42, genre.WriteInput{Title: stringPtr("Gothic")}), entity.ErrNotFound)
+	assert.ErrorIs(t, r.Delete(ctx, 2, nil), entity.ErrConflict)
+	require.NoError(t, r.Delete(ctx, 2, &id))
+
+	stats, err := r.Stats(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.BookCount)
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	sizedriver "github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"go.uber.org/zap"
+)
+
+type sizeRepository struct {
+	store  *Store
+	logger *zap.Logger
+}
+
+// NewSizeRepository accepts a pointer to a Store. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in a sizeRepository and a pointer to it is returned.
+func NewSizeRepository(store *Store, logger *zap.Logger) (*sizeRepository, error) {
+	if store == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &sizeRepository{
+		store:  store,
+		logger: logger,
+	}, nil
+}
+
+// List returns all Sizes in the Store, ordered by ID.
+func (r *sizeRepository) List(_ context.Context) ([]entity.Size, error) {
+	r.logger.Debug("listing sizes from memory repository")
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sizes := r.list()
+
+	r.logger.Debug(fmt.Sprintf("found %d sizes in memory repository", len(sizes)))
+
+	return sizes, nil
+}
+
+// Create stores a Size with the attributes of the size.WriteInput. A nil bound leaves that end of its range
+// open. The Store stays locked from check until the Size is stored. The ID assigned to the Size is returned. If
+// check returns an error, then it is returned and no Size is created.
+func (r *sizeRepository) Create(_ context.Context, params sizedriver.WriteInput,
+	check func([]entity.Size) error) (int32, error) {
+	r.logger.Debug("creating size in memory repository")
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return 0, err
+	}
+
+	id := r.store.nextID("size")
+	r.store.sizes[id] = toSize(id, params)
+	return id, nil
+}
+
+// Update sets the attributes of the Size with the provided ID to those of the size.WriteInput. The Store stays
+// locked from check until the Size is updated. If check returns an error, then it is returned and no Size is
+// changed. If no such Size exists, then an error wrapping entity.ErrNotFound is returned.
+func (r *sizeRepository) Update(_ context.Context, id int32, params sizedriver.WriteInput,
+	check func([]entity.Size) error) error {
+	r.logger.Debug(fmt.Sprintf("updating size %d in memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return err
+	}
+
+	if _, ok := r.store.sizes[id]; !ok {
+		return fmt.Errorf("%w: size %d does not exist", entity.ErrNotFound, id)
+	}
+	r.store.sizes[id] = toSize(id, params)
+	return nil
+}
+
+// Delete deletes the Size with the provided ID. The Store stays locked from check until the Size is deleted. If
+// check returns an error, then it is returned and no Size is deleted. If no such Size exists, then an error
+// wrapping entity.ErrNotFound is returned.
+func (r *sizeRepository) Delete(_ context.Context, id int32, check func([]entity.Size) error) error {
+	r.logger.Debug(fmt.Sprintf("deleting size %d from memory repository", id))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return err
+	}
+
+	if _, ok := r.store.sizes[id]; !ok {
+		return fmt.Errorf("%w: size %d does not exist", entity.ErrNotFound, id)
+	}
+	delete(r.store.sizes, id)
+	return nil
+}
+
+// ReplaceAll replaces every Size with those of the size.BulkInput at once. Sizes whose IDs are not in the
+// size.BulkInput are deleted, those whose IDs are in it are updated, and elements without an ID are created.
+// The Store stays locked from check until the Sizes are replaced. The resulting Sizes are returned in the order
+// of the size.BulkInput. If check returns an error, then it is returned and no Size is changed. If an ID does
+// not identify an existing Size, then an error wrapping entity.ErrNotFound is returned and no Size is changed.
+func (r *sizeRepository) ReplaceAll(_ context.Context, params []sizedriver.BulkInput,
+	check func([]entity.Size) error) ([]entity.Size, error) {
+	r.logger.Debug(fmt.Sprintf("replacing all sizes in memory repository with %d sizes", len(params)))
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if err := check(r.list()); err != nil {
+		return nil, err
+	}
+
+	for _, p := range params {
+		if p.ID == nil {
+			continue
+		}
+		if _, ok := r.store.sizes[*p.ID]; !ok {
+			return nil, fmt.Errorf("%w: size %d does not exist", entity.ErrNotFound, *p.ID)
+		}
+	}
+
+	replaced := map[int32]entity.Size{}
+	sizes := make([]entity.Size, len(params))
+	for i, p := range params {
+		var id int32
+		if p.ID == nil {
+			id = r.store.nextID("size")
+		} else {
+			id = *p.ID
+		}
+		sizes[i] = toSize(id, p.WriteInput)
+		replaced[id] = sizes[i]
+	}
+
+	r.store.sizes = replaced
+	return sizes, nil
+}
+
+// list returns all Sizes in the Store, ordered by ID. The Store must be locked.
+func (r *sizeRepository) list() []entity.Size {
+	var sizes []entity.Size
+	for _, s := range r.store.sizes {
+		sizes = append(sizes, s)
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i].ID < sizes[j].ID })
+	return sizes
+}
+
+// toSize maps the size.WriteInput to an entity.Size with the provided ID.
+func toSize(id int32, params sizedriver.WriteInput) entity.Size {
+	return entity.Size{ID: id, Title: *params.Title, MinPages: params.MinPages, MaxPages: params.MaxPages}
+}
@@ -0,0 +1,218 @@
+// Package memory implements every repository in memory, so that the service can run without a database. Its
+// repositories share a Store, which plays the part of the database: Books are joined with the Authors and Genres
+// they reference, and IDs are assigned by a sequence per table, as they are by Postgres.
+package memory
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidDependency = errors.New("expected a non-nil memory Store")
+)
+
+// record is a Book as it is stored: it references its Author and Genre by ID, rather than embedding them.
+type record struct {
+	ID            int32
+	Title         string
+	YearPublished int16
+	Rating        float32
+	Pages         int16
+	AuthorID      int32
+	GenreID       int32
+}
+
+// Store holds the rows of every table. It is safe for concurrent use by the repositories.
+type Store struct {
+	mu sync.RWMutex
+
+	books   map[int32]record
+	authors map[int32]entity.Author
+	genres  map[int32]entity.Genre
+	eras    map[int32]entity.Era
+	sizes   map[int32]entity.Size
+
+	// sequences holds the last ID assigned in each table. Like an identity column, a sequence never goes
+	// back, so the IDs of deleted rows are not reused.
+	sequences map[string]int32
+}
+
+// NewStore creates an empty Store.
+func NewStore() *Store {
+	return &Store{
+		books:     map[int32]record{},
+		authors:   map[int32]entity.Author{},
+		genres:    map[int32]entity.Genre{},
+		eras:      map[int32]entity.Era{},
+		sizes:     map[int32]entity.Size{},
+		sequences: map[string]int32{},
+	}
+}
+
+// Load replaces the contents of the Store with the Fixture. Every Book must reference an Author and a Genre of
+// the Fixture, and IDs must be unique within each table. If not, then an error is returned and the Store is left
+// as it was. The sequence of each table continues after its greatest ID.
+func (s *Store) Load(f Fixture) error {
+	loaded := NewStore()
+
+	for _, e := range f.Eras {
+		if _, ok := loaded.eras[e.ID]; ok {
+			return fmt.Errorf("era %d is repeated", e.ID)
+		}
+		loaded.eras[e.ID] = e
+		loaded.advance("era", e.ID)
+	}
+
+	for _, z := range f.Sizes {
+		if _, ok := loaded.sizes[z.ID]; ok {
+			return fmt.Errorf("size %d is repeated", z.ID)
+		}
+		loaded.sizes[z.ID] = z
+		loaded.advance("size", z.ID)
+	}
+
+	for _, g := range f.Genres {
+		if _, ok := loaded.genres[g.ID]; ok {
+			return fmt.Errorf("genre %d is repeated", g.ID)
+		}
+		loaded.genres[g.ID] = g
+		loaded.advance("genre", g.ID)
+	}
+
+	for _, a := range f.Authors {
+		if _, ok := loaded.authors[a.ID]; ok {
+			return fmt.Errorf("author %d is repeated", a.ID)
+		}
+		loaded.authors[a.ID] = a
+		loaded.advance("author", a.ID)
+	}
+
+	for _, b := range f.Books {
+		if _, ok := loaded.books[b.ID]; ok {
+			return fmt.Errorf("book %d is repeated", b.ID)
+		}
+		if _, ok := loaded.authors[b.AuthorID]; !ok {
+			return fmt.Errorf("book %d references author %d, which does not exist", b.ID, b.AuthorID)
+		}
+		if _, ok := loaded.genres[b.GenreID]; !ok {
+			return fmt.Errorf("book %d references genre %d, which does not exist", b.ID, b.GenreID)
+		}
+		loaded.books[b.ID] = record{
+			ID:            b.ID,
+			Title:         b.Title,
+			YearPublished: b.YearPublished,
+			Rating:        roundRating(b.Rating),
+			Pages:         b.Pages,
+			AuthorID:      b.AuthorID,
+			GenreID:       b.GenreID,
+		}
+		loaded.advance("book", b.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.books, s.authors, s.genres, s.eras, s.sizes = loaded.books, loaded.authors, loaded.genres, loaded.eras, loaded.sizes
+	s.sequences = loaded.sequences
+	return nil
+}
+
+// nextID assigns the next ID of the table. The Store must be locked for writing.
+func (s *Store) nextID(table string) int32 {
+	s.sequences[table]++
+	return s.sequences[table]
+}
+
+// advance moves the sequence of the table past the ID, so that it is not assigned again.
+func (s *Store) advance(table string, id int32) {
+	if id > s.sequences[table] {
+		s.sequences[table] = id
+	}
+}
+
+// book joins the record with its Author and Genre. Like a LEFT JOIN, an Author or Genre which does not exist
+// is left as its zero value. The Store must be locked for reading.
+func (s *Store) book(r record) entity.Book {
+	return entity.Book{
+		ID:            r.ID,
+		Title:         r.Title,
+		YearPublished: r.YearPublished,
+		Rating:        r.Rating,
+		Pages:         r.Pages,
+		Author:        s.authors[r.AuthorID],
+		Genre:         s.genres[r.GenreID],
+	}
+}
+
+// references counts the records for which the reference returns the ID. The Store must be locked for reading.
+func (s *Store) references(id int32, reference func(record) int32) int {
+	n := 0
+	for _, r := range s.books {
+		if reference(r) == id {
+			n++
+		}
+	}
+	return n
+}
+
+// reassign points the records which reference the ID at the reassignTo ID instead. The Store must be locked for
+// writing.
+func (s *Store) reassign(id, reassignTo int32, reference func(*record) *int32) {
+	for bookID, r := range s.books {
+		if ref := reference(&r); *ref == id {
+			*ref = reassignTo
+			s.books[bookID] = r
+		}
+	}
+}
+
+// aggregate summarizes the records for which the reference returns the ID, as the aggregate functions of SQL
+// would. The Store must be locked for reading.
+func (s *Store) aggregate(id int32, reference func(record) int32) aggregates {
+	var (
+		agg     aggregates
+		cents   int64
+		authors = map[int32]struct{}{}
+	)
+	for _, r := range s.books {
+		if reference(r) != id {
+			continue
+		}
+		agg.BookCount++
+		authors[r.AuthorID] = struct{}{}
+		cents += int64(math.Round(float64(r.Rating) * 100))
+		if agg.FirstYearPublished == nil || r.YearPublished < *agg.FirstYearPublished {
+			year := r.YearPublished
+			agg.FirstYearPublished = &year
+		}
+		if agg.LastYearPublished == nil || r.YearPublished > *agg.LastYearPublished {
+			year := r.YearPublished
+			agg.LastYearPublished = &year
+		}
+	}
+	agg.AuthorCount = int64(len(authors))
+	if agg.BookCount > 0 {
+		// Ratings are summed in hundredths, which they are stored in exactly, before the average is rounded.
+		avg := math.Round(float64(cents)/float64(agg.BookCount)) / 100
+		agg.AverageRating = &avg
+	}
+	return agg
+}
+
+// aggregates are the results of aggregate, from which the Stats of Authors and Genres are taken.
+type aggregates struct {
+	BookCount          int64
+	AuthorCount        int64
+	AverageRating      *float64
+	FirstYearPublished *int16
+	LastYearPublished  *int16
+}
+
+// roundRating rounds the rating to two decimal places, as it is stored by a NUMERIC(3, 2) column.
+func roundRating(rating float32) float32 {
+	return float32(math.Round(float64(rating)*100) / 100)
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func int16Ptr(v int16) *int16 { return &v }
+
+// newFixture returns a small Fixture which the tests of the package search and modify.
+func newFixture() Fixture {
+	return Fixture{
+		Eras: []entity.Era{
+			{ID: 1, Title: "Classic", MaxYear: int16Ptr(1969)},
+			{ID: 2, Title: "Modern", MinYear: int16Ptr(1970)},
+		},
+		Sizes: []entity.Size{
+			{ID: 1, Title: "Short story", MaxPages: int16Ptr(99)},
+			{ID: 2, Title: "Novel", MinPages: int16Ptr(100)},
+		},
+		Genres: []entity.Genre{
+			{ID: 1, Title: "Fantasy"},
+			{ID: 2, Title: "Mystery"},
+		},
+		Authors: []entity.Author{
+			{ID: 1, FirstName: "Ursula", LastName: "Le Guin"},
+			{ID: 2, FirstName: "Agatha", LastName: "Christie"},
+			{ID: 3, FirstName: "Idle", LastName: "Writer"},
+		},
+		Books: []FixtureBook{
+			{ID: 1, Title: "A Wizard of Earthsea", YearPublished: 1968, Rating: 4.5, Pages: 183, AuthorID: 1, GenreID: 1},
+			{ID: 2, Title: "The Tombs of Atuan", YearPublished: 1971, Rating: 4.2, Pages: 163, AuthorID: 1, GenreID: 1},
+			{ID: 3, Title: "The Murder of Roger Ackroyd", YearPublished: 1926, Rating: 4.7, Pages: 312, AuthorID: 2, GenreID: 2},
+			{ID: 4, Title: "Murder on the Orient Express", YearPublished: 1934, Rating: 4.5, Pages: 256, AuthorID: 2, GenreID: 2},
+			{ID: 5, Title: "The Witch and the Murder", YearPublished: 1990, Rating: 3.25, Pages: 64, AuthorID: 1, GenreID: 2},
+		},
+	}
+}
+
+// newStore returns a Store loaded with newFixture.
+func newStore(t *testing.T) *Store {
+	t.Helper()
+	s := NewStore()
+	require.NoError(t, s.Load(newFixture()))
+	return s
+}
+
+func TestStore_Load(t *testing.T) {
+
+	tests := map[string]struct {
+		modify       func(*Fixture)
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"valid fixture": {
+			modify:       func(*Fixture) {},
+			errAssertion: assert.NoError,
+		},
+		"repeated author": {
+			modify: func(f *Fixture) {
+				f.Authors = append(f.Authors, entity.Author{ID: 1, FirstName: "Again"})
+			},
+			errAssertion: assert.Error,
+		},
+		"book references missing author": {
+			modify: func(f *Fixture) {
+				f.Books[0].AuthorID = 42
+			},
+			errAssertion: assert.Error,
+		},
+		"book references missing genre": {
+			modify: func(f *Fixture) {
+				f.Books[0].GenreID = 42
+			},
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := NewStore()
+			f := newFixture()
+			tt.modify(&f)
+			tt.errAssertion(t, s.Load(f))
+		})
+	}
+
+	t.Run("failed load leaves the store as it was", func(t *testing.T) {
+		s := newStore(t)
+		f := newFixture()
+		f.Books[0].GenreID = 42
+		require.Error(t, s.Load(f))
+		assert.Len(t, s.books, 5)
+	})
+
+	t.Run("sequences continue after the greatest ID", func(t *testing.T) {
+		s := newStore(t)
+		assert.Equal(t, int32(6), s.nextID("book"))
+		assert.Equal(t, int32(4), s.nextID("author"))
+		assert.Equal(t, int32(1), s.nextID("unknown"))
+	})
+}
@@ -0,0 +1,388 @@
+// Package migration applies and rolls back the versioned schema Migrations of a SQL database, and records which
+// of them have been applied in its version table. What differs between databases is described by a Dialect.
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// VersionTable records the version of every Migration which has been applied to the database.
+const VersionTable = "schema_migration"
+
+// Dialect holds the SQL with which a Migrator keeps track of the Migrations applied to a database.
+type Dialect struct {
+	// Lock and Unlock are run, with the LockArgs, on the connection which migrates, so that concurrent
+	// migrations are applied one after the other. If Lock is empty, then no lock is taken.
+	Lock     string
+	Unlock   string
+	LockArgs []interface{}
+
+	// CreateTable creates the VersionTable, unless it exists, with the columns version, name and applied_at.
+	CreateTable string
+
+	// TableExists selects whether the VersionTable exists. Its placeholder takes the name of the table.
+	TableExists string
+
+	// Record inserts the version and name of an applied Migration into the VersionTable, and Forget deletes the
+	// version of a rolled back one.
+	Record string
+	Forget string
+}
+
+// migrationFile matches the names of migration files, such as "0001_create_tables.up.sql".
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned change to the schema of the database, which can be applied and rolled back.
+type Migration struct {
+	// Version orders the Migrations. Migrations are applied in ascending order and rolled back in descending order.
+	Version int
+
+	// Name describes the change, such as "create_tables".
+	Name string
+
+	// Up is the SQL which applies the Migration.
+	Up string
+
+	// Down is the SQL which rolls the Migration back.
+	Down string
+}
+
+// Status is a Migration and the time at which it was applied to the database.
+type Status struct {
+	Migration
+
+	// AppliedAt is nil if the Migration has not been applied.
+	AppliedAt *time.Time
+}
+
+// Read returns every Migration in the root of the file system, in ascending order of Version. Each Migration
+// is read from a pair of files named as VERSION_NAME.up.sql and VERSION_NAME.down.sql.
+func Read(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		match := migrationFile.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named as VERSION_NAME.up.sql or VERSION_NAME.down.sql", e.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has an invalid version: %w", e.Name(), err)
+		}
+
+		b, err := fs.ReadFile(files, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("unable to read migration file %s: %w", e.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Latest returns the Version of the latest of the Migrations, or 0 if there are none.
+func Latest(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrator applies Migrations to a database, in its Dialect.
+type Migrator struct {
+	db         *sql.DB
+	logger     *zap.Logger
+	dialect    Dialect
+	migrations []Migration
+	seed       string
+}
+
+// New creates a Migrator which applies the Migrations, in ascending order of Version, to the database. Seed
+// is the SQL which inserts the sample data. The database connection cannot be nil.
+func New(db *sql.DB, logger *zap.Logger, dialect Dialect, migrations []Migration, seed string) (*Migrator, error) {
+	if db == nil {
+		return nil, errors.New("database connection cannot be nil")
+	}
+	return &Migrator{db: db, logger: logger, dialect: dialect, migrations: migrations, seed: seed}, nil
+}
+
+// Version returns the Version of the latest Migration applied to the database. If none has been applied,
+// then it is 0.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return 0, err
+	}
+	return latest(applied), nil
+}
+
+// Status returns the Status of every Migration known to the Migrator.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies every Migration which has not been applied yet, and returns them. Migrations which are unknown to
+// the Migrator, such as those applied by a newer binary, are left as they are.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.migrate(ctx, m.SchemaVersion(), false)
+}
+
+// Down rolls back the given number of the latest applied Migrations, and returns them.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps is %d but should be greater than 0", steps)
+	}
+
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	target := 0
+	if steps < len(versions) {
+		target = versions[steps]
+	}
+	return m.To(ctx, target)
+}
+
+// To applies or rolls back Migrations until the latest applied Migration is the one with the given version,
+// and returns the Migrations that were applied or rolled back, in that order. Version 0 rolls back every
+// Migration. Migrations are run while holding the lock of the Dialect, and each runs in its own transaction.
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("migration %d does not exist", version)
+	}
+	return m.migrate(ctx, version, true)
+}
+
+// migrate applies every Migration up to the given version. If rollback is true, then every Migration after it
+// is rolled back as well.
+func (m *Migrator) migrate(ctx context.Context, version int, rollback bool) ([]Migration, error) {
+	// Session-level locks belong to a connection, so every statement must use the same one.
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get a database connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.dialect.Lock != "" {
+		if _, err := conn.ExecContext(ctx, m.dialect.Lock, m.dialect.LockArgs...); err != nil {
+			return nil, fmt.Errorf("unable to acquire the migration lock: %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), m.dialect.Unlock, m.dialect.LockArgs...); err != nil {
+				m.logger.Warn(fmt.Sprintf("unable to release the migration lock: %s", err))
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, m.dialect.CreateTable); err != nil {
+		return nil, fmt.Errorf("unable to create the %s table: %w", VersionTable, err)
+	}
+
+	// The applied Migrations are read only once the lock is held, as another migration may have just finished.
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var run []Migration
+	for v := range applied {
+		if rollback && v > version && m.find(v) == nil {
+			return nil, fmt.Errorf("migration %d is applied but is unknown to this binary, so it cannot be rolled back", v)
+		}
+	}
+
+	for i := len(m.migrations) - 1; i >= 0 && rollback; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+		if err := m.run(ctx, conn, migration.Down, m.dialect.Forget, migration.Version); err != nil {
+			return run, fmt.Errorf("unable to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		m.logger.Info(fmt.Sprintf("rolled back migration %d_%s", migration.Version, migration.Name))
+		run = append(run, migration)
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+		if err := m.run(ctx, conn, migration.Up, m.dialect.Record, migration.Version, migration.Name); err != nil {
+			return run, fmt.Errorf("unable to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		m.logger.Info(fmt.Sprintf("applied migration %d_%s", migration.Version, migration.Name))
+		run = append(run, migration)
+	}
+
+	return run, nil
+}
+
+// Seed inserts the sample genres, authors and books. Rows which already exist are left as they are, so it may
+// be run more than once. The schema must be up to date.
+func (m *Migrator) Seed(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version < m.SchemaVersion() {
+		return fmt.Errorf("the schema is at version %d but must be migrated to version %d before seeding",
+			version, m.SchemaVersion())
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, m.seed); err != nil {
+		return fmt.Errorf("unable to seed the database: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Conn.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// applied returns the time at which each applied Migration was applied, by Version. If the version table does
+// not exist yet, then no Migration has been applied.
+func (m *Migrator) applied(ctx context.Context, q queryer) (map[int]time.Time, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, m.dialect.TableExists, VersionTable).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("unable to check for the %s table: %w", VersionTable, err)
+	}
+
+	applied := make(map[int]time.Time)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT version, applied_at FROM "+VersionTable)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			version int
+			at      time.Time
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("unable to scan applied migration: %w", err)
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to query applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// run executes the SQL of a Migration and records it in the version table, in a single transaction.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, migration, record string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, migration); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("unable to record migration: %w", err)
+	}
+	return tx.Commit()
+}
+
+// SchemaVersion returns the Version of the latest Migration, which is the version of the schema that the
+// repositories expect, or 0 if there are none.
+func (m *Migrator) SchemaVersion() int {
+	return Latest(m.migrations)
+}
+
+// find returns the Migration with the given version, or nil if there is none.
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// latest returns the greatest applied version, or 0 if none has been applied.
+func latest(applied map[int]time.Time) int {
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version
+}
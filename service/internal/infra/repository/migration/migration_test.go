@@ -0,0 +1,232 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	lockQuery     = "SELECT pg_advisory_lock($1)"
+	unlockQuery   = "SELECT pg_advisory_unlock($1)"
+	createQuery   = "CREATE TABLE IF NOT EXISTS schema_migration"
+	existsQuery   = "SELECT to_regclass($1) IS NOT NULL"
+	appliedQuery  = "SELECT version, applied_at FROM schema_migration"
+	insertVersion = "INSERT INTO schema_migration (version, name) VALUES ($1, $2)"
+	deleteVersion = "DELETE FROM schema_migration WHERE version = $1"
+)
+
+const lockKey int64 = 42
+
+var testDialect = Dialect{
+	Lock:        lockQuery,
+	Unlock:      unlockQuery,
+	LockArgs:    []interface{}{lockKey},
+	CreateTable: createQuery + " (version INTEGER PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMPTZ)",
+	TableExists: existsQuery,
+	Record:      insertVersion,
+	Forget:      deleteVersion,
+}
+
+func newMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("unable to create sql mock: %v", err)
+	}
+	return db, mock
+}
+
+func TestRead(t *testing.T) {
+
+	tests := map[string]struct {
+		files        fstest.MapFS
+		expect       []Migration
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"ordered by version": {
+			files: fstest.MapFS{
+				"0002_add_index.up.sql":       {Data: []byte("CREATE INDEX")},
+				"0002_add_index.down.sql":     {Data: []byte("DROP INDEX")},
+				"0001_create_tables.up.sql":   {Data: []byte("CREATE TABLE")},
+				"0001_create_tables.down.sql": {Data: []byte("DROP TABLE")},
+			},
+			expect: []Migration{
+				{Version: 1, Name: "create_tables", Up: "CREATE TABLE", Down: "DROP TABLE"},
+				{Version: 2, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+			},
+			errAssertion: assert.NoError,
+		},
+		"missing down file": {
+			files: fstest.MapFS{
+				"0001_create_tables.up.sql": {Data: []byte("CREATE TABLE")},
+			},
+			errAssertion: assert.Error,
+		},
+		"misnamed file": {
+			files: fstest.MapFS{
+				"create_tables.sql": {Data: []byte("CREATE TABLE")},
+			},
+			errAssertion: assert.Error,
+		},
+		"conflicting names": {
+			files: fstest.MapFS{
+				"0001_create_tables.up.sql":   {Data: []byte("CREATE TABLE")},
+				"0001_create_things.down.sql": {Data: []byte("DROP TABLE")},
+			},
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Read(tt.files)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestMigrator_To(t *testing.T) {
+
+	migrations := []Migration{
+		{Version: 1, Name: "create_tables", Up: "CREATE TABLE thing (id INTEGER)", Down: "DROP TABLE thing"},
+		{Version: 2, Name: "add_index", Up: "CREATE INDEX thing_id ON thing (id)", Down: "DROP INDEX thing_id"},
+	}
+
+	applied := func(versions ...int) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"version", "applied_at"})
+		for _, v := range versions {
+			rows.AddRow(v, time.Now())
+		}
+		return rows
+	}
+
+	tests := map[string]struct {
+		version         int
+		setExpectations func(sqlmock.Sqlmock)
+		expect          []Migration
+		errAssertion    assert.ErrorAssertionFunc
+	}{
+		"unknown version": {
+			version:         3,
+			setExpectations: func(sqlmock.Sqlmock) {},
+			errAssertion:    assert.Error,
+		},
+		"apply every migration": {
+			version:      2,
+			expect:       migrations,
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(applied())
+				for _, m := range migrations {
+					mock.ExpectBegin()
+					mock.ExpectExec(regexp.QuoteMeta(m.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(regexp.QuoteMeta(insertVersion)).WithArgs(m.Version, m.Name).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				}
+			},
+		},
+		"roll back every migration": {
+			version:      0,
+			expect:       []Migration{migrations[1], migrations[0]},
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(applied(1, 2))
+				for _, m := range []Migration{migrations[1], migrations[0]} {
+					mock.ExpectBegin()
+					mock.ExpectExec(regexp.QuoteMeta(m.Down)).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(regexp.QuoteMeta(deleteVersion)).WithArgs(m.Version).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				}
+			},
+		},
+		"already at version": {
+			version:      1,
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(applied(1))
+			},
+		},
+		"applied migration unknown to the binary": {
+			version:      2,
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(applied(1, 2, 3))
+			},
+		},
+		"failed migration is rolled back": {
+			version:      2,
+			expect:       migrations[:1],
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnRows(applied())
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(migrations[0].Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(insertVersion)).WithArgs(1, "create_tables").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(migrations[1].Up)).WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			m := &Migrator{db: db, logger: zap.NewNop(), dialect: testDialect, migrations: migrations}
+
+			// Every migration locks, creates the version table and checks for it before running.
+			if tt.version <= len(migrations) {
+				mock.ExpectExec(regexp.QuoteMeta(lockQuery)).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(regexp.QuoteMeta(createQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(regexp.QuoteMeta(existsQuery)).WithArgs(VersionTable).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+			}
+			tt.setExpectations(mock)
+			if tt.version <= len(migrations) {
+				mock.ExpectExec(regexp.QuoteMeta(unlockQuery)).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+
+			actual, err := m.To(context.Background(), tt.version)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+
+	migrations := []Migration{
+		{Version: 1, Name: "create_tables", Up: "CREATE TABLE thing (id INTEGER)", Down: "DROP TABLE thing"},
+	}
+
+	db, mock := newMock(t)
+	m := &Migrator{db: db, logger: zap.NewNop(), dialect: testDialect, migrations: migrations}
+
+	// Migrations applied by a newer binary are left as they are.
+	mock.ExpectExec(regexp.QuoteMeta(lockQuery)).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(createQuery)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta(existsQuery)).WithArgs(VersionTable).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()).AddRow(2, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(unlockQuery)).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	actual, err := m.Up(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
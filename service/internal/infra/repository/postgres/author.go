@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/booksql"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -64,3 +67,148 @@ func (r *authorRepository) List(ctx context.Context) ([]entity.Author, error) {
 
 	return authors, nil
 }
+
+// Get selects the Author with the provided ID. If no such Author exists, then an error wrapping
+// entity.ErrNotFound is returned. If the query fails, then an error is returned.
+func (r *authorRepository) Get(ctx context.Context, id int32) (entity.Author, error) {
+	r.logger.Debug(fmt.Sprintf("getting author %d from postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("id", "first_name", "last_name").
+		From("author").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return entity.Author{}, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var a entity.Author
+	err = r.db.QueryRowContext(ctx, query, values...).Scan(&a.ID, &a.FirstName, &a.LastName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Author{}, fmt.Errorf("%w: author %d does not exist", entity.ErrNotFound, id)
+	}
+	if err != nil {
+		return entity.Author{}, fmt.Errorf("unable to get author: %w", err)
+	}
+
+	return a, nil
+}
+
+// Stats aggregates the Books written by the Author with the provided ID. The average rating is rounded
+// to two decimal places, like the ratings themselves. If the query fails, then an error is returned.
+func (r *authorRepository) Stats(ctx context.Context, id int32) (author.Stats, error) {
+	r.logger.Debug(fmt.Sprintf("aggregating books of author %d from postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("count(id)", "round(avg(rating), 2)", "min(year_published)", "max(year_published)").
+		From("book").
+		Where(sq.Eq{"author_id": id}).
+		ToSql()
+	if err != nil {
+		return author.Stats{}, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var stats author.Stats
+	err = r.db.QueryRowContext(ctx, query, values...).
+		Scan(&stats.BookCount, &stats.AverageRating, &stats.FirstYearPublished, &stats.LastYearPublished)
+	if err != nil {
+		return author.Stats{}, fmt.Errorf("unable to aggregate books of author: %w", err)
+	}
+
+	return stats, nil
+}
+
+// TopBooks selects up to limit of the Books written by the Author with the provided ID, from the best to the
+// worst rated. If the query fails or encounters an error while cursing through the result set, then an error
+// is returned.
+func (r *authorRepository) TopBooks(ctx context.Context, id int32, limit uint64) ([]entity.Book, error) {
+	r.logger.Debug(fmt.Sprintf("listing top books of author %d from postgres repository", id))
+
+	query, values, err := dialect.SelectBooks().
+		Where(sq.Eq{"book.author_id": id}).
+		OrderBy("rating DESC", "book.id").
+		Limit(limit).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get top books of author: %w", err)
+	}
+	defer rows.Close()
+
+	var books []entity.Book
+
+	// Iterate over result-set, map to entity.Book, and place in resulting slice.
+	for rows.Next() {
+		var b entity.Book
+		if err = rows.Scan(booksql.BookDest(&b)...); err != nil {
+			return nil, fmt.Errorf("unable to scan data into book: %w", err)
+		}
+		books = append(books, b)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// Create inserts an Author with the attributes of the author.WriteInput, which must all be set. The ID assigned
+// to the Author is returned. If the query fails, then an error is returned.
+func (r *authorRepository) Create(ctx context.Context, params author.WriteInput) (int32, error) {
+	r.logger.Debug("creating author in postgres repository")
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("author").
+		Columns("first_name", "last_name").
+		Values(*params.FirstName, *params.LastName).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var id int32
+	if err := r.db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to create author: %w", err)
+	}
+
+	r.logger.Debug(fmt.Sprintf("created author %d in postgres repository", id))
+	return id, nil
+}
+
+// Update sets the attributes of the Author with the provided ID to those of the author.WriteInput, which must
+// all be set. If no such Author exists, then an error wrapping entity.ErrNotFound is returned. If the query
+// fails, then an error is returned.
+func (r *authorRepository) Update(ctx context.Context, id int32, params author.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating author %d in postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("author").
+		Set("first_name", *params.FirstName).
+		Set("last_name", *params.LastName).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to update author: %w", err)
+	}
+
+	return affectedOne(res, "author", id)
+}
+
+// Delete deletes the Author with the provided ID. If reassignTo is not nil, then the Author's Books are first
+// reassigned to the Author with that ID, in the same transaction. If no such Author exists, then an error
+// wrapping entity.ErrNotFound is returned. If Books still reference the Author, then an error wrapping
+// entity.ErrConflict is returned.
+func (r *authorRepository) Delete(ctx context.Context, id int32, reassignTo *int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting author %d from postgres repository", id))
+	return deleteReferenced(ctx, r.db, "author", "author_id", id, reassignTo)
+}
@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -99,3 +102,326 @@ func TestAuthorPostgresRepo_GetAuthors(t *testing.T) {
 	}
 
 }
+
+func TestAuthorPostgresRepo_Get(t *testing.T) {
+
+	var query = "SELECT id, first_name, last_name FROM author WHERE id = $1"
+
+	tests := map[string]struct {
+		expect               entity.Author
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+		errIs                error
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"author does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}))
+			},
+		},
+		"successful get author": {
+			expect:       entity.Author{ID: 42, FirstName: "john", LastName: "doe"},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"id", "first_name", "last_name"}).
+					AddRow(42, "john", "doe")
+				return query.WillReturnRows(rows)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &authorRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(42))
+
+			actual, err := repo.Get(context.Background(), 42)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestAuthorPostgresRepo_Stats(t *testing.T) {
+
+	var query = "SELECT count(id), round(avg(rating), 2), min(year_published), max(year_published) FROM book WHERE author_id = $1"
+
+	tests := map[string]struct {
+		expect               author.Stats
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"author without books": {
+			expect:       author.Stats{},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"count", "round", "min", "max"}).
+					AddRow(0, nil, nil, nil)
+				return query.WillReturnRows(rows)
+			},
+		},
+		"successful aggregate books": {
+			expect: author.Stats{
+				BookCount:          3,
+				AverageRating:      util.Float64Ptr(3.87),
+				FirstYearPublished: util.Int16Ptr(1937),
+				LastYearPublished:  util.Int16Ptr(1977),
+			},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"count", "round", "min", "max"}).
+					AddRow(3, "3.87", 1937, 1977)
+				return query.WillReturnRows(rows)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &authorRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(42))
+
+			actual, err := repo.Stats(context.Background(), 42)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestAuthorPostgresRepo_TopBooks(t *testing.T) {
+
+	var query = "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, last_name, genre.id, genre.title " +
+		"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
+		"WHERE book.author_id = $1 ORDER BY rating DESC, book.id LIMIT 5"
+
+	columns := []string{"id", "title", "year_published", "rating", "pages", "id", "first_name", "last_name", "id", "title"}
+
+	tests := map[string]struct {
+		expect               []entity.Book
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"successful get top books": {
+			expect: []entity.Book{{
+				ID:            1,
+				Title:         "The Hobbit",
+				YearPublished: 1937,
+				Rating:        4.3,
+				Pages:         310,
+				Genre:         entity.Genre{ID: 2, Title: "SciFi/Fantasy"},
+				Author:        entity.Author{ID: 42, FirstName: "john", LastName: "doe"},
+			}},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows(columns).
+					AddRow(1, "The Hobbit", 1937, 4.3, 310, 42, "john", "doe", 2, "SciFi/Fantasy")
+				return query.WillReturnRows(rows)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &authorRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(42))
+
+			actual, err := repo.TopBooks(context.Background(), 42, 5)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestAuthorPostgresRepo_Create(t *testing.T) {
+
+	var query = "INSERT INTO author (first_name,last_name) VALUES ($1,$2) RETURNING id"
+
+	tests := map[string]struct {
+		expect               int32
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"successful create author": {
+			expect:       37,
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(37))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &authorRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Ursula", "Le Guin"))
+
+			actual, err := repo.Create(context.Background(),
+				author.WriteInput{FirstName: util.StringPtr("Ursula"), LastName: util.StringPtr("Le Guin")})
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestAuthorPostgresRepo_Update(t *testing.T) {
+
+	var query = "UPDATE author SET first_name = $1, last_name = $2 WHERE id = $3"
+
+	tests := map[string]struct {
+		setExecExpectations func(*sqlmock.ExpectedExec) *sqlmock.ExpectedExec
+		errAssertion        assert.ErrorAssertionFunc
+		errIs               error
+	}{
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"author does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		"successful update author": {
+			errAssertion: assert.NoError,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &authorRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExecExpectations(mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("C. S.", "Lewis", int32(2)))
+
+			err := repo.Update(context.Background(), 2,
+				author.WriteInput{FirstName: util.StringPtr("C. S."), LastName: util.StringPtr("Lewis")})
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestAuthorPostgresRepo_Delete(t *testing.T) {
+
+	var (
+		reassign = "UPDATE book SET author_id = $1 WHERE author_id = $2"
+		query    = "DELETE FROM author WHERE id = $1"
+	)
+
+	tests := map[string]struct {
+		reassignTo      *int32
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"author does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"author still referenced by books": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(1)).
+					WillReturnError(&pq.Error{Code: foreignKeyViolation})
+				mock.ExpectRollback()
+			},
+		},
+		"reassignment returns error": {
+			reassignTo:   util.Int32Ptr(2),
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(reassign)).WithArgs(int32(2), int32(1)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"successful delete author": {
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		"successful delete author with reassignment": {
+			reassignTo:   util.Int32Ptr(2),
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(reassign)).WithArgs(int32(2), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &authorRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			err := repo.Delete(context.Background(), 1, tt.reassignTo)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"strings"
+	"strconv"
 
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/booksql"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -34,110 +36,173 @@ func NewBookRepository(db *sql.DB, logger *zap.Logger) (*bookRepository, error)
 // cursing through the result set, then an error is returned.
 func (r *bookRepository) Search(ctx context.Context, params book.SearchInput) ([]entity.Book, error) {
 	r.logger.Debug("searching books from postgres repository")
+	return booksql.NewSearcher(r.db, dialect, r.logger).Search(ctx, params)
+}
 
-	/*
-	 * Start building SQL query
-	 */
-	builder := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
-		Select("book.id", "book.title", "year_published", "rating",
-			"pages", "author.id", "first_name", "last_name", "genre.id", "genre.title").
-		From("book").
-		LeftJoin("author ON book.author_id = author.id").
-		LeftJoin("genre ON book.genre_id = genre.id").
-		OrderBy("rating DESC")
+// Get selects the Book with the provided ID, along with its Author and Genre. If no such Book exists,
+// then an error wrapping entity.ErrNotFound is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Get(ctx context.Context, id int32) (entity.Book, error) {
+	r.logger.Debug(fmt.Sprintf("getting book %d from postgres repository", id))
 
-	builder = whereInt16In(builder, "author_id", params.AuthorIDs)
-	builder = whereInt16In(builder, "genre_id", params.GenreIDs)
+	query, values, err := dialect.SelectBooks().Where(sq.Eq{"book.id": id}).ToSql()
+	if err != nil {
+		return entity.Book{}, fmt.Errorf("unable to build SQL query: %w", err)
+	}
 
-	if params.Title != nil {
-		builder = builder.PlaceholderFormat(sq.Dollar).Where(sq.Eq{"book.title": *params.Title})
+	var b entity.Book
+	err = r.db.QueryRowContext(ctx, query, values...).Scan(booksql.BookDest(&b)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Book{}, fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	if err != nil {
+		return entity.Book{}, fmt.Errorf("unable to get book: %w", err)
 	}
 
-	builder = whereInt16Between(builder, "pages", params.MinPages, params.MaxPages)
-	builder = whereInt16Between(builder, "year_published", params.MinYearPublished, params.MaxYearPublished)
+	return b, nil
+}
+
+// Create inserts a Book with the attributes of the book.WriteInput, all of which must be set. The database
+// assigns the Book's ID, which is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Create(ctx context.Context, params book.WriteInput) (int32, error) {
+	r.logger.Debug("creating book in postgres repository")
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("book").
+		Columns("title", "year_published", "rating", "pages", "author_id", "genre_id").
+		Values(*params.Title, *params.YearPublished, *params.Rating, *params.Pages, *params.AuthorID, *params.GenreID).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to build SQL query: %w", err)
+	}
 
-	if params.Limit != nil {
-		builder = builder.Limit(*params.Limit)
+	var id int32
+	if err := r.db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to create book: %w", err)
 	}
 
-	query, values, err := builder.ToSql()
+	r.logger.Debug(fmt.Sprintf("created book %d in postgres repository", id))
+	return id, nil
+}
+
+// Update sets the attributes of the Book with the provided ID which are set in the book.WriteInput. If no such
+// Book exists, then an error wrapping entity.ErrNotFound is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Update(ctx context.Context, id int32, params book.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating book %d in postgres repository", id))
+
+	set := map[string]interface{}{}
+	if params.Title != nil {
+		set["title"] = *params.Title
+	}
+	if params.YearPublished != nil {
+		set["year_published"] = *params.YearPublished
+	}
+	if params.Rating != nil {
+		set["rating"] = *params.Rating
+	}
+	if params.Pages != nil {
+		set["pages"] = *params.Pages
+	}
+	if params.AuthorID != nil {
+		set["author_id"] = *params.AuthorID
+	}
+	if params.GenreID != nil {
+		set["genre_id"] = *params.GenreID
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("book").
+		SetMap(set).
+		Where(sq.Eq{"id": id}).
+		ToSql()
 	if err != nil {
-		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+		return fmt.Errorf("unable to build SQL query: %w", err)
 	}
-	r.logger.Debug(fmt.Sprintf("search book query: %s\n search book values: %v\n", query, values))
-	/*
-	 * Finish building SQL query
-	 */
 
-	rows, err := r.db.QueryContext(ctx, query, values...)
+	res, err := r.db.ExecContext(ctx, query, values...)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get books: %w", err)
+		return fmt.Errorf("unable to update book: %w", err)
 	}
-	defer rows.Close()
 
-	var books []entity.Book
+	return affectedOne(res, "book", id)
+}
 
-	// Iterate over result-set, map to entity.Book, and place in resulting slice.
-	for rows.Next() {
-		var b entity.Book
-		if err = rows.Scan(&b.ID,
-			&b.Title,
-			&b.YearPublished,
-			&b.Rating,
-			&b.Pages,
-			&b.Author.ID,
-			&b.Author.FirstName,
-			&b.Author.LastName,
-			&b.Genre.ID,
-			&b.Genre.Title); err != nil {
-			return nil, fmt.Errorf("unable to scan data into b: %w", err)
-		}
-		books = append(books, b)
+// Delete deletes the Book with the provided ID. If no such Book exists, then an error wrapping entity.ErrNotFound
+// is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Delete(ctx context.Context, id int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting book %d from postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("book").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
 	}
-	if err = rows.Err(); err != nil {
-		return nil, err
+
+	res, err := r.db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to delete book: %w", err)
 	}
 
-	r.logger.Debug(fmt.Sprintf("found %d books in postgres repository", len(books)))
+	return affectedOne(res, "book", id)
+}
 
-	return books, nil
+// Facets counts the Books in the repository which match the search parameters of each facet in the
+// book.FacetInput. Each facet is counted with a single aggregate query, which includes every bucket of the
+// facet, even if no Books fall into it. If a query fails or encounters an error while cursing through a
+// result set, then an error is returned.
+func (r *bookRepository) Facets(ctx context.Context, params book.FacetInput) (book.Facets, error) {
+	r.logger.Debug("counting book facets from postgres repository")
+	return booksql.NewSearcher(r.db, dialect, r.logger).Facets(ctx, params)
 }
 
-// whereInt16In accepts a query builder, a target column, and a slice of int16s.
-// If the slice is non-empty, then it a SQL WHERE clause section will be added for
-// records with col values IN the provided ints slice. The mutated builder is returned.
-func whereInt16In(builder sq.SelectBuilder, col string, ints []int16) sq.SelectBuilder {
-	if len(ints) > 0 {
-		var values = make([]interface{}, len(ints))
-		var placeholders = make([]interface{}, len(ints))
-		for i := range values {
-			values[i] = ints[i]
-			placeholders[i] = "?"
-		}
-		return builder.
-			PlaceholderFormat(sq.Dollar).
-			Where(
-				fmt.Sprintf("%s IN (%s)", col, strings.Trim(strings.Join(strings.Fields(fmt.Sprint(placeholders)), ","), "[]")),
-				values...,
-			)
-	}
-
-	return builder
+// Export calls each with every Book in the repository which Search would select, as it is scanned. The Books
+// are selected in a repeatable-read transaction, so that they are a consistent snapshot of the repository. If the query
+// fails, or each returns an error, then the export stops and an error is returned.
+func (r *bookRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) error {
+	r.logger.Debug("exporting books from postgres repository")
+	return booksql.Export(ctx, r.db, dialect, r.logger, params, each)
 }
 
-// whereInt16Between accepts a query builder, a target column, and pointers to int16
-// for the min and max values of the range. Because the builder dependency does not support
-// Postgres' BETWEEN semantics, this is done as two WHERE clause filters. Its possible
-// to let min or max be nil. In such a scenario, the query would only provide a lower
-// or upper bound.
-func whereInt16Between(builder sq.SelectBuilder, col string, min *int16, max *int16) sq.SelectBuilder {
-	if min != nil {
-		builder = builder.PlaceholderFormat(sq.Dollar).Where(sq.GtOrEq{col: *min})
-	}
+// dialect describes the SQL of Postgres to booksql, so that Books are searched with full-text search and
+// trigram similarity.
+var dialect = booksql.Dialect{
+	Name:        "postgres",
+	Placeholder: sq.Dollar,
 
-	if max != nil {
-		builder = builder.PlaceholderFormat(sq.Dollar).Where(sq.LtOrEq{col: *max})
-	}
+	// An expression index on book.title backs the same expression.
+	TitleSortKey: titleSortKey,
+
+	SearchMatch:     searchMatch,
+	SearchRelevance: searchRelevance,
 
-	return builder
+	// The rating and relevance are passed as fixed-point strings so that they compare exactly against the
+	// NUMERIC expressions.
+	Relevance: func(v float64) interface{} { return strconv.FormatFloat(v, 'f', 6, 64) },
+	Rating:    func(v float32) interface{} { return strconv.FormatFloat(float64(v), 'f', 2, 32) },
 }
+
+func init() {
+	// copyStaged refers to dialect, so it is assigned once dialect has been initialized.
+	dialect.StageImport = copyStaged
+}
+
+// titleSortKey is a SQL expression template that computes a library-style sort key for a title. The key
+// is lower-cased and has any leading article ("the", "a" or "an") removed.
+const titleSortKey = `regexp_replace(lower(%s), '^(the|an|a)\s+', '')`
+
+const (
+	// searchDocument is the text of a Book which book.SearchInput.Query is matched against: its title
+	// and the name of its author.
+	searchDocument = "concat_ws(' ', book.title, author.first_name, author.last_name)"
+
+	// searchMatch is a SQL predicate which includes Books that match the query, either through full-text
+	// search or, to tolerate typos, through trigram word similarity. Both placeholders take the query.
+	searchMatch = "(to_tsvector('english', " + searchDocument + ") @@ websearch_to_tsquery('english', ?) OR ? <% " + searchDocument + ")"
+
+	// searchRelevance is a SQL expression which ranks how well a Book matches the query. It combines the
+	// full-text rank with the trigram word similarity, rounded so that it can be compared exactly when
+	// resuming from a book.Cursor. Both placeholders take the query.
+	searchRelevance = "round((ts_rank(to_tsvector('english', " + searchDocument + "), websearch_to_tsquery('english', ?)) + word_similarity(?, " + searchDocument + "))::numeric, 6)"
+)
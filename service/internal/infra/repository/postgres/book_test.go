@@ -3,12 +3,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/LeviMatus/readcommend/service/internal/driver/book"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -58,6 +61,9 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 		johnID        int16  = 42
 		christopherID int16  = 43
 		limit         uint64 = 25
+		query                = "silmarilion"
+		relevance            = "round((ts_rank(to_tsvector('english', concat_ws(' ', book.title, author.first_name, author.last_name)), " +
+			"websearch_to_tsquery('english', $%d)) + word_similarity($%d, concat_ws(' ', book.title, author.first_name, author.last_name)))::numeric, 6)"
 
 		silmarillion = entity.Book{
 			ID:            1000,
@@ -70,6 +76,9 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 		}
 	)
 
+	relevantSilmarillion := silmarillion
+	relevantSilmarillion.Relevance = 0.5
+
 	tests := map[string]struct {
 		input                book.SearchInput
 		expectedQuery        string
@@ -94,7 +103,7 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 		"successful get authors with no parameters": {
 			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
 				"last_name, genre.id, genre.title FROM book LEFT JOIN author ON book.author_id = author.id " +
-				"LEFT JOIN genre ON book.genre_id = genre.id ORDER BY rating DESC",
+				"LEFT JOIN genre ON book.genre_id = genre.id ORDER BY rating DESC, book.id",
 			expect:       []entity.Book{silmarillion},
 			errAssertion: assert.NoError,
 			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
@@ -120,7 +129,7 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 				"last_name, genre.id, genre.title " +
 				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
 				"WHERE author_id IN ($1,$2) AND genre_id IN ($3,$4) AND book.title = $5 AND pages >= $6 " +
-				"AND pages <= $7 AND year_published >= $8 AND year_published <= $9 ORDER BY rating DESC LIMIT 25",
+				"AND pages <= $7 AND year_published >= $8 AND year_published <= $9 ORDER BY rating DESC, book.id LIMIT 25",
 			expect:       []entity.Book{silmarillion},
 			errAssertion: assert.NoError,
 			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
@@ -139,7 +148,7 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
 				"last_name, genre.id, genre.title " +
 				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
-				"WHERE pages >= $1 AND year_published >= $2 ORDER BY rating DESC",
+				"WHERE pages >= $1 AND year_published >= $2 ORDER BY rating DESC, book.id",
 			expect:       []entity.Book{silmarillion},
 			errAssertion: assert.NoError,
 			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
@@ -150,6 +159,103 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 				return query.WillReturnRows(rows)
 			},
 		},
+		"successful get books after cursor": {
+			input: book.SearchInput{
+				After: &book.Cursor{Sort: "-rating,id", Rating: 3.9, ID: 1000},
+				Limit: &limit,
+			},
+			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
+				"last_name, genre.id, genre.title " +
+				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
+				"WHERE ((rating < $1) OR (rating = $2 AND book.id > $3)) ORDER BY rating DESC, book.id LIMIT 25",
+			expect:       []entity.Book{silmarillion},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"book.id", "book.title", "year_published", "rating", "pages", "author.id", "first_name", "last_name", "genre.id", "genre.title"}).
+					AddRow(silmarillion.ID, silmarillion.Title, silmarillion.YearPublished, silmarillion.Rating,
+						silmarillion.Pages, silmarillion.Author.ID, silmarillion.Author.FirstName, silmarillion.Author.LastName,
+						silmarillion.Genre.ID, silmarillion.Genre.Title)
+				return query.WithArgs("3.90", "3.90", int32(1000)).WillReturnRows(rows)
+			},
+		},
+		"successful get books sorted by year and title after cursor": {
+			input: book.SearchInput{
+				Sort: book.Sort{
+					{Field: book.SortByYearPublished, Descending: true},
+					{Field: book.SortByTitle},
+				},
+				After: &book.Cursor{Sort: "-year_published,title,id", YearPublished: 1977, Title: title, ID: 1000},
+			},
+			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
+				"last_name, genre.id, genre.title " +
+				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
+				"WHERE ((year_published < $1) " +
+				"OR (year_published = $2 AND regexp_replace(lower(book.title), '^(the|an|a)\\s+', '') > regexp_replace(lower($3), '^(the|an|a)\\s+', '')) " +
+				"OR (year_published = $4 AND regexp_replace(lower(book.title), '^(the|an|a)\\s+', '') = regexp_replace(lower($5), '^(the|an|a)\\s+', '') AND book.id > $6)) " +
+				"ORDER BY year_published DESC, regexp_replace(lower(book.title), '^(the|an|a)\\s+', ''), book.id",
+			expect:       []entity.Book{silmarillion},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"book.id", "book.title", "year_published", "rating", "pages", "author.id", "first_name", "last_name", "genre.id", "genre.title"}).
+					AddRow(silmarillion.ID, silmarillion.Title, silmarillion.YearPublished, silmarillion.Rating,
+						silmarillion.Pages, silmarillion.Author.ID, silmarillion.Author.FirstName, silmarillion.Author.LastName,
+						silmarillion.Genre.ID, silmarillion.Genre.Title)
+				return query.WithArgs(int16(1977), int16(1977), title, int16(1977), title, int32(1000)).WillReturnRows(rows)
+			},
+		},
+		"successful full-text search sorted by relevance after cursor": {
+			input: book.SearchInput{
+				Query: &query,
+				Sort:  book.RelevanceSort,
+				After: &book.Cursor{Sort: "-relevance,-rating,id", Relevance: 0.75, Rating: 3.9, ID: 1000},
+			},
+			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
+				"last_name, genre.id, genre.title, (" + fmt.Sprintf(relevance, 1, 2) + ") AS relevance " +
+				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
+				"WHERE (to_tsvector('english', concat_ws(' ', book.title, author.first_name, author.last_name)) @@ websearch_to_tsquery('english', $3) " +
+				"OR $4 <% concat_ws(' ', book.title, author.first_name, author.last_name)) " +
+				"AND ((" + fmt.Sprintf(relevance, 5, 6) + " < $7) " +
+				"OR (" + fmt.Sprintf(relevance, 8, 9) + " = $10 AND rating < $11) " +
+				"OR (" + fmt.Sprintf(relevance, 12, 13) + " = $14 AND rating = $15 AND book.id > $16)) " +
+				"ORDER BY " + fmt.Sprintf(relevance, 17, 18) + " DESC, rating DESC, book.id",
+			expect:       []entity.Book{relevantSilmarillion},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(q *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"book.id", "book.title", "year_published", "rating", "pages", "author.id", "first_name", "last_name", "genre.id", "genre.title", "relevance"}).
+					AddRow(silmarillion.ID, silmarillion.Title, silmarillion.YearPublished, silmarillion.Rating,
+						silmarillion.Pages, silmarillion.Author.ID, silmarillion.Author.FirstName, silmarillion.Author.LastName,
+						silmarillion.Genre.ID, silmarillion.Genre.Title, "0.500000")
+				return q.WithArgs(query, query, query, query,
+					query, query, "0.750000",
+					query, query, "0.750000", "3.90",
+					query, query, "0.750000", "3.90", int32(1000),
+					query, query).WillReturnRows(rows)
+			},
+		},
+		"successful get books within era and size ranges": {
+			input: book.SearchInput{
+				MinPages: &minPages,
+				PageRanges: []book.Range{
+					{Min: util.Int16Ptr(85), Max: util.Int16Ptr(199)},
+					{Min: util.Int16Ptr(800)},
+				},
+				YearRanges: []book.Range{{Max: util.Int16Ptr(1969)}},
+			},
+			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
+				"last_name, genre.id, genre.title " +
+				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
+				"WHERE pages >= $1 AND ((pages >= $2 AND pages <= $3) OR (pages >= $4)) " +
+				"AND ((year_published <= $5)) ORDER BY rating DESC, book.id",
+			expect:       []entity.Book{silmarillion},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"book.id", "book.title", "year_published", "rating", "pages", "author.id", "first_name", "last_name", "genre.id", "genre.title"}).
+					AddRow(silmarillion.ID, silmarillion.Title, silmarillion.YearPublished, silmarillion.Rating,
+						silmarillion.Pages, silmarillion.Author.ID, silmarillion.Author.FirstName, silmarillion.Author.LastName,
+						silmarillion.Genre.ID, silmarillion.Genre.Title)
+				return query.WithArgs(minPages, int16(85), int16(199), int16(800), int16(1969)).WillReturnRows(rows)
+			},
+		},
 		"successful get authors with only upper bounds": {
 			input: book.SearchInput{
 				MaxYearPublished: &maxYear,
@@ -158,7 +264,7 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 			expectedQuery: "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
 				"last_name, genre.id, genre.title " +
 				"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id " +
-				"WHERE pages <= $1 AND year_published <= $2 ORDER BY rating DESC",
+				"WHERE pages <= $1 AND year_published <= $2 ORDER BY rating DESC, book.id",
 			expect:       []entity.Book{silmarillion},
 			errAssertion: assert.NoError,
 			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
@@ -184,3 +290,367 @@ func TestBookPostgresRepo_GetBooks(t *testing.T) {
 		})
 	}
 }
+
+func TestBookPostgresRepo_Export(t *testing.T) {
+
+	const query = "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, " +
+		"last_name, genre.id, genre.title FROM book LEFT JOIN author ON book.author_id = author.id " +
+		"LEFT JOIN genre ON book.genre_id = genre.id ORDER BY rating DESC, book.id"
+
+	columns := []string{"book.id", "book.title", "year_published", "rating", "pages", "author.id", "first_name", "last_name", "genre.id", "genre.title"}
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(1, "The Hobbit", 1937, 4.5, 310, 1, "John", "Tolkien", 2, "Fantasy").
+			AddRow(2, "The Silmarillion", 1977, 4.25, 365, 1, "John", "Tolkien", 2, "Fantasy")
+	}
+	stop := errors.New("unable to write book")
+
+	tests := map[string]struct {
+		each            func(entity.Book) error
+		setExpectations func(sqlmock.Sqlmock)
+		expect          []int32
+		errAssertion    assert.ErrorAssertionFunc
+	}{
+		"books are exported in a transaction": {
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows())
+				mock.ExpectCommit()
+			},
+			expect:       []int32{1, 2},
+			errAssertion: assert.NoError,
+		},
+		"error from each stops the export": {
+			each: func(entity.Book) error { return stop },
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(rows())
+				mock.ExpectRollback()
+			},
+			expect: []int32{1},
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, stop)
+			},
+		},
+		"query returns error": {
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			var actual []int32
+			err := repo.Export(context.Background(), book.SearchInput{}, func(b entity.Book) error {
+				actual = append(actual, b.ID)
+				if tt.each != nil {
+					return tt.each(b)
+				}
+				return nil
+			})
+			tt.errAssertion(t, err)
+			assert.Equal(t, tt.expect, actual)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBookPostgresRepo_Facets(t *testing.T) {
+	const matched = "SELECT book.id, book.genre_id, book.author_id, book.year_published, book.pages " +
+		"FROM book LEFT JOIN author ON book.author_id = author.id"
+
+	var (
+		genreQuery = "SELECT genre.id, count(matched.id) FROM genre LEFT JOIN (" + matched + " WHERE author_id IN ($1)) " +
+			"AS matched ON matched.genre_id = genre.id GROUP BY genre.id ORDER BY genre.id"
+		authorQuery = "SELECT author.id, count(matched.id) FROM author LEFT JOIN (" + matched + " WHERE genre_id IN ($1)) " +
+			"AS matched ON matched.author_id = author.id GROUP BY author.id ORDER BY author.id"
+		eraQuery = "SELECT era.id, count(matched.id) FROM era LEFT JOIN (" + matched + " WHERE author_id IN ($1) AND genre_id IN ($2)) " +
+			"AS matched ON (era.min_year IS NULL OR matched.year_published >= era.min_year) AND " +
+			"(era.max_year IS NULL OR matched.year_published <= era.max_year) GROUP BY era.id ORDER BY era.id"
+		sizeQuery = "SELECT size.id, count(matched.id) FROM size LEFT JOIN (" + matched + " WHERE author_id IN ($1) AND genre_id IN ($2)) " +
+			"AS matched ON (size.min_pages IS NULL OR matched.pages >= size.min_pages) AND " +
+			"(size.max_pages IS NULL OR matched.pages <= size.max_pages) GROUP BY size.id ORDER BY size.id"
+
+		input = book.FacetInput{
+			Genres:  book.SearchInput{AuthorIDs: []int16{42}},
+			Authors: book.SearchInput{GenreIDs: []int16{2}},
+			Eras:    book.SearchInput{AuthorIDs: []int16{42}, GenreIDs: []int16{2}},
+			Sizes:   book.SearchInput{AuthorIDs: []int16{42}, GenreIDs: []int16{2}},
+		}
+	)
+
+	countRows := func(counts ...book.FacetCount) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"id", "count"})
+		for _, c := range counts {
+			rows.AddRow(c.ID, c.Count)
+		}
+		return rows
+	}
+
+	t.Run("successful count facets", func(t *testing.T) {
+		db, mock := newMock(t)
+		repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+		mock.ExpectQuery(regexp.QuoteMeta(genreQuery)).WithArgs(int16(42)).
+			WillReturnRows(countRows(book.FacetCount{ID: 1, Count: 0}, book.FacetCount{ID: 2, Count: 3}))
+		mock.ExpectQuery(regexp.QuoteMeta(authorQuery)).WithArgs(int16(2)).
+			WillReturnRows(countRows(book.FacetCount{ID: 42, Count: 3}, book.FacetCount{ID: 43, Count: 1}))
+		mock.ExpectQuery(regexp.QuoteMeta(eraQuery)).WithArgs(int16(42), int16(2)).
+			WillReturnRows(countRows(book.FacetCount{ID: 0, Count: 3}, book.FacetCount{ID: 1, Count: 2}))
+		mock.ExpectQuery(regexp.QuoteMeta(sizeQuery)).WithArgs(int16(42), int16(2)).
+			WillReturnRows(countRows(book.FacetCount{ID: 0, Count: 3}))
+
+		actual, err := repo.Facets(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, book.Facets{
+			Genres:  []book.FacetCount{{ID: 1, Count: 0}, {ID: 2, Count: 3}},
+			Authors: []book.FacetCount{{ID: 42, Count: 3}, {ID: 43, Count: 1}},
+			Eras:    []book.FacetCount{{ID: 0, Count: 3}, {ID: 1, Count: 2}},
+			Sizes:   []book.FacetCount{{ID: 0, Count: 3}},
+		}, actual)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query returns error", func(t *testing.T) {
+		db, mock := newMock(t)
+		repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+		mock.ExpectQuery(regexp.QuoteMeta(genreQuery)).WithArgs(int16(42)).
+			WillReturnRows(countRows(book.FacetCount{ID: 1, Count: 0}))
+		mock.ExpectQuery(regexp.QuoteMeta(authorQuery)).WithArgs(int16(2)).
+			WillReturnError(errors.New("unable to perform query"))
+
+		actual, err := repo.Facets(context.Background(), input)
+		assert.Error(t, err)
+		assert.Equal(t, book.Facets{}, actual)
+	})
+}
+
+func TestBookPostgresRepo_Get(t *testing.T) {
+
+	var query = "SELECT book.id, book.title, year_published, rating, pages, author.id, first_name, last_name, genre.id, genre.title " +
+		"FROM book LEFT JOIN author ON book.author_id = author.id LEFT JOIN genre ON book.genre_id = genre.id WHERE book.id = $1"
+
+	columns := []string{"id", "title", "year_published", "rating", "pages", "id", "first_name", "last_name", "id", "title"}
+
+	tests := map[string]struct {
+		expect               entity.Book
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+		errIs                error
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"book does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnRows(sqlmock.NewRows(columns))
+			},
+		},
+		"successful get book": {
+			expect: entity.Book{
+				ID:            42,
+				Title:         "The Silmarillion",
+				YearPublished: 1977,
+				Rating:        3.9,
+				Pages:         365,
+				Genre:         entity.Genre{ID: 2, Title: "SciFi/Fantasy"},
+				Author:        entity.Author{ID: 7, FirstName: "John", LastName: "Tolkien"},
+			},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows(columns).
+					AddRow(42, "The Silmarillion", 1977, 3.9, 365, 7, "John", "Tolkien", 2, "SciFi/Fantasy")
+				return query.WillReturnRows(rows)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(42))
+
+			actual, err := repo.Get(context.Background(), 42)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestBookPostgresRepo_Create(t *testing.T) {
+
+	var query = "INSERT INTO book (title,year_published,rating,pages,author_id,genre_id) VALUES ($1,$2,$3,$4,$5,$6) RETURNING id"
+
+	input := book.WriteInput{
+		Title:         util.StringPtr("The Hobbit"),
+		YearPublished: util.Int16Ptr(1937),
+		Rating:        util.Float32Ptr(4.3),
+		Pages:         util.Int16Ptr(310),
+		AuthorID:      util.Int32Ptr(7),
+		GenreID:       util.Int32Ptr(2),
+	}
+
+	tests := map[string]struct {
+		expect               int32
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"successful create book": {
+			expect:       59,
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(59))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).
+				WithArgs("The Hobbit", int16(1937), float32(4.3), int16(310), int32(7), int32(2)))
+
+			actual, err := repo.Create(context.Background(), input)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestBookPostgresRepo_Update(t *testing.T) {
+
+	tests := map[string]struct {
+		input               book.WriteInput
+		expectedQuery       string
+		expectedArgs        []driver.Value
+		setExecExpectations func(*sqlmock.ExpectedExec) *sqlmock.ExpectedExec
+		errAssertion        assert.ErrorAssertionFunc
+		errIs               error
+	}{
+		"exec returns error": {
+			input:         book.WriteInput{Title: util.StringPtr("The Hobbit")},
+			expectedQuery: "UPDATE book SET title = $1 WHERE id = $2",
+			expectedArgs:  []driver.Value{"The Hobbit", int32(42)},
+			errAssertion:  assert.Error,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"book does not exist": {
+			input:         book.WriteInput{Title: util.StringPtr("The Hobbit")},
+			expectedQuery: "UPDATE book SET title = $1 WHERE id = $2",
+			expectedArgs:  []driver.Value{"The Hobbit", int32(42)},
+			errAssertion:  assert.Error,
+			errIs:         entity.ErrNotFound,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		"successful update every attribute": {
+			input: book.WriteInput{
+				Title:         util.StringPtr("The Hobbit"),
+				YearPublished: util.Int16Ptr(1937),
+				Rating:        util.Float32Ptr(4.3),
+				Pages:         util.Int16Ptr(310),
+				AuthorID:      util.Int32Ptr(7),
+				GenreID:       util.Int32Ptr(2),
+			},
+			expectedQuery: "UPDATE book SET author_id = $1, genre_id = $2, pages = $3, rating = $4, title = $5, year_published = $6 WHERE id = $7",
+			expectedArgs:  []driver.Value{int32(7), int32(2), int16(310), float32(4.3), "The Hobbit", int16(1937), int32(42)},
+			errAssertion:  assert.NoError,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExecExpectations(mock.ExpectExec(regexp.QuoteMeta(tt.expectedQuery)).WithArgs(tt.expectedArgs...))
+
+			err := repo.Update(context.Background(), 42, tt.input)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestBookPostgresRepo_Delete(t *testing.T) {
+
+	var query = "DELETE FROM book WHERE id = $1"
+
+	tests := map[string]struct {
+		setExecExpectations func(*sqlmock.ExpectedExec) *sqlmock.ExpectedExec
+		errAssertion        assert.ErrorAssertionFunc
+		errIs               error
+	}{
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"book does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		"successful delete book": {
+			errAssertion: assert.NoError,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &bookRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExecExpectations(mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(42)))
+
+			err := repo.Delete(context.Background(), 42)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/booksql"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type catalogRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewCatalogRepository accepts a pointer to a sql.DB type. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in a catalogRepository and a pointer to it is returned.
+func NewCatalogRepository(db *sql.DB, logger *zap.Logger) (*catalogRepository, error) {
+	if db == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &catalogRepository{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// Import writes the Book of every Record of the Source in a single transaction. The Records are streamed into
+// a temporary table with COPY, and then merged into the author, genre and book tables. If the Source reports
+// an error once it is exhausted, or any query fails, then nothing is written and an error is returned.
+func (r *catalogRepository) Import(ctx context.Context, records catalog.Source, upsert bool) (catalog.Summary, error) {
+	r.logger.Debug("importing catalog into postgres repository")
+
+	summary, err := dialect.Import(ctx, r.db, records, upsert)
+	if err != nil {
+		return summary, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("imported %d books into postgres repository", summary.Created+summary.Updated))
+	return summary, nil
+}
+
+// copyStaged is the StageImport of Postgres, which streams the Records into the booksql.ImportTable with COPY.
+func copyStaged(ctx context.Context, tx *sql.Tx, records catalog.Source) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(booksql.ImportTable, booksql.ImportColumns...))
+	if err != nil {
+		return fmt.Errorf("unable to start copying books: %w", err)
+	}
+
+	for records.Next() {
+		rec := records.Record()
+		if _, err := stmt.ExecContext(ctx, dialect.ImportValues(rec)...); err != nil {
+			_ = stmt.Close()
+			return fmt.Errorf("unable to copy book on line %d: %w", rec.Line, err)
+		}
+	}
+
+	// COPY is only finished, and its errors reported, once it is flushed by an Exec without values.
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		_ = stmt.Close()
+		return fmt.Errorf("unable to copy books: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("unable to copy books: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const copyQuery = `COPY "book_import" ("line", "title", "year_published", "rating", "pages", "author_first_name", "author_last_name", "genre") FROM STDIN`
+
+func TestCatalogRepository_Import(t *testing.T) {
+
+	const header = "title,author_first_name,author_last_name,genre,year_published,pages,rating\n"
+
+	tests := map[string]struct {
+		in              string
+		setExpectations func(sqlmock.Sqlmock)
+		expect          catalog.Summary
+		errAssertion    assert.ErrorAssertionFunc
+	}{
+		"records are copied and merged": {
+			in: header + "Dune,Frank,Herbert,Science Fiction,1965,412,4.5\n",
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TEMPORARY TABLE book_import").WillReturnResult(sqlmock.NewResult(0, 0))
+				copyIn := mock.ExpectPrepare(regexp.QuoteMeta(copyQuery))
+				copyIn.ExpectExec().WithArgs(2, "Dune", 1965, "4.50", 412, "Frank", "Herbert", "Science Fiction").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				copyIn.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO author").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO genre").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("UPDATE book_import SET author_id").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("UPDATE book_import SET genre_id").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO book").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("DROP TABLE book_import").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expect:       catalog.Summary{Records: 1, Created: 1, AuthorsCreated: 1},
+			errAssertion: assert.NoError,
+		},
+		"invalid records roll back": {
+			in: header + "Dune,Frank,Herbert,,1965,412,4.5\n",
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("CREATE TEMPORARY TABLE book_import").WillReturnResult(sqlmock.NewResult(0, 0))
+				copyIn := mock.ExpectPrepare(regexp.QuoteMeta(copyQuery))
+				copyIn.ExpectExec().WithArgs().WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrInvalidEntity)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			r, err := NewCatalogRepository(db, zap.NewNop())
+			require.NoError(t, err)
+
+			tt.setExpectations(mock)
+
+			reader, err := catalog.NewReader(strings.NewReader(tt.in), catalog.FormatCSV)
+			require.NoError(t, err)
+
+			summary, err := catalog.NewDriver(r).Import(context.Background(), reader, catalog.Options{})
+			tt.errAssertion(t, err)
+			summary.Errors = nil
+			assert.Equal(t, tt.expect, summary)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -1,9 +1,143 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
 var (
 	ErrInvalidDependency = errors.New("expected a non-nil sql Database connection")
 )
+
+// execQueryer is satisfied by both *sql.DB and *sql.Tx, so that statements can run within a transaction or not.
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// foreignKeyViolation is the SQLSTATE raised when a row is deleted while other rows still reference it.
+const foreignKeyViolation = "23503"
+
+// The SQLSTATEs which TranslateError translates.
+const (
+	// queryCanceled is raised when a statement runs for longer than the statement_timeout.
+	queryCanceled = "57014"
+
+	// lockNotAvailable is raised when a statement waits for a lock for longer than the lock_timeout.
+	lockNotAvailable = "55P03"
+
+	// adminShutdown, crashShutdown and cannotConnectNow are raised while the server is shutting down or
+	// starting up.
+	adminShutdown    = "57P01"
+	crashShutdown    = "57P02"
+	cannotConnectNow = "57P03"
+
+	// connectionException and insufficientResources are the classes of the SQLSTATEs raised when the connection
+	// failed, and when the server ran out of connections, memory or disk.
+	connectionException   = "08"
+	insufficientResources = "53"
+)
+
+// lockTable locks the table for the rest of the transaction against other writers, which wait for it to end.
+// Readers are not blocked.
+func lockTable(ctx context.Context, tx *sql.Tx, table string) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("LOCK TABLE %s IN SHARE ROW EXCLUSIVE MODE", table)); err != nil {
+		return fmt.Errorf("unable to lock %s: %w", table, err)
+	}
+	return nil
+}
+
+// affectedOne checks that the sql.Result of a statement targeting the row of the table with the provided ID
+// affected it. If no row was affected, then the row does not exist and an error wrapping entity.ErrNotFound
+// is returned.
+func affectedOne(res sql.Result, table string, id int32) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to count affected rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s %d does not exist", entity.ErrNotFound, table, id)
+	}
+	return nil
+}
+
+// referenced reports whether the error was raised because the row being deleted is still referenced.
+func referenced(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation
+}
+
+// TranslateError is the dberror.Translator of Postgres. Statements which ran out of the statement or lock timeout
+// are timeouts, and a server which is shutting down, starting up, unreachable or out of resources is unavailable.
+func TranslateError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+	switch {
+	case pqErr.Code == queryCanceled, pqErr.Code == lockNotAvailable:
+		return &entity.Error{Kind: entity.ErrTimeout, Err: err}
+	case pqErr.Code == adminShutdown, pqErr.Code == crashShutdown, pqErr.Code == cannotConnectNow,
+		pqErr.Code.Class() == connectionException, pqErr.Code.Class() == insufficientResources:
+		return &entity.Error{Kind: entity.ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+// deleteReferenced deletes the row of the table with the provided ID, which Books reference by the column. If
+// reassignTo is not nil, then the Books are first reassigned to the row with that ID, in the same transaction.
+// If no such row exists, then an error wrapping entity.ErrNotFound is returned. If Books still reference the
+// row, then an error wrapping entity.ErrConflict is returned.
+func deleteReferenced(ctx context.Context, db *sql.DB, table, column string, id int32, reassignTo *int32) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if reassignTo != nil {
+		query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+			Update("book").
+			Set(column, *reassignTo).
+			Where(sq.Eq{column: id}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to build SQL query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+			return fmt.Errorf("unable to reassign books of %s: %w", table, err)
+		}
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete(table).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, values...)
+	if referenced(err) {
+		return fmt.Errorf("%w: %s %d is still referenced by books", entity.ErrConflict, table, id)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete %s: %w", table, err)
+	}
+	if err := affectedOne(res, table, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
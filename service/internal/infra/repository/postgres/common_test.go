@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateError(t *testing.T) {
+
+	tests := map[string]struct {
+		err  error
+		kind error
+	}{
+		"statement timeout": {
+			err:  &pq.Error{Code: queryCanceled},
+			kind: entity.ErrTimeout,
+		},
+		"lock timeout": {
+			err:  &pq.Error{Code: lockNotAvailable},
+			kind: entity.ErrTimeout,
+		},
+		"server is shutting down": {
+			err:  &pq.Error{Code: adminShutdown},
+			kind: entity.ErrUnavailable,
+		},
+		"connection failure": {
+			err:  &pq.Error{Code: "08006"},
+			kind: entity.ErrUnavailable,
+		},
+		"too many connections": {
+			err:  &pq.Error{Code: "53300"},
+			kind: entity.ErrUnavailable,
+		},
+		"foreign key violation": {
+			err: &pq.Error{Code: foreignKeyViolation},
+		},
+		"not a postgres error": {
+			err: errors.New("unable to build SQL query"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := TranslateError(errors.Wrap(tt.err, "unable to get book"))
+			assert.ErrorIs(t, err, tt.err)
+			if tt.kind == nil {
+				assert.Equal(t, tt.err, errors.Cause(err))
+				return
+			}
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	eradriver "github.com/LeviMatus/readcommend/service/internal/driver/era"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/pkg/errors"
@@ -111,3 +112,360 @@ func TestEraPostgresRepo_GetEras(t *testing.T) {
 		})
 	}
 }
+
+// expectErasListed expects the era table to be locked, and its Eras to be listed for a check.
+func expectErasListed(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("LOCK TABLE era IN SHARE ROW EXCLUSIVE MODE")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM era")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "min_year", "max_year"}).AddRow(0, "Any", nil, nil))
+}
+
+func TestEraPostgresRepo_Create(t *testing.T) {
+
+	var query = "INSERT INTO era (title,min_year,max_year) VALUES ($1,$2,$3) RETURNING id"
+
+	tests := map[string]struct {
+		expect          int32
+		check           func([]entity.Era) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"check rejects the era": {
+			check:        func([]entity.Era) error { return entity.ErrConflict },
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"query returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Contemporary", int16(2000), nil).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"successful create era": {
+			expect:       3,
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Contemporary", int16(2000), nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &eraRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			var checked []entity.Era
+			check := func(eras []entity.Era) error {
+				checked = eras
+				if tt.check != nil {
+					return tt.check(eras)
+				}
+				return nil
+			}
+
+			actual, err := repo.Create(context.Background(),
+				eradriver.WriteInput{Title: util.StringPtr("Contemporary"), MinYear: util.Int16Ptr(2000)}, check)
+			assert.Equal(t, tt.expect, actual)
+			assert.Equal(t, []entity.Era{{ID: 0, Title: "Any"}}, checked)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEraPostgresRepo_Update(t *testing.T) {
+
+	var query = "UPDATE era SET title = $1, min_year = $2, max_year = $3 WHERE id = $4"
+
+	tests := map[string]struct {
+		check           func([]entity.Era) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"check rejects the era": {
+			check:        func([]entity.Era) error { return entity.ErrConflict },
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Classic", nil, int16(1969), int32(1)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"era does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Classic", nil, int16(1969), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"successful update era": {
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Classic", nil, int16(1969), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &eraRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			check := func(eras []entity.Era) error {
+				if tt.check != nil {
+					return tt.check(eras)
+				}
+				return nil
+			}
+
+			err := repo.Update(context.Background(), 1,
+				eradriver.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1969)}, check)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEraPostgresRepo_Delete(t *testing.T) {
+
+	var query = "DELETE FROM era WHERE id = $1"
+
+	tests := map[string]struct {
+		check           func([]entity.Era) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"check rejects the era": {
+			check:        func([]entity.Era) error { return entity.ErrConflict },
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(2)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"era does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(2)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"successful delete era": {
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &eraRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			check := func(eras []entity.Era) error {
+				if tt.check != nil {
+					return tt.check(eras)
+				}
+				return nil
+			}
+
+			err := repo.Delete(context.Background(), 2, check)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestEraPostgresRepo_ReplaceAll(t *testing.T) {
+
+	var (
+		lockQuery   = "LOCK TABLE era IN SHARE ROW EXCLUSIVE MODE"
+		deleteQuery = "DELETE FROM era WHERE id NOT IN ($1,$2)"
+		updateQuery = "UPDATE era SET title = $1, min_year = $2, max_year = $3 WHERE id = $4"
+		insertQuery = "INSERT INTO era (title,min_year,max_year) VALUES ($1,$2,$3) RETURNING id"
+	)
+
+	input := []eradriver.BulkInput{
+		{ID: util.Int32Ptr(0), WriteInput: eradriver.WriteInput{Title: util.StringPtr("Any")}},
+		{ID: util.Int32Ptr(1), WriteInput: eradriver.WriteInput{Title: util.StringPtr("Classic"), MaxYear: util.Int16Ptr(1959)}},
+		{WriteInput: eradriver.WriteInput{Title: util.StringPtr("Modern"), MinYear: util.Int16Ptr(1960)}},
+	}
+
+	listed := []entity.Era{{ID: 0, Title: "Any"}}
+
+	tests := map[string]struct {
+		expect          []entity.Era
+		expectChecked   []entity.Era
+		check           func([]entity.Era) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"lock returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(lockQuery)).WillReturnError(errors.New("unable to lock"))
+				mock.ExpectRollback()
+			},
+		},
+		"check rejects the eras": {
+			check:         func([]entity.Era) error { return entity.ErrConflict },
+			errAssertion:  assert.Error,
+			errIs:         entity.ErrConflict,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"delete returns error": {
+			errAssertion:  assert.Error,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(int32(0), int32(1)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"era to update does not exist": {
+			errAssertion:  assert.Error,
+			errIs:         entity.ErrNotFound,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(int32(0), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("Any", nil, nil, int32(0)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"successful replace all eras": {
+			expect: []entity.Era{
+				{ID: 0, Title: "Any"},
+				{ID: 1, Title: "Classic", MaxYear: util.Int16Ptr(1959)},
+				{ID: 3, Title: "Modern", MinYear: util.Int16Ptr(1960)},
+			},
+			errAssertion:  assert.NoError,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectErasListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(int32(0), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("Any", nil, nil, int32(0)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("Classic", nil, int16(1959), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WithArgs("Modern", int16(1960), nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &eraRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			var checked []entity.Era
+			check := func(eras []entity.Era) error {
+				checked = eras
+				if tt.check != nil {
+					return tt.check(eras)
+				}
+				return nil
+			}
+
+			actual, err := repo.ReplaceAll(context.Background(), input, check)
+			assert.Equal(t, tt.expect, actual)
+			assert.Equal(t, tt.expectChecked, checked)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -64,3 +66,110 @@ func (r *genreRepository) List(ctx context.Context) ([]entity.Genre, error) {
 	r.logger.Debug(fmt.Sprintf("found %d genres in postgres repository", len(genres)))
 	return genres, nil
 }
+
+// Get selects the Genre with the provided ID. If no such Genre exists, then an error wrapping
+// entity.ErrNotFound is returned. If the query fails, then an error is returned.
+func (r *genreRepository) Get(ctx context.Context, id int32) (entity.Genre, error) {
+	r.logger.Debug(fmt.Sprintf("getting genre %d from postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("id", "title").
+		From("genre").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return entity.Genre{}, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var g entity.Genre
+	err = r.db.QueryRowContext(ctx, query, values...).Scan(&g.ID, &g.Title)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Genre{}, fmt.Errorf("%w: genre %d does not exist", entity.ErrNotFound, id)
+	}
+	if err != nil {
+		return entity.Genre{}, fmt.Errorf("unable to get genre: %w", err)
+	}
+
+	return g, nil
+}
+
+// Stats aggregates the Books of the Genre with the provided ID. The average rating is rounded to two
+// decimal places, like the ratings themselves. If the query fails, then an error is returned.
+func (r *genreRepository) Stats(ctx context.Context, id int32) (genre.Stats, error) {
+	r.logger.Debug(fmt.Sprintf("aggregating books of genre %d from postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Select("count(id)", "count(DISTINCT author_id)", "round(avg(rating), 2)",
+			"min(year_published)", "max(year_published)").
+		From("book").
+		Where(sq.Eq{"genre_id": id}).
+		ToSql()
+	if err != nil {
+		return genre.Stats{}, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var stats genre.Stats
+	err = r.db.QueryRowContext(ctx, query, values...).Scan(&stats.BookCount, &stats.AuthorCount,
+		&stats.AverageRating, &stats.FirstYearPublished, &stats.LastYearPublished)
+	if err != nil {
+		return genre.Stats{}, fmt.Errorf("unable to aggregate books of genre: %w", err)
+	}
+
+	return stats, nil
+}
+
+// Create inserts a Genre with the attributes of the genre.WriteInput, which must all be set. The ID assigned
+// to the Genre is returned. If the query fails, then an error is returned.
+func (r *genreRepository) Create(ctx context.Context, params genre.WriteInput) (int32, error) {
+	r.logger.Debug("creating genre in postgres repository")
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("genre").
+		Columns("title").
+		Values(*params.Title).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var id int32
+	if err := r.db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to create genre: %w", err)
+	}
+
+	r.logger.Debug(fmt.Sprintf("created genre %d in postgres repository", id))
+	return id, nil
+}
+
+// Update sets the attributes of the Genre with the provided ID to those of the genre.WriteInput, which must
+// all be set. If no such Genre exists, then an error wrapping entity.ErrNotFound is returned. If the query
+// fails, then an error is returned.
+func (r *genreRepository) Update(ctx context.Context, id int32, params genre.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating genre %d in postgres repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("genre").
+		Set("title", *params.Title).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to update genre: %w", err)
+	}
+
+	return affectedOne(res, "genre", id)
+}
+
+// Delete deletes the Genre with the provided ID. If reassignTo is not nil, then the Genre's Books are first
+// reassigned to the Genre with that ID, in the same transaction. If no such Genre exists, then an error
+// wrapping entity.ErrNotFound is returned. If Books still reference the Genre, then an error wrapping
+// entity.ErrConflict is returned.
+func (r *genreRepository) Delete(ctx context.Context, id int32, reassignTo *int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting genre %d from postgres repository", id))
+	return deleteReferenced(ctx, r.db, "genre", "genre_id", id, reassignTo)
+}
@@ -7,7 +7,10 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -97,3 +100,247 @@ func TestGenreRepository_GetGenres(t *testing.T) {
 		})
 	}
 }
+
+func TestGenreRepository_Get(t *testing.T) {
+
+	var query = "SELECT id, title FROM genre WHERE id = $1"
+
+	tests := map[string]struct {
+		expect               entity.Genre
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+		errIs                error
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"genre does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnRows(sqlmock.NewRows([]string{"id", "title"}))
+			},
+		},
+		"successful get genre": {
+			expect:       entity.Genre{ID: 42, Title: "SciFi/Fantasy"},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"id", "title"}).
+					AddRow(42, "SciFi/Fantasy")
+				return query.WillReturnRows(rows)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &genreRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(42))
+
+			actual, err := repo.Get(context.Background(), 42)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestGenreRepository_Stats(t *testing.T) {
+
+	var query = "SELECT count(id), count(DISTINCT author_id), round(avg(rating), 2), " +
+		"min(year_published), max(year_published) FROM book WHERE genre_id = $1"
+
+	tests := map[string]struct {
+		expect               genre.Stats
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"genre without books": {
+			expect:       genre.Stats{},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"count", "count", "round", "min", "max"}).
+					AddRow(0, 0, nil, nil, nil)
+				return query.WillReturnRows(rows)
+			},
+		},
+		"successful aggregate books": {
+			expect: genre.Stats{
+				BookCount:          12,
+				AuthorCount:        5,
+				AverageRating:      util.Float64Ptr(3.2),
+				FirstYearPublished: util.Int16Ptr(1851),
+				LastYearPublished:  util.Int16Ptr(2004),
+			},
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				rows := sqlmock.NewRows([]string{"count", "count", "round", "min", "max"}).
+					AddRow(12, 5, "3.20", 1851, 2004)
+				return query.WillReturnRows(rows)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &genreRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(42))
+
+			actual, err := repo.Stats(context.Background(), 42)
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestGenreRepository_Create(t *testing.T) {
+
+	var query = "INSERT INTO genre (title) VALUES ($1) RETURNING id"
+
+	tests := map[string]struct {
+		expect               int32
+		setQueryExpectations func(*sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery
+		errAssertion         assert.ErrorAssertionFunc
+	}{
+		"query returns error": {
+			errAssertion: assert.Error,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"successful create genre": {
+			expect:       8,
+			errAssertion: assert.NoError,
+			setQueryExpectations: func(query *sqlmock.ExpectedQuery) *sqlmock.ExpectedQuery {
+				return query.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &genreRepository{db: db, logger: zap.NewNop()}
+
+			tt.setQueryExpectations(mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Poetry"))
+
+			actual, err := repo.Create(context.Background(), genre.WriteInput{Title: util.StringPtr("Poetry")})
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+		})
+	}
+}
+
+func TestGenreRepository_Update(t *testing.T) {
+
+	var query = "UPDATE genre SET title = $1 WHERE id = $2"
+
+	tests := map[string]struct {
+		setExecExpectations func(*sqlmock.ExpectedExec) *sqlmock.ExpectedExec
+		errAssertion        assert.ErrorAssertionFunc
+		errIs               error
+	}{
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"genre does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+		},
+		"successful update genre": {
+			errAssertion: assert.NoError,
+			setExecExpectations: func(exec *sqlmock.ExpectedExec) *sqlmock.ExpectedExec {
+				return exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &genreRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExecExpectations(mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Poetry", int32(2)))
+
+			err := repo.Update(context.Background(), 2, genre.WriteInput{Title: util.StringPtr("Poetry")})
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+		})
+	}
+}
+
+func TestGenreRepository_Delete(t *testing.T) {
+
+	var (
+		reassign = "UPDATE book SET genre_id = $1 WHERE genre_id = $2"
+		query    = "DELETE FROM genre WHERE id = $1"
+	)
+
+	tests := map[string]struct {
+		reassignTo      *int32
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"genre still referenced by books": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(1)).
+					WillReturnError(&pq.Error{Code: foreignKeyViolation})
+				mock.ExpectRollback()
+			},
+		},
+		"successful delete genre with reassignment": {
+			reassignTo:   util.Int32Ptr(2),
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(reassign)).WithArgs(int32(2), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &genreRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			err := repo.Delete(context.Background(), 1, tt.reassignTo)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
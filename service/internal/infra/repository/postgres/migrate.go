@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/migration"
+	"go.uber.org/zap"
+)
+
+// migrationLock is the key of the advisory lock which is held while migrating, so that concurrent migrations,
+// such as those of simultaneous deploys, are applied one after the other.
+const migrationLock int64 = 0x72656164636d6d64
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed seed.sql
+var seed string
+
+// migrationDialect keeps track of applied migrations in the schema_migration table, while holding an advisory lock.
+var migrationDialect = migration.Dialect{
+	Lock:     "SELECT pg_advisory_lock($1)",
+	Unlock:   "SELECT pg_advisory_unlock($1)",
+	LockArgs: []interface{}{migrationLock},
+	CreateTable: `CREATE TABLE IF NOT EXISTS ` + migration.VersionTable + ` (
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`,
+	TableExists: "SELECT to_regclass($1) IS NOT NULL",
+	Record:      "INSERT INTO " + migration.VersionTable + " (version, name) VALUES ($1, $2)",
+	Forget:      "DELETE FROM " + migration.VersionTable + " WHERE version = $1",
+}
+
+// SeedSQL returns the SQL which seeds the database with sample genres, authors and books.
+func SeedSQL() string {
+	return seed
+}
+
+// Migrations returns every migration.Migration embedded in the binary, in ascending order of Version.
+func Migrations() ([]migration.Migration, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migration.Read(files)
+}
+
+// SchemaVersion is the Version of the latest Migration embedded in the binary, which is the version of the
+// schema that the repositories expect.
+func SchemaVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	return migration.Latest(migrations), nil
+}
+
+// NewMigrator creates a migration.Migrator which applies the Migrations embedded in the binary to the database.
+// The database connection cannot be nil.
+func NewMigrator(db *sql.DB, logger *zap.Logger) (*migration.Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return migration.New(db, logger, migrationDialect, migrations, seed)
+}
@@ -0,0 +1,203 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/migration"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testDatabaseEnv names the environment variable holding the key/value DSN of a Postgres database which tests may
+// create schemas in. Tests which need a real database are skipped without it.
+const testDatabaseEnv = "READCOMMEND_TEST_POSTGRES"
+
+// legacySchema is the schema which the former migrate.sql created, whose ids are not generated by the database.
+const legacySchema = `
+CREATE TABLE era (id INTEGER NOT NULL PRIMARY KEY, title TEXT NOT NULL, min_year SMALLINT, max_year SMALLINT);
+CREATE TABLE size (id INTEGER NOT NULL PRIMARY KEY, title TEXT NOT NULL, min_pages SMALLINT, max_pages SMALLINT);
+CREATE TABLE genre (id INTEGER NOT NULL PRIMARY KEY, title TEXT NOT NULL);
+CREATE TABLE author (id INTEGER NOT NULL PRIMARY KEY, first_name TEXT NOT NULL, last_name TEXT NOT NULL);
+CREATE TABLE book
+(
+  id INTEGER NOT NULL PRIMARY KEY,
+  title TEXT NOT NULL,
+  year_published SMALLINT NOT NULL,
+  rating NUMERIC(3, 2) NOT NULL,
+  pages SMALLINT NOT NULL,
+  genre_id INTEGER REFERENCES genre(id),
+  author_id INTEGER REFERENCES author(id)
+);
+
+INSERT INTO era (id, title, min_year, max_year) VALUES (0, 'Any', NULL, NULL), (1, 'Classic', NULL, 1969),
+  (2, 'Modern', 1970, NULL);
+INSERT INTO size (id, title, min_pages, max_pages) VALUES (0, 'Any', NULL, NULL), (1, 'Short', NULL, 299),
+  (2, 'Long', 300, NULL);
+INSERT INTO genre (id, title) VALUES (1, 'Fiction'), (8, 'Childrens');
+INSERT INTO author (id, first_name, last_name) VALUES (1, 'Wendell', 'Stackhouse'), (5, 'Arturo', 'Hijuelos');
+INSERT INTO book (id, title, year_published, rating, pages, genre_id, author_id)
+VALUES (1, 'Iliad', 1900, 4.5, 300, 1, 1), (12, 'Odyssey', 1901, 4.2, 320, 8, 5);
+`
+
+// newTestSchema creates a schema in the database named by testDatabaseEnv, and returns a connection whose
+// statements are run in it. The schema is dropped once the test finishes. The test is skipped if no database
+// is given.
+func newTestSchema(t *testing.T) *sql.DB {
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	_, err = admin.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Logf("unable to drop schema %s: %v", schema, err)
+		}
+	})
+
+	db, err := sql.Open("postgres", fmt.Sprintf("%s search_path=%s", dsn, schema))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+const (
+	existsQuery  = "SELECT to_regclass($1) IS NOT NULL"
+	appliedQuery = "SELECT version, applied_at FROM schema_migration"
+)
+
+func TestMigrations(t *testing.T) {
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions should be consecutive")
+		assert.NotEmpty(t, m.Up)
+		assert.NotEmpty(t, m.Down)
+	}
+
+	version, err := SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+}
+
+func TestMigrator_UpLegacySchema(t *testing.T) {
+
+	db := newTestSchema(t)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, legacySchema)
+	require.NoError(t, err)
+
+	migrator, err := NewMigrator(db, zap.NewNop())
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Rows whose ids are left to the database get the ids after those which are already taken.
+	tests := map[string]struct {
+		insert string
+		expect int32
+	}{
+		"era": {
+			insert: "INSERT INTO era (title, min_year) VALUES ('Future', 2100) RETURNING id",
+			expect: 3,
+		},
+		"size": {
+			insert: "INSERT INTO size (title, min_pages) VALUES ('Tome', 2000) RETURNING id",
+			expect: 7,
+		},
+		"genre": {
+			insert: "INSERT INTO genre (title) VALUES ('Poetry') RETURNING id",
+			expect: 9,
+		},
+		"author": {
+			insert: "INSERT INTO author (first_name, last_name) VALUES ('Anastasia', 'Inez') RETURNING id",
+			expect: 6,
+		},
+		"book": {
+			insert: "INSERT INTO book (title, year_published, rating, pages, genre_id, author_id) " +
+				"VALUES ('Aeneid', 1902, 4.0, 280, 1, 1) RETURNING id",
+			expect: 13,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var id int32
+			require.NoError(t, db.QueryRowContext(ctx, test.insert).Scan(&id))
+			assert.Equal(t, test.expect, id)
+		})
+	}
+}
+
+func TestMigrator_Version(t *testing.T) {
+
+	tests := map[string]struct {
+		setExpectations func(sqlmock.Sqlmock)
+		expect          int
+		errAssertion    assert.ErrorAssertionFunc
+	}{
+		"version table does not exist": {
+			expect:       0,
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(existsQuery)).WithArgs(migration.VersionTable).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+			},
+		},
+		"query returns error": {
+			expect:       0,
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(existsQuery)).WithArgs(migration.VersionTable).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).WillReturnError(errors.New("unable to perform query"))
+			},
+		},
+		"latest applied version": {
+			expect:       2,
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta(existsQuery)).WithArgs(migration.VersionTable).
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+				mock.ExpectQuery(regexp.QuoteMeta(appliedQuery)).
+					WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).
+						AddRow(2, time.Now()).
+						AddRow(1, time.Now()))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			m, err := NewMigrator(db, zap.NewNop())
+			require.NoError(t, err)
+
+			tt.setExpectations(mock)
+
+			actual, err := m.Version(context.Background())
+			assert.Equal(t, tt.expect, actual)
+			tt.errAssertion(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
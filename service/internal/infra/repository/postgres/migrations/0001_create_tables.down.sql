@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS book;
+DROP TABLE IF EXISTS author;
+DROP TABLE IF EXISTS genre;
+DROP TABLE IF EXISTS size;
+DROP TABLE IF EXISTS era;
@@ -0,0 +1,47 @@
+-- Databases which were created by the former migrate.sql already have these tables, so they are only
+-- created if they do not exist. 0005 then lets the database generate their ids.
+CREATE TABLE IF NOT EXISTS era
+(
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  title TEXT NOT NULL,
+  min_year SMALLINT,
+  max_year SMALLINT
+);
+
+CREATE TABLE IF NOT EXISTS size
+(
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  title TEXT NOT NULL,
+  min_pages SMALLINT,
+  max_pages SMALLINT
+);
+
+CREATE TABLE IF NOT EXISTS genre
+(
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  title TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS author
+(
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  first_name TEXT NOT NULL,
+  last_name TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS book
+(
+  id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  title TEXT NOT NULL,
+  year_published SMALLINT NOT NULL,
+  rating NUMERIC(3, 2) NOT NULL,
+  pages SMALLINT NOT NULL,
+  genre_id INTEGER REFERENCES genre(id),
+  author_id INTEGER REFERENCES author(id)
+);
+
+CREATE INDEX IF NOT EXISTS book_published ON book USING btree (year_published);
+CREATE INDEX IF NOT EXISTS book_rating ON book USING btree (rating);
+CREATE INDEX IF NOT EXISTS book_pages ON book USING btree (pages);
+CREATE INDEX IF NOT EXISTS book_genre_id ON book USING btree (genre_id);
+CREATE INDEX IF NOT EXISTS book_author_id ON book USING btree (author_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS book_title_sort;
+DROP INDEX IF EXISTS book_rating_id;
+
+-- pg_trgm is left installed, since other schemas of the database may depend on it.
@@ -0,0 +1,5 @@
+-- pg_trgm provides the similarity matching of book search queries.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS book_rating_id ON book USING btree (rating DESC, id);
+CREATE INDEX IF NOT EXISTS book_title_sort ON book USING btree (regexp_replace(lower(title), '^(the|an|a)\s+', ''));
@@ -0,0 +1,21 @@
+-- Eras and Sizes are the buckets which book searches are filtered by, so every database needs them.
+INSERT INTO era (id, title, min_year, max_year)
+VALUES
+  (0, 'Any', NULL, NULL),
+  (1, 'Classic', NULL, 1969),
+  (2, 'Modern', 1970, NULL)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO size (id, title, min_pages, max_pages)
+VALUES
+  (0, 'Any', NULL, NULL),
+  (1, 'Short story – up to 35 pages', NULL, 34),
+  (2, 'Novelette – 35 to 85 pages', 35, 84),
+  (3, 'Novella – 85 to 200 pages', 85, 199),
+  (4, 'Novel – 200 to 500 pages', 200, 499),
+  (5, 'Brick – 500 to 800 pages', 500, 799),
+  (6, 'Monument – 800 pages and up', 800, NULL)
+ON CONFLICT (id) DO NOTHING;
+
+SELECT setval(pg_get_serial_sequence('era', 'id'), (SELECT max(id) FROM era));
+SELECT setval(pg_get_serial_sequence('size', 'id'), (SELECT max(id) FROM size));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS book_author_title;
+DROP INDEX IF EXISTS genre_title;
+DROP INDEX IF EXISTS author_name;
@@ -0,0 +1,5 @@
+-- Imported catalogs name their authors and genres, which are matched against these indexes, and books are
+-- upserted by their title and author.
+CREATE INDEX IF NOT EXISTS author_name ON author USING btree (last_name, first_name);
+CREATE INDEX IF NOT EXISTS genre_title ON genre USING btree (title);
+CREATE INDEX IF NOT EXISTS book_author_title ON book USING btree (author_id, title);
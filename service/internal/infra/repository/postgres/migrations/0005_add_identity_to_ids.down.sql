@@ -0,0 +1 @@
+-- Ids stay identities, since 0001 creates them as such and the former migrate.sql is gone.
@@ -0,0 +1,17 @@
+-- Databases which were created by the former migrate.sql kept plain integer ids when 0001 skipped their
+-- tables, so ids which are left to the database are generated once their columns are made identities. The
+-- sequences then continue after the ids which are already taken.
+DO $$
+DECLARE
+  t TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['era', 'size', 'genre', 'author', 'book'] LOOP
+    IF NOT EXISTS (
+      SELECT 1 FROM pg_attribute WHERE attrelid = t::regclass AND attname = 'id' AND attidentity <> ''
+    ) THEN
+      EXECUTE format('ALTER TABLE %I ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY', t);
+      EXECUTE format('SELECT setval(pg_get_serial_sequence(%L, ''id''), max(id)) FROM %I', t, t);
+    END IF;
+  END LOOP;
+END
+$$;
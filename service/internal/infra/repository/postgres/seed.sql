@@ -1,65 +1,5 @@
-CREATE TABLE era
-(
-  id INTEGER NOT NULL PRIMARY KEY,
-  title TEXT NOT NULL,
-  min_year SMALLINT,
-  max_year SMALLINT
-);
-
-CREATE TABLE size
-(
-  id INTEGER NOT NULL PRIMARY KEY,
-  title TEXT NOT NULL,
-  min_pages SMALLINT,
-  max_pages SMALLINT
-);
-
-CREATE TABLE genre
-(
-  id INTEGER NOT NULL PRIMARY KEY,
-  title TEXT NOT NULL
-); 
-
-CREATE TABLE author
-(
-  id INTEGER NOT NULL PRIMARY KEY,
-  first_name TEXT NOT NULL,
-  last_name TEXT NOT NULL
-);
-
-CREATE TABLE book
-(
-  id INTEGER NOT NULL PRIMARY KEY,
-  title TEXT NOT NULL,
-  year_published SMALLINT NOT NULL,
-  rating NUMERIC(3, 2) NOT NULL,
-  pages SMALLINT NOT NULL,
-  genre_id INTEGER REFERENCES genre(id),
-  author_id INTEGER REFERENCES author(id)
-);
-
-CREATE INDEX book_published ON book USING btree (year_published);
-CREATE INDEX book_rating ON book USING btree (rating);
-CREATE INDEX book_pages ON book USING btree (pages);
-CREATE INDEX book_genre_id ON book USING btree (genre_id);
-CREATE INDEX book_author_id ON book USING btree (author_id);
-
-INSERT INTO era (id, title, min_year, max_year)
-VALUES
-  (0, 'Any', NULL, NULL),
-  (1, 'Classic', NULL, 1969),
-  (2, 'Modern', 1970, NULL);
-
-INSERT INTO size (id, title, min_pages, max_pages)
-VALUES
-  (0, 'Any', NULL, NULL),
-  (1, 'Short story – up to 35 pages', NULL, 34),
-  (2, 'Novelette – 35 to 85 pages', 35, 84),
-  (3, 'Novella – 85 to 200 pages', 85, 199),
-  (4, 'Novel – 200 to 500 pages', 200, 499),
-  (5, 'Brick – 500 to 800 pages', 500, 799),
-  (6, 'Monument – 800 pages and up', 800, NULL);
-
+-- Sample genres, authors and books. Rows which already exist are left as they are, so seeding may be
+-- repeated.
 INSERT INTO genre (id, title)
 VALUES
   (1, 'Young Adult'),
@@ -69,7 +9,8 @@ VALUES
   (5, 'Mystery'),
   (6, 'Memoir'),
   (7, 'Fiction'),
-  (8, 'Childrens');
+  (8, 'Childrens')
+ON CONFLICT (id) DO NOTHING;
 
 INSERT INTO author (id, first_name, last_name)
 VALUES
@@ -113,7 +54,8 @@ VALUES
   (38, 'Robert', 'Milofsky'),
   (39, 'Ursula', 'Karénine'),
   (40, 'Ward', 'Haigh'),
-  (41, 'Abraham', 'Barton');
+  (41, 'Abraham', 'Barton')
+ON CONFLICT (id) DO NOTHING;
 
 INSERT INTO book (id, title, year_published, rating, pages, genre_id, author_id)
 VALUES
@@ -174,4 +116,10 @@ VALUES
   (55, 'Whither Thou Goest', 1963, 4.44, 146, 3, 30),
   (56, 'Who Did You Think You Were Kidding?', 1986, 4.6, 867, 6, 34),
   (57, 'We''re Sisters and We Kinda Like Each Other', 1989, 4.71, 67, 6, 33),
-  (58, 'Zero over Twelve', 1981, 1.01, 287, 5, 9);
+  (58, 'Zero over Twelve', 1981, 1.01, 287, 5, 9)
+ON CONFLICT (id) DO NOTHING;
+
+-- Resources created through the API are assigned IDs following the seeded ones.
+SELECT setval(pg_get_serial_sequence('genre', 'id'), (SELECT max(id) FROM genre));
+SELECT setval(pg_get_serial_sequence('author', 'id'), (SELECT max(id) FROM author));
+SELECT setval(pg_get_serial_sequence('book', 'id'), (SELECT max(id) FROM book));
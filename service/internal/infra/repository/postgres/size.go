@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	sizedriver "github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/encoding"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	sq "github.com/Masterminds/squirrel"
@@ -68,6 +69,224 @@ func NewSizeRepository(db *sql.DB, logger *zap.Logger) (*sizeRepository, error)
 func (r *sizeRepository) List(ctx context.Context) ([]entity.Size, error) {
 	r.logger.Debug("listing sizes from postgres repository")
 
+	sizes, err := listSizes(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("found %d sizes in postgres repository", len(sizes)))
+
+	return sizes, nil
+}
+
+// Create inserts a Size with the attributes of the size.WriteInput. A nil bound is stored as NULL. The size table
+// is locked against other writers, and check is called with every Size, before it is inserted. The ID assigned
+// to the Size is returned. If check returns an error or the query fails, then no Size is created and the error
+// is returned.
+func (r *sizeRepository) Create(ctx context.Context, params sizedriver.WriteInput,
+	check func([]entity.Size) error) (int32, error) {
+	r.logger.Debug("creating size in postgres repository")
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id, err := insertSize(ctx, tx, params)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return id, nil
+}
+
+// Update sets the attributes of the Size with the provided ID to those of the size.WriteInput. A nil bound is
+// stored as NULL. The size table is locked against other writers, and check is called with every Size, before it
+// is updated. If no such Size exists, then an error wrapping entity.ErrNotFound is returned. If check returns an
+// error or the query fails, then no Size is changed and the error is returned.
+func (r *sizeRepository) Update(ctx context.Context, id int32, params sizedriver.WriteInput,
+	check func([]entity.Size) error) error {
+	r.logger.Debug(fmt.Sprintf("updating size %d in postgres repository", id))
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := updateSize(ctx, tx, id, params); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the Size with the provided ID. The size table is locked against other writers, and check is
+// called with every Size, before it is deleted. If no such Size exists, then an error wrapping entity.ErrNotFound
+// is returned. If check returns an error or the query fails, then no Size is deleted and the error is returned.
+func (r *sizeRepository) Delete(ctx context.Context, id int32, check func([]entity.Size) error) error {
+	r.logger.Debug(fmt.Sprintf("deleting size %d from postgres repository", id))
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("size").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to delete size: %w", err)
+	}
+	if err := affectedOne(res, "size", id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ReplaceAll replaces every Size with those of the size.BulkInput in a single transaction. The size table is
+// locked against other writers, and check is called with every Size, before anything is written. Sizes whose IDs
+// are not in the size.BulkInput are then deleted, those whose IDs are in it are updated, and elements without
+// an ID are inserted. The resulting Sizes are returned in the order of the size.BulkInput. If check returns an
+// error or any query fails, then no Size is changed and the error is returned.
+func (r *sizeRepository) ReplaceAll(ctx context.Context, params []sizedriver.BulkInput,
+	check func([]entity.Size) error) ([]entity.Size, error) {
+	r.logger.Debug(fmt.Sprintf("replacing all sizes in postgres repository with %d sizes", len(params)))
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var kept []int32
+	for _, p := range params {
+		if p.ID != nil {
+			kept = append(kept, *p.ID)
+		}
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Delete("size").
+		Where(sq.NotEq{"id": kept}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+		return nil, fmt.Errorf("unable to delete sizes: %w", err)
+	}
+
+	sizes := make([]entity.Size, len(params))
+	for i, p := range params {
+		var id int32
+		if p.ID == nil {
+			if id, err = insertSize(ctx, tx, p.WriteInput); err != nil {
+				return nil, err
+			}
+		} else {
+			id = *p.ID
+			if err := updateSize(ctx, tx, id, p.WriteInput); err != nil {
+				return nil, err
+			}
+		}
+		sizes[i] = entity.Size{ID: id, Title: *p.Title, MinPages: p.MinPages, MaxPages: p.MaxPages}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return sizes, nil
+}
+
+// begin begins a transaction in which the size table is locked against other writers, and calls check with every
+// Size within it, so that concurrent writes cannot each pass check against the Sizes the other is changing. The
+// transaction is returned for the caller to commit or roll back. If check returns an error or any query fails,
+// then the transaction is rolled back and the error is returned.
+func (r *sizeRepository) begin(ctx context.Context, check func([]entity.Size) error) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+
+	if err := lockTable(ctx, tx, "size"); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	current, err := listSizes(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := check(current); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// insertSize inserts a Size with the attributes of the size.WriteInput and returns its ID.
+func insertSize(ctx context.Context, db execQueryer, params sizedriver.WriteInput) (int32, error) {
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Insert("size").
+		Columns("title", "min_pages", "max_pages").
+		Values(*params.Title, params.MinPages, params.MaxPages).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var id int32
+	if err := db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to create size: %w", err)
+	}
+	return id, nil
+}
+
+// updateSize sets the attributes of the Size with the provided ID to those of the size.WriteInput.
+func updateSize(ctx context.Context, db execQueryer, id int32, params sizedriver.WriteInput) error {
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Dollar).
+		Update("size").
+		Set("title", *params.Title).
+		Set("min_pages", params.MinPages).
+		Set("max_pages", params.MaxPages).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to update size: %w", err)
+	}
+
+	return affectedOne(res, "size", id)
+}
+
+// listSizes selects all Sizes with the execQueryer, such as within a transaction.
+func listSizes(ctx context.Context, db execQueryer) ([]entity.Size, error) {
 	query, _, err := sq.StatementBuilder.
 		Select("*").
 		From("size").
@@ -76,7 +295,7 @@ func (r *sizeRepository) List(ctx context.Context) ([]entity.Size, error) {
 		return nil, fmt.Errorf("unable to build SQL query: %w", err)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get sizes: %w", err)
 	}
@@ -95,8 +314,5 @@ func (r *sizeRepository) List(ctx context.Context) ([]entity.Size, error) {
 	if err = rows.Err(); err != nil {
 		return nil, err
 	}
-
-	r.logger.Debug(fmt.Sprintf("found %d sizes in postgres repository", len(sizes)))
-
 	return sizes, nil
 }
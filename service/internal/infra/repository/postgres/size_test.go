@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	sizedriver "github.com/LeviMatus/readcommend/service/internal/driver/size"
 	"github.com/LeviMatus/readcommend/service/internal/entity"
 	"github.com/LeviMatus/readcommend/service/pkg/util"
 	"github.com/pkg/errors"
@@ -143,3 +144,360 @@ func TestSizeRepository_GetSizes(t *testing.T) {
 		})
 	}
 }
+
+// expectSizesListed expects the size table to be locked, and its Sizes to be listed for a check.
+func expectSizesListed(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("LOCK TABLE size IN SHARE ROW EXCLUSIVE MODE")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM size")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "min_pages", "max_pages"}).AddRow(0, "Any", nil, nil))
+}
+
+func TestSizeRepository_Create(t *testing.T) {
+
+	var query = "INSERT INTO size (title,min_pages,max_pages) VALUES ($1,$2,$3) RETURNING id"
+
+	tests := map[string]struct {
+		expect          int32
+		check           func([]entity.Size) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"check rejects the size": {
+			check:        func([]entity.Size) error { return entity.ErrConflict },
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"query returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Novel", int16(200), nil).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"successful create size": {
+			expect:       3,
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs("Novel", int16(200), nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &sizeRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			var checked []entity.Size
+			check := func(sizes []entity.Size) error {
+				checked = sizes
+				if tt.check != nil {
+					return tt.check(sizes)
+				}
+				return nil
+			}
+
+			actual, err := repo.Create(context.Background(),
+				sizedriver.WriteInput{Title: util.StringPtr("Novel"), MinPages: util.Int16Ptr(200)}, check)
+			assert.Equal(t, tt.expect, actual)
+			assert.Equal(t, []entity.Size{{ID: 0, Title: "Any"}}, checked)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSizeRepository_Update(t *testing.T) {
+
+	var query = "UPDATE size SET title = $1, min_pages = $2, max_pages = $3 WHERE id = $4"
+
+	tests := map[string]struct {
+		check           func([]entity.Size) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"check rejects the size": {
+			check:        func([]entity.Size) error { return entity.ErrConflict },
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Short story", nil, int16(84), int32(1)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"size does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Short story", nil, int16(84), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"successful update size": {
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs("Short story", nil, int16(84), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &sizeRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			check := func(sizes []entity.Size) error {
+				if tt.check != nil {
+					return tt.check(sizes)
+				}
+				return nil
+			}
+
+			err := repo.Update(context.Background(), 1,
+				sizedriver.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(84)}, check)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSizeRepository_Delete(t *testing.T) {
+
+	var query = "DELETE FROM size WHERE id = $1"
+
+	tests := map[string]struct {
+		check           func([]entity.Size) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"check rejects the size": {
+			check:        func([]entity.Size) error { return entity.ErrConflict },
+			errAssertion: assert.Error,
+			errIs:        entity.ErrConflict,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"exec returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(2)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"size does not exist": {
+			errAssertion: assert.Error,
+			errIs:        entity.ErrNotFound,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(2)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"successful delete size": {
+			errAssertion: assert.NoError,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(query)).WithArgs(int32(2)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &sizeRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			check := func(sizes []entity.Size) error {
+				if tt.check != nil {
+					return tt.check(sizes)
+				}
+				return nil
+			}
+
+			err := repo.Delete(context.Background(), 2, check)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestSizeRepository_ReplaceAll(t *testing.T) {
+
+	var (
+		lockQuery   = "LOCK TABLE size IN SHARE ROW EXCLUSIVE MODE"
+		deleteQuery = "DELETE FROM size WHERE id NOT IN ($1,$2)"
+		updateQuery = "UPDATE size SET title = $1, min_pages = $2, max_pages = $3 WHERE id = $4"
+		insertQuery = "INSERT INTO size (title,min_pages,max_pages) VALUES ($1,$2,$3) RETURNING id"
+	)
+
+	input := []sizedriver.BulkInput{
+		{ID: util.Int32Ptr(0), WriteInput: sizedriver.WriteInput{Title: util.StringPtr("Any")}},
+		{ID: util.Int32Ptr(1), WriteInput: sizedriver.WriteInput{Title: util.StringPtr("Short story"), MaxPages: util.Int16Ptr(74)}},
+		{WriteInput: sizedriver.WriteInput{Title: util.StringPtr("Novelette"), MinPages: util.Int16Ptr(75)}},
+	}
+
+	listed := []entity.Size{{ID: 0, Title: "Any"}}
+
+	tests := map[string]struct {
+		expect          []entity.Size
+		expectChecked   []entity.Size
+		check           func([]entity.Size) error
+		setExpectations func(sqlmock.Sqlmock)
+		errAssertion    assert.ErrorAssertionFunc
+		errIs           error
+	}{
+		"lock returns error": {
+			errAssertion: assert.Error,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta(lockQuery)).WillReturnError(errors.New("unable to lock"))
+				mock.ExpectRollback()
+			},
+		},
+		"check rejects the sizes": {
+			check:         func([]entity.Size) error { return entity.ErrConflict },
+			errAssertion:  assert.Error,
+			errIs:         entity.ErrConflict,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectRollback()
+			},
+		},
+		"delete returns error": {
+			errAssertion:  assert.Error,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(int32(0), int32(1)).
+					WillReturnError(errors.New("unable to perform query"))
+				mock.ExpectRollback()
+			},
+		},
+		"size to update does not exist": {
+			errAssertion:  assert.Error,
+			errIs:         entity.ErrNotFound,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(int32(0), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("Any", nil, nil, int32(0)).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+		},
+		"successful replace all sizes": {
+			expect: []entity.Size{
+				{ID: 0, Title: "Any"},
+				{ID: 1, Title: "Short story", MaxPages: util.Int16Ptr(74)},
+				{ID: 3, Title: "Novelette", MinPages: util.Int16Ptr(75)},
+			},
+			errAssertion:  assert.NoError,
+			expectChecked: listed,
+			setExpectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				expectSizesListed(mock)
+				mock.ExpectExec(regexp.QuoteMeta(deleteQuery)).WithArgs(int32(0), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("Any", nil, nil, int32(0)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta(updateQuery)).WithArgs("Short story", nil, int16(74), int32(1)).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery(regexp.QuoteMeta(insertQuery)).WithArgs("Novelette", int16(75), nil).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+				mock.ExpectCommit()
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, mock := newMock(t)
+			repo := &sizeRepository{db: db, logger: zap.NewNop()}
+
+			tt.setExpectations(mock)
+
+			var checked []entity.Size
+			check := func(sizes []entity.Size) error {
+				checked = sizes
+				if tt.check != nil {
+					return tt.check(sizes)
+				}
+				return nil
+			}
+
+			actual, err := repo.ReplaceAll(context.Background(), input, check)
+			assert.Equal(t, tt.expect, actual)
+			assert.Equal(t, tt.expectChecked, checked)
+			tt.errAssertion(t, err)
+			if tt.errIs != nil {
+				assert.ErrorIs(t, err, tt.errIs)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
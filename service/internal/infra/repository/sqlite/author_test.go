@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAuthorRepository_Stats(t *testing.T) {
+
+	r, err := NewAuthorRepository(newDB(t), zap.NewNop())
+	require.NoError(t, err)
+
+	stats, err := r.Stats(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.BookCount)
+	require.NotNil(t, stats.AverageRating)
+	assert.Equal(t, 3.98, *stats.AverageRating)
+	assert.Equal(t, int16Ptr(1968), stats.FirstYearPublished)
+	assert.Equal(t, int16Ptr(1990), stats.LastYearPublished)
+
+	stats, err = r.Stats(context.Background(), 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), stats.BookCount)
+	assert.Nil(t, stats.AverageRating)
+}
+
+func TestAuthorRepository_Delete(t *testing.T) {
+
+	tests := map[string]struct {
+		id           int32
+		reassignTo   *int32
+		errAssertion assert.ErrorAssertionFunc
+		remaining    int
+	}{
+		"author without books": {
+			id:           3,
+			errAssertion: assert.NoError,
+			remaining:    2,
+		},
+		"author with books": {
+			id: 1,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrConflict)
+			},
+			remaining: 3,
+		},
+		"books are reassigned": {
+			id:           1,
+			reassignTo:   int32Ptr(3),
+			errAssertion: assert.NoError,
+			remaining:    2,
+		},
+		"reassigned to missing author": {
+			id:           1,
+			reassignTo:   int32Ptr(42),
+			errAssertion: assert.Error,
+			remaining:    3,
+		},
+		"missing author": {
+			id: 42,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrNotFound)
+			},
+			remaining: 3,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewAuthorRepository(newDB(t), zap.NewNop())
+			require.NoError(t, err)
+
+			tt.errAssertion(t, r.Delete(context.Background(), tt.id, tt.reassignTo))
+
+			authors, err := r.List(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, authors, tt.remaining)
+		})
+	}
+}
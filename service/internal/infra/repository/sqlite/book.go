@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/booksql"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+type bookRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewBookRepository accepts a pointer to a sql.DB type. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in a bookRepository and a pointer to it is returned.
+func NewBookRepository(db *sql.DB, logger *zap.Logger) (*bookRepository, error) {
+	if db == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &bookRepository{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// Search selects all Books in the repository. If the query fails or encounters an error while
+// cursing through the result set, then an error is returned.
+func (r *bookRepository) Search(ctx context.Context, params book.SearchInput) ([]entity.Book, error) {
+	r.logger.Debug("searching books from sqlite repository")
+	return booksql.NewSearcher(r.db, dialect, r.logger).Search(ctx, params)
+}
+
+// Get selects the Book with the provided ID, along with its Author and Genre. If no such Book exists,
+// then an error wrapping entity.ErrNotFound is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Get(ctx context.Context, id int32) (entity.Book, error) {
+	r.logger.Debug(fmt.Sprintf("getting book %d from sqlite repository", id))
+
+	query, values, err := dialect.SelectBooks().Where(sq.Eq{"book.id": id}).ToSql()
+	if err != nil {
+		return entity.Book{}, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var b entity.Book
+	err = r.db.QueryRowContext(ctx, query, values...).Scan(booksql.BookDest(&b)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Book{}, fmt.Errorf("%w: book %d does not exist", entity.ErrNotFound, id)
+	}
+	if err != nil {
+		return entity.Book{}, fmt.Errorf("unable to get book: %w", err)
+	}
+
+	return b, nil
+}
+
+// Create inserts a Book with the attributes of the book.WriteInput, all of which must be set. The rating is
+// rounded to two decimal places, as NUMERIC(3, 2) would. The database assigns the Book's ID, which is returned.
+// If the query fails, then an error is returned.
+func (r *bookRepository) Create(ctx context.Context, params book.WriteInput) (int32, error) {
+	r.logger.Debug("creating book in sqlite repository")
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Insert("book").
+		Columns("title", "year_published", "rating", "pages", "author_id", "genre_id").
+		Values(*params.Title, *params.YearPublished, roundRating(*params.Rating), *params.Pages, *params.AuthorID, *params.GenreID).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var id int32
+	if err := r.db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to create book: %w", err)
+	}
+
+	r.logger.Debug(fmt.Sprintf("created book %d in sqlite repository", id))
+	return id, nil
+}
+
+// Update sets the attributes of the Book with the provided ID which are set in the book.WriteInput. If no such
+// Book exists, then an error wrapping entity.ErrNotFound is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Update(ctx context.Context, id int32, params book.WriteInput) error {
+	r.logger.Debug(fmt.Sprintf("updating book %d in sqlite repository", id))
+
+	set := map[string]interface{}{}
+	if params.Title != nil {
+		set["title"] = *params.Title
+	}
+	if params.YearPublished != nil {
+		set["year_published"] = *params.YearPublished
+	}
+	if params.Rating != nil {
+		set["rating"] = roundRating(*params.Rating)
+	}
+	if params.Pages != nil {
+		set["pages"] = *params.Pages
+	}
+	if params.AuthorID != nil {
+		set["author_id"] = *params.AuthorID
+	}
+	if params.GenreID != nil {
+		set["genre_id"] = *params.GenreID
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Update("book").
+		SetMap(set).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to update book: %w", err)
+	}
+
+	return affectedOne(res, "book", id)
+}
+
+// Delete deletes the Book with the provided ID. If no such Book exists, then an error wrapping entity.ErrNotFound
+// is returned. If the query fails, then an error is returned.
+func (r *bookRepository) Delete(ctx context.Context, id int32) error {
+	r.logger.Debug(fmt.Sprintf("deleting book %d from sqlite repository", id))
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Delete("book").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := r.db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to delete book: %w", err)
+	}
+
+	return affectedOne(res, "book", id)
+}
+
+// Facets counts the Books in the repository which match the search parameters of each facet in the
+// book.FacetInput. Each facet is counted with a single aggregate query, which includes every bucket of the
+// facet, even if no Books fall into it. If a query fails or encounters an error while cursing through a
+// result set, then an error is returned.
+func (r *bookRepository) Facets(ctx context.Context, params book.FacetInput) (book.Facets, error) {
+	r.logger.Debug("counting book facets from sqlite repository")
+	return booksql.NewSearcher(r.db, dialect, r.logger).Facets(ctx, params)
+}
+
+// Export calls each with every Book in the repository which Search would select, as it is scanned. The Books
+// are selected in a single read transaction, so that they are a consistent snapshot of the repository. If the query
+// fails, or each returns an error, then the export stops and an error is returned.
+func (r *bookRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) error {
+	r.logger.Debug("exporting books from sqlite repository")
+	return booksql.Export(ctx, r.db, dialect, r.logger, params, each)
+}
+
+// dialect describes the SQL of SQLite to booksql. Books are searched by the functions which are registered on
+// every connection of the DriverName driver, so that they match as in the memory store.
+var dialect = booksql.Dialect{
+	Name:        "sqlite",
+	Placeholder: sq.Question,
+
+	TitleSortKey: "title_sort_key(%s)",
+
+	SearchMatch:     "search_match(?, " + searchDocument + ")",
+	SearchRelevance: "search_relevance(?, " + searchDocument + ")",
+
+	// Ratings are stored as REAL, rounded to two decimal places, and relevance is rounded by search_relevance,
+	// so both compare exactly against the float64 with the same rounding.
+	Relevance: func(v float64) interface{} { return v },
+	Rating:    func(v float32) interface{} { return roundRating(v) },
+}
+
+// searchDocument is the text of a Book which book.SearchInput.Query is matched against, passed as the
+// arguments of the search functions: its title and the name of its author.
+const searchDocument = "book.title, author.first_name, author.last_name"
+
+// roundRating rounds a rating to two decimal places. The float32 is formatted first, so that 4.3 is not stored
+// as 4.300000190734863.
+func roundRating(v float32) float64 {
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'f', 2, 32), 64)
+	return r
+}
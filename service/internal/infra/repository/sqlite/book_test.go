@@ -0,0 +1,209 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newBookRepository(t *testing.T) *bookRepository {
+	t.Helper()
+	r, err := NewBookRepository(newDB(t), zap.NewNop())
+	require.NoError(t, err)
+	return r
+}
+
+func TestNewBookRepository(t *testing.T) {
+	_, err := NewBookRepository(nil, zap.NewNop())
+	assert.ErrorIs(t, err, ErrInvalidDependency)
+}
+
+func TestBookRepository_Search(t *testing.T) {
+
+	tests := map[string]struct {
+		params book.SearchInput
+		expect []int32
+	}{
+		"default order is best rated first": {
+			params: book.SearchInput{},
+			expect: []int32{3, 1, 4, 2, 5},
+		},
+		"exact title": {
+			params: book.SearchInput{Title: stringPtr("The Tombs of Atuan")},
+			expect: []int32{2},
+		},
+		"authors and genres": {
+			params: book.SearchInput{AuthorIDs: []int16{1}, GenreIDs: []int16{2}},
+			expect: []int32{5},
+		},
+		"bounds are inclusive": {
+			params: book.SearchInput{MinPages: int16Ptr(163), MaxPages: int16Ptr(256), MaxYearPublished: int16Ptr(1968)},
+			expect: []int32{1, 4},
+		},
+		"any of the ranges": {
+			params: book.SearchInput{
+				YearRanges: []book.Range{{Max: int16Ptr(1930)}, {Min: int16Ptr(1980)}},
+				PageRanges: []book.Range{{Max: int16Ptr(99)}, {Min: int16Ptr(300)}},
+			},
+			expect: []int32{3, 5},
+		},
+		"sorted by title ignoring articles": {
+			params: book.SearchInput{Sort: book.Sort{{Field: book.SortByTitle}}},
+			expect: []int32{3, 4, 2, 5, 1},
+		},
+		"ties are broken by id": {
+			params: book.SearchInput{Sort: book.Sort{{Field: book.SortByRating}}, Limit: uint64Ptr(4)},
+			expect: []int32{5, 2, 1, 4},
+		},
+		"after cursor": {
+			params: book.SearchInput{
+				After: &book.Cursor{Rating: 4.5, ID: 1},
+				Limit: uint64Ptr(2),
+			},
+			expect: []int32{4, 2},
+		},
+		"after title cursor": {
+			params: book.SearchInput{
+				Sort:  book.Sort{{Field: book.SortByTitle}},
+				After: &book.Cursor{Title: "Murder on the Orient Express", ID: 4},
+			},
+			expect: []int32{2, 5, 1},
+		},
+		"full-text query": {
+			params: book.SearchInput{Query: stringPtr("murders"), Sort: book.RelevanceSort},
+			expect: []int32{3, 4, 5},
+		},
+		"query matches author": {
+			params: book.SearchInput{Query: stringPtr("christie")},
+			expect: []int32{3, 4},
+		},
+		"query tolerates typos": {
+			params: book.SearchInput{Query: stringPtr("earthsee")},
+			expect: []int32{1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newBookRepository(t)
+			actual, err := r.Search(context.Background(), tt.params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, ids(actual))
+		})
+	}
+
+	t.Run("resuming after relevance cursor", func(t *testing.T) {
+		r := newBookRepository(t)
+		params := book.SearchInput{Query: stringPtr("murder"), Sort: book.RelevanceSort}
+
+		all, err := r.Search(context.Background(), params)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+
+		first := all[0]
+		params.After = &book.Cursor{Relevance: first.Relevance, Rating: first.Rating, ID: first.ID}
+		rest, err := r.Search(context.Background(), params)
+		require.NoError(t, err)
+		assert.Equal(t, ids(all[1:]), ids(rest))
+	})
+
+	t.Run("books are joined with their author and genre", func(t *testing.T) {
+		r := newBookRepository(t)
+		actual, err := r.Search(context.Background(), book.SearchInput{Title: stringPtr("A Wizard of Earthsea")})
+		require.NoError(t, err)
+		require.Len(t, actual, 1)
+		assert.Equal(t, entity.Author{ID: 1, FirstName: "Ursula", LastName: "Le Guin"}, actual[0].Author)
+		assert.Equal(t, entity.Genre{ID: 1, Title: "Fantasy"}, actual[0].Genre)
+	})
+}
+
+func TestBookRepository_Export(t *testing.T) {
+
+	r := newBookRepository(t)
+	params := book.SearchInput{Query: stringPtr("murder"), Sort: book.Sort{{Field: book.SortByTitle}}}
+
+	expect, err := r.Search(context.Background(), params)
+	require.NoError(t, err)
+
+	var actual []entity.Book
+	err = r.Export(context.Background(), params, func(b entity.Book) error {
+		actual = append(actual, b)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, expect, actual)
+
+	stop := errors.New("unable to write book")
+	var exported int
+	err = r.Export(context.Background(), book.SearchInput{}, func(entity.Book) error {
+		exported++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, exported)
+}
+
+func TestBookRepository_Facets(t *testing.T) {
+
+	r := newBookRepository(t)
+	actual, err := r.Facets(context.Background(), book.FacetInput{
+		Genres:  book.SearchInput{AuthorIDs: []int16{1}},
+		Authors: book.SearchInput{GenreIDs: []int16{2}},
+		Eras:    book.SearchInput{},
+		Sizes:   book.SearchInput{AuthorIDs: []int16{2}, Query: stringPtr("murder")},
+	})
+	require.NoError(t, err)
+
+	// The eras and sizes are those inserted by the migrations.
+	assert.Equal(t, []book.FacetCount{{ID: 1, Count: 2}, {ID: 2, Count: 1}}, actual.Genres)
+	assert.Equal(t, []book.FacetCount{{ID: 1, Count: 1}, {ID: 2, Count: 2}, {ID: 3, Count: 0}}, actual.Authors)
+	assert.Equal(t, []book.FacetCount{{ID: 0, Count: 5}, {ID: 1, Count: 3}, {ID: 2, Count: 2}}, actual.Eras)
+	assert.Equal(t, []book.FacetCount{
+		{ID: 0, Count: 2}, {ID: 1, Count: 0}, {ID: 2, Count: 0}, {ID: 3, Count: 0},
+		{ID: 4, Count: 2}, {ID: 5, Count: 0}, {ID: 6, Count: 0},
+	}, actual.Sizes)
+}
+
+func TestBookRepository_Write(t *testing.T) {
+
+	ctx := context.Background()
+	r := newBookRepository(t)
+
+	id, err := r.Create(ctx, book.WriteInput{
+		Title:         stringPtr("The Left Hand of Darkness"),
+		YearPublished: int16Ptr(1969),
+		Rating:        float32Ptr(4.456),
+		Pages:         int16Ptr(304),
+		AuthorID:      int32Ptr(1),
+		GenreID:       int32Ptr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(6), id)
+
+	b, err := r.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, float32(4.46), b.Rating)
+	assert.Equal(t, "Le Guin", b.Author.LastName)
+
+	require.NoError(t, r.Update(ctx, id, book.WriteInput{Pages: int16Ptr(286), GenreID: int32Ptr(2)}))
+	b, err = r.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, int16(286), b.Pages)
+	assert.Equal(t, "Mystery", b.Genre.Title)
+	assert.Equal(t, "The Left Hand of Darkness", b.Title)
+
+	// Foreign keys are enforced.
+	assert.Error(t, r.Update(ctx, id, book.WriteInput{AuthorID: int32Ptr(42)}))
+	assert.ErrorIs(t, r.Update(ctx, 42, book.WriteInput{Pages: int16Ptr(1)}), entity.ErrNotFound)
+
+	require.NoError(t, r.Delete(ctx, id))
+	_, err = r.Get(ctx, id)
+	assert.ErrorIs(t, err, entity.ErrNotFound)
+	assert.ErrorIs(t, r.Delete(ctx, id), entity.ErrNotFound)
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"go.uber.org/zap"
+)
+
+type catalogRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewCatalogRepository accepts a pointer to a sql.DB type. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in a catalogRepository and a pointer to it is returned.
+func NewCatalogRepository(db *sql.DB, logger *zap.Logger) (*catalogRepository, error) {
+	if db == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &catalogRepository{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// Import writes the Book of every Record of the Source in a single transaction. The Records are inserted into
+// a temporary table, and then merged into the author, genre and book tables. If the Source reports
+// an error once it is exhausted, or any query fails, then nothing is written and an error is returned.
+func (r *catalogRepository) Import(ctx context.Context, records catalog.Source, upsert bool) (catalog.Summary, error) {
+	r.logger.Debug("importing catalog into sqlite repository")
+
+	summary, err := dialect.Import(ctx, r.db, records, upsert)
+	if err != nil {
+		return summary, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("imported %d books into sqlite repository", summary.Created+summary.Updated))
+	return summary, nil
+}
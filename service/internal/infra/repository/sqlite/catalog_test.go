@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/catalog"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const catalogHeader = "title,author_first_name,author_last_name,genre,year_published,pages,rating\n"
+
+func TestCatalogRepository_Import(t *testing.T) {
+
+	tests := map[string]struct {
+		in           string
+		upsert       bool
+		expect       catalog.Summary
+		books        int
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"authors and genres are matched by name": {
+			in: catalogHeader +
+				"The Farthest Shore,Ursula,Le Guin,Fantasy,1972,259,4.3\n" +
+				"The Mysterious Affair at Styles,Agatha,Christie,Mystery,1920,296,4.0\n",
+			expect:       catalog.Summary{Records: 2, Created: 2},
+			books:        7,
+			errAssertion: assert.NoError,
+		},
+		"authors and genres are created once": {
+			in: catalogHeader +
+				"Dune,Frank,Herbert,Science Fiction,1965,412,4.5\n" +
+				"Dune Messiah,Frank,Herbert,Science Fiction,1969,256,3.9\n",
+			expect:       catalog.Summary{Records: 2, Created: 2, AuthorsCreated: 1, GenresCreated: 1},
+			books:        7,
+			errAssertion: assert.NoError,
+		},
+		"upsert updates books by title and author": {
+			in: catalogHeader +
+				"A Wizard of Earthsea,Ursula,Le Guin,Fantasy,1968,183,4.0\n" +
+				"A Wizard of Earthsea,Ursula,Le Guin,Fantasy,1968,183,4.9\n" +
+				"Tehanu,Ursula,Le Guin,Fantasy,1990,226,4.1\n",
+			upsert:       true,
+			expect:       catalog.Summary{Records: 3, Created: 1, Updated: 1},
+			books:        6,
+			errAssertion: assert.NoError,
+		},
+		"invalid records roll back the import": {
+			in: catalogHeader +
+				"Dune,Frank,Herbert,Science Fiction,1965,412,4.5\n" +
+				"Dune Messiah,Frank,Herbert,,1969,256,3.9\n",
+			expect: catalog.Summary{Records: 1},
+			books:  5,
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrInvalidEntity)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := newDB(t)
+			r, err := NewCatalogRepository(db, zap.NewNop())
+			require.NoError(t, err)
+
+			reader, err := catalog.NewReader(strings.NewReader(tt.in), catalog.FormatCSV)
+			require.NoError(t, err)
+
+			summary, err := catalog.NewDriver(r).Import(context.Background(), reader, catalog.Options{Upsert: tt.upsert})
+			tt.errAssertion(t, err)
+			summary.Errors = nil
+			assert.Equal(t, tt.expect, summary)
+
+			var books int
+			require.NoError(t, db.QueryRow("SELECT count(*) FROM book").Scan(&books))
+			assert.Equal(t, tt.books, books)
+		})
+	}
+}
+
+func TestCatalogRepository_Import_Upsert(t *testing.T) {
+
+	db := newDB(t)
+	r, err := NewCatalogRepository(db, zap.NewNop())
+	require.NoError(t, err)
+
+	reader, err := catalog.NewReader(strings.NewReader(catalogHeader+
+		"A Wizard of Earthsea,Ursula,Le Guin,Mystery,1968,200,4.0\n"+
+		"A Wizard of Earthsea,Ursula,Le Guin,Fantasy,1969,190,4.9\n"), catalog.FormatCSV)
+	require.NoError(t, err)
+
+	_, err = catalog.NewDriver(r).Import(context.Background(), reader, catalog.Options{Upsert: true})
+	require.NoError(t, err)
+
+	books, err := NewBookRepository(db, zap.NewNop())
+	require.NoError(t, err)
+	b, err := books.Get(context.Background(), 1)
+	require.NoError(t, err)
+
+	// The last record with the title and author wins.
+	assert.Equal(t, int16(1969), b.YearPublished)
+	assert.Equal(t, int16(190), b.Pages)
+	assert.Equal(t, float32(4.9), b.Rating)
+	assert.Equal(t, "Fantasy", b.Genre.Title)
+}
+
+func TestCatalogRepository_ExportImport(t *testing.T) {
+
+	// shape drops the IDs of the Books, which differ between databases.
+	shape := func(books []entity.Book) []entity.Book {
+		for i := range books {
+			books[i].ID, books[i].Author.ID, books[i].Genre.ID = 0, 0, 0
+		}
+		return books
+	}
+	params := book.SearchInput{Sort: book.Sort{{Field: book.SortByTitle}}}
+
+	source, err := NewBookRepository(newDB(t), zap.NewNop())
+	require.NoError(t, err)
+	expect, err := source.Search(context.Background(), params)
+	require.NoError(t, err)
+
+	for _, format := range []string{catalog.FormatCSV, catalog.FormatJSON, catalog.FormatJSONL} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := catalog.NewWriter(&buf, format)
+			require.NoError(t, err)
+			require.NoError(t, source.Export(context.Background(), book.SearchInput{}, w.Write))
+			require.NoError(t, w.Close())
+
+			db := newEmptyDB(t)
+			r, err := NewCatalogRepository(db, zap.NewNop())
+			require.NoError(t, err)
+			reader, err := catalog.NewReader(&buf, format)
+			require.NoError(t, err)
+			_, err = catalog.NewDriver(r).Import(context.Background(), reader, catalog.Options{})
+			require.NoError(t, err)
+
+			target, err := NewBookRepository(db, zap.NewNop())
+			require.NoError(t, err)
+			actual, err := target.Search(context.Background(), params)
+			require.NoError(t, err)
+			assert.Equal(t, shape(expect), shape(actual))
+		})
+	}
+}
+
+func TestCatalogRepository_Generate(t *testing.T) {
+
+	ctx := context.Background()
+	db := newEmptyDB(t)
+
+	eras, err := NewEraRepository(db, zap.NewNop())
+	require.NoError(t, err)
+	sizes, err := NewSizeRepository(db, zap.NewNop())
+	require.NoError(t, err)
+
+	opts := catalog.GenerateOptions{Books: 2000, Authors: 200, Genres: 12, Seed: 7}
+	allEras, err := eras.List(ctx)
+	require.NoError(t, err)
+	for _, e := range allEras {
+		opts.YearRanges = append(opts.YearRanges, book.Range{Min: e.MinYear, Max: e.MaxYear})
+	}
+	allSizes, err := sizes.List(ctx)
+	require.NoError(t, err)
+	for _, s := range allSizes {
+		opts.PageRanges = append(opts.PageRanges, book.Range{Min: s.MinPages, Max: s.MaxPages})
+	}
+
+	r, err := NewCatalogRepository(db, zap.NewNop())
+	require.NoError(t, err)
+	summary, err := catalog.NewDriver(r).Generate(ctx, opts)
+	require.NoError(t, err)
+	assert.Equal(t, catalog.Summary{Records: 2000, Created: 2000, AuthorsCreated: 200, GenresCreated: 12}, summary)
+
+	// Every author, genre, era and size has books.
+	books, err := NewBookRepository(db, zap.NewNop())
+	require.NoError(t, err)
+	facets, err := books.Facets(ctx, book.FacetInput{})
+	require.NoError(t, err)
+	for _, counts := range [][]book.FacetCount{facets.Authors, facets.Genres, facets.Eras, facets.Sizes} {
+		require.NotEmpty(t, counts)
+		for _, c := range counts {
+			assert.NotZero(t, c.Count, "bucket %d", c.ID)
+		}
+	}
+	assert.Len(t, facets.Authors, opts.Authors)
+	assert.Len(t, facets.Genres, opts.Genres)
+}
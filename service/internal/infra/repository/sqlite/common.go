@@ -0,0 +1,195 @@
+// Package sqlite implements the repositories over a single-file SQLite database, for small installs and demos.
+// Connections must be opened with the DriverName driver, which registers the functions that book searches use.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/textsearch"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidDependency = errors.New("expected a non-nil sql Database connection")
+)
+
+// DriverName is the name of the database/sql driver which opens SQLite databases with the search functions
+// registered on every connection.
+const DriverName = "sqlite3_readcommend"
+
+func init() {
+	sql.Register(DriverName, &sqlite3.SQLiteDriver{ConnectHook: registerFunctions})
+}
+
+// Open opens the SQLite database in the file, creating it if it does not exist. Foreign keys are enforced, as
+// SQLite leaves them off by default, and writers wait for each other rather than failing.
+func Open(file string) (*sql.DB, error) {
+	return sql.Open(DriverName, DSN(file))
+}
+
+// DSN returns the data source name which Open opens the SQLite database in the file with, for opening it under
+// DriverName by other means.
+func DSN(file string) string {
+	return fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", file)
+}
+
+// registerFunctions registers the functions of the Dialect on a connection. They are deterministic, so that
+// SQLite may evaluate them once per row. The search functions share a queryCache, so that the query a statement
+// passes for every row is parsed once.
+func registerFunctions(conn *sqlite3.SQLiteConn) error {
+	var cache queryCache
+	searchMatch := func(query string, text ...interface{}) bool {
+		return cache.parse(query).Matches(textsearch.NewDocument(joinText(text)))
+	}
+	searchRelevance := func(query string, text ...interface{}) float64 {
+		return cache.parse(query).Relevance(textsearch.NewDocument(joinText(text)))
+	}
+
+	if err := conn.RegisterFunc("search_match", searchMatch, true); err != nil {
+		return err
+	}
+	if err := conn.RegisterFunc("search_relevance", searchRelevance, true); err != nil {
+		return err
+	}
+	return conn.RegisterFunc("title_sort_key", textsearch.TitleSortKey, true)
+}
+
+// queryCache holds the last query parsed on a connection, along with its text. A connection is only used by one
+// goroutine at a time, so the cache is not locked.
+type queryCache struct {
+	text  string
+	query *textsearch.Query
+}
+
+// parse returns the parsed query, which is only parsed again if its text differs from the last one.
+func (c *queryCache) parse(text string) *textsearch.Query {
+	if c.query == nil || c.text != text {
+		c.text, c.query = text, textsearch.Parse(text)
+	}
+	return c.query
+}
+
+// joinText joins the text with spaces, skipping NULLs, as concat_ws does.
+func joinText(text []interface{}) string {
+	parts := make([]string, 0, len(text))
+	for _, t := range text {
+		switch v := t.(type) {
+		case string:
+			parts = append(parts, v)
+		case []byte:
+			parts = append(parts, string(v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// execQueryer is satisfied by both *sql.DB and *sql.Tx, so that statements can run within a transaction or not.
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// lockTable takes the write lock of the database for the rest of the transaction, so that other writers wait for
+// it to end, by running a statement which writes to the table but matches no row. SQLite otherwise defers the
+// lock until the first write, so two transactions which read before writing could not both proceed. Readers are
+// not blocked.
+func lockTable(ctx context.Context, tx *sql.Tx, table string) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE 0", table)); err != nil {
+		return fmt.Errorf("unable to lock %s: %w", table, err)
+	}
+	return nil
+}
+
+// affectedOne checks that the sql.Result of a statement targeting the row of the table with the provided ID
+// affected it. If no row was affected, then the row does not exist and an error wrapping entity.ErrNotFound
+// is returned.
+func affectedOne(res sql.Result, table string, id int32) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to count affected rows: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s %d does not exist", entity.ErrNotFound, table, id)
+	}
+	return nil
+}
+
+// referenced reports whether the error was raised because the row being deleted is still referenced.
+func referenced(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
+
+// TranslateError is the dberror.Translator of SQLite. A database which stayed locked by another writer for
+// longer than the busy timeout is a timeout, and one which cannot be opened, read or written is unavailable.
+func TranslateError(err error) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrBusy, sqlite3.ErrLocked:
+		return &entity.Error{Kind: entity.ErrTimeout, Err: err}
+	case sqlite3.ErrCantOpen, sqlite3.ErrIoErr, sqlite3.ErrFull:
+		return &entity.Error{Kind: entity.ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+// deleteReferenced deletes the row of the table with the provided ID, which Books reference by the column. If
+// reassignTo is not nil, then the Books are first reassigned to the row with that ID, in the same transaction.
+// If no such row exists, then an error wrapping entity.ErrNotFound is returned. If Books still reference the
+// row, then an error wrapping entity.ErrConflict is returned.
+func deleteReferenced(ctx context.Context, db *sql.DB, table, column string, id int32, reassignTo *int32) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if reassignTo != nil {
+		query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+			Update("book").
+			Set(column, *reassignTo).
+			Where(sq.Eq{column: id}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("unable to build SQL query: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+			return fmt.Errorf("unable to reassign books of %s: %w", table, err)
+		}
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Delete(table).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, values...)
+	if referenced(err) {
+		return fmt.Errorf("%w: %s %d is still referenced by books", entity.ErrConflict, table, id)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to delete %s: %w", table, err)
+	}
+	if err := affectedOne(res, table, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/textsearch"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func int16Ptr(v int16) *int16 { return &v }
+
+func stringPtr(v string) *string { return &v }
+
+func uint64Ptr(v uint64) *uint64 { return &v }
+
+func float32Ptr(v float32) *float32 { return &v }
+
+func int32Ptr(v int32) *int32 { return &v }
+
+// fixture is a small set of genres, authors and books which the tests of the package search and modify. They
+// are those of the fixture of the memory store's tests, so that both return the same results.
+const fixture = `
+INSERT INTO genre (id, title) VALUES (1, 'Fantasy'), (2, 'Mystery');
+INSERT INTO author (id, first_name, last_name) VALUES (1, 'Ursula', 'Le Guin'), (2, 'Agatha', 'Christie'), (3, 'Idle', 'Writer');
+INSERT INTO book (id, title, year_published, rating, pages, author_id, genre_id) VALUES
+  (1, 'A Wizard of Earthsea', 1968, 4.5, 183, 1, 1),
+  (2, 'The Tombs of Atuan', 1971, 4.2, 163, 1, 1),
+  (3, 'The Murder of Roger Ackroyd', 1926, 4.7, 312, 2, 2),
+  (4, 'Murder on the Orient Express', 1934, 4.5, 256, 2, 2),
+  (5, 'The Witch and the Murder', 1990, 3.25, 64, 1, 2);
+`
+
+// newDB returns a database in a temporary file, which is migrated and holds the fixture.
+func newDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := newEmptyDB(t)
+	_, err := db.Exec(fixture)
+	require.NoError(t, err)
+	return db
+}
+
+// newEmptyDB returns a database in a temporary file, which is migrated but holds no rows.
+func newEmptyDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := Open(filepath.Join(t.TempDir(), "readcommend.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	m, err := NewMigrator(db, zap.NewNop())
+	require.NoError(t, err)
+	_, err = m.Up(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+// ids returns the IDs of the Books, in order.
+func ids(books []entity.Book) []int32 {
+	var out []int32
+	for _, b := range books {
+		out = append(out, b.ID)
+	}
+	return out
+}
+
+func TestQueryCache(t *testing.T) {
+
+	var cache queryCache
+	wizard := cache.parse("wizard")
+	assert.Same(t, wizard, cache.parse("wizard"))
+
+	murder := cache.parse("murder")
+	assert.NotSame(t, wizard, murder)
+	assert.True(t, murder.Matches(textsearch.NewDocument("The Murder of Roger Ackroyd")))
+	assert.Same(t, murder, cache.parse("murder"))
+}
+
+func TestTranslateError(t *testing.T) {
+
+	tests := map[string]struct {
+		err  error
+		kind error
+	}{
+		"database is busy": {
+			err:  sqlite3.Error{Code: sqlite3.ErrBusy},
+			kind: entity.ErrTimeout,
+		},
+		"table is locked": {
+			err:  sqlite3.Error{Code: sqlite3.ErrLocked},
+			kind: entity.ErrTimeout,
+		},
+		"file cannot be opened": {
+			err:  sqlite3.Error{Code: sqlite3.ErrCantOpen},
+			kind: entity.ErrUnavailable,
+		},
+		"constraint fails": {
+			err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey},
+		},
+		"not a sqlite error": {
+			err: errors.New("unable to build SQL query"),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := TranslateError(errors.Wrap(tt.err, "unable to get book"))
+			assert.ErrorIs(t, err, tt.err)
+			if tt.kind == nil {
+				assert.Equal(t, tt.err, errors.Cause(err))
+				return
+			}
+			assert.ErrorIs(t, err, tt.kind)
+		})
+	}
+}
@@ -0,0 +1,325 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	eradriver "github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/encoding"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	sq "github.com/Masterminds/squirrel"
+	"go.uber.org/zap"
+)
+
+// era is a persistence layer model. It has support for nullable SQL fields.
+type era struct {
+	// ID is the primary identifier of an era.
+	ID int32
+
+	// Title is the name of an era.
+	Title string
+
+	// MinYear satisfies interfaces necessary to scan SQL's NULL into a Go type.
+	MinYear encoding.NullInt16
+
+	// MaxYear satisfies interfaces necessary to scan SQL's NULL into a Go type.
+	MaxYear encoding.NullInt16
+}
+
+func (e era) toEraEntity() entity.Era {
+	var (
+		min *int16
+		max *int16
+	)
+
+	if val, _ := e.MinYear.Value(); val == nil {
+		min = nil
+	} else {
+		min = &e.MinYear.Int16
+	}
+
+	if val, _ := e.MaxYear.Value(); val == nil {
+		max = nil
+	} else {
+		max = &e.MaxYear.Int16
+	}
+
+	return entity.Era{
+		ID:      e.ID,
+		Title:   e.Title,
+		MinYear: min,
+		MaxYear: max,
+	}
+}
+
+type eraRepository struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+// NewEraRepository accepts a pointer to a sql.DB type. If the pointer is nil, then an error is returned.
+// Otherwise the pointer is wrapped in an eraRepository and a pointer to it is returned.
+func NewEraRepository(db *sql.DB, logger *zap.Logger) (*eraRepository, error) {
+	if db == nil || logger == nil {
+		return nil, ErrInvalidDependency
+	}
+
+	return &eraRepository{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+// List selects all Eras in the repository. If the query fails or encounters an error while
+// cursing through the result set, then an error is returned.
+func (r *eraRepository) List(ctx context.Context) ([]entity.Era, error) {
+	r.logger.Debug("listing eras from sqlite repository")
+
+	eras, err := listEras(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug(fmt.Sprintf("found %d eras in sqlite repository", len(eras)))
+
+	return eras, nil
+}
+
+// Create inserts an Era with the attributes of the era.WriteInput. A nil bound is stored as NULL. The era table
+// is locked against other writers, and check is called with every Era, before it is inserted. The ID assigned
+// to the Era is returned. If check returns an error or the query fails, then no Era is created and the error
+// is returned.
+func (r *eraRepository) Create(ctx context.Context, params eradriver.WriteInput,
+	check func([]entity.Era) error) (int32, error) {
+	r.logger.Debug("creating era in sqlite repository")
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	id, err := insertEra(ctx, tx, params)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return id, nil
+}
+
+// Update sets the attributes of the Era with the provided ID to those of the era.WriteInput. A nil bound is
+// stored as NULL. The era table is locked against other writers, and check is called with every Era, before it
+// is updated. If no such Era exists, then an error wrapping entity.ErrNotFound is returned. If check returns an
+// error or the query fails, then no Era is changed and the error is returned.
+func (r *eraRepository) Update(ctx context.Context, id int32, params eradriver.WriteInput,
+	check func([]entity.Era) error) error {
+	r.logger.Debug(fmt.Sprintf("updating era %d in sqlite repository", id))
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := updateEra(ctx, tx, id, params); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the Era with the provided ID. The era table is locked against other writers, and check is
+// called with every Era, before it is deleted. If no such Era exists, then an error wrapping entity.ErrNotFound
+// is returned. If check returns an error or the query fails, then no Era is deleted and the error is returned.
+func (r *eraRepository) Delete(ctx context.Context, id int32, check func([]entity.Era) error) error {
+	r.logger.Debug(fmt.Sprintf("deleting era %d from sqlite repository", id))
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Delete("era").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to delete era: %w", err)
+	}
+	if err := affectedOne(res, "era", id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ReplaceAll replaces every Era with those of the era.BulkInput in a single transaction. The era table is
+// locked against other writers, and check is called with every Era, before anything is written. Eras whose IDs
+// are not in the era.BulkInput are then deleted, those whose IDs are in it are updated, and elements without
+// an ID are inserted. The resulting Eras are returned in the order of the era.BulkInput. If check returns an
+// error or any query fails, then no Era is changed and the error is returned.
+func (r *eraRepository) ReplaceAll(ctx context.Context, params []eradriver.BulkInput,
+	check func([]entity.Era) error) ([]entity.Era, error) {
+	r.logger.Debug(fmt.Sprintf("replacing all eras in sqlite repository with %d eras", len(params)))
+
+	tx, err := r.begin(ctx, check)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var kept []int32
+	for _, p := range params {
+		if p.ID != nil {
+			kept = append(kept, *p.ID)
+		}
+	}
+
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Delete("era").
+		Where(sq.NotEq{"id": kept}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+		return nil, fmt.Errorf("unable to delete eras: %w", err)
+	}
+
+	eras := make([]entity.Era, len(params))
+	for i, p := range params {
+		var id int32
+		if p.ID == nil {
+			if id, err = insertEra(ctx, tx, p.WriteInput); err != nil {
+				return nil, err
+			}
+		} else {
+			id = *p.ID
+			if err := updateEra(ctx, tx, id, p.WriteInput); err != nil {
+				return nil, err
+			}
+		}
+		eras[i] = entity.Era{ID: id, Title: *p.Title, MinYear: p.MinYear, MaxYear: p.MaxYear}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("unable to commit transaction: %w", err)
+	}
+	return eras, nil
+}
+
+// begin begins a transaction in which the era table is locked against other writers, and calls check with every
+// Era within it, so that concurrent writes cannot each pass check against the Eras the other is changing. The
+// transaction is returned for the caller to commit or roll back. If check returns an error or any query fails,
+// then the transaction is rolled back and the error is returned.
+func (r *eraRepository) begin(ctx context.Context, check func([]entity.Era) error) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+
+	if err := lockTable(ctx, tx, "era"); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	current, err := listEras(ctx, tx)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	if err := check(current); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// insertEra inserts an Era with the attributes of the era.WriteInput and returns its ID.
+func insertEra(ctx context.Context, db execQueryer, params eradriver.WriteInput) (int32, error) {
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Insert("era").
+		Columns("title", "min_year", "max_year").
+		Values(*params.Title, params.MinYear, params.MaxYear).
+		Suffix("RETURNING id").
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	var id int32
+	if err := db.QueryRowContext(ctx, query, values...).Scan(&id); err != nil {
+		return 0, fmt.Errorf("unable to create era: %w", err)
+	}
+	return id, nil
+}
+
+// updateEra sets the attributes of the Era with the provided ID to those of the era.WriteInput.
+func updateEra(ctx context.Context, db execQueryer, id int32, params eradriver.WriteInput) error {
+	query, values, err := sq.StatementBuilder.PlaceholderFormat(sq.Question).
+		Update("era").
+		Set("title", *params.Title).
+		Set("min_year", params.MinYear).
+		Set("max_year", params.MaxYear).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	res, err := db.ExecContext(ctx, query, values...)
+	if err != nil {
+		return fmt.Errorf("unable to update era: %w", err)
+	}
+
+	return affectedOne(res, "era", id)
+}
+
+// listEras selects all Eras with the execQueryer, such as within a transaction.
+func listEras(ctx context.Context, db execQueryer) ([]entity.Era, error) {
+	query, _, err := sq.StatementBuilder.
+		Select("*").
+		From("era").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("unable to build SQL query: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get eras: %w", err)
+	}
+	defer rows.Close()
+
+	var eras []entity.Era
+
+	// Iterate over result-set, map to entity.Era, and place in resulting slice.
+	for rows.Next() {
+		var era era
+		if err = rows.Scan(&era.ID, &era.Title, &era.MinYear, &era.MaxYear); err != nil {
+			return nil, fmt.Errorf("unable to scan data into era: %w", err)
+		}
+		eras = append(eras, era.toEraEntity())
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return eras, nil
+}
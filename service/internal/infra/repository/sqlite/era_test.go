@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	eradriver "github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEraRepository_ReplaceAll(t *testing.T) {
+
+	// The eras inserted by the migrations.
+	migrated := []entity.Era{
+		{ID: 0, Title: "Any"},
+		{ID: 1, Title: "Classic", MaxYear: int16Ptr(1969)},
+		{ID: 2, Title: "Modern", MinYear: int16Ptr(1970)},
+	}
+
+	tests := map[string]struct {
+		params       []eradriver.BulkInput
+		check        func([]entity.Era) error
+		expect       []entity.Era
+		errAssertion assert.ErrorAssertionFunc
+		list         []entity.Era
+	}{
+		"update, create and delete": {
+			params: []eradriver.BulkInput{
+				{ID: int32Ptr(2), WriteInput: eradriver.WriteInput{Title: stringPtr("Modern"), MinYear: int16Ptr(1950)}},
+				{WriteInput: eradriver.WriteInput{Title: stringPtr("Classic"), MaxYear: int16Ptr(1949)}},
+			},
+			expect: []entity.Era{
+				{ID: 2, Title: "Modern", MinYear: int16Ptr(1950)},
+				{ID: 3, Title: "Classic", MaxYear: int16Ptr(1949)},
+			},
+			errAssertion: assert.NoError,
+			list: []entity.Era{
+				{ID: 2, Title: "Modern", MinYear: int16Ptr(1950)},
+				{ID: 3, Title: "Classic", MaxYear: int16Ptr(1949)},
+			},
+		},
+		"missing era changes nothing": {
+			params: []eradriver.BulkInput{
+				{WriteInput: eradriver.WriteInput{Title: stringPtr("Any")}},
+				{ID: int32Ptr(42), WriteInput: eradriver.WriteInput{Title: stringPtr("Modern")}},
+			},
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrNotFound)
+			},
+			list: migrated,
+		},
+		"rejected eras change nothing": {
+			params: []eradriver.BulkInput{
+				{WriteInput: eradriver.WriteInput{Title: stringPtr("Any")}},
+			},
+			check: func([]entity.Era) error { return entity.ErrConflict },
+			errAssertion: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, entity.ErrConflict)
+			},
+			list: migrated,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewEraRepository(newDB(t), zap.NewNop())
+			require.NoError(t, err)
+
+			var checked []entity.Era
+			check := func(eras []entity.Era) error {
+				checked = eras
+				if tt.check != nil {
+					return tt.check(eras)
+				}
+				return nil
+			}
+
+			actual, err := r.ReplaceAll(context.Background(), tt.params, check)
+			assert.Equal(t, migrated, checked)
+			tt.errAssertion(t, err)
+			assert.Equal(t, tt.expect, actual)
+
+			list, err := r.List(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.list, list)
+		})
+	}
+}
+
+func TestEraRepository_ReplaceAll_Concurrent(t *testing.T) {
+
+	ctx := context.Background()
+	r, err := NewEraRepository(newDB(t), zap.NewNop())
+	require.NoError(t, err)
+
+	onlyAny := []eradriver.BulkInput{{ID: int32Ptr(0), WriteInput: eradriver.WriteInput{Title: stringPtr("Any")}}}
+
+	checking, release, first := make(chan struct{}), make(chan struct{}), make(chan error)
+	go func() {
+		_, err := r.ReplaceAll(ctx, onlyAny, func([]entity.Era) error {
+			close(checking)
+			<-release
+			return nil
+		})
+		first <- err
+	}()
+	<-checking
+
+	checked, second := make(chan []entity.Era, 1), make(chan error)
+	go func() {
+		_, err := r.ReplaceAll(ctx, onlyAny, func(eras []entity.Era) error {
+			checked <- eras
+			return nil
+		})
+		second <- err
+	}()
+
+	select {
+	case <-checked:
+		t.Fatal("the second replacement was checked while the first was in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+	assert.Equal(t, []entity.Era{{ID: 0, Title: "Any"}}, <-checked)
+}
+
+func TestEraRepository_Create_Concurrent(t *testing.T) {
+
+	ctx := context.Background()
+	r, err := NewEraRepository(newDB(t), zap.NewNop())
+	require.NoError(t, err)
+
+	future := eradriver.WriteInput{Title: stringPtr("Future"), MinYear: int16Ptr(2100)}
+
+	checking, release, first := make(chan struct{}), make(chan struct{}), make(chan error)
+	go func() {
+		_, err := r.Create(ctx, future, func([]entity.Era) error {
+			close(checking)
+			<-release
+			return nil
+		})
+		first <- err
+	}()
+	<-checking
+
+	checked, second := make(chan []entity.Era, 1), make(chan error)
+	go func() {
+		_, err := r.Create(ctx, future, func(eras []entity.Era) error {
+			checked <- eras
+			return entity.ErrConflict
+		})
+		second <- err
+	}()
+
+	select {
+	case <-checked:
+		t.Fatal("the second creation was checked while the first was in progress")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	require.NoError(t, <-first)
+	assert.ErrorIs(t, <-second, entity.ErrConflict)
+	assert.Contains(t, <-checked, entity.Era{ID: 3, Title: "Future", MinYear: int16Ptr(2100)})
+}
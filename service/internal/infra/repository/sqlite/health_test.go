@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/booksql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTables(t *testing.T) {
+	db := newEmptyDB(t)
+	assert.NoError(t, booksql.CheckTables(context.Background(), db))
+
+	_, err := db.Exec("DROP TABLE book")
+	require.NoError(t, err)
+	err = booksql.CheckTables(context.Background(), db)
+	assert.EqualError(t, err, "unable to query tables: book")
+
+	// Tables which another connection drops are missing as well, although this one cached the schema before.
+	var file string
+	require.NoError(t, db.QueryRow("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&file))
+	other, err := Open(file)
+	require.NoError(t, err)
+	defer other.Close()
+	_, err = other.Exec("ALTER TABLE size RENAME TO size_old")
+	require.NoError(t, err)
+	err = booksql.CheckTables(context.Background(), db)
+	assert.EqualError(t, err, "unable to query tables: size, book")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, booksql.CheckTables(ctx, db), context.Canceled)
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/LeviMatus/readcommend/service/internal/infra/repository/migration"
+	"go.uber.org/zap"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+//go:embed seed.sql
+var seed string
+
+// migrationDialect keeps track of applied migrations in the schema_migration table. SQLite locks the whole
+// database while a transaction writes to it, so no other lock is taken.
+var migrationDialect = migration.Dialect{
+	CreateTable: `CREATE TABLE IF NOT EXISTS ` + migration.VersionTable + ` (
+  version INTEGER PRIMARY KEY,
+  name TEXT NOT NULL,
+  applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+	TableExists: "SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?",
+	Record:      "INSERT INTO " + migration.VersionTable + " (version, name) VALUES (?, ?)",
+	Forget:      "DELETE FROM " + migration.VersionTable + " WHERE version = ?",
+}
+
+// SeedSQL returns the SQL which seeds the database with sample genres, authors and books.
+func SeedSQL() string {
+	return seed
+}
+
+// Migrations returns every migration.Migration embedded in the binary, in ascending order of Version. They
+// create the same schema as those of the postgres package, and share their versions.
+func Migrations() ([]migration.Migration, error) {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return migration.Read(files)
+}
+
+// NewMigrator creates a migration.Migrator which applies the Migrations embedded in the binary to the database.
+// The database connection cannot be nil.
+func NewMigrator(db *sql.DB, logger *zap.Logger) (*migration.Migrator, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	return migration.New(db, logger, migrationDialect, migrations, seed)
+}
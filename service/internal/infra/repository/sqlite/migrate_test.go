@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestMigrations(t *testing.T) {
+
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.Version, "migration versions should be consecutive")
+		assert.NotEmpty(t, m.Up)
+		assert.NotEmpty(t, m.Down)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+
+	ctx := context.Background()
+
+	db, err := Open(filepath.Join(t.TempDir(), "readcommend.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	m, err := NewMigrator(db, zap.NewNop())
+	require.NoError(t, err)
+
+	// Seeding requires an up to date schema.
+	assert.Error(t, m.Seed(ctx))
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, m.SchemaVersion())
+
+	version, err := m.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, m.SchemaVersion(), version)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	for _, s := range statuses {
+		assert.NotNil(t, s.AppliedAt)
+	}
+
+	// Seeding may be repeated.
+	require.NoError(t, m.Seed(ctx))
+	require.NoError(t, m.Seed(ctx))
+
+	var books int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM book").Scan(&books))
+	assert.Equal(t, 58, books)
+
+	// Genres created after seeding are assigned IDs following the seeded ones.
+	r, err := NewGenreRepository(db, zap.NewNop())
+	require.NoError(t, err)
+	id, err := r.Create(ctx, genre.WriteInput{Title: stringPtr("Horror")})
+	require.NoError(t, err)
+	assert.Equal(t, int32(9), id)
+
+	_, err = m.To(ctx, 0)
+	require.NoError(t, err)
+	version, err = m.Version(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, version)
+}
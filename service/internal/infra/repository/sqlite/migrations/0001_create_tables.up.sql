@@ -0,0 +1,45 @@
+CREATE TABLE era
+(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  min_year SMALLINT,
+  max_year SMALLINT
+);
+
+CREATE TABLE size
+(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  min_pages SMALLINT,
+  max_pages SMALLINT
+);
+
+CREATE TABLE genre
+(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL
+);
+
+CREATE TABLE author
+(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  first_name TEXT NOT NULL,
+  last_name TEXT NOT NULL
+);
+
+CREATE TABLE book
+(
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  title TEXT NOT NULL,
+  year_published SMALLINT NOT NULL,
+  rating NUMERIC(3, 2) NOT NULL,
+  pages SMALLINT NOT NULL,
+  genre_id INTEGER REFERENCES genre(id),
+  author_id INTEGER REFERENCES author(id)
+);
+
+CREATE INDEX book_published ON book (year_published);
+CREATE INDEX book_rating ON book (rating);
+CREATE INDEX book_pages ON book (pages);
+CREATE INDEX book_genre_id ON book (genre_id);
+CREATE INDEX book_author_id ON book (author_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS book_rating_id;
@@ -0,0 +1,3 @@
+-- Titles are sorted by the title_sort_key function, which is registered by the application rather than built
+-- into SQLite, so it is not indexed: the database file stays writable by other SQLite clients.
+CREATE INDEX book_rating_id ON book (rating DESC, id);
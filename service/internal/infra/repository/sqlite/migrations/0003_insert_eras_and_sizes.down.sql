@@ -0,0 +1,2 @@
+DELETE FROM size WHERE id BETWEEN 0 AND 6;
+DELETE FROM era WHERE id BETWEEN 0 AND 2;
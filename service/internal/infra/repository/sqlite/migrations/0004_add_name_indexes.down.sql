@@ -0,0 +1,3 @@
+DROP INDEX book_author_title;
+DROP INDEX genre_title;
+DROP INDEX author_name;
@@ -0,0 +1,5 @@
+-- Imported catalogs name their authors and genres, which are matched against these indexes, and books are
+-- upserted by their title and author.
+CREATE INDEX author_name ON author (last_name, first_name);
+CREATE INDEX genre_title ON genre (title);
+CREATE INDEX book_author_title ON book (author_id, title);
@@ -0,0 +1,390 @@
+// Package textsearch matches book.SearchInput queries against text as the postgres repository does, for the
+// repositories whose databases lack full-text search. A Document, which is the title of a Book and the name of
+// its author, matches a Query through full-text search, or if the query's trigram word similarity to the
+// Document reaches wordSimilarityThreshold. Its relevance is the sum of its full-text rank and that similarity,
+// rounded to six decimal places.
+//
+// The full-text search follows websearch_to_tsquery and ts_rank with the 'english' configuration. Words are
+// reduced to lexemes with a light stemmer rather than the Snowball stemmer used by Postgres, so a few words,
+// and therefore relevances, differ from those of the postgres repository.
+package textsearch
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// wordSimilarityThreshold is the default pg_trgm.word_similarity_threshold, above which the <% operator matches.
+const wordSimilarityThreshold = 0.6
+
+// Query is a parsed search query.
+type Query struct {
+	// groups are alternatives, separated by "or": a Document matches if it matches every term of any group.
+	groups [][]term
+
+	// lexemes are the distinct lexemes of every term which is not negated. They are the operands of the query
+	// which are ranked.
+	lexemes []string
+
+	// and is true if the query has no alternatives, in which case it is ranked as a conjunction.
+	and bool
+
+	// trigrams are the distinct trigrams of the raw query.
+	trigrams map[string]struct{}
+}
+
+// term is a single word, or a quoted phrase, of a query.
+type term struct {
+	// lexemes of the term, in order, along with their offsets from the first one.
+	lexemes []string
+	offsets []int
+
+	// negated terms, which are prefixed with "-", exclude documents that contain them.
+	negated bool
+}
+
+// Document is the text a Query is matched against.
+type Document struct {
+	// positions of every lexeme, counted in words from 1 as they are by to_tsvector.
+	positions map[string][]int
+
+	// trigrams of the text, in order.
+	trigrams []string
+}
+
+// Parse parses a query as websearch_to_tsquery does: unquoted words and quoted phrases must all match,
+// "or" separates alternatives and a leading "-" negates a word or phrase.
+func Parse(query string) *Query {
+	q := &Query{groups: [][]term{nil}, trigrams: map[string]struct{}{}}
+	for _, t := range trigrams(query) {
+		q.trigrams[t] = struct{}{}
+	}
+
+	negate := false
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimSpace(rest) {
+		var text string
+		switch {
+		case rest[0] == '"':
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				text, rest = rest[1:], ""
+			} else {
+				text, rest = rest[1:end+1], rest[end+2:]
+			}
+		case rest[0] == '-':
+			negate, rest = true, rest[1:]
+			continue
+		default:
+			end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '"' })
+			if end < 0 {
+				end = len(rest)
+			}
+			text, rest = rest[:end], rest[end:]
+		}
+
+		if !negate && strings.EqualFold(text, "or") {
+			q.groups = append(q.groups, nil)
+			continue
+		}
+
+		t := term{negated: negate}
+		ls := lexemes(text)
+		for _, l := range ls {
+			t.lexemes = append(t.lexemes, l.lexeme)
+			t.offsets = append(t.offsets, l.position-ls[0].position)
+		}
+		negate = false
+
+		// Terms made only of stop words are dropped.
+		if len(t.lexemes) == 0 {
+			continue
+		}
+		last := len(q.groups) - 1
+		q.groups[last] = append(q.groups[last], t)
+		if !t.negated {
+			for _, l := range t.lexemes {
+				q.addLexeme(l)
+			}
+		}
+	}
+
+	// Alternatives without any terms are dropped, as "or" at the start or end of a query is.
+	groups := q.groups[:0]
+	for _, g := range q.groups {
+		if len(g) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	q.groups = groups
+	q.and = len(groups) == 1
+	return q
+}
+
+// addLexeme adds the lexeme to the ranked operands of the query, unless it already is one.
+func (q *Query) addLexeme(l string) {
+	for _, existing := range q.lexemes {
+		if existing == l {
+			return
+		}
+	}
+	q.lexemes = append(q.lexemes, l)
+}
+
+// NewDocument prepares the text to be matched against queries.
+func NewDocument(text string) Document {
+	d := Document{positions: map[string][]int{}, trigrams: trigrams(text)}
+	for _, l := range lexemes(text) {
+		d.positions[l.lexeme] = append(d.positions[l.lexeme], l.position)
+	}
+	return d
+}
+
+// Matches reports whether the Document matches the Query, either through full-text search or trigram word
+// similarity.
+func (q *Query) Matches(d Document) bool {
+	return q.fullText(d) || q.similarity(d) >= wordSimilarityThreshold
+}
+
+// Relevance ranks how well the Document matches the Query.
+func (q *Query) Relevance(d Document) float64 {
+	return math.Round((float64(q.rank(d))+q.similarity(d))*1e6) / 1e6
+}
+
+// fullText reports whether the Document matches every term of any alternative of the query.
+func (q *Query) fullText(d Document) bool {
+	for _, group := range q.groups {
+		matched := true
+		for _, t := range group {
+			if d.contains(t) == t.negated {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether the Document contains the lexemes of the term at their offsets from one another.
+func (d Document) contains(t term) bool {
+	for _, start := range d.positions[t.lexemes[0]] {
+		found := true
+		for i := 1; i < len(t.lexemes); i++ {
+			if !containsInt(d.positions[t.lexemes[i]], start+t.offsets[i]) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// rank follows ts_rank with its default weights and normalization, under which every lexeme of a document
+// weighs 0.1. If the query is a conjunction of several lexemes, then it is ranked by how close together they
+// are. Otherwise, it is ranked by how often each lexeme occurs.
+func (q *Query) rank(d Document) float32 {
+	const weight = 0.1
+
+	if q.and && len(q.lexemes) > 1 {
+		res := float64(-1)
+		for i, l := range q.lexemes {
+			for _, k := range q.lexemes[:i] {
+				for _, p := range d.positions[l] {
+					for _, o := range d.positions[k] {
+						dist := p - o
+						if dist < 0 {
+							dist = -dist
+						}
+						if dist == 0 {
+							continue
+						}
+						cur := math.Sqrt(weight * weight * wordDistance(dist))
+						if res < 0 {
+							res = cur
+						} else {
+							res = 1 - (1-res)*(1-cur)
+						}
+					}
+				}
+			}
+		}
+		if res < 0 {
+			res = 1e-20
+		}
+		return float32(res)
+	}
+
+	if len(q.lexemes) == 0 {
+		return 0
+	}
+	var res float64
+	for _, l := range q.lexemes {
+		positions := d.positions[l]
+		if len(positions) == 0 {
+			continue
+		}
+		// Later occurrences of a lexeme weigh less and less.
+		var sum float64
+		for j := range positions {
+			sum += weight / float64((j+1)*(j+1))
+		}
+		res += sum / 1.64493406685
+	}
+	return float32(res / float64(len(q.lexemes)))
+}
+
+// wordDistance weighs the distance between two lexemes of a conjunction, as ts_rank does.
+func wordDistance(dist int) float64 {
+	if dist > 100 {
+		return 1e-30
+	}
+	return 1.0 / (1.005 + 0.05*math.Exp(float64(dist)/1.5-2))
+}
+
+// similarity follows pg_trgm's word_similarity: the greatest similarity between the trigrams of the query and
+// those of any continuous extent of the document's trigrams.
+func (q *Query) similarity(d Document) float64 {
+	if len(q.trigrams) == 0 {
+		return 0
+	}
+
+	var best float64
+	for i := range d.trigrams {
+		if _, ok := q.trigrams[d.trigrams[i]]; !ok {
+			continue
+		}
+		extent := map[string]struct{}{}
+		shared := 0
+		for j := i; j < len(d.trigrams); j++ {
+			t := d.trigrams[j]
+			if _, seen := extent[t]; !seen {
+				extent[t] = struct{}{}
+				if _, ok := q.trigrams[t]; ok {
+					shared++
+				}
+			}
+			sim := float64(shared) / float64(len(q.trigrams)+len(extent)-shared)
+			if sim > best {
+				best = sim
+			}
+		}
+	}
+	return best
+}
+
+// trigrams splits the text into lower-cased words of letters and digits, and returns the trigrams of each word
+// in order. As in pg_trgm, each word is padded with two spaces before it and one after it.
+func trigrams(text string) []string {
+	var out []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), notWordRune) {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			out = append(out, string(padded[i:i+3]))
+		}
+	}
+	return out
+}
+
+// lexeme is a normalized word and its position in the text, counted from 1.
+type lexeme struct {
+	lexeme   string
+	position int
+}
+
+// lexemes splits the text into words, as to_tsvector does, and normalizes them. Stop words are dropped but
+// still counted, so that the positions of the remaining lexemes are those of their words.
+func lexemes(text string) []lexeme {
+	var out []lexeme
+	for i, w := range strings.FieldsFunc(strings.ToLower(text), notWordRune) {
+		if _, ok := stopWords[w]; ok {
+			continue
+		}
+		out = append(out, lexeme{lexeme: stem(w), position: i + 1})
+	}
+	return out
+}
+
+// notWordRune reports whether the rune separates words.
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// stem reduces a word to its lexeme by removing common English suffixes. It is a light stand-in for the
+// Snowball English stemmer, which conflates plurals and most verb forms in the same way.
+func stem(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"):
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed"} {
+		if base := strings.TrimSuffix(w, suffix); base != w && len(base) >= 3 && hasVowel(base) {
+			w = base
+			// A doubled consonant left by the suffix, as in "running", is undoubled.
+			if n := len(w); w[n-1] == w[n-2] && !strings.ContainsRune("aeiouylsz", rune(w[n-1])) {
+				w = w[:n-1]
+			}
+			break
+		}
+	}
+
+	if n := len(w); n > 2 && w[n-1] == 'y' && !strings.ContainsRune("aeiou", rune(w[n-2])) {
+		w = w[:n-1] + "i"
+	}
+	return w
+}
+
+// hasVowel reports whether the word contains a vowel.
+func hasVowel(w string) bool {
+	return strings.ContainsAny(w, "aeiouy")
+}
+
+// containsInt reports whether the slice contains the value.
+func containsInt(values []int, v int) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// leadingArticle matches the article which TitleSortKey removes from the start of a title.
+var leadingArticle = regexp.MustCompile(`^(the|an|a)\s+`)
+
+// TitleSortKey computes the library-style sort key of a title, as the postgres repository does: the title is
+// lower-cased and has any leading article ("the", "a" or "an") removed.
+func TitleSortKey(title string) string {
+	return leadingArticle.ReplaceAllString(strings.ToLower(title), "")
+}
+
+// stopWords are the words of Postgres' english.stop, which are not indexed by full-text search.
+var stopWords = func() map[string]struct{} {
+	words := map[string]struct{}{}
+	for _, w := range strings.Fields(`i me my myself we our ours ourselves you your yours yourself yourselves he him
+		his himself she her hers herself it its itself they them their theirs themselves what which who whom this
+		that these those am is are was were be been being have has had having do does did doing a an the and but
+		if or because as until while of at by for with about against between into through during before after above
+		below to from up down in out on off over under again further then once here there when where why how all
+		any both each few more most other some such no nor not only own same so than too very s t can will just
+		don should now`) {
+		words[w] = struct{}{}
+	}
+	return words
+}()
@@ -0,0 +1,73 @@
+package textsearch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextQuery_FullText(t *testing.T) {
+
+	tests := map[string]struct {
+		query  string
+		text   string
+		expect bool
+	}{
+		"every word must match":          {query: "wizard sea", text: "A Wizard of Earthsea", expect: false},
+		"words are stemmed":              {query: "wizards", text: "A Wizard of Earthsea", expect: true},
+		"alternatives":                   {query: "dragon or wizard", text: "A Wizard of Earthsea", expect: true},
+		"negated word":                   {query: "wizard -earthsea", text: "A Wizard of Earthsea", expect: false},
+		"phrase in order":                {query: `"wizard of earthsea"`, text: "A Wizard of Earthsea", expect: true},
+		"phrase out of order":            {query: `"earthsea of wizard"`, text: "A Wizard of Earthsea", expect: false},
+		"unterminated phrase":            {query: `"wizard of`, text: "A Wizard of Earthsea", expect: true},
+		"or at the start is ignored":     {query: "or wizard", text: "A Wizard of Earthsea", expect: true},
+		"punctuation separates words":    {query: "earth-sea", text: "A Wizard of Earthsea", expect: false},
+		"case is ignored":                {query: "EARTHSEA", text: "A Wizard of Earthsea", expect: true},
+		"doubled consonants are undone":  {query: "running", text: "The Run", expect: true},
+		"stop words keep their position": {query: `"tombs atuan"`, text: "The Tombs of Atuan", expect: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, Parse(tt.query).fullText(NewDocument(tt.text)))
+		})
+	}
+}
+
+func TestTextQuery_Matches(t *testing.T) {
+
+	tests := map[string]struct {
+		query  string
+		text   string
+		expect bool
+	}{
+		"full-text match":     {query: "wizards", text: "A Wizard of Earthsea", expect: true},
+		"misspelled word":     {query: "wizzard", text: "A Wizard of Earthsea", expect: true},
+		"similar phrase":      {query: "earthsea wizard", text: "A Wizard of Earthsea", expect: true},
+		"dissimilar word":     {query: "warlock", text: "A Wizard of Earthsea", expect: false},
+		"dissimilar stopword": {query: "which", text: "The Witch and the Murder", expect: false},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, Parse(tt.query).Matches(NewDocument(tt.text)))
+		})
+	}
+}
+
+func TestTextQuery_Relevance(t *testing.T) {
+
+	q := Parse("murder")
+	once := q.Relevance(NewDocument("Murder on the Orient Express Agatha Christie"))
+	twice := q.Relevance(NewDocument("The Witch and the Murder of Murder"))
+	none := q.Relevance(NewDocument("A Wizard of Earthsea"))
+
+	assert.Greater(t, twice, once)
+	assert.Greater(t, once, none)
+
+	// Words of a conjunction rank higher the closer together they are.
+	q = Parse("orient christie")
+	near := q.Relevance(NewDocument("Orient Christie"))
+	far := q.Relevance(NewDocument("Orient Express on a long winter night by Christie"))
+	assert.Greater(t, near, far)
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// unmatchedRoute labels the requests which matched no route, so that every unknown path shares one series.
+const unmatchedRoute = "unmatched"
+
+// HTTP measures the requests served by a chi router: how many were served for each route and status, and how
+// long they took. Routes are labelled by their pattern, such as /api/v1/books/{id}, rather than by their path,
+// so that the number of series is bounded by the number of routes.
+type HTTP struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTP creates the HTTP metrics and registers them with the registerer.
+func NewHTTP(reg prometheus.Registerer) (*HTTP, error) {
+	m := HTTP{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "The number of HTTP requests served, by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "How long HTTP requests took to serve, by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+	}
+	if err := register(reg, m.requests, m.duration); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Middleware measures every request served by the next handler. It must be used by the root chi router, so
+// that the pattern of the route is complete once the request has been served, and before any middleware which
+// recovers from panics, so that it sees the 500s which that middleware writes. Requests which write no status
+// are counted as 200s, as net/http serves them.
+func (m *HTTP) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.requests.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		m.duration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverer writes a 500 for requests which panic, as middleware.Recoverer does without logging their stack.
+func recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if recover() != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestHTTP_Middleware(t *testing.T) {
+	m, err := NewHTTP(prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	books := chi.NewRouter()
+	books.Get("/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "0" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	books.Post("/", func(http.ResponseWriter, *http.Request) { panic("failed") })
+
+	router := chi.NewRouter()
+	router.Use(m.Middleware, recoverer)
+	router.Route("/api", func(r chi.Router) {
+		r.Mount("/books", books)
+	})
+
+	for _, req := range []struct{ method, path string }{
+		{http.MethodGet, "/api/books/1"},
+		{http.MethodGet, "/api/books/2"},
+		{http.MethodGet, "/api/books/0"},
+		{http.MethodPost, "/api/books"},
+		{http.MethodGet, "/missing/1"},
+		{http.MethodGet, "/missing/2"},
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.path, nil))
+	}
+
+	tests := map[string]struct {
+		method string
+		route  string
+		status string
+		count  float64
+	}{
+		"paths of a route share its pattern": {
+			method: http.MethodGet,
+			route:  "/api/books/{id}",
+			status: "200",
+			count:  2,
+		},
+		"statuses are counted apart": {
+			method: http.MethodGet,
+			route:  "/api/books/{id}",
+			status: "404",
+			count:  1,
+		},
+		"panics are counted as 500s": {
+			method: http.MethodPost,
+			route:  "/api/books/",
+			status: "500",
+			count:  1,
+		},
+		"unmatched paths share a route": {
+			method: http.MethodGet,
+			route:  unmatchedRoute,
+			status: "404",
+			count:  2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.count, testutil.ToFloat64(m.requests.WithLabelValues(tt.method, tt.route, tt.status)))
+		})
+	}
+
+	// Every request was observed by the histogram of its route, and no other series were created.
+	assert.Equal(t, 4, testutil.CollectAndCount(m.requests))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.duration))
+}
+
+func TestNewHTTP(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := NewHTTP(reg)
+	require.NoError(t, err)
+
+	// The metrics can only be registered once.
+	_, err = NewHTTP(reg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+// Package metrics measures the service in the Prometheus format: the requests it serves, the queries of its
+// repositories and, through the collectors of the client library, its database pool and runtime. Every metric is
+// registered with a Registry which the caller creates, rather than the global one, so that it can be served
+// wherever the caller chooses.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the name of every metric of the service.
+const namespace = "readcommend"
+
+// NewRegistry creates a prometheus.Registry, which holds the metrics of the Go runtime and of the process.
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// Handler serves the metrics of the gatherer in the Prometheus text format.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// register registers every collector with the registerer, and returns the first error.
+func register(reg prometheus.Registerer, cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterDBStats registers gauges and counters of the sql.DBStats of the database's pool with the registerer,
+// such as its open, in-use and idle connections and how long queries waited for one. The name labels them.
+func RegisterDBStats(reg prometheus.Registerer, db *sql.DB, name string) error {
+	return reg.Register(collectors.NewDBStatsCollector(db, name))
+}
@@ -0,0 +1,267 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Repositories measures the queries of repositories: how long every method of each repository took, and how
+// many of them failed. Errors caused by the request, such as entity.ErrNotFound, are not counted as failures.
+// Repositories wrap a repository of each entity in a decorator, which measures it.
+type Repositories struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewRepositories creates the repository metrics and registers them with the registerer.
+func NewRepositories(reg prometheus.Registerer) (*Repositories, error) {
+	m := Repositories{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "How long the methods of repositories took, by repository and method.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 16),
+		}, []string{"repository", "method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_errors_total",
+			Help:      "The number of calls to the methods of repositories which failed, by repository and method.",
+		}, []string{"repository", "method"}),
+	}
+	if err := register(reg, m.duration, m.errors); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// observer measures the methods of one repository.
+type observer struct {
+	metrics    *Repositories
+	repository string
+}
+
+// observe measures a call to the method, which started at the time, once it has returned the error that errp
+// points to. It is deferred by each method of a decorator, which names its error result.
+func (o observer) observe(method string, start time.Time, errp *error) {
+	o.metrics.duration.WithLabelValues(o.repository, method).Observe(time.Since(start).Seconds())
+	if err := *errp; err != nil && !entity.IsClientError(err) {
+		o.metrics.errors.WithLabelValues(o.repository, method).Inc()
+	}
+}
+
+// Books wraps the book.Repository in a decorator which measures its methods. The duration of Export includes
+// the calls to its callback, which are part of the query while its rows are streamed.
+func (m *Repositories) Books(repo book.Repository) book.Repository {
+	return bookRepository{next: repo, observer: observer{metrics: m, repository: "book"}}
+}
+
+// Authors wraps the author.Repository in a decorator which measures its methods.
+func (m *Repositories) Authors(repo author.Repository) author.Repository {
+	return authorRepository{next: repo, observer: observer{metrics: m, repository: "author"}}
+}
+
+// Genres wraps the genre.Repository in a decorator which measures its methods.
+func (m *Repositories) Genres(repo genre.Repository) genre.Repository {
+	return genreRepository{next: repo, observer: observer{metrics: m, repository: "genre"}}
+}
+
+// Eras wraps the era.Repository in a decorator which measures its methods.
+func (m *Repositories) Eras(repo era.Repository) era.Repository {
+	return eraRepository{next: repo, observer: observer{metrics: m, repository: "era"}}
+}
+
+// Sizes wraps the size.Repository in a decorator which measures its methods.
+func (m *Repositories) Sizes(repo size.Repository) size.Repository {
+	return sizeRepository{next: repo, observer: observer{metrics: m, repository: "size"}}
+}
+
+type bookRepository struct {
+	next book.Repository
+	observer
+}
+
+func (r bookRepository) Search(ctx context.Context, params book.SearchInput) (_ []entity.Book, err error) {
+	defer r.observe("Search", time.Now(), &err)
+	return r.next.Search(ctx, params)
+}
+
+func (r bookRepository) Get(ctx context.Context, id int32) (_ entity.Book, err error) {
+	defer r.observe("Get", time.Now(), &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r bookRepository) Create(ctx context.Context, params book.WriteInput) (_ int32, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r bookRepository) Update(ctx context.Context, id int32, params book.WriteInput) (err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r bookRepository) Delete(ctx context.Context, id int32) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r bookRepository) Facets(ctx context.Context, params book.FacetInput) (_ book.Facets, err error) {
+	defer r.observe("Facets", time.Now(), &err)
+	return r.next.Facets(ctx, params)
+}
+
+func (r bookRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) (err error) {
+	defer r.observe("Export", time.Now(), &err)
+	return r.next.Export(ctx, params, each)
+}
+
+type authorRepository struct {
+	next author.Repository
+	observer
+}
+
+func (r authorRepository) List(ctx context.Context) (_ []entity.Author, err error) {
+	defer r.observe("List", time.Now(), &err)
+	return r.next.List(ctx)
+}
+
+func (r authorRepository) Get(ctx context.Context, id int32) (_ entity.Author, err error) {
+	defer r.observe("Get", time.Now(), &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r authorRepository) Stats(ctx context.Context, id int32) (_ author.Stats, err error) {
+	defer r.observe("Stats", time.Now(), &err)
+	return r.next.Stats(ctx, id)
+}
+
+func (r authorRepository) TopBooks(ctx context.Context, id int32, limit uint64) (_ []entity.Book, err error) {
+	defer r.observe("TopBooks", time.Now(), &err)
+	return r.next.TopBooks(ctx, id, limit)
+}
+
+func (r authorRepository) Create(ctx context.Context, params author.WriteInput) (_ int32, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r authorRepository) Update(ctx context.Context, id int32, params author.WriteInput) (err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r authorRepository) Delete(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id, reassignTo)
+}
+
+type genreRepository struct {
+	next genre.Repository
+	observer
+}
+
+func (r genreRepository) List(ctx context.Context) (_ []entity.Genre, err error) {
+	defer r.observe("List", time.Now(), &err)
+	return r.next.List(ctx)
+}
+
+func (r genreRepository) Get(ctx context.Context, id int32) (_ entity.Genre, err error) {
+	defer r.observe("Get", time.Now(), &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r genreRepository) Stats(ctx context.Context, id int32) (_ genre.Stats, err error) {
+	defer r.observe("Stats", time.Now(), &err)
+	return r.next.Stats(ctx, id)
+}
+
+func (r genreRepository) Create(ctx context.Context, params genre.WriteInput) (_ int32, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r genreRepository) Update(ctx context.Context, id int32, params genre.WriteInput) (err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r genreRepository) Delete(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id, reassignTo)
+}
+
+type eraRepository struct {
+	next era.Repository
+	observer
+}
+
+func (r eraRepository) List(ctx context.Context) (_ []entity.Era, err error) {
+	defer r.observe("List", time.Now(), &err)
+	return r.next.List(ctx)
+}
+
+func (r eraRepository) Create(ctx context.Context, params era.WriteInput,
+	check func([]entity.Era) error) (_ int32, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, params, check)
+}
+
+func (r eraRepository) Update(ctx context.Context, id int32, params era.WriteInput,
+	check func([]entity.Era) error) (err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, id, params, check)
+}
+
+func (r eraRepository) Delete(ctx context.Context, id int32, check func([]entity.Era) error) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id, check)
+}
+
+func (r eraRepository) ReplaceAll(ctx context.Context, params []era.BulkInput,
+	check func([]entity.Era) error) (_ []entity.Era, err error) {
+	defer r.observe("ReplaceAll", time.Now(), &err)
+	return r.next.ReplaceAll(ctx, params, check)
+}
+
+type sizeRepository struct {
+	next size.Repository
+	observer
+}
+
+func (r sizeRepository) List(ctx context.Context) (_ []entity.Size, err error) {
+	defer r.observe("List", time.Now(), &err)
+	return r.next.List(ctx)
+}
+
+func (r sizeRepository) Create(ctx context.Context, params size.WriteInput,
+	check func([]entity.Size) error) (_ int32, err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, params, check)
+}
+
+func (r sizeRepository) Update(ctx context.Context, id int32, params size.WriteInput,
+	check func([]entity.Size) error) (err error) {
+	defer r.observe("Update", time.Now(), &err)
+	return r.next.Update(ctx, id, params, check)
+}
+
+func (r sizeRepository) Delete(ctx context.Context, id int32, check func([]entity.Size) error) (err error) {
+	defer r.observe("Delete", time.Now(), &err)
+	return r.next.Delete(ctx, id, check)
+}
+
+func (r sizeRepository) ReplaceAll(ctx context.Context, params []size.BulkInput,
+	check func([]entity.Size) error) (_ []entity.Size, err error) {
+	defer r.observe("ReplaceAll", time.Now(), &err)
+	return r.next.ReplaceAll(ctx, params, check)
+}
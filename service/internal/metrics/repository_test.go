@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getBookRepository is a book.Repository whose Get returns its book and error, and which implements no other
+// method.
+type getBookRepository struct {
+	book.Repository
+	book entity.Book
+	err  error
+}
+
+func (r getBookRepository) Get(context.Context, int32) (entity.Book, error) {
+	return r.book, r.err
+}
+
+func TestRepositories_Books(t *testing.T) {
+
+	tests := map[string]struct {
+		err    error
+		errors float64
+	}{
+		"query succeeds": {},
+		"entity does not exist": {
+			err: errors.Wrap(entity.ErrNotFound, "book 1"),
+		},
+		"query fails": {
+			err:    errors.New("connection refused"),
+			errors: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := NewRepositories(prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			want := entity.Book{ID: 1}
+			got, err := m.Books(getBookRepository{book: want, err: tt.err}).Get(context.Background(), 1)
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, want, got)
+
+			assert.Equal(t, 1, testutil.CollectAndCount(m.duration, "readcommend_repository_query_duration_seconds"))
+			assert.Equal(t, tt.errors, testutil.ToFloat64(m.errors.WithLabelValues("book", "Get")))
+		})
+	}
+}
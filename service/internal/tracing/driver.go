@@ -0,0 +1,225 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+)
+
+// BookDriver wraps the book.Driver in a decorator which traces each of its methods in a span.
+func BookDriver(d book.Driver) book.Driver {
+	return bookDriver{next: d}
+}
+
+// AuthorDriver wraps the author.Driver in a decorator which traces each of its methods in a span.
+func AuthorDriver(d author.Driver) author.Driver {
+	return authorDriver{next: d}
+}
+
+// GenreDriver wraps the genre.Driver in a decorator which traces each of its methods in a span.
+func GenreDriver(d genre.Driver) genre.Driver {
+	return genreDriver{next: d}
+}
+
+// EraDriver wraps the era.Driver in a decorator which traces each of its methods in a span.
+func EraDriver(d era.Driver) era.Driver {
+	return eraDriver{next: d}
+}
+
+// SizeDriver wraps the size.Driver in a decorator which traces each of its methods in a span.
+func SizeDriver(d size.Driver) size.Driver {
+	return sizeDriver{next: d}
+}
+
+type bookDriver struct {
+	next book.Driver
+}
+
+func (d bookDriver) SearchBooks(ctx context.Context, params book.SearchInput) (_ book.Page, err error) {
+	ctx, span := start(ctx, "book.Driver.SearchBooks")
+	defer end(span, &err)
+	return d.next.SearchBooks(ctx, params)
+}
+
+func (d bookDriver) GetBook(ctx context.Context, id int32) (_ entity.Book, err error) {
+	ctx, span := start(ctx, "book.Driver.GetBook")
+	defer end(span, &err)
+	return d.next.GetBook(ctx, id)
+}
+
+func (d bookDriver) CreateBook(ctx context.Context, params book.WriteInput) (_ entity.Book, err error) {
+	ctx, span := start(ctx, "book.Driver.CreateBook")
+	defer end(span, &err)
+	return d.next.CreateBook(ctx, params)
+}
+
+func (d bookDriver) ReplaceBook(ctx context.Context, id int32, params book.WriteInput) (_ entity.Book, err error) {
+	ctx, span := start(ctx, "book.Driver.ReplaceBook")
+	defer end(span, &err)
+	return d.next.ReplaceBook(ctx, id, params)
+}
+
+func (d bookDriver) UpdateBook(ctx context.Context, id int32, params book.WriteInput) (_ entity.Book, err error) {
+	ctx, span := start(ctx, "book.Driver.UpdateBook")
+	defer end(span, &err)
+	return d.next.UpdateBook(ctx, id, params)
+}
+
+func (d bookDriver) DeleteBook(ctx context.Context, id int32) (err error) {
+	ctx, span := start(ctx, "book.Driver.DeleteBook")
+	defer end(span, &err)
+	return d.next.DeleteBook(ctx, id)
+}
+
+func (d bookDriver) CountFacets(ctx context.Context, params book.SearchInput) (_ book.Facets, err error) {
+	ctx, span := start(ctx, "book.Driver.CountFacets")
+	defer end(span, &err)
+	return d.next.CountFacets(ctx, params)
+}
+
+func (d bookDriver) ExportBooks(ctx context.Context, params book.SearchInput, each func(entity.Book) error) (err error) {
+	ctx, span := start(ctx, "book.Driver.ExportBooks")
+	defer end(span, &err)
+	return d.next.ExportBooks(ctx, params, each)
+}
+
+type authorDriver struct {
+	next author.Driver
+}
+
+func (d authorDriver) ListAuthors(ctx context.Context) (_ []entity.Author, err error) {
+	ctx, span := start(ctx, "author.Driver.ListAuthors")
+	defer end(span, &err)
+	return d.next.ListAuthors(ctx)
+}
+
+func (d authorDriver) GetAuthor(ctx context.Context, id int32) (_ author.Detail, err error) {
+	ctx, span := start(ctx, "author.Driver.GetAuthor")
+	defer end(span, &err)
+	return d.next.GetAuthor(ctx, id)
+}
+
+func (d authorDriver) CreateAuthor(ctx context.Context, params author.WriteInput) (_ entity.Author, err error) {
+	ctx, span := start(ctx, "author.Driver.CreateAuthor")
+	defer end(span, &err)
+	return d.next.CreateAuthor(ctx, params)
+}
+
+func (d authorDriver) ReplaceAuthor(ctx context.Context, id int32, params author.WriteInput) (_ entity.Author, err error) {
+	ctx, span := start(ctx, "author.Driver.ReplaceAuthor")
+	defer end(span, &err)
+	return d.next.ReplaceAuthor(ctx, id, params)
+}
+
+func (d authorDriver) DeleteAuthor(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	ctx, span := start(ctx, "author.Driver.DeleteAuthor")
+	defer end(span, &err)
+	return d.next.DeleteAuthor(ctx, id, reassignTo)
+}
+
+type genreDriver struct {
+	next genre.Driver
+}
+
+func (d genreDriver) ListGenres(ctx context.Context) (_ []entity.Genre, err error) {
+	ctx, span := start(ctx, "genre.Driver.ListGenres")
+	defer end(span, &err)
+	return d.next.ListGenres(ctx)
+}
+
+func (d genreDriver) GetGenre(ctx context.Context, id int32) (_ genre.Detail, err error) {
+	ctx, span := start(ctx, "genre.Driver.GetGenre")
+	defer end(span, &err)
+	return d.next.GetGenre(ctx, id)
+}
+
+func (d genreDriver) CreateGenre(ctx context.Context, params genre.WriteInput) (_ entity.Genre, err error) {
+	ctx, span := start(ctx, "genre.Driver.CreateGenre")
+	defer end(span, &err)
+	return d.next.CreateGenre(ctx, params)
+}
+
+func (d genreDriver) ReplaceGenre(ctx context.Context, id int32, params genre.WriteInput) (_ entity.Genre, err error) {
+	ctx, span := start(ctx, "genre.Driver.ReplaceGenre")
+	defer end(span, &err)
+	return d.next.ReplaceGenre(ctx, id, params)
+}
+
+func (d genreDriver) DeleteGenre(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	ctx, span := start(ctx, "genre.Driver.DeleteGenre")
+	defer end(span, &err)
+	return d.next.DeleteGenre(ctx, id, reassignTo)
+}
+
+type eraDriver struct {
+	next era.Driver
+}
+
+func (d eraDriver) ListEras(ctx context.Context) (_ []entity.Era, err error) {
+	ctx, span := start(ctx, "era.Driver.ListEras")
+	defer end(span, &err)
+	return d.next.ListEras(ctx)
+}
+
+func (d eraDriver) CreateEra(ctx context.Context, params era.WriteInput) (_ entity.Era, err error) {
+	ctx, span := start(ctx, "era.Driver.CreateEra")
+	defer end(span, &err)
+	return d.next.CreateEra(ctx, params)
+}
+
+func (d eraDriver) ReplaceEra(ctx context.Context, id int32, params era.WriteInput) (_ entity.Era, err error) {
+	ctx, span := start(ctx, "era.Driver.ReplaceEra")
+	defer end(span, &err)
+	return d.next.ReplaceEra(ctx, id, params)
+}
+
+func (d eraDriver) DeleteEra(ctx context.Context, id int32) (err error) {
+	ctx, span := start(ctx, "era.Driver.DeleteEra")
+	defer end(span, &err)
+	return d.next.DeleteEra(ctx, id)
+}
+
+func (d eraDriver) ReplaceAllEras(ctx context.Context, params []era.BulkInput) (_ []entity.Era, err error) {
+	ctx, span := start(ctx, "era.Driver.ReplaceAllEras")
+	defer end(span, &err)
+	return d.next.ReplaceAllEras(ctx, params)
+}
+
+type sizeDriver struct {
+	next size.Driver
+}
+
+func (d sizeDriver) ListSizes(ctx context.Context) (_ []entity.Size, err error) {
+	ctx, span := start(ctx, "size.Driver.ListSizes")
+	defer end(span, &err)
+	return d.next.ListSizes(ctx)
+}
+
+func (d sizeDriver) CreateSize(ctx context.Context, params size.WriteInput) (_ entity.Size, err error) {
+	ctx, span := start(ctx, "size.Driver.CreateSize")
+	defer end(span, &err)
+	return d.next.CreateSize(ctx, params)
+}
+
+func (d sizeDriver) ReplaceSize(ctx context.Context, id int32, params size.WriteInput) (_ entity.Size, err error) {
+	ctx, span := start(ctx, "size.Driver.ReplaceSize")
+	defer end(span, &err)
+	return d.next.ReplaceSize(ctx, id, params)
+}
+
+func (d sizeDriver) DeleteSize(ctx context.Context, id int32) (err error) {
+	ctx, span := start(ctx, "size.Driver.DeleteSize")
+	defer end(span, &err)
+	return d.next.DeleteSize(ctx, id)
+}
+
+func (d sizeDriver) ReplaceAllSizes(ctx context.Context, params []size.BulkInput) (_ []entity.Size, err error) {
+	ctx, span := start(ctx, "size.Driver.ReplaceAllSizes")
+	defer end(span, &err)
+	return d.next.ReplaceAllSizes(ctx, params)
+}
@@ -0,0 +1,36 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book/booktest"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookDriver(t *testing.T) {
+	recorder := record(t)
+
+	driver := booktest.DriverMock{}
+	driver.
+		On("GetBook", mock.Anything, int32(1)).
+		Run(func(args mock.Arguments) {
+			// The driver's context carries its span, so that repositories continue the trace.
+			_, span := start(args.Get(0).(context.Context), "book.Repository.Get")
+			span.End()
+		}).
+		Return(entity.Book{ID: 1}, nil)
+
+	got, err := BookDriver(&driver).GetBook(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, entity.Book{ID: 1}, got)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "book.Repository.Get", spans[0].Name())
+	assert.Equal(t, "book.Driver.GetBook", spans[1].Name())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
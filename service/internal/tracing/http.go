@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// requestIDKey records the request ID of a request on its span.
+	requestIDKey = attribute.Key("http.request_id")
+
+	// unmatchedRoute names the spans of requests which matched no route.
+	unmatchedRoute = "unmatched"
+)
+
+// Middleware traces every request served by the next handler in a span, which continues the trace of the
+// request's W3C traceparent header if it has one. The span is named for the method and the pattern of the route,
+// such as "GET /api/v1/books/{id}", so it must be used by the root chi router.
+//
+// Unless the request has an X-Request-Id header, it is set to the trace ID, so that middleware.RequestID, which
+// must come after, identifies the request by its trace. The traceparent of the span is set on the response, so
+// that clients can find the trace of any request.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := start(ctx, fmt.Sprintf("%s %s", r.Method, unmatchedRoute),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("readcommend", "", r)...))
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.HasTraceID() {
+			if r.Header.Get(middleware.RequestIDHeader) == "" {
+				r.Header.Set(middleware.RequestIDHeader, sc.TraceID().String())
+			}
+			propagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+		}
+		span.SetAttributes(requestIDKey.String(r.Header.Get(middleware.RequestIDHeader)))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName(fmt.Sprintf("%s %s", r.Method, rctx.RoutePattern()))
+			span.SetAttributes(semconv.HTTPRouteKey.String(rctx.RoutePattern()))
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer))
+	})
+}
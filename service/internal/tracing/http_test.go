@@ -0,0 +1,122 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	const (
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID    = "00f067aa0ba902b7"
+		traceparent = "00-" + traceID + "-" + parentID + "-01"
+	)
+
+	tests := map[string]struct {
+		path      string
+		header    http.Header
+		status    int
+		name      string
+		spanCode  codes.Code
+		requestID func(t *testing.T, got string, span trace.SpanContext)
+	}{
+		"new trace": {
+			path:   "/books/1",
+			status: http.StatusOK,
+			name:   "GET /books/{id}",
+			requestID: func(t *testing.T, got string, span trace.SpanContext) {
+				assert.Equal(t, span.TraceID().String(), got)
+			},
+		},
+		"continues the trace of the traceparent": {
+			path:   "/books/1",
+			header: http.Header{"Traceparent": {traceparent}},
+			status: http.StatusOK,
+			name:   "GET /books/{id}",
+			requestID: func(t *testing.T, got string, span trace.SpanContext) {
+				assert.Equal(t, traceID, span.TraceID().String())
+				assert.Equal(t, traceID, got)
+			},
+		},
+		"keeps the client's request ID": {
+			path:   "/books/1",
+			header: http.Header{"Traceparent": {traceparent}, middleware.RequestIDHeader: {"client-id"}},
+			status: http.StatusOK,
+			name:   "GET /books/{id}",
+			requestID: func(t *testing.T, got string, _ trace.SpanContext) {
+				assert.Equal(t, "client-id", got)
+			},
+		},
+		"failed request": {
+			path:     "/books/0",
+			status:   http.StatusInternalServerError,
+			name:     "GET /books/{id}",
+			spanCode: codes.Error,
+			requestID: func(t *testing.T, got string, span trace.SpanContext) {
+				assert.Equal(t, span.TraceID().String(), got)
+			},
+		},
+		"unmatched route": {
+			path:   "/missing",
+			status: http.StatusNotFound,
+			name:   "GET unmatched",
+			requestID: func(t *testing.T, got string, span trace.SpanContext) {
+				assert.Equal(t, span.TraceID().String(), got)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := record(t)
+
+			var requestID string
+			router := chi.NewRouter()
+			router.Use(Middleware, middleware.RequestID)
+			router.Get("/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+				requestID = middleware.GetReqID(r.Context())
+				// The handler's context carries the span, so that drivers continue its trace.
+				assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid())
+				if chi.URLParam(r, "id") == "0" {
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			})
+			router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+				requestID = middleware.GetReqID(r.Context())
+				w.WriteHeader(http.StatusNotFound)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+			assert.Equal(t, tt.status, res.Code)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, tt.name, span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, tt.spanCode, span.Status().Code)
+			assert.Equal(t, int64(tt.status), attributes(span)[semconv.HTTPStatusCodeKey].AsInt64())
+			tt.requestID(t, requestID, span.SpanContext())
+
+			// The traceparent of the span is echoed in the response.
+			assert.Contains(t, res.Header().Get("Traceparent"), span.SpanContext().SpanID().String())
+			if tt.header.Get("Traceparent") != "" {
+				assert.Equal(t, parentID, span.Parent().SpanID().String())
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/author"
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/driver/era"
+	"github.com/LeviMatus/readcommend/service/internal/driver/genre"
+	"github.com/LeviMatus/readcommend/service/internal/driver/size"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// resultsKey records how many entities a repository method returned, for methods which return a list of them.
+const resultsKey = attribute.Key("readcommend.results")
+
+// BookRepository wraps the book.Repository in a decorator which traces each of its methods in a span.
+func BookRepository(repo book.Repository) book.Repository {
+	return bookRepository{next: repo}
+}
+
+// AuthorRepository wraps the author.Repository in a decorator which traces each of its methods in a span.
+func AuthorRepository(repo author.Repository) author.Repository {
+	return authorRepository{next: repo}
+}
+
+// GenreRepository wraps the genre.Repository in a decorator which traces each of its methods in a span.
+func GenreRepository(repo genre.Repository) genre.Repository {
+	return genreRepository{next: repo}
+}
+
+// EraRepository wraps the era.Repository in a decorator which traces each of its methods in a span.
+func EraRepository(repo era.Repository) era.Repository {
+	return eraRepository{next: repo}
+}
+
+// SizeRepository wraps the size.Repository in a decorator which traces each of its methods in a span.
+func SizeRepository(repo size.Repository) size.Repository {
+	return sizeRepository{next: repo}
+}
+
+type bookRepository struct {
+	next book.Repository
+}
+
+func (r bookRepository) Search(ctx context.Context, params book.SearchInput) (results []entity.Book, err error) {
+	ctx, span := start(ctx, "book.Repository.Search")
+	defer end(span, &err)
+	results, err = r.next.Search(ctx, params)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+func (r bookRepository) Get(ctx context.Context, id int32) (_ entity.Book, err error) {
+	ctx, span := start(ctx, "book.Repository.Get")
+	defer end(span, &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r bookRepository) Create(ctx context.Context, params book.WriteInput) (_ int32, err error) {
+	ctx, span := start(ctx, "book.Repository.Create")
+	defer end(span, &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r bookRepository) Update(ctx context.Context, id int32, params book.WriteInput) (err error) {
+	ctx, span := start(ctx, "book.Repository.Update")
+	defer end(span, &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r bookRepository) Delete(ctx context.Context, id int32) (err error) {
+	ctx, span := start(ctx, "book.Repository.Delete")
+	defer end(span, &err)
+	return r.next.Delete(ctx, id)
+}
+
+func (r bookRepository) Facets(ctx context.Context, params book.FacetInput) (_ book.Facets, err error) {
+	ctx, span := start(ctx, "book.Repository.Facets")
+	defer end(span, &err)
+	return r.next.Facets(ctx, params)
+}
+
+func (r bookRepository) Export(ctx context.Context, params book.SearchInput, each func(entity.Book) error) (err error) {
+	ctx, span := start(ctx, "book.Repository.Export")
+	defer end(span, &err)
+	return r.next.Export(ctx, params, each)
+}
+
+type authorRepository struct {
+	next author.Repository
+}
+
+func (r authorRepository) List(ctx context.Context) (results []entity.Author, err error) {
+	ctx, span := start(ctx, "author.Repository.List")
+	defer end(span, &err)
+	results, err = r.next.List(ctx)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+func (r authorRepository) Get(ctx context.Context, id int32) (_ entity.Author, err error) {
+	ctx, span := start(ctx, "author.Repository.Get")
+	defer end(span, &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r authorRepository) Stats(ctx context.Context, id int32) (_ author.Stats, err error) {
+	ctx, span := start(ctx, "author.Repository.Stats")
+	defer end(span, &err)
+	return r.next.Stats(ctx, id)
+}
+
+func (r authorRepository) TopBooks(ctx context.Context, id int32, limit uint64) (results []entity.Book, err error) {
+	ctx, span := start(ctx, "author.Repository.TopBooks")
+	defer end(span, &err)
+	results, err = r.next.TopBooks(ctx, id, limit)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+func (r authorRepository) Create(ctx context.Context, params author.WriteInput) (_ int32, err error) {
+	ctx, span := start(ctx, "author.Repository.Create")
+	defer end(span, &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r authorRepository) Update(ctx context.Context, id int32, params author.WriteInput) (err error) {
+	ctx, span := start(ctx, "author.Repository.Update")
+	defer end(span, &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r authorRepository) Delete(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	ctx, span := start(ctx, "author.Repository.Delete")
+	defer end(span, &err)
+	return r.next.Delete(ctx, id, reassignTo)
+}
+
+type genreRepository struct {
+	next genre.Repository
+}
+
+func (r genreRepository) List(ctx context.Context) (results []entity.Genre, err error) {
+	ctx, span := start(ctx, "genre.Repository.List")
+	defer end(span, &err)
+	results, err = r.next.List(ctx)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+func (r genreRepository) Get(ctx context.Context, id int32) (_ entity.Genre, err error) {
+	ctx, span := start(ctx, "genre.Repository.Get")
+	defer end(span, &err)
+	return r.next.Get(ctx, id)
+}
+
+func (r genreRepository) Stats(ctx context.Context, id int32) (_ genre.Stats, err error) {
+	ctx, span := start(ctx, "genre.Repository.Stats")
+	defer end(span, &err)
+	return r.next.Stats(ctx, id)
+}
+
+func (r genreRepository) Create(ctx context.Context, params genre.WriteInput) (_ int32, err error) {
+	ctx, span := start(ctx, "genre.Repository.Create")
+	defer end(span, &err)
+	return r.next.Create(ctx, params)
+}
+
+func (r genreRepository) Update(ctx context.Context, id int32, params genre.WriteInput) (err error) {
+	ctx, span := start(ctx, "genre.Repository.Update")
+	defer end(span, &err)
+	return r.next.Update(ctx, id, params)
+}
+
+func (r genreRepository) Delete(ctx context.Context, id int32, reassignTo *int32) (err error) {
+	ctx, span := start(ctx, "genre.Repository.Delete")
+	defer end(span, &err)
+	return r.next.Delete(ctx, id, reassignTo)
+}
+
+type eraRepository struct {
+	next era.Repository
+}
+
+func (r eraRepository) List(ctx context.Context) (results []entity.Era, err error) {
+	ctx, span := start(ctx, "era.Repository.List")
+	defer end(span, &err)
+	results, err = r.next.List(ctx)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+func (r eraRepository) Create(ctx context.Context, params era.WriteInput,
+	check func([]entity.Era) error) (_ int32, err error) {
+	ctx, span := start(ctx, "era.Repository.Create")
+	defer end(span, &err)
+	return r.next.Create(ctx, params, check)
+}
+
+func (r eraRepository) Update(ctx context.Context, id int32, params era.WriteInput,
+	check func([]entity.Era) error) (err error) {
+	ctx, span := start(ctx, "era.Repository.Update")
+	defer end(span, &err)
+	return r.next.Update(ctx, id, params, check)
+}
+
+func (r eraRepository) Delete(ctx context.Context, id int32, check func([]entity.Era) error) (err error) {
+	ctx, span := start(ctx, "era.Repository.Delete")
+	defer end(span, &err)
+	return r.next.Delete(ctx, id, check)
+}
+
+func (r eraRepository) ReplaceAll(ctx context.Context, params []era.BulkInput,
+	check func([]entity.Era) error) (results []entity.Era, err error) {
+	ctx, span := start(ctx, "era.Repository.ReplaceAll")
+	defer end(span, &err)
+	results, err = r.next.ReplaceAll(ctx, params, check)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+type sizeRepository struct {
+	next size.Repository
+}
+
+func (r sizeRepository) List(ctx context.Context) (results []entity.Size, err error) {
+	ctx, span := start(ctx, "size.Repository.List")
+	defer end(span, &err)
+	results, err = r.next.List(ctx)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
+
+func (r sizeRepository) Create(ctx context.Context, params size.WriteInput,
+	check func([]entity.Size) error) (_ int32, err error) {
+	ctx, span := start(ctx, "size.Repository.Create")
+	defer end(span, &err)
+	return r.next.Create(ctx, params, check)
+}
+
+func (r sizeRepository) Update(ctx context.Context, id int32, params size.WriteInput,
+	check func([]entity.Size) error) (err error) {
+	ctx, span := start(ctx, "size.Repository.Update")
+	defer end(span, &err)
+	return r.next.Update(ctx, id, params, check)
+}
+
+func (r sizeRepository) Delete(ctx context.Context, id int32, check func([]entity.Size) error) (err error) {
+	ctx, span := start(ctx, "size.Repository.Delete")
+	defer end(span, &err)
+	return r.next.Delete(ctx, id, check)
+}
+
+func (r sizeRepository) ReplaceAll(ctx context.Context, params []size.BulkInput,
+	check func([]entity.Size) error) (results []entity.Size, err error) {
+	ctx, span := start(ctx, "size.Repository.ReplaceAll")
+	defer end(span, &err)
+	results, err = r.next.ReplaceAll(ctx, params, check)
+	span.SetAttributes(resultsKey.Int(len(results)))
+	return results, err
+}
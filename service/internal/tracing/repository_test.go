@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/driver/book"
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// searchBookRepository is a book.Repository whose Search returns its books and error, and which implements no
+// other method.
+type searchBookRepository struct {
+	book.Repository
+	books []entity.Book
+	err   error
+}
+
+func (r searchBookRepository) Search(context.Context, book.SearchInput) ([]entity.Book, error) {
+	return r.books, r.err
+}
+
+func TestBookRepository(t *testing.T) {
+
+	tests := map[string]struct {
+		books  []entity.Book
+		err    error
+		events int
+		code   codes.Code
+	}{
+		"books are found": {
+			books: []entity.Book{{ID: 1}, {ID: 2}},
+		},
+		"request is invalid": {
+			err:    errors.Wrap(entity.ErrInvalidQueryParam, "cursor"),
+			events: 1,
+		},
+		"query fails": {
+			err:    errors.New("connection refused"),
+			events: 1,
+			code:   codes.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := record(t)
+
+			books, err := BookRepository(searchBookRepository{books: tt.books, err: tt.err}).Search(context.Background(), book.SearchInput{})
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.books, books)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "book.Repository.Search", spans[0].Name())
+			assert.Equal(t, int64(len(tt.books)), attributes(spans[0])[resultsKey].AsInt64())
+			assert.Len(t, spans[0].Events(), tt.events, "errors are recorded as events")
+			assert.Equal(t, tt.code, spans[0].Status().Code)
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The databases which OpenDB records as the db.system of its spans.
+const (
+	SystemPostgres = "postgresql"
+	SystemSQLite   = "sqlite"
+)
+
+const (
+	// rowsReturnedKey records how many rows a query returned, once they have all been read.
+	rowsReturnedKey = attribute.Key("db.rows_returned")
+
+	// rowsAffectedKey records how many rows a statement inserted, updated or deleted.
+	rowsAffectedKey = attribute.Key("db.rows_affected")
+)
+
+// OpenDB opens a database of the driver registered under the name, as sql.Open does, whose statements are traced.
+// Every query and exec is a span, which records its SQL and how many rows it returned or affected, as a child of
+// the span of its context. The system is the kind of database, such as SystemPostgres. A query's span ends once
+// its rows are closed.
+func OpenDB(driverName, dsn, system string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+
+	var connector driver.Connector = dsnConnector{dsn: dsn, driver: d}
+	if dc, ok := d.(driver.DriverContext); ok {
+		if connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(tracedConnector{next: connector, system: semconv.DBSystemKey.String(system)}), nil
+}
+
+// dsnConnector is the driver.Connector of a driver which does not implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// tracedConnector connects tracedConns.
+type tracedConnector struct {
+	next   driver.Connector
+	system attribute.KeyValue
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.next.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{next: conn, system: c.system}, nil
+}
+
+func (c tracedConnector) Driver() driver.Driver {
+	return c.next.Driver()
+}
+
+// startStatement starts the span of the SQL statement, which is named for the operation, "query" or "exec".
+func startStatement(ctx context.Context, system attribute.KeyValue, operation, query string) (context.Context, trace.Span) {
+	return start(ctx, "sql."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(system, semconv.DBStatementKey.String(query)))
+}
+
+// endExec ends the span of an exec, recording the rows which it affected.
+func endExec(span trace.Span, res driver.Result, err error) {
+	if err == nil {
+		if n, rowsErr := res.RowsAffected(); rowsErr == nil {
+			span.SetAttributes(rowsAffectedKey.Int64(n))
+		}
+	}
+	end(span, &err)
+}
+
+// tracedConn traces the statements of a driver.Conn. The optional interfaces of database/sql/driver which it
+// implements are forwarded to the connection, or fall back to what database/sql does for connections without
+// them.
+type tracedConn struct {
+	next   driver.Conn
+	system attribute.KeyValue
+}
+
+func (c *tracedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if pc, ok := c.next.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.next.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{next: stmt, query: query, system: c.system}, nil
+}
+
+func (c *tracedConn) Close() error {
+	return c.next.Close()
+}
+
+func (c *tracedConn) Begin() (driver.Tx, error) {
+	return c.next.Begin()
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.next.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Begin()
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.next.(driver.QueryerContext)
+	if !ok {
+		// database/sql prepares the statement instead, which is traced by the tracedStmt.
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatement(ctx, c.system, "query", query)
+	rows, err := qc.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		// The statement is prepared instead, which is traced by the tracedStmt.
+		span.End()
+		return nil, err
+	}
+	if err != nil {
+		end(span, &err)
+		return nil, err
+	}
+	return &tracedRows{next: rows, span: span}, nil
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.next.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := startStatement(ctx, c.system, "exec", query)
+	res, err := ec.ExecContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		span.End()
+		return nil, err
+	}
+	endExec(span, res, err)
+	return res, err
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.next.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.next.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if v, ok := c.next.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.next.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// tracedStmt traces the executions of a prepared driver.Stmt.
+type tracedStmt struct {
+	next   driver.Stmt
+	query  string
+	system attribute.KeyValue
+}
+
+func (s *tracedStmt) Close() error {
+	return s.next.Close()
+}
+
+func (s *tracedStmt) NumInput() int {
+	return s.next.NumInput()
+}
+
+func (s *tracedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.next.Exec(args)
+}
+
+func (s *tracedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.next.Query(args)
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, span := startStatement(ctx, s.system, "exec", s.query)
+	var (
+		res driver.Result
+		err error
+	)
+	if ec, ok := s.next.(driver.StmtExecContext); ok {
+		res, err = ec.ExecContext(ctx, args)
+	} else {
+		res, err = s.Exec(values(args))
+	}
+	endExec(span, res, err)
+	return res, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, span := startStatement(ctx, s.system, "query", s.query)
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if qc, ok := s.next.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Query(values(args))
+	}
+	if err != nil {
+		end(span, &err)
+		return nil, err
+	}
+	return &tracedRows{next: rows, span: span}, nil
+}
+
+func (s *tracedStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := s.next.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// values returns the values of the arguments, for drivers which only take positional arguments.
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, arg := range args {
+		vs[i] = arg.Value
+	}
+	return vs
+}
+
+// tracedRows counts the rows of a query, and ends its span once they are closed.
+type tracedRows struct {
+	next  driver.Rows
+	span  trace.Span
+	count int
+	err   error
+}
+
+func (r *tracedRows) Columns() []string {
+	return r.next.Columns()
+}
+
+func (r *tracedRows) Next(dest []driver.Value) error {
+	err := r.next.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case err != io.EOF:
+		r.err = err
+	}
+	return err
+}
+
+func (r *tracedRows) Close() error {
+	err := r.next.Close()
+	if r.err == nil {
+		r.err = err
+	}
+	r.span.SetAttributes(rowsReturnedKey.Int(r.count))
+	end(r.span, &r.err)
+	return err
+}
+
+func (r *tracedRows) HasNextResultSet() bool {
+	if rs, ok := r.next.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+func (r *tracedRows) NextResultSet() error {
+	if rs, ok := r.next.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+func (r *tracedRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.next.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+func (r *tracedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.next.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+func (r *tracedRows) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.next.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+func (r *tracedRows) ColumnTypeNullable(index int) (bool, bool) {
+	if ct, ok := r.next.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+func (r *tracedRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if ct, ok := r.next.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOpenDB(t *testing.T) {
+	recorder := record(t)
+
+	db, err := OpenDB("sqlite3", "file::memory:", SystemSQLite)
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx, parent := start(context.Background(), "book.Repository.Search")
+	_, err = db.ExecContext(ctx, "CREATE TABLE genre (id INTEGER PRIMARY KEY, title TEXT)")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO genre (title) VALUES (?), (?), (?)", "Fantasy", "Horror", "Poetry")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, "SELECT title FROM genre WHERE id > ?", 1)
+	require.NoError(t, err)
+	var titles []string
+	for rows.Next() {
+		var title string
+		require.NoError(t, rows.Scan(&title))
+		titles = append(titles, title)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"Horror", "Poetry"}, titles)
+
+	// Prepared statements are traced as they are executed.
+	stmt, err := db.PrepareContext(ctx, "UPDATE genre SET title = upper(title)")
+	require.NoError(t, err)
+	_, err = stmt.ExecContext(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stmt.Close())
+
+	_, err = db.QueryContext(ctx, "SELECT missing FROM genre")
+	assert.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 6)
+	statements := spans[:5]
+
+	expected := []struct {
+		name      string
+		statement string
+		rows      int64
+		code      codes.Code
+	}{
+		{name: "sql.exec", statement: "CREATE TABLE genre (id INTEGER PRIMARY KEY, title TEXT)", rows: 0},
+		{name: "sql.exec", statement: "INSERT INTO genre (title) VALUES (?), (?), (?)", rows: 3},
+		{name: "sql.query", statement: "SELECT title FROM genre WHERE id > ?", rows: 2},
+		{name: "sql.exec", statement: "UPDATE genre SET title = upper(title)", rows: 3},
+		{name: "sql.query", statement: "SELECT missing FROM genre", code: codes.Error},
+	}
+	for i, tt := range expected {
+		span := statements[i]
+		attrs := attributes(span)
+		assert.Equal(t, tt.name, span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, tt.statement, attrs[semconv.DBStatementKey].AsString())
+		assert.Equal(t, "sqlite", attrs[semconv.DBSystemKey].AsString())
+		assert.Equal(t, tt.code, span.Status().Code)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+
+		if tt.code == codes.Error {
+			continue
+		}
+		if tt.name == "sql.query" {
+			assert.Equal(t, tt.rows, attrs[rowsReturnedKey].AsInt64())
+		} else {
+			assert.Equal(t, tt.rows, attrs[rowsAffectedKey].AsInt64())
+		}
+	}
+}
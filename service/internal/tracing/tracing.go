@@ -0,0 +1,133 @@
+// Package tracing traces requests through the service with OpenTelemetry: a span for every request, as routed by
+// chi, within which are spans for the methods of the drivers, then of the repositories, and finally for every SQL
+// statement, which records the statement and the number of rows it returned or affected. Spans are created with
+// the global TracerProvider, which Setup installs, so that until it is called they cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the service.
+const instrumentationName = "github.com/LeviMatus/readcommend/service"
+
+// propagator reads and writes the W3C traceparent and baggage headers.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// The exporters which Setup can export spans with.
+const (
+	// ExporterNone exports nothing. Spans are still created, so that requests are given trace IDs, and incoming
+	// traces are propagated.
+	ExporterNone = "none"
+
+	// ExporterStdout writes every span to stdout as a line of JSON.
+	ExporterStdout = "stdout"
+
+	// ExporterFile appends every span to Options.File as a line of JSON.
+	ExporterFile = "file"
+)
+
+// Options configure how Setup exports spans.
+type Options struct {
+	// Exporter is ExporterNone, ExporterStdout or ExporterFile.
+	Exporter string
+
+	// File is the file which ExporterFile appends spans to.
+	File string
+
+	// SampleRatio is the fraction of traces which are sampled, from 0 to 1, unless an incoming request's trace
+	// was sampled upstream, in which case that decision is followed.
+	SampleRatio float64
+}
+
+// Setup installs a global TracerProvider, which exports spans as the Options configure, and propagates W3C trace
+// context and baggage. The returned function flushes the spans which have yet to be exported, and must be called
+// before the process exits.
+func Setup(opts Options) (func(context.Context) error, error) {
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid sample ratio %g: must be from 0 to 1", opts.SampleRatio)
+	}
+
+	var (
+		w     io.Writer
+		close func() error
+	)
+	switch opts.Exporter {
+	case ExporterNone:
+	case ExporterStdout:
+		w = os.Stdout
+	case ExporterFile:
+		if opts.File == "" {
+			return nil, errors.New("a file is required to export spans to")
+		}
+		f, err := os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open trace file: %w", err)
+		}
+		w, close = f, f.Close
+	default:
+		return nil, fmt.Errorf("invalid exporter %q: must be %q, %q or %q", opts.Exporter, ExporterNone, ExporterStdout, ExporterFile)
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String("readcommend"))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	}
+	if w == nil {
+		// There is nothing to export, so there is nothing to flush.
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(providerOpts...))
+		otel.SetTextMapPropagator(propagator)
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(append(providerOpts, sdktrace.WithBatcher(exporter))...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if close != nil {
+			if closeErr := close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// start starts a span, named for the method of a layer such as "book.Driver.SearchBooks", as a child of the span
+// of the context.
+func start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// end ends the span, once the traced call has returned the error that errp points to. The error is recorded
+// on the span, and unless it was caused by the request, such as entity.ErrNotFound, the span is marked failed.
+// It is deferred by each traced method, which names its error result.
+func end(span trace.Span, errp *error) {
+	if err := *errp; err != nil {
+		span.RecordError(err)
+		if !entity.IsClientError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
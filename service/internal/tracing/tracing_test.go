@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record installs a global TracerProvider which records every span.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagator)
+	return recorder
+}
+
+// attributes returns the attributes of the span by key.
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSetup(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := map[string]struct {
+		opts         Options
+		errAssertion assert.ErrorAssertionFunc
+	}{
+		"no exporter": {
+			opts:         Options{Exporter: ExporterNone, SampleRatio: 1},
+			errAssertion: assert.NoError,
+		},
+		"stdout": {
+			opts:         Options{Exporter: ExporterStdout, SampleRatio: 1},
+			errAssertion: assert.NoError,
+		},
+		"file": {
+			opts:         Options{Exporter: ExporterFile, File: filepath.Join(dir, "traces.jsonl"), SampleRatio: 0.5},
+			errAssertion: assert.NoError,
+		},
+		"file without a name": {
+			opts:         Options{Exporter: ExporterFile, SampleRatio: 1},
+			errAssertion: assert.Error,
+		},
+		"file in a missing directory": {
+			opts:         Options{Exporter: ExporterFile, File: filepath.Join(dir, "missing", "traces.jsonl"), SampleRatio: 1},
+			errAssertion: assert.Error,
+		},
+		"unknown exporter": {
+			opts:         Options{Exporter: "jaeger", SampleRatio: 1},
+			errAssertion: assert.Error,
+		},
+		"sample ratio out of range": {
+			opts:         Options{Exporter: ExporterNone, SampleRatio: 1.5},
+			errAssertion: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			flush, err := Setup(tt.opts)
+			tt.errAssertion(t, err)
+			if flush != nil {
+				assert.NoError(t, flush(context.Background()))
+			}
+		})
+	}
+}
+
+func TestSetup_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traces.jsonl")
+	flush, err := Setup(Options{Exporter: ExporterFile, File: file, SampleRatio: 1})
+	require.NoError(t, err)
+
+	_, span := start(context.Background(), "book.Driver.GetBook")
+	span.End()
+	require.NoError(t, flush(context.Background()))
+
+	// Spans are exported once flushed, with no collector running.
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"book.Driver.GetBook"`)
+}
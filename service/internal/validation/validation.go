@@ -0,0 +1,148 @@
+// Package validation collects every violation found while validating the input of a request, such as its query
+// parameters or the entity in its body, so that they are reported together rather than one at a time.
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+)
+
+// The codes of violations, which tell clients what is wrong with a field. Like the codes of Problems, they are
+// stable, so clients may act on them.
+const (
+	// CodeInvalidType is the code of a value which cannot be parsed as the type of its field.
+	CodeInvalidType = "invalid_type"
+
+	// CodeInvalid is the code of a value which is malformed, such as a sort of an unknown field.
+	CodeInvalid = "invalid"
+
+	// CodeRequired is the code of a field which must be set but is not.
+	CodeRequired = "required"
+
+	// CodeBlank is the code of a value which must not be blank but is.
+	CodeBlank = "blank"
+
+	// CodeOutOfRange is the code of a value which lies outside of the range of its field.
+	CodeOutOfRange = "out_of_range"
+
+	// CodeMinExceedsMax is the code of the lower bound of a range which is greater than its upper bound.
+	CodeMinExceedsMax = "min_exceeds_max"
+
+	// CodeConflict is the code of a value which cannot be used with the values of other fields.
+	CodeConflict = "conflict"
+
+	// CodeNotFound is the code of an ID which refers to a resource that does not exist, such as the author of a
+	// book.
+	CodeNotFound = "not_found"
+
+	// CodeUnknown is the code of a field which is not known, such as an unknown query parameter.
+	CodeUnknown = "unknown"
+)
+
+// Validator collects the violations of a single input. Its checks record a violation rather than returning an
+// error, so that every check is run, and Err reports them all once validation is done. The zero value is not
+// usable; create Validators with New.
+type Validator struct {
+	kind   error
+	fields []entity.FieldError
+}
+
+// New creates a Validator whose violations are reported as an error of the kind, such as
+// entity.ErrInvalidQueryParam or entity.ErrInvalidEntity.
+func New(kind error) *Validator {
+	return &Validator{kind: kind}
+}
+
+// Add records a violation of the field with the code, described by the formatted message.
+func (v *Validator) Add(field, code, format string, args ...interface{}) {
+	v.fields = append(v.fields, entity.FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check records a violation of the field, as Add does, unless ok is true. It returns ok, so that checks which
+// depend on it can be skipped.
+func (v *Validator) Check(ok bool, field, code, format string, args ...interface{}) bool {
+	if !ok {
+		v.Add(field, code, format, args...)
+	}
+	return ok
+}
+
+// Invalid returns true if a violation of the field has been recorded. Cross-field checks skip fields which are
+// already invalid, so that a single mistake is not reported twice.
+func (v *Validator) Invalid(field string) bool {
+	for _, f := range v.fields {
+		if f.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Required checks that the field is set. It returns set, so that checks of the value can be skipped.
+func (v *Validator) Required(field string, set bool) bool {
+	return v.Check(set, field, CodeRequired, "is required")
+}
+
+// NotBlank checks that the value, if it is set, is not blank.
+func (v *Validator) NotBlank(field string, value *string) {
+	if value != nil {
+		v.Check(strings.TrimSpace(*value) != "", field, CodeBlank, "must not be blank")
+	}
+}
+
+// Int16InRange checks that the value, if it is set, lies within [min,max].
+func (v *Validator) Int16InRange(field string, value *int16, min, max int16) {
+	if value != nil {
+		v.Check(util.Int16InRange(*value, min, max), field, CodeOutOfRange,
+			"is %d but should be in range [%d,%d]", *value, min, max)
+	}
+}
+
+// Float32InRange checks that the value, if it is set, lies within [min,max].
+func (v *Validator) Float32InRange(field string, value *float32, min, max float32) {
+	if value != nil {
+		v.Check(*value >= min && *value <= max, field, CodeOutOfRange,
+			"is %g but should be in range [%g,%g]", *value, min, max)
+	}
+}
+
+// Uint64AtLeast checks that the value, if it is set, is at least min.
+func (v *Validator) Uint64AtLeast(field string, value *uint64, min uint64) {
+	if value != nil {
+		v.Check(*value >= min, field, CodeOutOfRange, "is %d but should be at least %d", *value, min)
+	}
+}
+
+// Int16Range checks that the lower bound of a range is not greater than its upper bound, if both are set and
+// valid. The violation is recorded against the lower bound. The bounds may be the same field, such as a range
+// query parameter.
+func (v *Validator) Int16Range(minField string, min *int16, maxField string, max *int16) {
+	if min == nil || max == nil || v.Invalid(minField) || v.Invalid(maxField) {
+		return
+	}
+	if minField == maxField {
+		v.Check(*min <= *max, minField, CodeMinExceedsMax, "starts at %d but should not end before it, at %d",
+			*min, *max)
+		return
+	}
+	v.Check(*min <= *max, minField, CodeMinExceedsMax, "is %d but should not be greater than %s, which is %d",
+		*min, maxField, *max)
+}
+
+// Fields returns every violation which has been recorded, for callers which report them other than as an
+// *entity.ValidationError, such as by the line of a catalog.
+func (v *Validator) Fields() []entity.FieldError {
+	return v.fields
+}
+
+// Err returns an *entity.ValidationError, of the kind of the Validator, which lists every violation which has
+// been recorded, or nil if there are none.
+func (v *Validator) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &entity.ValidationError{Kind: v.kind, Fields: v.fields}
+}
@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/LeviMatus/readcommend/service/internal/entity"
+	"github.com/LeviMatus/readcommend/service/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	tests := map[string]struct {
+		validate       func(v *Validator)
+		expectedFields []entity.FieldError
+	}{
+		"unset values are valid": {
+			validate: func(v *Validator) {
+				v.NotBlank("q", nil)
+				v.Int16InRange("min", nil, 1, 10)
+				v.Uint64AtLeast("limit", nil, 1)
+				v.Float32InRange("rating", nil, 0, 5)
+				v.Int16Range("min", nil, "max", util.Int16Ptr(1))
+			},
+		},
+		"valid values": {
+			validate: func(v *Validator) {
+				v.NotBlank("q", util.StringPtr("tolkien"))
+				v.Int16InRange("min", util.Int16Ptr(1), 1, 10)
+				v.Int16InRange("max", util.Int16Ptr(10), 1, 10)
+				v.Uint64AtLeast("limit", util.Uint64Ptr(1), 1)
+				v.Float32InRange("rating", util.Float32Ptr(5), 0, 5)
+				v.Required("title", true)
+				v.Int16Range("min", util.Int16Ptr(5), "max", util.Int16Ptr(5))
+			},
+		},
+		"every violation is recorded in order": {
+			validate: func(v *Validator) {
+				v.NotBlank("q", util.StringPtr(" "))
+				v.Int16InRange("min", util.Int16Ptr(0), 1, 10)
+				v.Uint64AtLeast("limit", util.Uint64Ptr(0), 1)
+				v.Float32InRange("rating", util.Float32Ptr(5.5), 0, 5)
+				v.Required("title", false)
+				v.Add("colour", CodeUnknown, "is not a known parameter")
+			},
+			expectedFields: []entity.FieldError{
+				{Field: "q", Code: CodeBlank, Message: "must not be blank"},
+				{Field: "min", Code: CodeOutOfRange, Message: "is 0 but should be in range [1,10]"},
+				{Field: "limit", Code: CodeOutOfRange, Message: "is 0 but should be at least 1"},
+				{Field: "rating", Code: CodeOutOfRange, Message: "is 5.5 but should be in range [0,5]"},
+				{Field: "title", Code: CodeRequired, Message: "is required"},
+				{Field: "colour", Code: CodeUnknown, Message: "is not a known parameter"},
+			},
+		},
+		"min exceeds max": {
+			validate: func(v *Validator) {
+				v.Int16Range("min", util.Int16Ptr(6), "max", util.Int16Ptr(5))
+			},
+			expectedFields: []entity.FieldError{
+				{Field: "min", Code: CodeMinExceedsMax, Message: "is 6 but should not be greater than max, which is 5"},
+			},
+		},
+		"range of an invalid bound is not checked": {
+			validate: func(v *Validator) {
+				v.Int16InRange("min", util.Int16Ptr(11), 1, 10)
+				v.Int16InRange("max", util.Int16Ptr(5), 1, 10)
+				v.Int16Range("min", util.Int16Ptr(11), "max", util.Int16Ptr(5))
+			},
+			expectedFields: []entity.FieldError{
+				{Field: "min", Code: CodeOutOfRange, Message: "is 11 but should be in range [1,10]"},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := New(entity.ErrInvalidQueryParam)
+			tt.validate(v)
+
+			assert.Equal(t, tt.expectedFields, v.Fields())
+			err := v.Err()
+			if tt.expectedFields == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, entity.ErrInvalidQueryParam)
+			var validationErr *entity.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.expectedFields, validationErr.Fields)
+			}
+		})
+	}
+}
+
+func TestValidator_Check(t *testing.T) {
+	v := New(entity.ErrInvalidQueryParam)
+
+	assert.True(t, v.Check(true, "sort", CodeInvalid, "is invalid"))
+	assert.False(t, v.Invalid("sort"))
+
+	assert.False(t, v.Check(false, "sort", CodeInvalid, "%q is not a field", "author"))
+	assert.True(t, v.Invalid("sort"))
+	assert.EqualError(t, v.Err(), `invalid URL query parameter provided: sort "author" is not a field`)
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 // Config defines the configurations needed to run the readcommend backend service.
 type Config struct {
 	// Database defines the configs needed to connect to the persistance-layer DB.
@@ -7,9 +9,33 @@ type Config struct {
 
 	// API defines the configs needed to stand up an API listening for network connections.
 	API API `mapstructure:"api"`
+
+	// Search defines the configs which bound book searches, such as page sizes.
+	Search Search `mapstructure:"search"`
+
+	// Store defines which repositories back the API, and how they are seeded.
+	Store Store `mapstructure:"store"`
+
+	// Tracing defines where the spans of traced requests are exported.
+	Tracing Tracing `mapstructure:"tracing"`
+
+	// Log defines the level, encoding and sampling of the logs.
+	Log Log `mapstructure:"log"`
+
+	// Environment is either "development" or "production". It sets the defaults of the settings which help while
+	// developing the service but cost too much to run in production, such as API.SpecValidation. It is
+	// "production" unless set, so that only development opts in to them.
+	Environment string `mapstructure:"environment"`
 }
 
 type Database struct {
+	// Driver is either "postgres" or "sqlite". The connection settings below apply to postgres, and File to
+	// sqlite.
+	Driver string `mapstructure:"driver"`
+
+	// File is the path of the SQLite database, which is created if it does not exist.
+	File string `mapstructure:"file"`
+
 	Host     string `mapstructure:"host"`
 	Port     string `mapstructure:"port"`
 	Database string `mapstructure:"database"`
@@ -17,9 +43,100 @@ type Database struct {
 	SSL      string `mapstructure:"ssl-mode"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
+
+	// CheckSchema refuses to serve if the schema of the database is behind the version the binary expects.
+	CheckSchema bool `mapstructure:"check-schema"`
 }
 
 type API struct {
 	Port string `mapstructure:"port"`
 	Host string `mapstructure:"host"`
+
+	// ReadTimeout, ReadHeaderTimeout, WriteTimeout and IdleTimeout bound how long a connection may take to send
+	// a request, send its headers, receive a response, and wait idle for its next request.
+	ReadTimeout       time.Duration `mapstructure:"read-timeout"`
+	ReadHeaderTimeout time.Duration `mapstructure:"read-header-timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write-timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle-timeout"`
+
+	// ExportTimeout bounds how long a book export may take to be written, in place of the WriteTimeout, as an
+	// export streams every matching book.
+	ExportTimeout time.Duration `mapstructure:"export-timeout"`
+
+	// MaxHeaderBytes is the largest size of the headers of a request.
+	MaxHeaderBytes int `mapstructure:"max-header-bytes"`
+
+	// TLSCert and TLSKey are the PEM certificate chain and private key which the API terminates TLS with. If
+	// both are empty, then the API serves plain HTTP.
+	TLSCert string `mapstructure:"tls-cert"`
+	TLSKey  string `mapstructure:"tls-key"`
+
+	// ClientCA is a PEM certificate authority which client certificates are verified against. If it is set, then
+	// requests which write resources must present a certificate it signed.
+	ClientCA string `mapstructure:"client-ca"`
+
+	// AdminPort is the port, on the same host, of the admin server which serves /metrics and /log/level. If it is
+	// empty, then they are served by the API.
+	AdminPort string `mapstructure:"admin-port"`
+
+	// AdminToken is the bearer token which requests to /log/level must present. If it is empty, then /log/level
+	// is not served.
+	AdminToken string `mapstructure:"admin-token"`
+
+	// StrictQuery rejects requests with query parameters which their route does not know. Otherwise they are
+	// logged and ignored.
+	StrictQuery bool `mapstructure:"strict-query"`
+
+	// SpecValidation validates requests and responses against the OpenAPI spec: "off", "log" or "reject". If it
+	// is empty, then it is "log" in development and "off" in production.
+	SpecValidation string `mapstructure:"spec-validation"`
+
+	// ShutdownTimeout is how long in-flight requests may take to complete once the server is shutting down.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown-timeout"`
+
+	// ShutdownDelay is how long the server keeps accepting connections once it is shutting down, while its
+	// readiness endpoint reports that it is not ready. It is part of the ShutdownTimeout.
+	ShutdownDelay time.Duration `mapstructure:"shutdown-delay"`
+}
+
+type Search struct {
+	DefaultPageSize uint64 `mapstructure:"default-page-size"`
+	MaxPageSize     uint64 `mapstructure:"max-page-size"`
+}
+
+type Store struct {
+	// Type is either "database", which serves from the configured database, or "memory", which serves from memory.
+	// "postgres", its name before the database driver was configurable, is a deprecated alias of "database".
+	Type string `mapstructure:"type"`
+
+	// Fixture is a JSON, YAML or SQL file which seeds the memory store. If it is empty, then the memory
+	// store is seeded with the migrations and seed data of the postgres store.
+	Fixture string `mapstructure:"fixture"`
+}
+
+type Tracing struct {
+	// Exporter is "none", "stdout" or "file". Requests are traced whichever it is, so that they are given trace
+	// IDs and incoming traces are propagated, but only "stdout" and "file" export their spans.
+	Exporter string `mapstructure:"exporter"`
+
+	// File is the file which the "file" exporter appends spans to.
+	File string `mapstructure:"file"`
+
+	// SampleRatio is the fraction of traces which are sampled, unless a request's trace was sampled upstream.
+	SampleRatio float64 `mapstructure:"sample-ratio"`
+}
+
+type Log struct {
+	// Level is the minimum level of the logs: "debug", "info", "warn" or "error". It can be changed at runtime
+	// through /log/level.
+	Level string `mapstructure:"level"`
+
+	// Encoding is either "json" or "console".
+	Encoding string `mapstructure:"encoding"`
+
+	// SamplingInitial and SamplingThereafter sample repetitive logs: of the logs with the same level and message
+	// within a second, the first SamplingInitial are written, and then every SamplingThereafter-th. Sampling is
+	// disabled if SamplingInitial is 0, as it is by default, since every access log has the same message.
+	SamplingInitial    int `mapstructure:"sampling-initial"`
+	SamplingThereafter int `mapstructure:"sampling-thereafter"`
 }
@@ -10,6 +10,21 @@ func Uint64Ptr(i uint64) *uint64 {
 	return &i
 }
 
+// Int32Ptr accepts an int32 and returns a pointer to that int32.
+func Int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// Float32Ptr accepts a float32 and returns a pointer to that float32.
+func Float32Ptr(f float32) *float32 {
+	return &f
+}
+
+// Float64Ptr accepts a float64 and returns a pointer to that float64.
+func Float64Ptr(f float64) *float64 {
+	return &f
+}
+
 // StringPtr accepts a string and returns a pointer to that string.
 func StringPtr(s string) *string {
 	if s == "" {
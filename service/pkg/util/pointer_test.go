@@ -15,6 +15,33 @@ func TestInt16Ptr(t *testing.T) {
 	assert.Equal(t, expected, *actual)
 }
 
+func TestInt32Ptr(t *testing.T) {
+	var expected int32 = 42
+	actual := Int32Ptr(expected)
+	if actual == nil {
+		t.FailNow()
+	}
+	assert.Equal(t, expected, *actual)
+}
+
+func TestFloat32Ptr(t *testing.T) {
+	var expected float32 = 4.3
+	actual := Float32Ptr(expected)
+	if actual == nil {
+		t.FailNow()
+	}
+	assert.Equal(t, expected, *actual)
+}
+
+func TestFloat64Ptr(t *testing.T) {
+	var expected = 3.87
+	actual := Float64Ptr(expected)
+	if actual == nil {
+		t.FailNow()
+	}
+	assert.Equal(t, expected, *actual)
+}
+
 func TestStringPtr(t *testing.T) {
 	var expected = "foobar"
 	actual := StringPtr(expected)